@@ -109,6 +109,16 @@ func GetShopServiceURL() string {
     return os.Getenv("SHOP_SERVICE_URL")
 }
 
+// GetProductServiceURL fetches the product service URL from env vars
+func GetProductServiceURL() string {
+    return os.Getenv("PRODUCT_SERVICE_URL")
+}
+
+// GetInternalAPIKey returns the shared key sent to other services' internal routes
+func GetInternalAPIKey() string {
+    return os.Getenv("API_KEY")
+}
+
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
 	value := os.Getenv(key)
@@ -240,3 +240,88 @@ func (ctrl *AdminController) BlockShop(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Shop blocked"})
 }
+
+func (ctrl *AdminController) UnblockShop(c *gin.Context) {
+	shopID := c.Param("id")
+	if err := ctrl.Service.UnblockShop(shopID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Shop unblocked"})
+}
+
+// Product Moderation
+func getAdminID(c *gin.Context) (uint, error) {
+	userIDRaw, exists := c.Get("userID")
+	if !exists {
+		return 0, fmt.Errorf("no user info found in context")
+	}
+	userIDStr, ok := userIDRaw.(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid userID type in context")
+	}
+	id, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID format")
+	}
+	return uint(id), nil
+}
+
+func (ctrl *AdminController) ProductModerationQueue(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	flaggedOnly := c.Query("flagged") == "true"
+
+	status, body, err := ctrl.Service.ProductModerationQueue(flaggedOnly, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to contact product-service"})
+		return
+	}
+	c.Data(status, "application/json", body)
+}
+
+// moderateProduct forwards a lifecycle decision for the product in the URL to product-service
+func (ctrl *AdminController) moderateProduct(c *gin.Context, status string, reasonRequired bool) {
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if reasonRequired && payload.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	adminID, err := getAdminID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: " + err.Error()})
+		return
+	}
+
+	code, body, err := ctrl.Service.ModerateProduct(c.Param("id"), adminID, status, payload.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to contact product-service"})
+		return
+	}
+	c.Data(code, "application/json", body)
+}
+
+func (ctrl *AdminController) ApproveProduct(c *gin.Context) {
+	ctrl.moderateProduct(c, "active", false)
+}
+
+func (ctrl *AdminController) RejectProduct(c *gin.Context) {
+	ctrl.moderateProduct(c, "draft", true)
+}
+
+func (ctrl *AdminController) SuspendProduct(c *gin.Context) {
+	ctrl.moderateProduct(c, "suspended", true)
+}
+
+func (ctrl *AdminController) ReinstateProduct(c *gin.Context) {
+	ctrl.moderateProduct(c, "active", false)
+}
@@ -26,6 +26,13 @@ func RegisterAdminRoutes(router *gin.Engine, adminController *controllers.AdminC
 
         admins.PATCH("/shop/:id/approve", adminController.ApproveShop)
         admins.PATCH("/shop/:id/block", adminController.BlockShop)
+        admins.PATCH("/shop/:id/unblock", adminController.UnblockShop)
+
+        admins.GET("/products/moderation-queue", adminController.ProductModerationQueue)
+        admins.PATCH("/products/:id/approve", adminController.ApproveProduct)
+        admins.PATCH("/products/:id/reject", adminController.RejectProduct)
+        admins.PATCH("/products/:id/suspend", adminController.SuspendProduct)
+        admins.PATCH("/products/:id/reinstate", adminController.ReinstateProduct)
 
     }
     router.GET("/health", func(c *gin.Context) {
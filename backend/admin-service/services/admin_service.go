@@ -146,20 +146,51 @@ func (s *AdminService) DeleteUser(userID uint) error {
 
 // ApproveShop approves a shop via shop-service
 func (s *AdminService) ApproveShop(shopID string) error {
-	url := fmt.Sprintf("%s/api/shops/%s/approve", config.GetShopServiceURL(), shopID)
-	headers := map[string]string{"Content-Type": "application/json"}
-	_, err := utils.HttpRequest("PATCH", url, nil, headers)
+	url := fmt.Sprintf("%s/internal/shops/%s/approve", config.GetShopServiceURL(), shopID)
+	_, err := utils.HttpRequest("PATCH", url, nil, internalHeaders())
 	return err
 }
 
 // BlockShop blocks a shop via shop-service
 func (s *AdminService) BlockShop(shopID string) error {
-	url := fmt.Sprintf("%s/api/shops/%s/block", config.GetShopServiceURL(), shopID)
-	headers := map[string]string{"Content-Type": "application/json"}
-	_, err := utils.HttpRequest("PATCH", url, nil, headers)
+	url := fmt.Sprintf("%s/internal/shops/%s/block", config.GetShopServiceURL(), shopID)
+	_, err := utils.HttpRequest("PATCH", url, nil, internalHeaders())
 	return err
 }
 
+// UnblockShop lifts a shop block via shop-service
+func (s *AdminService) UnblockShop(shopID string) error {
+	url := fmt.Sprintf("%s/internal/shops/%s/unblock", config.GetShopServiceURL(), shopID)
+	_, err := utils.HttpRequest("PATCH", url, nil, internalHeaders())
+	return err
+}
+
+// internalHeaders are sent on calls to other services' internal routes
+func internalHeaders() map[string]string {
+	return map[string]string{
+		"Content-Type": "application/json",
+		"X-API-Key":    config.GetInternalAPIKey(),
+	}
+}
+
+// ProductModerationQueue fetches products awaiting review from product-service
+func (s *AdminService) ProductModerationQueue(flaggedOnly bool, offset, limit int) (int, []byte, error) {
+	url := fmt.Sprintf("%s/internal/products/moderation/queue?flagged=%t&offset=%d&limit=%d",
+		config.GetProductServiceURL(), flaggedOnly, offset, limit)
+	return utils.HttpRequestWithStatus("GET", url, nil, internalHeaders())
+}
+
+// ModerateProduct moves a product to a new lifecycle status via product-service
+func (s *AdminService) ModerateProduct(productID string, adminID uint, status, reason string) (int, []byte, error) {
+	url := fmt.Sprintf("%s/internal/products/%s/status", config.GetProductServiceURL(), productID)
+	payload := map[string]interface{}{
+		"status":   status,
+		"reason":   reason,
+		"admin_id": adminID,
+	}
+	return utils.HttpRequestWithStatus("POST", url, payload, internalHeaders())
+}
+
 // Dashboard returns mock dashboard data
 func (s *AdminService) Dashboard() map[string]interface{} {
 	return map[string]interface{}{
@@ -8,18 +8,24 @@ import (
 )
 
 func HttpRequest(method, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+    _, body, err := HttpRequestWithStatus(method, url, payload, headers)
+    return body, err
+}
+
+// HttpRequestWithStatus behaves like HttpRequest but also returns the response status code
+func HttpRequestWithStatus(method, url string, payload interface{}, headers map[string]string) (int, []byte, error) {
     var body []byte
     if payload != nil {
         jsonBody, err := json.Marshal(payload)
         if err != nil {
-            return nil, err
+            return 0, nil, err
         }
         body = jsonBody
     }
 
     req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
     if err != nil {
-        return nil, err
+        return 0, nil, err
     }
 
     for k, v := range headers {
@@ -29,9 +35,10 @@ func HttpRequest(method, url string, payload interface{}, headers map[string]str
     client := &http.Client{}
     resp, err := client.Do(req)
     if err != nil {
-        return nil, err
+        return 0, nil, err
     }
     defer resp.Body.Close()
 
-    return ioutil.ReadAll(resp.Body)
+    respBody, err := ioutil.ReadAll(resp.Body)
+    return resp.StatusCode, respBody, err
 }
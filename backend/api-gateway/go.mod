@@ -4,4 +4,4 @@ go 1.24.2
 
 require github.com/gorilla/mux v1.8.1
 
-require github.com/joho/godotenv v1.5.1 // indirect
+require github.com/joho/godotenv v1.5.1
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"gorm.io/driver/postgres"
@@ -12,6 +14,7 @@ import (
 	"order-service/services"
 	"order-service/controllers"
 	"order-service/routes"
+	"shared/idempotency"
 )
 
 func main() {
@@ -25,20 +28,90 @@ func main() {
     }
 
     // Auto migrate Order model
-	if err := db.AutoMigrate(&models.Order{}, &models.OrderItem{}); err != nil {
+	if err := db.AutoMigrate(&models.Order{}, &models.OrderItem{}, &models.SubOrder{}, &models.OrderItemComponent{}, &models.OrderStatusTransition{}, &models.Cart{}, &models.CartItem{}, &models.CheckoutSaga{}, &models.ReturnRequest{}, &models.ReturnItem{}, &idempotency.Record{}, &models.Invoice{}, &models.InvoiceCounter{}, &models.Coupon{}, &models.CouponRedemption{}, &models.JobLock{}); err != nil {
         log.Fatalf("❌ Auto migration failed: %v", err)
     }
 
     // Initialize repository, service, and controller
     orderRepo := repository.NewOrderRepository(db)
-	orderService := services.NewOrderService(orderRepo)
-	orderController := controllers.NewOrderController(orderService)
+	productClient := services.NewProductClient()
+	orderService := services.NewOrderService(orderRepo, productClient)
+	if err := orderService.Pricing.ParseShippingRates(config.GetShippingRates()); err != nil {
+		log.Fatalf("❌ Invalid SHIPPING_RATES: %v", err)
+	}
+	if rate, ok := config.GetTaxRate(); ok {
+		orderService.Pricing.TaxRate = rate
+	}
+
+	// Checkouts run as a saga across product, payment and shipment services;
+	// unfinished ones are resumed in the background
+	sagaRepo := repository.NewCheckoutSagaRepository(db)
+	paymentClient := services.NewPaymentClient()
+	shipmentClient := services.NewShipmentClient()
+	orderService.Payments = paymentClient
+	orderService.Shipments = shipmentClient
+
+	// Coupons are evaluated at checkout and redeemed when the order is saved
+	shopClient := services.NewShopClient()
+	promotionService := services.NewPromotionService(repository.NewCouponRepository(db), shopClient)
+	orderService.Promotions = promotionService
+	couponController := controllers.NewCouponController(promotionService)
+
+	checkoutService := services.NewCheckoutService(sagaRepo, orderService, productClient,
+		paymentClient, shipmentClient)
+	go checkoutService.Run(context.Background(), services.CheckoutResumeInterval)
+
+	cancellationService := services.NewCancellationService(orderService, productClient, paymentClient)
+	orderController := controllers.NewOrderController(orderService, checkoutService, cancellationService)
+
+	// Returns are refunded through payment-service or replaced by a new checkout
+	returnService := services.NewReturnService(repository.NewReturnRepository(db), orderService, paymentClient, checkoutService)
+	returnWindows, err := services.ParseReturnWindows(config.GetReturnWindows())
+	if err != nil {
+		log.Fatalf("❌ Invalid RETURN_WINDOWS: %v", err)
+	}
+	returnService.Policy.CategoryWindows = returnWindows
+	returnController := controllers.NewReturnController(returnService)
+
+	// Invoices and packing slips are generated on first download and kept on disk
+	documentService := services.NewDocumentService(orderService, repository.NewInvoiceRepository(db),
+		shopClient, shipmentClient, services.NewLocalBlobStore(config.GetDocumentStoreDir()))
+	documentController := controllers.NewDocumentController(documentService)
+
+	cartRepo := repository.NewCartRepository(db)
+	cartService := services.NewCartService(cartRepo, productClient, checkoutService)
+	cartController := controllers.NewCartController(cartService)
+
+	// Retried order creations with the same Idempotency-Key get the first response
+	idempotencyService := idempotency.NewService(idempotency.NewRepository(db), config.GetIdempotencyKeyTTL())
+
+	// Unpaid orders are cancelled and delivered ones completed in the background; each
+	// job runs on one instance at a time, whichever holds its lock
+	lifecycleService := services.NewLifecycleService(orderService, productClient, paymentClient, repository.NewReturnRepository(db))
+	lifecycleService.Policy = returnService.Policy
+	if timeout := config.GetUnpaidOrderTimeout(); timeout > 0 {
+		lifecycleService.UnpaidTimeout = timeout
+	}
+	if after := config.GetOrderCompleteAfter(); after > 0 {
+		lifecycleService.CompleteAfter = after
+	}
+	scheduler := services.NewScheduler(repository.NewJobLockRepository(db), lifecycleService.Jobs()...)
+	scheduler.Jobs = append(scheduler.Jobs, services.Job{
+		Name:     "purge-idempotency-keys",
+		Interval: time.Hour,
+		Run: func(context.Context) error {
+			_, err := idempotencyService.PurgeExpired()
+			return err
+		},
+	})
+	go scheduler.Run(context.Background())
 
     // Initialize Gin router
     router := gin.Default()
 
     // Register routes
-    routes.RegisterOrderRoutes(router, orderController)
+    routes.RegisterOrderRoutes(router, orderController, cartController, returnController, documentController,
+		couponController, idempotency.Middleware(idempotencyService))
 
     log.Printf("Starting Order Service on port %s", cfg.Port)
 
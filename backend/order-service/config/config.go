@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -71,7 +73,81 @@ func LoadConfig() Config {
 	}
 }
 
+// GetProductServiceURL fetches the product service URL from env vars
+func GetProductServiceURL() string {
+	return os.Getenv("PRODUCT_SERVICE_URL")
+}
+
+// GetPaymentServiceURL fetches the payment service URL from env vars
+func GetPaymentServiceURL() string {
+	return os.Getenv("PAYMENT_SERVICE_URL")
+}
+
+// GetShipmentServiceURL fetches the shipment service URL from env vars
+func GetShipmentServiceURL() string {
+	return os.Getenv("SHIPMENT_SERVICE_URL")
+}
+
+// GetShopServiceURL fetches the shop service URL from env vars
+func GetShopServiceURL() string {
+	return os.Getenv("SHOP_SERVICE_URL")
+}
+
+// GetDocumentStoreDir fetches where generated invoice and packing-slip PDFs are kept
+func GetDocumentStoreDir() string {
+	return getEnv("DOCUMENT_STORE_DIR", "data/documents")
+}
+
+// GetReturnWindows fetches per-category return windows ("category_id:days,...")
+func GetReturnWindows() string {
+	return os.Getenv("RETURN_WINDOWS")
+}
+
+// GetShippingRates fetches shipping prices overriding the defaults
+// ("method:price[:free_over],...")
+func GetShippingRates() string {
+	return os.Getenv("SHIPPING_RATES")
+}
 
+// GetTaxRate fetches the tax rate charged on order subtotals (e.g. "0.08"); ok is false
+// when it is not set or unreadable
+func GetTaxRate() (rate float64, ok bool) {
+	rate, err := strconv.ParseFloat(os.Getenv("TAX_RATE"), 64)
+	if err != nil || rate < 0 {
+		return 0, false
+	}
+	return rate, true
+}
+
+// GetIdempotencyKeyTTL fetches how long Idempotency-Keys are kept (e.g. "24h"); zero
+// means the default
+func GetIdempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil {
+		return 0
+	}
+	return ttl
+}
+
+// GetUnpaidOrderTimeout fetches how long an order waits for its online payment before
+// it is cancelled (e.g. "24h"); zero means the default
+func GetUnpaidOrderTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("UNPAID_ORDER_TIMEOUT"))
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// GetOrderCompleteAfter fetches how many days after delivery an order completes
+// (ORDER_COMPLETE_AFTER_DAYS); zero means the default
+func GetOrderCompleteAfter() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("ORDER_COMPLETE_AFTER_DAYS"))
+	if err != nil || days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
 
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
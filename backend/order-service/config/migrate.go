@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"order-service/models"
 	"log"
+	"shared/idempotency"
 
 	"gorm.io/gorm"
 )
@@ -13,6 +14,20 @@ func MigrateDB(db *gorm.DB) {
 	err := db.AutoMigrate(
 		&models.Order{},
 		&models.OrderItem{},
+		&models.SubOrder{},
+		&models.OrderItemComponent{},
+		&models.OrderStatusTransition{},
+		&models.Cart{},
+		&models.CartItem{},
+		&models.CheckoutSaga{},
+		&models.ReturnRequest{},
+		&models.ReturnItem{},
+		&idempotency.Record{},
+		&models.Invoice{},
+		&models.InvoiceCounter{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.JobLock{},
 	)
 
 	if err != nil {
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"order-service/models"
+	"order-service/services"
+	"shared/idempotency"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Guest carts are identified by this cookie
+const (
+	cartCookieName   = "cart_token"
+	cartCookieMaxAge = 30 * 24 * 60 * 60 // 30 days
+)
+
+type CartController struct {
+	Service *services.CartService
+}
+
+func NewCartController(service *services.CartService) *CartController {
+	return &CartController{Service: service}
+}
+
+// cartOwner identifies the signed-in buyer, if any, and the guest cart cookie, if any
+func cartOwner(ctx *gin.Context) *services.CartOwner {
+	owner := &services.CartOwner{}
+	if userID, ok := ctx.Get("user_id"); ok {
+		owner.UserID = userID.(uint)
+	}
+	owner.Token, _ = ctx.Cookie(cartCookieName)
+	return owner
+}
+
+// syncCartCookie sets the cookie for a newly created guest cart, or clears it once the
+// guest cart has been merged into the buyer's cart
+func syncCartCookie(ctx *gin.Context, owner *services.CartOwner, previous string) {
+	if owner.Token == previous {
+		return
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	if owner.Token == "" {
+		ctx.SetCookie(cartCookieName, "", -1, "/", "", ctx.Request.TLS != nil, true)
+		return
+	}
+	ctx.SetCookie(cartCookieName, owner.Token, cartCookieMaxAge, "/", "", ctx.Request.TLS != nil, true)
+}
+
+// respondCartError maps cart service errors to HTTP responses
+func respondCartError(ctx *gin.Context, err error) {
+	var productErr *services.ProductServiceError
+	switch {
+	case errors.Is(err, services.ErrInvalidCartQuantity), errors.Is(err, services.ErrCartFull),
+		errors.Is(err, services.ErrCartEmpty), isCheckoutChoiceError(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCartItemNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInsufficientStock):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrProductUnavailable), isCouponError(err):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.As(err, &productErr) && productErr.StatusCode < http.StatusInternalServerError:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": productErr.Message})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+	}
+}
+
+// respondCart writes the cart after a successful change
+func (c *CartController) respondCart(ctx *gin.Context, owner *services.CartOwner, previous string, cart *models.Cart, err error) {
+	syncCartCookie(ctx, owner, previous)
+	if err != nil {
+		respondCartError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, cart)
+}
+
+// GET /api/cart
+func (c *CartController) GetCart(ctx *gin.Context) {
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	cart, err := c.Service.GetCart(owner)
+	c.respondCart(ctx, owner, previous, cart, err)
+}
+
+// POST /api/cart/items
+func (c *CartController) AddItem(ctx *gin.Context) {
+	var payload struct {
+		ProductID uint `json:"product_id" binding:"required"`
+		Quantity  int  `json:"quantity"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload.Quantity == 0 {
+		payload.Quantity = 1
+	}
+
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	cart, err := c.Service.AddItem(owner, payload.ProductID, payload.Quantity)
+	c.respondCart(ctx, owner, previous, cart, err)
+}
+
+// PUT /api/cart/items/:product_id
+func (c *CartController) UpdateItem(ctx *gin.Context) {
+	productID, err := strconv.ParseUint(ctx.Param("product_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	var payload struct {
+		Quantity *int `json:"quantity" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	cart, err := c.Service.UpdateItem(owner, uint(productID), *payload.Quantity)
+	c.respondCart(ctx, owner, previous, cart, err)
+}
+
+// DELETE /api/cart/items/:product_id
+func (c *CartController) RemoveItem(ctx *gin.Context) {
+	productID, err := strconv.ParseUint(ctx.Param("product_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	cart, err := c.Service.RemoveItem(owner, uint(productID))
+	c.respondCart(ctx, owner, previous, cart, err)
+}
+
+// POST /api/cart/merge
+// Called after login to move the guest cart into the buyer's cart
+func (c *CartController) Merge(ctx *gin.Context) {
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	owner.Token = ""
+	if err := c.Service.Merge(owner.UserID, previous); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge cart"})
+		return
+	}
+	cart, err := c.Service.GetCart(owner)
+	c.respondCart(ctx, owner, previous, cart, err)
+}
+
+// POST /api/cart/checkout
+// Body (optional): {"shipping_method", "payment_method", "coupon_code", "total_amount", ...quoted breakdown}
+func (c *CartController) Checkout(ctx *gin.Context) {
+	var payload models.Order
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	options := services.CheckoutOptions{
+		ShippingMethod: payload.ShippingMethod,
+		PaymentMethod:  payload.PaymentMethod,
+		CouponCode:     payload.CouponCode,
+		Quote:          services.QuotedTotals(&payload),
+	}
+
+	owner := cartOwner(ctx)
+	previous := owner.Token
+	if err := c.Service.Merge(owner.UserID, previous); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge cart"})
+		return
+	}
+	owner.Token = ""
+	syncCartCookie(ctx, owner, previous)
+
+	order, cart, err := c.Service.Checkout(owner.UserID, options)
+	if order != nil && order.ID != 0 {
+		// A retry must not place the order again, whatever this request answers
+		idempotency.MarkPersisted(ctx)
+	}
+	if errors.Is(err, services.ErrCartNeedsReview) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "cart": cart})
+		return
+	}
+	if errors.Is(err, services.ErrTotalsMismatch) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "cart": cart, "totals": orderTotals(order)})
+		return
+	}
+	if err != nil {
+		respondCartError(ctx, err)
+		return
+	}
+	respondPlacedOrder(ctx, order)
+}
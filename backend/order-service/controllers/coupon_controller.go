@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"order-service/models"
+	"order-service/repository"
+	"order-service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CouponController struct {
+	Service *services.PromotionService
+}
+
+func NewCouponController(service *services.PromotionService) *CouponController {
+	return &CouponController{Service: service}
+}
+
+// respondCouponError maps coupon management errors to HTTP responses
+func respondCouponError(ctx *gin.Context, err error, fallback string) {
+	var serviceErr *services.ServiceError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Coupon not found"})
+	case errors.Is(err, services.ErrCouponForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCoupon):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCouponCodeTaken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// isCouponError reports whether err refuses the coupon a buyer entered
+func isCouponError(err error) bool {
+	return errors.Is(err, services.ErrCouponNotFound) ||
+		errors.Is(err, services.ErrCouponNotActive) ||
+		errors.Is(err, services.ErrCouponNotApplicable) ||
+		errors.Is(err, services.ErrCouponMinimum) ||
+		errors.Is(err, repository.ErrCouponExhausted) ||
+		errors.Is(err, repository.ErrCouponUserLimit)
+}
+
+// POST /api/coupons
+func (c *CouponController) CreateCoupon(ctx *gin.Context) {
+	var coupon models.Coupon
+	if err := ctx.ShouldBindJSON(&coupon); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.Service.CreateCoupon(&coupon, requestActor(ctx)); err != nil {
+		respondCouponError(ctx, err, "Failed to create coupon")
+		return
+	}
+	ctx.JSON(http.StatusCreated, coupon)
+}
+
+// GET /api/coupons?shop_id=
+func (c *CouponController) ListCoupons(ctx *gin.Context) {
+	shopID, err := parseQueryUint(ctx.Query("shop_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shop_id"})
+		return
+	}
+	coupons, err := c.Service.ListCoupons(shopID, requestActor(ctx))
+	if err != nil {
+		respondCouponError(ctx, err, "Failed to fetch coupons")
+		return
+	}
+	ctx.JSON(http.StatusOK, coupons)
+}
+
+// GET /api/coupons/:id
+func (c *CouponController) GetCoupon(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+	coupon, err := c.Service.GetCoupon(uint(id), requestActor(ctx))
+	if err != nil {
+		respondCouponError(ctx, err, "Failed to fetch coupon")
+		return
+	}
+	ctx.JSON(http.StatusOK, coupon)
+}
+
+// PUT /api/coupons/:id
+func (c *CouponController) UpdateCoupon(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+	var changes models.Coupon
+	if err := ctx.ShouldBindJSON(&changes); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	coupon, err := c.Service.UpdateCoupon(uint(id), &changes, requestActor(ctx))
+	if err != nil {
+		respondCouponError(ctx, err, "Failed to update coupon")
+		return
+	}
+	ctx.JSON(http.StatusOK, coupon)
+}
+
+// DELETE /api/coupons/:id
+func (c *CouponController) DeactivateCoupon(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coupon ID"})
+		return
+	}
+	coupon, err := c.Service.DeactivateCoupon(uint(id), requestActor(ctx))
+	if err != nil {
+		respondCouponError(ctx, err, "Failed to deactivate coupon")
+		return
+	}
+	ctx.JSON(http.StatusOK, coupon)
+}
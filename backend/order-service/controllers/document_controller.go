@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"order-service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DocumentController struct {
+	Service *services.DocumentService
+}
+
+func NewDocumentController(service *services.DocumentService) *DocumentController {
+	return &DocumentController{Service: service}
+}
+
+// respondDocumentError maps invoice and packing-slip errors to HTTP responses
+func respondDocumentError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrNotOrderBuyer),
+		errors.Is(err, services.ErrNotOrderSeller):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Sub-order not found"})
+	case errors.Is(err, services.ErrOrderActionForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvoiceNotReady), errors.Is(err, services.ErrNothingToPack):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrShopDetailsMissing):
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate document"})
+	}
+}
+
+// GET /api/orders/sub-orders/:id/invoice
+func (c *DocumentController) GetInvoice(ctx *gin.Context) {
+	subOrderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sub-order ID"})
+		return
+	}
+
+	invoice, pdf, err := c.Service.InvoicePDF(uint(subOrderID), requestActor(ctx))
+	if err != nil {
+		respondDocumentError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="`+invoice.Number+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GET /api/orders/sub-orders/:id/packing-slip
+func (c *DocumentController) GetPackingSlip(ctx *gin.Context) {
+	subOrderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sub-order ID"})
+		return
+	}
+
+	pdf, err := c.Service.PackingSlipPDF(uint(subOrderID), requestActor(ctx))
+	if err != nil {
+		respondDocumentError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="packing-slip-%d.pdf"`, subOrderID))
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
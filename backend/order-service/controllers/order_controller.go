@@ -1,20 +1,53 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"order-service/models"
+	"order-service/repository"
 	"order-service/services"
+	"shared/idempotency"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type OrderController struct {
-	Service *services.OrderService
+	Service       *services.OrderService
+	Checkout      *services.CheckoutService
+	Cancellations *services.CancellationService
 }
 
-func NewOrderController(service *services.OrderService) *OrderController {
-	return &OrderController{Service: service}
+func NewOrderController(service *services.OrderService, checkout *services.CheckoutService, cancellations *services.CancellationService) *OrderController {
+	return &OrderController{Service: service, Checkout: checkout, Cancellations: cancellations}
+}
+
+// respondPlacedOrder reports a new order according to how far its checkout got: placed,
+// still being processed, or rolled back
+func respondPlacedOrder(ctx *gin.Context, order *models.Order) {
+	switch {
+	case order.Checkout == nil || order.Checkout.Status == models.SagaStatusCompleted:
+		ctx.JSON(http.StatusCreated, gin.H{
+			"message": "Order placed successfully",
+			"order":   order,
+		})
+	case order.Checkout.Status == models.SagaStatusFailed:
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "Checkout failed: " + order.Checkout.FailureReason,
+			"order": order,
+		})
+	default:
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"message": "Order received and is being processed",
+			"order":   order,
+		})
+	}
 }
 
 // POST /api/orders
@@ -32,43 +65,293 @@ func (c *OrderController) CreateOrder(ctx *gin.Context) {
 		return
 	}
 	order.BuyerID = buyerID.(uint)
-	order.Status = "pending"
 
-	if err := c.Service.CreateOrder(&order); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+	err := c.Checkout.PlaceOrder(&order, services.QuotedTotals(&order))
+	if order.ID != 0 {
+		// A retry must not place the order again, whatever this request answers
+		idempotency.MarkPersisted(ctx)
+	}
+	if err != nil {
+		var productErr *services.ProductServiceError
+		switch {
+		case errors.Is(err, services.ErrEmptyOrder), isCheckoutChoiceError(err):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTotalsMismatch):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "totals": orderTotals(&order)})
+		case errors.Is(err, services.ErrProductUnavailable), isCouponError(err):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.As(err, &productErr) && productErr.StatusCode < http.StatusInternalServerError:
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": productErr.Message})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{
-		"message": "Order placed successfully",
-		"order":   order,
-	})
+	respondPlacedOrder(ctx, &order)
+}
+
+// POST /api/orders/quote
+// Body: {"order_items", "shipping_method", "payment_method", "coupon_code"}, as for placing the order
+func (c *OrderController) QuoteOrder(ctx *gin.Context) {
+	var order models.Order
+	if err := ctx.ShouldBindJSON(&order); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.BuyerID = ctx.GetUint("user_id")
+
+	totals, err := c.Service.Quote(&order)
+	if err != nil {
+		var productErr *services.ProductServiceError
+		switch {
+		case errors.Is(err, services.ErrEmptyOrder), isCheckoutChoiceError(err):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrProductUnavailable), isCouponError(err):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.As(err, &productErr) && productErr.StatusCode < http.StatusInternalServerError:
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": productErr.Message})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price order"})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"coupon_code": order.CouponCode, "totals": totals})
+}
+
+// isCheckoutChoiceError reports whether err rejects the buyer's shipping or payment choice
+func isCheckoutChoiceError(err error) bool {
+	return errors.Is(err, services.ErrUnknownShippingMethod) ||
+		errors.Is(err, services.ErrUnknownPaymentMethod) ||
+		errors.Is(err, services.ErrCODUnavailable)
 }
 
-// GET /api/orders/buyer
+// orderTotals is the price breakdown stored on an order
+func orderTotals(order *models.Order) services.OrderTotals {
+	return services.OrderTotals{
+		Subtotal:         order.Subtotal,
+		ShippingFee:      order.ShippingFee,
+		Tax:              order.Tax,
+		CODFee:           order.CODFee,
+		Discount:         order.Discount,
+		ShippingDiscount: order.ShippingDiscount,
+		Total:            order.TotalAmount,
+	}
+}
+
+// GET /api/orders/buyer?status=&from=&to=&min_total=&max_total=&payment_method=&product_id=&sort=&order=&limit=&cursor=
 func (c *OrderController) GetBuyerOrders(ctx *gin.Context) {
-	userID := ctx.MustGet("user_id").(uint)
+	filter, err := orderFilterFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	orders, err := c.Service.GetOrdersByBuyer(userID)
+	page, err := c.Service.SearchBuyerOrders(requestActor(ctx), filter, ctx.Query("cursor"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch buyer orders"})
+		respondListError(ctx, err, "Failed to fetch buyer orders")
 		return
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	ctx.JSON(http.StatusOK, page)
 }
 
-// GET /api/orders/seller
+// GET /api/orders/seller — same filters as the buyer listing, one entry per sub-order
 func (c *OrderController) GetSellerOrders(ctx *gin.Context) {
-	userID := ctx.MustGet("user_id").(uint)
+	filter, err := orderFilterFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	orders, err := c.Service.GetOrdersBySeller(userID)
+	page, err := c.Service.SearchSellerOrders(requestActor(ctx), filter, ctx.Query("cursor"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seller orders"})
+		respondListError(ctx, err, "Failed to fetch seller orders")
 		return
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GET /api/orders — every order, for admins; buyer_id narrows it to one buyer
+func (c *OrderController) ListOrders(ctx *gin.Context) {
+	filter, err := orderFilterFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := c.Service.SearchAllOrders(requestActor(ctx), filter, ctx.Query("cursor"))
+	if err != nil {
+		respondListError(ctx, err, "Failed to fetch orders")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GET /api/orders/seller/export?from=2026-01-01&to=2026-04-01
+// CSV of the seller's sub-orders for accounting; admins may pass seller_id
+func (c *OrderController) ExportSellerOrders(ctx *gin.Context) {
+	from, errFrom := parseQueryTime(ctx.Query("from"))
+	to, errTo := parseQueryTime(ctx.Query("to"))
+	if errFrom != nil || errTo != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be dates (2006-01-02) or RFC3339 times"})
+		return
+	}
+	sellerID, err := parseQueryUint(ctx.Query("seller_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seller_id"})
+		return
+	}
+
+	filename := fmt.Sprintf("orders-%s-%s.csv", from.Format("20060102"), to.Format("20060102"))
+	out := &csvDownload{ctx: ctx, filename: filename}
+	if err := c.Service.ExportSellerOrders(requestActor(ctx), sellerID, from, to, out); err != nil {
+		if out.started {
+			// Too late for an error response; the client gets a cut-off file
+			log.Printf("⚠️ Order export for %s stopped part way: %v", filename, err)
+			return
+		}
+		respondListError(ctx, err, "Failed to export orders")
+	}
+}
+
+// csvDownload streams a CSV file to the client. The download starts with the first
+// write, so an export refused before writing anything can still answer with JSON.
+type csvDownload struct {
+	ctx      *gin.Context
+	filename string
+	started  bool
+}
+
+func (w *csvDownload) Write(data []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		w.ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		w.ctx.Status(http.StatusOK)
+	}
+	n, err := w.ctx.Writer.Write(data)
+	w.ctx.Writer.Flush()
+	return n, err
+}
+
+// orderFilterFromQuery reads the listing filters shared by the order listings
+func orderFilterFromQuery(ctx *gin.Context) (repository.OrderFilter, error) {
+	var filter repository.OrderFilter
+	var err error
+
+	if raw := ctx.Query("status"); raw != "" {
+		for _, status := range strings.Split(raw, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+	if filter.CreatedFrom, err = parseQueryTime(ctx.Query("from")); err != nil {
+		return filter, errors.New("from must be a date (2006-01-02) or an RFC3339 time")
+	}
+	if filter.CreatedTo, err = parseQueryTime(ctx.Query("to")); err != nil {
+		return filter, errors.New("to must be a date (2006-01-02) or an RFC3339 time")
+	}
+	if filter.MinTotal, err = parseQueryAmount(ctx.Query("min_total")); err != nil {
+		return filter, errors.New("min_total must be a number")
+	}
+	if filter.MaxTotal, err = parseQueryAmount(ctx.Query("max_total")); err != nil {
+		return filter, errors.New("max_total must be a number")
+	}
+	if filter.ProductID, err = parseQueryUint(ctx.Query("product_id")); err != nil {
+		return filter, errors.New("product_id must be a number")
+	}
+	if filter.BuyerID, err = parseQueryUint(ctx.Query("buyer_id")); err != nil {
+		return filter, errors.New("buyer_id must be a number")
+	}
+	if filter.SellerID, err = parseQueryUint(ctx.Query("seller_id")); err != nil {
+		return filter, errors.New("seller_id must be a number")
+	}
+	filter.PaymentMethod = ctx.Query("payment_method")
+	filter.SortBy = ctx.DefaultQuery("sort", repository.SortByCreatedAt)
+
+	switch ctx.DefaultQuery("order", "desc") {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+	if raw := ctx.Query("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit < 1 {
+			return filter, errors.New("limit must be a positive number")
+		}
+	}
+	return filter, nil
+}
+
+// parseQueryTime accepts a plain date or an RFC3339 time; empty means no bound
+func parseQueryTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.Parse("2006-01-02", raw); err == nil {
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func parseQueryAmount(raw string) (*float64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+func parseQueryUint(raw string) (uint, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 32)
+	return uint(value), err
+}
+
+// respondListError maps a refused order listing or export to an HTTP response
+func respondListError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrderActionForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidOrderCursor), errors.Is(err, services.ErrInvalidOrderSort),
+		errors.Is(err, services.ErrExportRange):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// requestActor identifies the signed-in user making a request, with every role their
+// token grants
+func requestActor(ctx *gin.Context) services.Actor {
+	return services.Actor{ID: ctx.GetUint("user_id"), Role: ctx.GetString("role"), Roles: ctx.GetStringSlice("roles")}
+}
+
+// respondTransitionError maps a refused status change to an HTTP response
+func respondTransitionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrNotOrderBuyer),
+		errors.Is(err, services.ErrNotOrderSeller):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, services.ErrTransitionForbidden), errors.Is(err, services.ErrOrderActionForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIllegalTransition), errors.Is(err, repository.ErrStatusChanged):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCancelThroughCancellation):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
+	}
 }
 
 // PUT /api/orders/:id/ship
@@ -80,23 +363,305 @@ func (c *OrderController) MarkOrderShipped(ctx *gin.Context) {
 		return
 	}
 
-	// Ensure only sellers can mark orders as shipped (role check optional)
-	role := ctx.MustGet("role").(string)
-	if role != "seller" {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only sellers can mark orders as shipped"})
+	if err := c.Service.MarkAsShipped(uint(orderID), requestActor(ctx)); err != nil {
+		respondTransitionError(ctx, err)
 		return
 	}
 
-	if err := c.Service.MarkAsShipped(uint(orderID)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark order as shipped"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Order marked as shipped"})
+}
+
+// PUT /api/orders/:id/status
+// Body: {"status": "processing", "reason": "optional note"}
+func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Order marked as shipped"})
+	order, err := c.Service.TransitionOrder(uint(orderID), payload.Status, requestActor(ctx), payload.Reason)
+	if err != nil {
+		respondTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Order status updated", "order": order})
 }
 
+// POST /api/orders/:id/cancel
+// Body: {"reason": "..."}; sellers and admins must give a reason
+func (c *OrderController) CancelOrder(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := c.Cancellations.CancelOrder(uint(orderID), requestActor(ctx), payload.Reason)
+	switch {
+	case errors.Is(err, services.ErrCancelReasonRequired):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCancelSettlement):
+		// The cancellation stands; asking again retries the refund and stock release
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "order": order})
+	case err != nil:
+		respondTransitionError(ctx, err)
+	default:
+		ctx.JSON(http.StatusOK, gin.H{"message": "Order cancelled", "order": order})
+	}
+}
 
+// PUT /api/orders/sub-orders/:id/status
+// Sellers move their own part of a split order, e.g. {"status": "shipped"}
+func (c *OrderController) UpdateSubOrderStatus(ctx *gin.Context) {
+	c.updateSubOrderStatus(ctx, requestActor(ctx))
+}
+
+// POST /internal/orders/sub-orders/:id/status
+// Lets other services report progress of one shop's shipment
+func (c *OrderController) UpdateSubOrderStatusInternal(ctx *gin.Context) {
+	c.updateSubOrderStatus(ctx, services.SystemActor)
+}
+
+func (c *OrderController) updateSubOrderStatus(ctx *gin.Context, actor services.Actor) {
+	subOrderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sub-order ID"})
+		return
+	}
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subOrder, err := c.Service.TransitionSubOrder(uint(subOrderID), payload.Status, actor, payload.Reason)
+	if err != nil {
+		respondTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sub-order status updated", "sub_order": subOrder})
+}
+
+// GET /api/orders/:id/history
+func (c *OrderController) GetOrderHistory(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, actor, err := c.Service.OrderHistory(uint(orderID), requestActor(ctx))
+	if err != nil {
+		respondTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"status":        order.Status,
+		"history":       order.History,
+		"next_statuses": services.NextStatuses(order.Status, actor.Role),
+	})
+}
+
+// GET /api/orders/:id
+// Items, history, payment and shipments. Buyers see their own orders, sellers their own sub-orders of one, admins any order
+func (c *OrderController) GetMyOrder(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	detail, err := c.Service.GetOrderDetail(uint(orderID), requestActor(ctx))
+	if err != nil {
+		respondTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, detail)
+}
+
+// GET /internal/orders/:id
+func (c *OrderController) GetOrder(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := c.Service.GetOrder(uint(orderID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order"})
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+// POST /internal/orders/:id/paid
+// Called by payment-service once the order's payment completes
+func (c *OrderController) MarkOrderPaid(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := c.Service.MarkPaid(uint(orderID))
+	if err != nil {
+		var productErr *services.ProductServiceError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, services.ErrOrderNotPayable):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.As(err, &productErr):
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to deliver digital items: " + productErr.Message})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark order as paid"})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Order marked as paid", "order": order})
+}
+
+// POST /internal/orders/:id/status
+// Lets other services (e.g. shipment-service) report delivery progress
+func (c *OrderController) UpdateOrderStatusInternal(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := c.Service.TransitionOrder(uint(orderID), payload.Status, services.SystemActor, payload.Reason)
+	if err != nil {
+		respondTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+// GET /internal/orders/delivered-items?buyer_id=&product_id=
+func (c *OrderController) GetDeliveredItem(ctx *gin.Context) {
+	buyerID, err := strconv.ParseUint(ctx.Query("buyer_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid buyer_id"})
+		return
+	}
+	productID, err := strconv.ParseUint(ctx.Query("product_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product_id"})
+		return
+	}
+
+	item, err := c.Service.GetDeliveredItem(uint(buyerID), uint(productID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No delivered order item found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up order items"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"order_id":      item.OrderID,
+		"order_item_id": item.ID,
+		"product_id":    item.ProductID,
+	})
+}
+
+// feedPage reads the since, after_id and limit query parameters of the internal feeds,
+// responding with 400 if any is invalid
+func feedPage(ctx *gin.Context) (since time.Time, afterID uint, limit int, ok bool) {
+	if raw := ctx.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since, expected RFC3339"})
+			return
+		}
+		since = parsed
+	}
+	after, err := strconv.ParseUint(ctx.DefaultQuery("after_id", "0"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after_id"})
+		return
+	}
+	limit, err = strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	return since, uint(after), limit, true
+}
+
+// ListCompletedItems is used by product-service to build co-purchase recommendations
+func (c *OrderController) ListCompletedItems(ctx *gin.Context) {
+	since, afterID, limit, ok := feedPage(ctx)
+	if !ok {
+		return
+	}
+
+	items, err := c.Service.ListCompletedItems(since, afterID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list completed items"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// GET /internal/orders/settleable?since=&after_id=&limit=
+// Completed sub-orders whose sellers can be paid, for settlement to page through by id
+func (c *OrderController) ListSettleable(ctx *gin.Context) {
+	since, afterID, limit, ok := feedPage(ctx)
+	if !ok {
+		return
+	}
+
+	subOrders, err := c.Service.ListSettleable(since, afterID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list settleable sub-orders"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"sub_orders": subOrders})
+}
+
+// DELETE /api/orders/:id
+// Admins only; buyers and sellers cancel orders instead
 func (oc *OrderController) DeleteOrder(c *gin.Context) {
+    if _, err := services.Authorize(requestActor(c), services.ActionDeleteOrder, nil); err != nil {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can delete orders"})
+        return
+    }
     id := c.Param("id")
     err := oc.Service.DeleteOrder(id)
     if err != nil {
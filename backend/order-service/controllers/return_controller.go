@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"order-service/repository"
+	"order-service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReturnController struct {
+	Service *services.ReturnService
+}
+
+func NewReturnController(service *services.ReturnService) *ReturnController {
+	return &ReturnController{Service: service}
+}
+
+// respondReturnError maps return service errors to HTTP responses
+func respondReturnError(ctx *gin.Context, err error) {
+	var serviceErr *services.ServiceError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrNotReturnParty),
+		errors.Is(err, services.ErrNotOrderBuyer):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Return request not found"})
+	case errors.Is(err, services.ErrReturnItemsRequired), errors.Is(err, services.ErrInvalidReturnResolution),
+		errors.Is(err, services.ErrInvalidReturnEvidence), errors.Is(err, services.ErrReturnNoteRequired),
+		errors.Is(err, services.ErrReturnMixedShops):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotReturnable), errors.Is(err, services.ErrNotDelivered),
+		errors.Is(err, services.ErrReturnWindowClosed), errors.Is(err, services.ErrReturnQuantity):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReturnStatus), errors.Is(err, repository.ErrReturnChanged):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &serviceErr):
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process return"})
+	}
+}
+
+func returnID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid return ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// POST /api/orders/:id/returns
+// Body: {"items": [{"order_item_id", "quantity"}], "reason", "resolution"?, "evidence"?}
+func (c *ReturnController) RequestReturn(ctx *gin.Context) {
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	var input services.ReturnInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := c.Service.RequestReturn(uint(orderID), requestActor(ctx), input)
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Return requested", "return": request})
+}
+
+// GET /api/returns
+func (c *ReturnController) ListReturns(ctx *gin.Context) {
+	requests, err := c.Service.ListReturns(requestActor(ctx))
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"returns": requests})
+}
+
+// GET /api/returns/:id
+func (c *ReturnController) GetReturn(ctx *gin.Context) {
+	id, ok := returnID(ctx)
+	if !ok {
+		return
+	}
+	request, err := c.Service.GetReturn(id, requestActor(ctx))
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, request)
+}
+
+// POST /api/returns/:id/review
+// Body: {"approve": true} or {"approve": false, "note": "why not"}
+func (c *ReturnController) ReviewReturn(ctx *gin.Context) {
+	id, ok := returnID(ctx)
+	if !ok {
+		return
+	}
+	var payload struct {
+		Approve *bool  `json:"approve" binding:"required"`
+		Note    string `json:"note"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := c.Service.ReviewReturn(id, requestActor(ctx), *payload.Approve, payload.Note)
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Return reviewed", "return": request})
+}
+
+// POST /api/returns/:id/ship
+// Body: {"tracking_code": "..."}
+func (c *ReturnController) ShipReturn(ctx *gin.Context) {
+	id, ok := returnID(ctx)
+	if !ok {
+		return
+	}
+	var payload struct {
+		TrackingCode string `json:"tracking_code" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := c.Service.ShipReturn(id, requestActor(ctx), payload.TrackingCode)
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Return on its way back", "return": request})
+}
+
+// POST /api/returns/:id/inspect
+// Body: {"passed": true, "note": "optional"}
+func (c *ReturnController) InspectReturn(ctx *gin.Context) {
+	id, ok := returnID(ctx)
+	if !ok {
+		return
+	}
+	var payload struct {
+		Passed *bool  `json:"passed" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request, err := c.Service.InspectReturn(id, requestActor(ctx), *payload.Passed, payload.Note)
+	if err != nil {
+		respondReturnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Return inspected", "return": request})
+}
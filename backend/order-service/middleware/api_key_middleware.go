@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAPIKey guards internal service-to-service routes with the shared API_KEY
+func RequireAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("API_KEY")
+		if expected == "" {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "API_KEY not set"})
+			return
+		}
+
+		provided := c.GetHeader("X-API-Key")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,7 +3,6 @@ package middleware
 import (
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -44,22 +43,36 @@ func RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Extract and set claims in context
-		userID, err := strconv.Atoi(claims["user_id"].(string))
-		if err != nil {
+		// Extract and set claims in context; user_id is always a uint
+		userID, ok := claimUserID(claims)
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id"})
 			return
 		}
 
-		role, ok := claims["role"].(string)
+		roles, ok := claimRoles(claims)
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing role in token"})
 			return
 		}
 
-		c.Set("user_id", uint(userID))
-		c.Set("role", role)
+		c.Set("user_id", userID)
+		c.Set("role", roles[0])
+		c.Set("roles", roles)
 
 		c.Next()
 	}
 }
+
+// OptionalAuth authenticates requests that carry a bearer token and lets anonymous
+// ones through; a token that is present but invalid is still rejected
+func OptionalAuth() gin.HandlerFunc {
+	requireAuth := RequireAuth()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		requireAuth(c)
+	}
+}
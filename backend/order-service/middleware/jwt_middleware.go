@@ -1,35 +1,65 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
+	"math"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// JWTAuth is the old name of RequireAuth
+//
+// Deprecated: use RequireAuth.
 func JWTAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing token"})
-			return
-		}
+	return RequireAuth()
+}
 
-		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+// claimUserID reads the user's id from a token. auth-service signs it as a numeric
+// "id"; older tokens carry "user_id", as a number or a string.
+func claimUserID(claims jwt.MapClaims) (uint, bool) {
+	if id, ok := claimUint(claims["id"]); ok && id != 0 {
+		return id, true
+	}
+	id, ok := claimUint(claims["user_id"])
+	return id, ok && id != 0
+}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			uid, _ := strconv.Atoi(claims["user_id"].(string))
-			c.Set("user_id", uint(uid))
-			c.Set("role", claims["role"])
-			c.Next()
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": err.Error()})
+// claimRoles reads the user's roles from a token: its "roles" list and, on older
+// tokens, a "role" string. Authorization picks among them, so none is dropped.
+func claimRoles(claims jwt.MapClaims) ([]string, bool) {
+	var roles []string
+	seen := map[string]bool{}
+	add := func(value interface{}) {
+		if role, ok := value.(string); ok && role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	add(claims["role"])
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, value := range list {
+			add(value)
+		}
+	}
+	return roles, len(roles) > 0
+}
+
+// claimUint reads a whole, non-negative number that JSON decoding left as a float64,
+// or that was signed as a string
+func claimUint(value interface{}) (uint, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 || v > math.MaxUint32 || v != math.Trunc(v) {
+			return 0, false
+		}
+		return uint(v), true
+	case string:
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return 0, false
 		}
+		return uint(n), true
 	}
+	return 0, false
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Problems found when a cart item is revalidated against product-service
+const (
+	CartIssueUnavailable       = "unavailable"        // no longer sold
+	CartIssueInsufficientStock = "insufficient_stock" // fewer in stock than in the cart
+)
+
+// Cart holds what a shopper intends to buy. A signed-in buyer's cart is keyed by
+// UserID; a guest cart by the random Token kept in the shopper's cart cookie.
+type Cart struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    *uint      `json:"user_id,omitempty" gorm:"uniqueIndex"`
+	Token     *string    `json:"-" gorm:"uniqueIndex;size:64"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Items     []CartItem `json:"items" gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE"`
+
+	// Filled in when the cart is revalidated; never persisted
+	Total     float64 `json:"total" gorm:"-"`
+	HasIssues bool    `json:"has_issues" gorm:"-"`
+}
+
+// CartItem is one product in a cart. SavedPrice is the unit price the shopper last saw.
+type CartItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CartID     uint      `json:"-" gorm:"uniqueIndex:idx_cart_items_cart_product"`
+	ProductID  uint      `json:"product_id" gorm:"uniqueIndex:idx_cart_items_cart_product"` // foreign key from product-service
+	Quantity   int       `json:"quantity"`
+	SavedPrice float64   `json:"saved_price"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Current catalogue state from product-service; never persisted
+	ProductName  string  `json:"product_name" gorm:"-"`
+	UnitPrice    float64 `json:"unit_price" gorm:"-"`
+	Subtotal     float64 `json:"subtotal" gorm:"-"`
+	InStock      int     `json:"in_stock" gorm:"-"`
+	PriceChanged bool    `json:"price_changed" gorm:"-"`
+	Issue        string  `json:"issue,omitempty" gorm:"-"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Checkout saga statuses
+const (
+	SagaStatusRunning      = "running"
+	SagaStatusCompensating = "compensating" // undoing completed steps after a failure
+	SagaStatusCompleted    = "completed"
+	SagaStatusFailed       = "failed" // compensated; the order is cancelled
+)
+
+// Checkout saga steps, in the order they run
+const (
+	SagaStepReserveStock    = "reserve_stock"
+	SagaStepCreatePayment   = "create_payment"
+	SagaStepConfirmOrder    = "confirm_order"
+	SagaStepRequestShipment = "request_shipment"
+	SagaStepDone            = "done"
+)
+
+// CheckoutSaga tracks an order's checkout across product, payment and shipment
+// services so that an interrupted checkout can be resumed or undone
+type CheckoutSaga struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	OrderID       uint      `json:"order_id" gorm:"uniqueIndex"`
+	Status        string    `json:"status" gorm:"index"`
+	Step          string    `json:"step"` // next step to run while running
+	PaymentID     uint      `json:"payment_id,omitempty"`
+	ShipmentID    uint      `json:"shipment_id,omitempty"` // orders without sub-orders; split orders keep theirs per sub-order
+	Attempts      int       `json:"attempts"`              // failed tries of the current step
+	LastError     string    `json:"last_error,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"` // why the checkout was undone
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
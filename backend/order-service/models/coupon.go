@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Kinds of coupon
+const (
+	CouponTypePercentage   = "percentage"    // Value percent off the eligible items, up to MaxDiscount
+	CouponTypeFixed        = "fixed"         // Value off the eligible items
+	CouponTypeFreeShipping = "free_shipping" // no shipping for the shops with eligible items
+)
+
+// Coupon is a discount code buyers enter at checkout. A shop's coupon only discounts
+// that shop's items and is run by its owner; one without a shop is platform-wide and
+// run by admins. Product and category lists narrow down which items it applies to.
+type Coupon struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Code          string     `json:"code" gorm:"uniqueIndex"` // upper-case
+	ShopID        uint       `json:"shop_id" gorm:"index"`    // 0 for platform-wide
+	Type          string     `json:"type"`
+	Value         float64    `json:"value"`           // percent for percentage coupons, amount for fixed ones
+	MaxDiscount   float64    `json:"max_discount"`    // cap on a percentage discount; 0 for none
+	MinOrderValue float64    `json:"min_order_value"` // of the items the coupon applies to
+	UsageLimit    int        `json:"usage_limit"`     // uses in total; 0 for unlimited
+	PerUserLimit  int        `json:"per_user_limit"`  // uses per buyer; 0 for unlimited
+	UsedCount     int        `json:"used_count"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`                   // nil for no end
+	ProductIDs    []uint     `json:"product_ids" gorm:"serializer:json"`  // empty for every product
+	CategoryIDs   []uint     `json:"category_ids" gorm:"serializer:json"` // empty for every category
+	Active        bool       `json:"active"`
+	CreatedBy     uint       `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// CouponRedemption is one order's use of a coupon. Cancelling the order releases it,
+// giving the use back to the coupon and the buyer.
+type CouponRedemption struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CouponID   uint       `json:"coupon_id" gorm:"index"`
+	OrderID    uint       `json:"order_id" gorm:"uniqueIndex"`
+	UserID     uint       `json:"user_id" gorm:"index"`
+	Discount   float64    `json:"discount"` // on items and shipping together
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Invoice is the tax invoice for one shop's part of an order. Numbers run on without
+// gaps per seller, and the seller's details are copied in when it is issued so later
+// changes to the shop do not alter invoices already handed out.
+type Invoice struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SubOrderID    uint      `json:"sub_order_id" gorm:"uniqueIndex"`
+	OrderID       uint      `json:"order_id" gorm:"index"`
+	SellerID      uint      `json:"seller_id" gorm:"uniqueIndex:idx_invoice_seller_sequence"`
+	Sequence      uint      `json:"sequence" gorm:"uniqueIndex:idx_invoice_seller_sequence"`
+	Number        string    `json:"number"`
+	SellerName    string    `json:"seller_name"`
+	SellerAddress string    `json:"seller_address"`
+	SellerPhone   string    `json:"seller_phone"`
+	SellerBIN     string    `json:"seller_bin"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// InvoiceCounter holds the last invoice sequence handed out to a seller
+type InvoiceCounter struct {
+	SellerID     uint `gorm:"primaryKey;autoIncrement:false"`
+	LastSequence uint
+}
+
+// InvoiceNumber formats a seller's invoice sequence as printed, e.g. INV-12-000042
+func InvoiceNumber(sellerID, sequence uint) string {
+	return fmt.Sprintf("INV-%d-%06d", sellerID, sequence)
+}
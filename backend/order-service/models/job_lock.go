@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// JobLock lets one instance of the service at a time run a scheduled job. Whoever
+// holds an unexpired lock runs the job; the others skip that round.
+type JobLock struct {
+	Name        string    `json:"name" gorm:"primaryKey"`
+	Holder      string    `json:"holder"`
+	LockedUntil time.Time `json:"locked_until"`
+	LastRunAt   time.Time `json:"last_run_at"`
+	LastError   string    `json:"last_error,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -7,10 +7,26 @@ import (
 type Order struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	BuyerID     uint           `json:"buyer_id"`
-	ShopID      uint           `json:"shop_id"` // if ordering from specific vendor/shop
-	Status      string         `json:"status"` // e.g., "pending", "paid", "shipped", "cancelled"
+	ShopID      uint           `json:"shop_id"` // set when every item comes from one shop
+	Status      string         `json:"status"` // one of the OrderStatus constants; change it only through a transition
+	ShippingMethod string      `json:"shipping_method"` // standard, express, overnight
+	PaymentMethod  string      `json:"payment_method"`  // card, paypal, bank, cod
+	CouponCode     string      `json:"coupon_code,omitempty"`
+	SaleClaimKey   string      `json:"-" gorm:"size:64"` // names the sale units claimed for the order in product-service
+	// Price breakdown computed at order time; TotalAmount is their sum less the discounts
+	Subtotal    float64        `json:"subtotal"`
+	ShippingFee float64        `json:"shipping_fee"`
+	Tax         float64        `json:"tax"`
+	CODFee      float64        `json:"cod_fee"`
+	Discount    float64        `json:"discount"`          // coupon discount on the items
+	ShippingDiscount float64   `json:"shipping_discount"` // shipping waived by a free-shipping coupon
 	TotalAmount float64        `json:"total_amount"`
+	PaidAt      *time.Time     `json:"paid_at,omitempty"` // when payment-service reported the payment complete
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	OrderItems  []OrderItem    `json:"order_items" gorm:"foreignKey:OrderID"`
+	SubOrders   []SubOrder     `json:"sub_orders,omitempty" gorm:"foreignKey:OrderID"` // one per shop
+	History     []OrderStatusTransition `json:"history,omitempty" gorm:"foreignKey:OrderID"`
+	Checkout    *CheckoutSaga  `json:"checkout,omitempty" gorm:"foreignKey:OrderID"`
+	Redemption  *CouponRedemption `json:"-" gorm:"foreignKey:OrderID"` // the coupon use, saved with the order
 }
@@ -3,9 +3,26 @@ package models
 type OrderItem struct {
 	ID        uint    `json:"id" gorm:"primaryKey"`
 	OrderID   uint    `json:"order_id"`
+	SubOrderID uint   `json:"sub_order_id,omitempty" gorm:"index"` // the shop's part of the order
+	SellerID  uint    `json:"seller_id"` // snapshot from product-service
+	ShopID    uint    `json:"shop_id"`   // snapshot from product-service
+	CategoryID uint   `json:"category_id"` // snapshot from product-service, decides the return window
 	ProductID uint    `json:"product_id"`   // foreign key from product-service
 	ProductName string `json:"product_name"` // snapshot (optional)
 	Quantity  int     `json:"quantity"`
 	UnitPrice float64 `json:"unit_price"` // price at order time
 	Subtotal  float64 `json:"subtotal"`   // calculated = quantity * unit_price
+	Discount  float64 `json:"discount"`   // the line's share of the order's coupon discount
+	IsDigital bool    `json:"is_digital"` // delivered by download or key, never shipped
+	// For a bundle line, the products shipped in its place (snapshot)
+	Components []OrderItemComponent `json:"components,omitempty" gorm:"foreignKey:OrderItemID"`
+}
+
+// OrderItemComponent is one product contained in a bundle line, with the quantity for the whole line
+type OrderItemComponent struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	OrderItemID uint   `json:"order_item_id" gorm:"index"`
+	ProductID   uint   `json:"product_id"`   // foreign key from product-service
+	ProductName string `json:"product_name"` // snapshot
+	Quantity    int    `json:"quantity"`
 }
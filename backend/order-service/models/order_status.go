@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Order statuses, matching the storefront's order status union
+const (
+	OrderStatusPending        = "pending"
+	OrderStatusConfirmed      = "confirmed"
+	OrderStatusProcessing     = "processing"
+	OrderStatusShipped        = "shipped"
+	OrderStatusOutForDelivery = "out_for_delivery"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCompleted      = "completed" // the return window has closed; the seller can be paid
+	OrderStatusCancelled      = "cancelled"
+	OrderStatusReturned       = "returned"
+)
+
+// Roles that can move an order between statuses. System covers other services acting
+// through the internal API.
+const (
+	ActorBuyer  = "buyer"
+	ActorSeller = "seller"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+// OrderStatusTransition records one change of an order's or sub-order's status
+type OrderStatusTransition struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OrderID    uint      `json:"order_id" gorm:"index"`
+	SubOrderID uint      `json:"sub_order_id,omitempty" gorm:"index"` // set when one sub-order changed
+	FromStatus string    `json:"from_status"`                         // empty for the order's creation
+	ToStatus   string    `json:"to_status"`
+	ActorID    uint      `json:"actor_id"` // 0 for system
+	ActorRole  string    `json:"actor_role"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Return request statuses. A return is requested by the buyer, reviewed by the seller,
+// sent back, inspected, and finally refunded or replaced.
+const (
+	ReturnStatusRequested        = "requested"
+	ReturnStatusApproved         = "approved"
+	ReturnStatusRejected         = "rejected"
+	ReturnStatusInTransit        = "in_transit"        // buyer sent the goods back
+	ReturnStatusAccepted         = "accepted"          // passed inspection, refund or replacement pending
+	ReturnStatusInspectionFailed = "inspection_failed" // goods came back unfit for a refund
+	ReturnStatusRefunded         = "refunded"
+	ReturnStatusReplaced         = "replaced"
+)
+
+// What the buyer wants for returned goods
+const (
+	ReturnResolutionRefund  = "refund"
+	ReturnResolutionReplace = "replace"
+)
+
+// ReturnRequest is a buyer's request to send back delivered items from one shop's part
+// of an order
+type ReturnRequest struct {
+	ID                 uint         `json:"id" gorm:"primaryKey"`
+	OrderID            uint         `json:"order_id" gorm:"index"`
+	SubOrderID         uint         `json:"sub_order_id,omitempty" gorm:"index"` // 0 for orders placed before the split
+	BuyerID            uint         `json:"buyer_id" gorm:"index"`
+	SellerID           uint         `json:"seller_id" gorm:"index"`
+	Status             string       `json:"status"`
+	Resolution         string       `json:"resolution"` // refund or replace
+	Reason             string       `json:"reason"`
+	Evidence           []string     `json:"evidence" gorm:"serializer:json"` // photo URLs
+	ReviewNote         string       `json:"review_note,omitempty"`
+	TrackingCode       string       `json:"tracking_code,omitempty"` // of the parcel sent back
+	InspectionNote     string       `json:"inspection_note,omitempty"`
+	RefundAmount       float64      `json:"refund_amount"`
+	ReplacementOrderID uint         `json:"replacement_order_id,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+	Items              []ReturnItem `json:"items" gorm:"foreignKey:ReturnRequestID"`
+}
+
+// ReturnItem is a quantity of one order line being sent back
+type ReturnItem struct {
+	ID              uint    `json:"id" gorm:"primaryKey"`
+	ReturnRequestID uint    `json:"return_request_id" gorm:"index"`
+	OrderItemID     uint    `json:"order_item_id" gorm:"index"`
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	Quantity        int     `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"` // snapshot from the order line
+	Discount        float64 `json:"discount"`   // the returned quantity's share of the line's discount
+}
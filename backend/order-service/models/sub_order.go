@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SubOrder is the part of an order sold and shipped by one shop. Each sub-order moves
+// through the order lifecycle on its own and gets its own shipment; its totals are its
+// share of the order's payment. Buyers see the parent order with every sub-order,
+// sellers only their own.
+type SubOrder struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	OrderID  uint   `json:"order_id" gorm:"index"`
+	BuyerID  uint   `json:"buyer_id"`
+	SellerID uint   `json:"seller_id" gorm:"index"`
+	ShopID   uint   `json:"shop_id" gorm:"index"`
+	Status   string `json:"status"` // one of the OrderStatus constants; change it only through a transition
+	// Share of the parent order's price breakdown
+	Subtotal         float64     `json:"subtotal"`
+	ShippingFee      float64     `json:"shipping_fee"`
+	Tax              float64     `json:"tax"`
+	CODFee           float64     `json:"cod_fee"`
+	Discount         float64     `json:"discount"`
+	ShippingDiscount float64     `json:"shipping_discount"`
+	TotalAmount      float64     `json:"total_amount"`
+	ShipmentID       uint        `json:"shipment_id,omitempty"` // set once shipment-service accepted it
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Items            []OrderItem `json:"items" gorm:"foreignKey:SubOrderID"`
+
+	// Set when the sub-order completes; the seller can be paid for it from then on
+	SettlementEligibleAt *time.Time `json:"settlement_eligible_at,omitempty" gorm:"index"`
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"time"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CartRepository defines the contract for shopper carts
+type CartRepository interface {
+	ForUser(userID uint) (*models.Cart, error)
+	GetByToken(token string) (*models.Cart, error)
+	CreateGuest(token string) (*models.Cart, error)
+	SetItem(cartID, productID uint, quantity int, price float64) error
+	RemoveItem(cartID, productID uint) (bool, error)
+	SavePrices(cartID uint, prices map[uint]float64) error
+	Merge(fromCartID, intoCartID uint, maxQuantity int) error
+	Clear(cartID uint) error
+}
+
+type cartRepo struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepo{db}
+}
+
+// ForUser returns the buyer's cart with its items, creating an empty one on first use
+func (r *cartRepo) ForUser(userID uint) (*models.Cart, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Cart{UserID: &userID}).Error; err != nil {
+		return nil, err
+	}
+	var cart models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", userID).
+		First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetByToken returns a guest cart with its items
+func (r *cartRepo) GetByToken(token string) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("token = ?", token).
+		First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// CreateGuest starts an empty guest cart
+func (r *cartRepo) CreateGuest(token string) (*models.Cart, error) {
+	cart := models.Cart{Token: &token}
+	if err := r.db.Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SetItem puts a product in the cart at the given quantity and price, replacing any existing line
+func (r *cartRepo) SetItem(cartID, productID uint, quantity int, price float64) error {
+	item := models.CartItem{CartID: cartID, ProductID: productID, Quantity: quantity, SavedPrice: price}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "saved_price", "updated_at"}),
+		}).Create(&item).Error; err != nil {
+			return err
+		}
+		return touchCart(tx, cartID)
+	})
+}
+
+// RemoveItem takes a product out of the cart, reporting whether it was there
+func (r *cartRepo) RemoveItem(cartID, productID uint) (bool, error) {
+	var removed bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return touchCart(tx, cartID)
+	})
+	return removed, err
+}
+
+// SavePrices records the unit price the shopper has now seen for each product
+func (r *cartRepo) SavePrices(cartID uint, prices map[uint]float64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for productID, price := range prices {
+			if err := tx.Model(&models.CartItem{}).
+				Where("cart_id = ? AND product_id = ?", cartID, productID).
+				Update("saved_price", price).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Merge moves every item of one cart into another and deletes the emptied cart.
+// Quantities of products in both carts are added up to maxQuantity.
+func (r *cartRepo) Merge(fromCartID, intoCartID uint, maxQuantity int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Exec(`INSERT INTO cart_items (cart_id, product_id, quantity, saved_price, created_at, updated_at)
+			SELECT ?, product_id, LEAST(quantity, ?), saved_price, ?, ?
+			FROM cart_items WHERE cart_id = ?
+			ON CONFLICT (cart_id, product_id) DO UPDATE
+			SET quantity = LEAST(cart_items.quantity + EXCLUDED.quantity, ?), updated_at = EXCLUDED.updated_at`,
+			intoCartID, maxQuantity, now, now, fromCartID, maxQuantity).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id = ?", fromCartID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Cart{}, fromCartID).Error; err != nil {
+			return err
+		}
+		return touchCart(tx, intoCartID)
+	})
+}
+
+// Clear empties a cart after checkout
+func (r *cartRepo) Clear(cartID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return touchCart(tx, cartID)
+	})
+}
+
+// touchCart marks a cart as changed
+func touchCart(db *gorm.DB, cartID uint) error {
+	return db.Model(&models.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now()).Error
+}
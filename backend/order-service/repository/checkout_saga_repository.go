@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+)
+
+// CheckoutSagaRepository defines the contract for persisted checkout sagas
+type CheckoutSagaRepository interface {
+	Create(saga *models.CheckoutSaga) error
+	Save(saga *models.CheckoutSaga) error
+	ListDue(now time.Time, limit int) ([]models.CheckoutSaga, error)
+	Claim(saga *models.CheckoutSaga, until time.Time) (bool, error)
+}
+
+type checkoutSagaRepo struct {
+	db *gorm.DB
+}
+
+func NewCheckoutSagaRepository(db *gorm.DB) CheckoutSagaRepository {
+	return &checkoutSagaRepo{db}
+}
+
+func (r *checkoutSagaRepo) Create(saga *models.CheckoutSaga) error {
+	return r.db.Create(saga).Error
+}
+
+// Save writes the saga's progress
+func (r *checkoutSagaRepo) Save(saga *models.CheckoutSaga) error {
+	return r.db.Save(saga).Error
+}
+
+// ListDue returns unfinished sagas whose next attempt is due, oldest first
+func (r *checkoutSagaRepo) ListDue(now time.Time, limit int) ([]models.CheckoutSaga, error) {
+	var sagas []models.CheckoutSaga
+	err := r.db.
+		Where("status IN ? AND next_attempt_at <= ?", []string{models.SagaStatusRunning, models.SagaStatusCompensating}, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&sagas).Error
+	return sagas, err
+}
+
+// Claim takes a due saga for the caller by pushing its next attempt out to until. It
+// reports false if another worker got there first.
+func (r *checkoutSagaRepo) Claim(saga *models.CheckoutSaga, until time.Time) (bool, error) {
+	result := r.db.Model(&models.CheckoutSaga{}).
+		Where("id = ? AND next_attempt_at = ?", saga.ID, saga.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	saga.NextAttemptAt = until
+	return true, nil
+}
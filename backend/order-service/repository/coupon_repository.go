@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCouponExhausted = errors.New("coupon has been used up")
+	ErrCouponUserLimit = errors.New("coupon already used as many times as allowed")
+)
+
+// CouponRepository defines the contract for coupons and their uses. Coupons are
+// redeemed as part of saving an order, see OrderRepository.Create.
+type CouponRepository interface {
+	Create(coupon *models.Coupon) error
+	GetByID(id uint) (*models.Coupon, error)
+	GetByCode(code string) (*models.Coupon, error)
+	List(shopID uint) ([]models.Coupon, error)
+	Update(coupon *models.Coupon) error
+	CountRedemptions(couponID, userID uint) (int64, error)
+	Release(orderID uint) error
+}
+
+type couponRepo struct {
+	db *gorm.DB
+}
+
+func NewCouponRepository(db *gorm.DB) CouponRepository {
+	return &couponRepo{db}
+}
+
+func (r *couponRepo) Create(coupon *models.Coupon) error {
+	return r.db.Create(coupon).Error
+}
+
+func (r *couponRepo) GetByID(id uint) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.First(&coupon, id).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *couponRepo) GetByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.Where("code = ?", code).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// List returns a shop's coupons, or every coupon when shopID is 0, newest first
+func (r *couponRepo) List(shopID uint) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	query := r.db.Order("id DESC")
+	if shopID != 0 {
+		query = query.Where("shop_id = ?", shopID)
+	}
+	err := query.Find(&coupons).Error
+	return coupons, err
+}
+
+// Update saves a coupon's terms. The usage count is left alone, it only changes with
+// redemptions.
+func (r *couponRepo) Update(coupon *models.Coupon) error {
+	return r.db.Model(coupon).Select("*").Omit("ID", "Code", "ShopID", "UsedCount", "CreatedBy", "CreatedAt").
+		Updates(coupon).Error
+}
+
+// CountRedemptions counts a buyer's uses of a coupon that were not released
+func (r *couponRepo) CountRedemptions(couponID, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.CouponRedemption{}).
+		Where("coupon_id = ? AND user_id = ? AND released_at IS NULL", couponID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// redeemCoupon records an order's use of a coupon within tx. The coupon row stays
+// locked until tx ends, so concurrent orders cannot use it past its limits.
+func redeemCoupon(tx *gorm.DB, redemption *models.CouponRedemption) error {
+	var coupon models.Coupon
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, redemption.CouponID).Error; err != nil {
+		return err
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return ErrCouponExhausted
+	}
+	if coupon.PerUserLimit > 0 {
+		var used int64
+		err := tx.Model(&models.CouponRedemption{}).
+			Where("coupon_id = ? AND user_id = ? AND released_at IS NULL", coupon.ID, redemption.UserID).
+			Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= int64(coupon.PerUserLimit) {
+			return ErrCouponUserLimit
+		}
+	}
+	if err := tx.Create(redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Coupon{}).Where("id = ?", coupon.ID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error
+}
+
+// Release gives an order's coupon use back. Releasing twice, or an order that used no
+// coupon, does nothing.
+func (r *couponRepo) Release(orderID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.CouponRedemption{}).
+			Where("order_id = ? AND released_at IS NULL", orderID).
+			Update("released_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		var redemption models.CouponRedemption
+		if err := tx.Where("order_id = ?", orderID).First(&redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Coupon{}).Where("id = ? AND used_count > 0", redemption.CouponID).
+			Update("used_count", gorm.Expr("used_count - 1")).Error
+	})
+}
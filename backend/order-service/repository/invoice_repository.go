@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvoiceExists means the sub-order was invoiced by a concurrent request
+var ErrInvoiceExists = errors.New("sub-order already has an invoice")
+
+// InvoiceRepository defines the contract for issued invoices
+type InvoiceRepository interface {
+	GetBySubOrder(subOrderID uint) (*models.Invoice, error)
+	Issue(invoice *models.Invoice) error
+}
+
+type invoiceRepo struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepo{db}
+}
+
+func (r *invoiceRepo) GetBySubOrder(subOrderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Where("sub_order_id = ?", subOrderID).First(&invoice).Error
+	return &invoice, err
+}
+
+// Issue numbers an invoice with the seller's next sequence and saves it. The counter
+// row stays locked until the invoice is saved, and is rolled back with it, so numbers
+// never skip. A sub-order that already has an invoice fails with ErrInvoiceExists.
+func (r *invoiceRepo) Issue(invoice *models.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var counter models.InvoiceCounter
+		err := tx.Raw(`INSERT INTO invoice_counters (seller_id, last_sequence) VALUES (?, 1)
+			ON CONFLICT (seller_id) DO UPDATE SET last_sequence = invoice_counters.last_sequence + 1
+			RETURNING seller_id, last_sequence`, invoice.SellerID).Scan(&counter).Error
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = counter.LastSequence
+		invoice.Number = models.InvoiceNumber(invoice.SellerID, counter.LastSequence)
+
+		result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "sub_order_id"}}, DoNothing: true}).
+			Create(invoice)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvoiceExists
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) ForUser(userID uint) (*models.Cart, error) {
+	args := m.Called(userID)
+	cart, _ := args.Get(0).(*models.Cart)
+	return cart, args.Error(1)
+}
+
+func (m *MockCartRepository) GetByToken(token string) (*models.Cart, error) {
+	args := m.Called(token)
+	cart, _ := args.Get(0).(*models.Cart)
+	return cart, args.Error(1)
+}
+
+func (m *MockCartRepository) CreateGuest(token string) (*models.Cart, error) {
+	args := m.Called(token)
+	cart, _ := args.Get(0).(*models.Cart)
+	return cart, args.Error(1)
+}
+
+func (m *MockCartRepository) SetItem(cartID, productID uint, quantity int, price float64) error {
+	args := m.Called(cartID, productID, quantity, price)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveItem(cartID, productID uint) (bool, error) {
+	args := m.Called(cartID, productID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCartRepository) SavePrices(cartID uint, prices map[uint]float64) error {
+	args := m.Called(cartID, prices)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) Merge(fromCartID, intoCartID uint, maxQuantity int) error {
+	args := m.Called(fromCartID, intoCartID, maxQuantity)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) Clear(cartID uint) error {
+	args := m.Called(cartID)
+	return args.Error(0)
+}
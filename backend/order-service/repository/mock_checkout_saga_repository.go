@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCheckoutSagaRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckoutSagaRepository) Create(saga *models.CheckoutSaga) error {
+	args := m.Called(saga)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutSagaRepository) Save(saga *models.CheckoutSaga) error {
+	args := m.Called(saga)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutSagaRepository) ListDue(now time.Time, limit int) ([]models.CheckoutSaga, error) {
+	args := m.Called(now, limit)
+	sagas, _ := args.Get(0).([]models.CheckoutSaga)
+	return sagas, args.Error(1)
+}
+
+func (m *MockCheckoutSagaRepository) Claim(saga *models.CheckoutSaga, until time.Time) (bool, error) {
+	args := m.Called(saga, until)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCouponRepository struct {
+	mock.Mock
+}
+
+func (m *MockCouponRepository) Create(coupon *models.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) GetByID(id uint) (*models.Coupon, error) {
+	args := m.Called(id)
+	coupon, _ := args.Get(0).(*models.Coupon)
+	return coupon, args.Error(1)
+}
+
+func (m *MockCouponRepository) GetByCode(code string) (*models.Coupon, error) {
+	args := m.Called(code)
+	coupon, _ := args.Get(0).(*models.Coupon)
+	return coupon, args.Error(1)
+}
+
+func (m *MockCouponRepository) List(shopID uint) ([]models.Coupon, error) {
+	args := m.Called(shopID)
+	coupons, _ := args.Get(0).([]models.Coupon)
+	return coupons, args.Error(1)
+}
+
+func (m *MockCouponRepository) Update(coupon *models.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *MockCouponRepository) CountRedemptions(couponID, userID uint) (int64, error) {
+	args := m.Called(couponID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCouponRepository) Release(orderID uint) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvoiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceRepository) GetBySubOrder(subOrderID uint) (*models.Invoice, error) {
+	args := m.Called(subOrderID)
+	invoice, _ := args.Get(0).(*models.Invoice)
+	return invoice, args.Error(1)
+}
+
+func (m *MockInvoiceRepository) Issue(invoice *models.Invoice) error {
+	args := m.Called(invoice)
+	return args.Error(0)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) Create(order *models.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetByID(orderID uint) (*models.Order, error) {
+	args := m.Called(orderID)
+	order, _ := args.Get(0).(*models.Order)
+	return order, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByBuyerID(buyerID uint) ([]models.Order, error) {
+	args := m.Called(buyerID)
+	return args.Get(0).([]models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetBySellerID(sellerID uint) ([]models.SubOrder, error) {
+	args := m.Called(sellerID)
+	return args.Get(0).([]models.SubOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetSubOrder(subOrderID uint) (*models.SubOrder, error) {
+	args := m.Called(subOrderID)
+	subOrder, _ := args.Get(0).(*models.SubOrder)
+	return subOrder, args.Error(1)
+}
+
+func (m *MockOrderRepository) SetSubOrderShipment(subOrderID, shipmentID uint) error {
+	args := m.Called(subOrderID, shipmentID)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) Transition(entry *models.OrderStatusTransition) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) DeleteOrder(orderID string) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) FindDeliveredItem(buyerID, productID uint) (*models.OrderItem, error) {
+	args := m.Called(buyerID, productID)
+	item, _ := args.Get(0).(*models.OrderItem)
+	return item, args.Error(1)
+}
+
+func (m *MockOrderRepository) ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedItem, error) {
+	args := m.Called(since, afterID, limit)
+	items, _ := args.Get(0).([]CompletedItem)
+	return items, args.Error(1)
+}
+
+func (m *MockOrderRepository) SearchOrders(filter OrderFilter) ([]models.Order, error) {
+	args := m.Called(filter)
+	orders, _ := args.Get(0).([]models.Order)
+	return orders, args.Error(1)
+}
+
+func (m *MockOrderRepository) SearchSubOrders(filter OrderFilter) ([]models.SubOrder, error) {
+	args := m.Called(filter)
+	subOrders, _ := args.Get(0).([]models.SubOrder)
+	return subOrders, args.Error(1)
+}
+
+func (m *MockOrderRepository) SetPaid(orderID uint, at time.Time) error {
+	args := m.Called(orderID, at)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) ListUnpaid(createdBefore time.Time, payOnDelivery string, afterID uint, limit int) ([]models.Order, error) {
+	args := m.Called(createdBefore, payOnDelivery, afterID, limit)
+	orders, _ := args.Get(0).([]models.Order)
+	return orders, args.Error(1)
+}
+
+func (m *MockOrderRepository) ListDelivered(deliveredBefore time.Time, afterID uint, limit int) ([]models.Order, error) {
+	args := m.Called(deliveredBefore, afterID, limit)
+	orders, _ := args.Get(0).([]models.Order)
+	return orders, args.Error(1)
+}
+
+func (m *MockOrderRepository) ListSettleable(since time.Time, afterID uint, limit int) ([]models.SubOrder, error) {
+	args := m.Called(since, afterID, limit)
+	subOrders, _ := args.Get(0).([]models.SubOrder)
+	return subOrders, args.Error(1)
+}
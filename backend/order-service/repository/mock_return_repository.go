@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"order-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReturnRepository struct {
+	mock.Mock
+}
+
+func (m *MockReturnRepository) Create(request *models.ReturnRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockReturnRepository) GetByID(id uint) (*models.ReturnRequest, error) {
+	args := m.Called(id)
+	request, _ := args.Get(0).(*models.ReturnRequest)
+	return request, args.Error(1)
+}
+
+func (m *MockReturnRepository) ListByBuyer(buyerID uint) ([]models.ReturnRequest, error) {
+	args := m.Called(buyerID)
+	requests, _ := args.Get(0).([]models.ReturnRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockReturnRepository) ListBySeller(sellerID uint) ([]models.ReturnRequest, error) {
+	args := m.Called(sellerID)
+	requests, _ := args.Get(0).([]models.ReturnRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockReturnRepository) ListAll() ([]models.ReturnRequest, error) {
+	args := m.Called()
+	requests, _ := args.Get(0).([]models.ReturnRequest)
+	return requests, args.Error(1)
+}
+
+func (m *MockReturnRepository) ReturnedQuantities(orderID uint) (map[uint]int, error) {
+	args := m.Called(orderID)
+	quantities, _ := args.Get(0).(map[uint]int)
+	return quantities, args.Error(1)
+}
+
+func (m *MockReturnRepository) Update(request *models.ReturnRequest, fromStatus string) error {
+	args := m.Called(request, fromStatus)
+	return args.Error(0)
+}
+
+func (m *MockReturnRepository) CountOpen(orderID, subOrderID uint) (int64, error) {
+	args := m.Called(orderID, subOrderID)
+	return args.Get(0).(int64), args.Error(1)
+}
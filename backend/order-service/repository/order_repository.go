@@ -1,16 +1,44 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"order-service/models"
 	"gorm.io/gorm"
 )
 
+// ErrStatusChanged means the order's status moved on before a transition could be saved
+var ErrStatusChanged = errors.New("order status changed concurrently")
+
+// CompletedItem is a delivered order line as exported to other services
+type CompletedItem struct {
+	OrderItemID uint      `json:"order_item_id"`
+	OrderID     uint      `json:"order_id"`
+	BuyerID     uint      `json:"buyer_id"`
+	ProductID   uint      `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
 type OrderRepository interface {
 	Create(order *models.Order) error
+	GetByID(orderID uint) (*models.Order, error)
 	GetByBuyerID(buyerID uint) ([]models.Order, error)
-	GetBySellerID(sellerID uint) ([]models.Order, error)
-	UpdateStatus(orderID uint, status string) error
+	GetBySellerID(sellerID uint) ([]models.SubOrder, error)
+	GetSubOrder(subOrderID uint) (*models.SubOrder, error)
+	SetSubOrderShipment(subOrderID, shipmentID uint) error
+	Transition(entry *models.OrderStatusTransition) error
 	DeleteOrder(orderID string) error  // <-- Ensure this line exists
+	FindDeliveredItem(buyerID, productID uint) (*models.OrderItem, error)
+	ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedItem, error)
+	SearchOrders(filter OrderFilter) ([]models.Order, error)
+	SearchSubOrders(filter OrderFilter) ([]models.SubOrder, error)
+	SetPaid(orderID uint, at time.Time) error
+	ListUnpaid(createdBefore time.Time, payOnDelivery string, afterID uint, limit int) ([]models.Order, error)
+	ListDelivered(deliveredBefore time.Time, afterID uint, limit int) ([]models.Order, error)
+	ListSettleable(since time.Time, afterID uint, limit int) ([]models.SubOrder, error)
 }
 
 type orderRepo struct {
@@ -21,26 +49,230 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepo{db}
 }
 
+// Create saves an order with its items, history, sub-orders and checkout saga, linking
+// each item to the sub-order of its seller and shop. An order using a coupon redeems it
+// in the same transaction; one that would take the coupon past its limits fails with
+// ErrCouponExhausted or ErrCouponUserLimit and is not saved.
 func (r *orderRepo) Create(order *models.Order) error {
-	return r.db.Create(order).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("SubOrders", "Redemption", "Checkout").Create(order).Error; err != nil {
+			return err
+		}
+		if order.Checkout != nil {
+			order.Checkout.OrderID = order.ID
+			if err := tx.Create(order.Checkout).Error; err != nil {
+				return err
+			}
+		}
+		if order.Redemption != nil {
+			order.Redemption.OrderID = order.ID
+			if err := redeemCoupon(tx, order.Redemption); err != nil {
+				return err
+			}
+		}
+		for i := range order.SubOrders {
+			sub := &order.SubOrders[i]
+			sub.OrderID = order.ID
+			if err := tx.Omit("Items").Create(sub).Error; err != nil {
+				return err
+			}
+			err := tx.Model(&models.OrderItem{}).
+				Where("order_id = ? AND seller_id = ? AND shop_id = ?", order.ID, sub.SellerID, sub.ShopID).
+				Update("sub_order_id", sub.ID).Error
+			if err != nil {
+				return err
+			}
+			sub.Items = nil
+			for j := range order.OrderItems {
+				item := &order.OrderItems[j]
+				if item.SellerID == sub.SellerID && item.ShopID == sub.ShopID {
+					item.SubOrderID = sub.ID
+					sub.Items = append(sub.Items, *item)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID fetches an order with its items, their bundle components, its sub-orders and
+// its status history
+func (r *orderRepo) GetByID(orderID uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Preload("OrderItems.Components").
+		Preload("SubOrders", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Checkout").
+		First(&order, orderID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
 }
 
 func (r *orderRepo) GetByBuyerID(buyerID uint) ([]models.Order, error) {
 	var orders []models.Order
-	err := r.db.Where("buyer_id = ?", buyerID).Find(&orders).Error
+	err := r.db.Preload("SubOrders", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("buyer_id = ?", buyerID).Find(&orders).Error
 	return orders, err
 }
 
-func (r *orderRepo) GetBySellerID(sellerID uint) ([]models.Order, error) {
-	var orders []models.Order
-	err := r.db.Where("seller_id = ?", sellerID).Find(&orders).Error
-	return orders, err
+// GetBySellerID lists the seller's sub-orders with their items, newest first
+func (r *orderRepo) GetBySellerID(sellerID uint) ([]models.SubOrder, error) {
+	var subOrders []models.SubOrder
+	err := r.db.Preload("Items.Components").
+		Where("seller_id = ?", sellerID).
+		Order("id DESC").
+		Find(&subOrders).Error
+	return subOrders, err
 }
 
-func (r *orderRepo) UpdateStatus(orderID uint, status string) error {
-	return r.db.Model(&models.Order{}).Where("id = ?", orderID).Update("status", status).Error
+// GetSubOrder fetches a sub-order with its items
+func (r *orderRepo) GetSubOrder(subOrderID uint) (*models.SubOrder, error) {
+	var subOrder models.SubOrder
+	if err := r.db.Preload("Items.Components").First(&subOrder, subOrderID).Error; err != nil {
+		return nil, err
+	}
+	return &subOrder, nil
+}
+
+// SetSubOrderShipment records the shipment sending a sub-order
+func (r *orderRepo) SetSubOrderShipment(subOrderID, shipmentID uint) error {
+	return r.db.Model(&models.SubOrder{}).Where("id = ?", subOrderID).Update("shipment_id", shipmentID).Error
+}
+
+// Transition moves an order, or the sub-order named by entry.SubOrderID, from
+// entry.FromStatus to entry.ToStatus and records the change. A sub-order that completes
+// becomes eligible for settlement at the same time. It fails with ErrStatusChanged if it
+// is no longer in FromStatus.
+func (r *orderRepo) Transition(entry *models.OrderStatusTransition) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Order{}).Where("id = ?", entry.OrderID)
+		if entry.SubOrderID != 0 {
+			query = tx.Model(&models.SubOrder{}).Where("id = ? AND order_id = ?", entry.SubOrderID, entry.OrderID)
+		}
+		changes := map[string]interface{}{"status": entry.ToStatus, "updated_at": time.Now()}
+		if entry.SubOrderID != 0 && entry.ToStatus == models.OrderStatusCompleted {
+			changes["settlement_eligible_at"] = changes["updated_at"]
+		}
+		result := query.Where("status = ?", entry.FromStatus).Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		return tx.Create(entry).Error
+	})
 }
 
 func (r *orderRepo) DeleteOrder(orderID string) error {
     return r.db.Delete(&models.Order{}, "id = ?", orderID).Error
-}
\ No newline at end of file
+}
+
+// subOrderDeliveredAt and orderDeliveredAt are when a sub-order, or an order that was
+// never split, was delivered: the time of its delivered transition. Orders delivered
+// before transitions were recorded fall back to their last update, as the return
+// window does. Later changes such as completion must not move the delivery time.
+var (
+	subOrderDeliveredAt = fmt.Sprintf(`COALESCE((SELECT MAX(delivered.created_at) FROM order_status_transitions delivered
+		WHERE delivered.sub_order_id = sub_orders.id AND delivered.to_status = '%s'), sub_orders.updated_at)`,
+		models.OrderStatusDelivered)
+	orderDeliveredAt = fmt.Sprintf(`COALESCE((SELECT MAX(delivered.created_at) FROM order_status_transitions delivered
+		WHERE delivered.order_id = orders.id AND delivered.sub_order_id = 0 AND delivered.to_status = '%s'), orders.updated_at)`,
+		models.OrderStatusDelivered)
+	// itemDeliveredAt goes by the item's sub-order where it has one, as receivedItems does
+	itemDeliveredAt = fmt.Sprintf("CASE WHEN sub_orders.id IS NULL THEN %s ELSE %s END", orderDeliveredAt, subOrderDeliveredAt)
+)
+
+// receivedStatuses are those of orders whose goods reached the buyer and were kept
+var receivedStatuses = []string{models.OrderStatusDelivered, models.OrderStatusCompleted}
+
+// receivedItems joins an order item to its order and, when it has one, to its sub-order.
+// Each shop's sub-order is delivered on its own, so an item counts as received by its
+// sub-order's status; items of orders that were never split go by the order's.
+func receivedItems(db *gorm.DB) *gorm.DB {
+	return db.
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("LEFT JOIN sub_orders ON sub_orders.id = order_items.sub_order_id").
+		Where("COALESCE(sub_orders.status, orders.status) IN ?", receivedStatuses)
+}
+
+// FindDeliveredItem returns the buyer's most recent delivered order item for a product
+func (r *orderRepo) FindDeliveredItem(buyerID, productID uint) (*models.OrderItem, error) {
+	var item models.OrderItem
+	err := receivedItems(r.db).
+		Where("orders.buyer_id = ? AND order_items.product_id = ?", buyerID, productID).
+		Order(itemDeliveredAt + " DESC").
+		First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListCompletedItems pages through received items delivered at or after since, going by
+// their sub-order where they have one, ordered by order item id
+func (r *orderRepo) ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedItem, error) {
+	var items []CompletedItem
+	err := receivedItems(r.db.Model(&models.OrderItem{})).
+		Select("order_items.id AS order_item_id, order_items.order_id, orders.buyer_id, order_items.product_id, order_items.quantity, "+itemDeliveredAt+" AS completed_at").
+		Where(itemDeliveredAt+" >= ? AND order_items.id > ?", since, afterID).
+		Order("order_items.id").
+		Limit(limit).
+		Scan(&items).Error
+	return items, err
+}
+
+// SetPaid records when an order's payment completed. The first time recorded is kept.
+func (r *orderRepo) SetPaid(orderID uint, at time.Time) error {
+	return r.db.Model(&models.Order{}).
+		Where("id = ? AND paid_at IS NULL", orderID).
+		UpdateColumn("paid_at", at).Error
+}
+
+// ListUnpaid pages through orders placed before createdBefore that are still waiting
+// for their payment, ordered by id. Orders paid on delivery and free orders are left out.
+func (r *orderRepo) ListUnpaid(createdBefore time.Time, payOnDelivery string, afterID uint, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Preload("OrderItems").
+		Preload("SubOrders", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("status IN ? AND paid_at IS NULL AND total_amount > 0 AND payment_method <> ?",
+			[]string{models.OrderStatusPending, models.OrderStatusConfirmed}, payOnDelivery).
+		Where("created_at < ? AND id > ?", createdBefore, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// ListDelivered pages through orders with a part delivered before deliveredBefore and
+// not yet completed, ordered by id, loaded as GetByID loads them
+func (r *orderRepo) ListDelivered(deliveredBefore time.Time, afterID uint, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Preload("OrderItems.Components").
+		Preload("SubOrders", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where(`EXISTS (SELECT 1 FROM sub_orders WHERE sub_orders.order_id = orders.id
+				AND sub_orders.status = ? AND `+subOrderDeliveredAt+` < ?)
+			OR (orders.status = ? AND `+orderDeliveredAt+` < ?
+				AND NOT EXISTS (SELECT 1 FROM sub_orders WHERE sub_orders.order_id = orders.id))`,
+			models.OrderStatusDelivered, deliveredBefore, models.OrderStatusDelivered, deliveredBefore).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// ListSettleable pages through sub-orders that became eligible for settlement at or
+// after since, ordered by id
+func (r *orderRepo) ListSettleable(since time.Time, afterID uint, limit int) ([]models.SubOrder, error) {
+	var subOrders []models.SubOrder
+	err := r.db.
+		Where("status = ? AND settlement_eligible_at >= ? AND id > ?", models.OrderStatusCompleted, since, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&subOrders).Error
+	return subOrders, err
+}
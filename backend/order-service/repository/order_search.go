@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Columns order listings can be sorted by
+const (
+	SortByCreatedAt   = "created_at"
+	SortByTotalAmount = "total_amount"
+)
+
+// OrderFilter narrows and orders a listing of orders or sub-orders. Zero fields do not
+// filter.
+type OrderFilter struct {
+	BuyerID       uint
+	SellerID      uint // sub-order listings only
+	Statuses      []string
+	CreatedFrom   time.Time // inclusive
+	CreatedTo     time.Time // exclusive
+	MinTotal      *float64
+	MaxTotal      *float64
+	PaymentMethod string
+	ProductID     uint
+	SortBy        string // SortByCreatedAt (default) or SortByTotalAmount
+	Ascending     bool
+	After         *OrderCursor // continue after this row
+	Limit         int
+}
+
+// OrderCursor marks the last row of a page: its sort value and id
+type OrderCursor struct {
+	CreatedAt   time.Time
+	TotalAmount float64
+	ID          uint
+}
+
+// SearchOrders lists orders matching filter with their items and sub-orders
+func (r *orderRepo) SearchOrders(filter OrderFilter) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Model(&models.Order{}).
+		Preload("OrderItems").
+		Preload("SubOrders", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	err := applyOrderFilter(query, "orders", "order_id", filter).Find(&orders).Error
+	return orders, err
+}
+
+// SearchSubOrders lists sub-orders matching filter with their items
+func (r *orderRepo) SearchSubOrders(filter OrderFilter) ([]models.SubOrder, error) {
+	var subOrders []models.SubOrder
+	query := r.db.Model(&models.SubOrder{}).Select("sub_orders.*").Preload("Items.Components")
+	if filter.SellerID != 0 {
+		query = query.Where("sub_orders.seller_id = ?", filter.SellerID)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Joins("JOIN orders ON orders.id = sub_orders.order_id").
+			Where("orders.payment_method = ?", filter.PaymentMethod)
+		filter.PaymentMethod = ""
+	}
+	err := applyOrderFilter(query, "sub_orders", "sub_order_id", filter).Find(&subOrders).Error
+	return subOrders, err
+}
+
+// applyOrderFilter adds the filter's conditions, keyset pagination and sort order to a
+// query on table, whose items point back at it through itemKey
+func applyOrderFilter(query *gorm.DB, table, itemKey string, filter OrderFilter) *gorm.DB {
+	if filter.BuyerID != 0 {
+		query = query.Where(table+".buyer_id = ?", filter.BuyerID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where(table+".status IN ?", filter.Statuses)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where(table+".created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where(table+".created_at < ?", filter.CreatedTo)
+	}
+	if filter.MinTotal != nil {
+		query = query.Where(table+".total_amount >= ?", *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		query = query.Where(table+".total_amount <= ?", *filter.MaxTotal)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where(table+".payment_method = ?", filter.PaymentMethod)
+	}
+	if filter.ProductID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM order_items WHERE order_items."+itemKey+" = "+table+".id AND order_items.product_id = ?)", filter.ProductID)
+	}
+
+	column := table + ".created_at"
+	if filter.SortBy == SortByTotalAmount {
+		column = table + ".total_amount"
+	}
+	direction, beyond := " DESC", " < "
+	if filter.Ascending {
+		direction, beyond = " ASC", " > "
+	}
+	if filter.After != nil {
+		var value interface{} = filter.After.CreatedAt
+		if filter.SortBy == SortByTotalAmount {
+			value = filter.After.TotalAmount
+		}
+		query = query.Where("("+column+beyond+"?) OR ("+column+" = ? AND "+table+".id"+beyond+"?)",
+			value, value, filter.After.ID)
+	}
+	query = query.Order(column + direction).Order(table + ".id" + direction)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return query
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+
+	"order-service/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrReturnChanged means a return request moved on before an update could be saved
+var ErrReturnChanged = errors.New("return request changed concurrently")
+
+// ReturnRepository defines the contract for return requests
+type ReturnRepository interface {
+	Create(request *models.ReturnRequest) error
+	GetByID(id uint) (*models.ReturnRequest, error)
+	ListByBuyer(buyerID uint) ([]models.ReturnRequest, error)
+	ListBySeller(sellerID uint) ([]models.ReturnRequest, error)
+	ListAll() ([]models.ReturnRequest, error)
+	ReturnedQuantities(orderID uint) (map[uint]int, error)
+	CountOpen(orderID, subOrderID uint) (int64, error)
+	Update(request *models.ReturnRequest, fromStatus string) error
+}
+
+type returnRepo struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepo{db}
+}
+
+func (r *returnRepo) Create(request *models.ReturnRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *returnRepo) GetByID(id uint) (*models.ReturnRequest, error) {
+	var request models.ReturnRequest
+	if err := r.db.Preload("Items").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *returnRepo) ListByBuyer(buyerID uint) ([]models.ReturnRequest, error) {
+	var requests []models.ReturnRequest
+	err := r.db.Preload("Items").Where("buyer_id = ?", buyerID).Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *returnRepo) ListBySeller(sellerID uint) ([]models.ReturnRequest, error) {
+	var requests []models.ReturnRequest
+	err := r.db.Preload("Items").Where("seller_id = ?", sellerID).Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *returnRepo) ListAll() ([]models.ReturnRequest, error) {
+	var requests []models.ReturnRequest
+	err := r.db.Preload("Items").Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// ReturnedQuantities sums, per order line, the quantities in the order's return
+// requests that have not been turned down
+func (r *returnRepo) ReturnedQuantities(orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := r.db.Model(&models.ReturnItem{}).
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ? AND return_requests.status NOT IN ?", orderID,
+			[]string{models.ReturnStatusRejected, models.ReturnStatusInspectionFailed}).
+		Group("return_items.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// CountOpen counts the return requests for an order, or one of its sub-orders, that are
+// still waiting on a decision, the goods or the refund
+func (r *returnRepo) CountOpen(orderID, subOrderID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ReturnRequest{}).
+		Where("order_id = ? AND sub_order_id = ? AND status IN ?", orderID, subOrderID, []string{
+			models.ReturnStatusRequested, models.ReturnStatusApproved,
+			models.ReturnStatusInTransit, models.ReturnStatusAccepted,
+		}).
+		Count(&count).Error
+	return count, err
+}
+
+// Update saves a return request's progress, provided it is still in fromStatus. It
+// fails with ErrReturnChanged otherwise.
+func (r *returnRepo) Update(request *models.ReturnRequest, fromStatus string) error {
+	result := r.db.Model(&models.ReturnRequest{}).
+		Where("id = ? AND status = ?", request.ID, fromStatus).
+		Select("status", "review_note", "tracking_code", "inspection_note", "refund_amount", "replacement_order_id").
+		Updates(request)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReturnChanged
+	}
+	return nil
+}
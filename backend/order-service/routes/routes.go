@@ -7,16 +7,74 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterOrderRoutes(router *gin.Engine, orderController *controllers.OrderController) {
+func RegisterOrderRoutes(router *gin.Engine, orderController *controllers.OrderController, cartController *controllers.CartController, returnController *controllers.ReturnController, documentController *controllers.DocumentController, couponController *controllers.CouponController, idempotent gin.HandlerFunc) {
     protected := router.Group("/api/orders")
     protected.Use(middleware.RequireAuth())
 	{
-		protected.POST("/", orderController.CreateOrder)         // ✅ Create new order
-		protected.GET("/buyer", orderController.GetBuyerOrders)
-		protected.GET("/seller",orderController.GetSellerOrders)
+		protected.POST("/", idempotent, orderController.CreateOrder) // ✅ Create new order (Idempotency-Key aware)
+		protected.POST("/quote", orderController.QuoteOrder)               // 🧮 Totals at current prices, coupon applied
+		protected.GET("/", orderController.ListOrders)                     // 📋 Every order (admin), filtered and paginated
+		protected.GET("/buyer", orderController.GetBuyerOrders)            // 📋 Buyer's orders, filtered and paginated
+		protected.GET("/seller",orderController.GetSellerOrders)           // 🏪 Seller's sub-orders, filtered and paginated
+		protected.GET("/seller/export", orderController.ExportSellerOrders) // 📊 Seller's sub-orders as CSV for a date range
+		protected.GET("/:id", orderController.GetMyOrder)                  // 📄 Order as the caller may see it
 		protected.PUT("/:id/ship", orderController.MarkOrderShipped)       // ✏️ Update existing order
-		protected.DELETE("/:id", orderController.DeleteOrder)    // ❌ Delete order
+		protected.PUT("/:id/status", orderController.UpdateOrderStatus)    // 🔁 Move order through its lifecycle
+		protected.GET("/:id/history", orderController.GetOrderHistory)     // 🕓 Status history
+		protected.PUT("/sub-orders/:id/status", orderController.UpdateSubOrderStatus) // 🏪 Seller moves their part of an order
+		protected.GET("/sub-orders/:id/invoice", documentController.GetInvoice)           // 🧾 Invoice PDF
+		protected.GET("/sub-orders/:id/packing-slip", documentController.GetPackingSlip)  // 📦 Packing slip PDF
+		protected.POST("/:id/cancel", orderController.CancelOrder)         // 🛑 Cancel, release stock and refund
+		protected.POST("/:id/returns", returnController.RequestReturn)     // ↩️ Ask to return delivered items
+		protected.DELETE("/:id", orderController.DeleteOrder)    // ❌ Delete order (admin)
 
 	}
 
+	// Returns: buyers request and send back, sellers review and inspect
+	returns := router.Group("/api/returns")
+	returns.Use(middleware.RequireAuth())
+	{
+		returns.GET("/", returnController.ListReturns)               // 📋 Buyer's, seller's or all returns
+		returns.GET("/:id", returnController.GetReturn)              // 📄 One return
+		returns.POST("/:id/review", returnController.ReviewReturn)   // ✅ Approve or reject
+		returns.POST("/:id/ship", returnController.ShipReturn)       // 📦 Buyer sent it back
+		returns.POST("/:id/inspect", returnController.InspectReturn) // 🔍 Inspect, then refund or replace
+	}
+
+	// Coupons: shop owners run their shop's, admins every one
+	coupons := router.Group("/api/coupons")
+	coupons.Use(middleware.RequireAuth())
+	{
+		coupons.POST("/", couponController.CreateCoupon)        // 🎟️ New coupon
+		coupons.GET("/", couponController.ListCoupons)          // 📋 A shop's coupons, or all (admin)
+		coupons.GET("/:id", couponController.GetCoupon)         // 📄 One coupon
+		coupons.PUT("/:id", couponController.UpdateCoupon)      // ✏️ Change its terms
+		coupons.DELETE("/:id", couponController.DeactivateCoupon) // ⛔ Stop it being used
+	}
+
+	// Carts: guests by cookie, buyers by account
+	cart := router.Group("/api/cart")
+	cart.Use(middleware.OptionalAuth())
+	{
+		cart.GET("/", cartController.GetCart)                                      // 🛒 Revalidated cart
+		cart.POST("/items", cartController.AddItem)                                // ➕ Add product
+		cart.PUT("/items/:product_id", cartController.UpdateItem)                  // ✏️ Change quantity
+		cart.DELETE("/items/:product_id", cartController.RemoveItem)               // ➖ Remove product
+		cart.POST("/merge", middleware.RequireAuth(), cartController.Merge)        // 🔀 Guest cart into buyer cart
+		cart.POST("/checkout", middleware.RequireAuth(), idempotent, cartController.Checkout) // ✅ Cart into order (Idempotency-Key aware)
+	}
+
+	// Internal service-to-service routes
+	internal := router.Group("/internal/orders")
+	internal.Use(middleware.RequireAPIKey())
+	{
+		internal.GET("/delivered-items", orderController.GetDeliveredItem) // ⭐ Review eligibility (product-service)
+		internal.GET("/completed-items", orderController.ListCompletedItems) // 🛒 Co-purchase feed (product-service)
+		internal.GET("/settleable", orderController.ListSettleable)          // 💰 Completed sub-orders sellers can be paid for
+		internal.GET("/:id", orderController.GetOrder)                       // 📄 Order with items (shipment-service)
+		internal.POST("/:id/paid", orderController.MarkOrderPaid)            // 💳 Payment completed (payment-service)
+		internal.POST("/:id/status", orderController.UpdateOrderStatusInternal) // 🚚 Delivery progress (shipment-service)
+		internal.POST("/sub-orders/:id/status", orderController.UpdateSubOrderStatusInternal) // 🚚 Delivery progress of one shop's part
+	}
+
 }
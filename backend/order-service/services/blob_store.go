@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound means nothing is stored under the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps generated files, such as invoice PDFs, under slash-separated keys
+type BlobStore interface {
+	Get(key string) ([]byte, error)
+	Put(key string, content []byte) error
+}
+
+type localBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore creates a BlobStore that keeps blobs as files under dir
+func NewLocalBlobStore(dir string) BlobStore {
+	return &localBlobStore{dir: dir}
+}
+
+func (s *localBlobStore) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	return content, err
+}
+
+// Put writes the blob next to its final name and renames it into place, so readers
+// never see half a file
+func (s *localBlobStore) Put(key string, content []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("invalid blob key " + key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
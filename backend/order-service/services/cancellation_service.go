@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"order-service/models"
+)
+
+var (
+	ErrCancelReasonRequired = errors.New("a reason is required to cancel a buyer's order")
+	ErrCancelSettlement     = errors.New("order cancelled but its stock or payment could not be settled")
+)
+
+// CancellationService cancels orders and gives back what they held: reserved stock in
+// product-service and the buyer's money in payment-service
+type CancellationService struct {
+	Orders   *OrderService
+	Products ProductClient
+	Payments PaymentClient
+}
+
+func NewCancellationService(orders *OrderService, products ProductClient, payments PaymentClient) *CancellationService {
+	return &CancellationService{Orders: orders, Products: products, Payments: payments}
+}
+
+// CancelOrder cancels whatever part of an order actor may still cancel, then releases
+// its stock and refunds its share of the payment. Buyers can cancel until their goods
+// ship; sellers cancel their own sub-orders and must say why. Calling it again for an
+// order already cancelled only retries the stock release and refunds.
+func (s *CancellationService) CancelOrder(orderID uint, actor Actor, reason string) (*models.Order, error) {
+	order, err := s.Orders.Repo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	actor, err = Authorize(actor, ActionCancelOrder, order)
+	if err != nil {
+		return nil, err
+	}
+	if (actor.Role == models.ActorSeller || actor.Role == models.ActorAdmin) && reason == "" {
+		return nil, ErrCancelReasonRequired
+	}
+
+	err = s.Orders.transition(order, models.OrderStatusCancelled, actor, reason)
+	var transitionErr *TransitionError
+	if err != nil && !errors.As(err, &transitionErr) {
+		return nil, err
+	}
+	if settleErr := s.settle(order, actor); settleErr != nil {
+		return order, fmt.Errorf("%w: %v", ErrCancelSettlement, settleErr)
+	}
+	// A refused transition is fine when there was nothing left to cancel
+	if err != nil && !cancelledFor(order, actor) {
+		return nil, err
+	}
+	return order, nil
+}
+
+// settle releases the stock and refunds the payment of every cancelled part of the
+// order actor can see, and the order's coupon use once all of it is cancelled. Refund
+// references are fixed per part, so settling twice never refunds twice.
+func (s *CancellationService) settle(order *models.Order, actor Actor) error {
+	if err := s.Orders.releaseCoupon(order); err != nil {
+		return err
+	}
+	if len(order.SubOrders) == 0 {
+		if order.Status != models.OrderStatusCancelled {
+			return nil
+		}
+		return s.release(order, nil, order.TotalAmount, fmt.Sprintf("cancel-order-%d", order.ID))
+	}
+	for _, sub := range order.SubOrders {
+		if sub.Status != models.OrderStatusCancelled || !actor.canSee(&sub) {
+			continue
+		}
+		var productIDs []uint
+		for _, item := range order.OrderItems {
+			if item.SellerID == sub.SellerID && item.ShopID == sub.ShopID {
+				productIDs = append(productIDs, item.ProductID)
+			}
+		}
+		if err := s.release(order, productIDs, sub.TotalAmount, fmt.Sprintf("cancel-sub-%d", sub.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release gives back the stock and sale units held for productIDs (all of the order's
+// when empty) and refunds amount under reference
+func (s *CancellationService) release(order *models.Order, productIDs []uint, amount float64, reference string) error {
+	if err := s.Products.ReleaseStock(order.ID, productIDs); err != nil {
+		return err
+	}
+	if err := s.Orders.releaseSaleClaims(order, productIDs); err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return nil
+	}
+	return s.Payments.RefundPayment(order.ID, amount, reference, "order cancelled")
+}
+
+// canSee reports whether the actor deals with a sub-order: sellers only with their own
+func (a Actor) canSee(sub *models.SubOrder) bool {
+	return a.Role != models.ActorSeller || a.OwnsSubOrder(sub)
+}
+
+// cancelledFor reports whether every part of the order the actor deals with is cancelled
+func cancelledFor(order *models.Order, actor Actor) bool {
+	if len(order.SubOrders) == 0 {
+		return order.Status == models.OrderStatusCancelled
+	}
+	for _, sub := range order.SubOrders {
+		if actor.canSee(&sub) && sub.Status != models.OrderStatusCancelled {
+			return false
+		}
+	}
+	return true
+}
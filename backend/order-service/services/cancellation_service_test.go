@@ -0,0 +1,105 @@
+package services
+
+import (
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCancellationService_CancelOrder_BuyerGetsStockReleasedAndRefund(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, payments)
+	order := pendingOrder()
+	order.Status = models.OrderStatusConfirmed
+
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	repo.On("Transition", transitionTo(models.OrderStatusCancelled)).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	payments.On("RefundPayment", uint(7), 3000.0, "cancel-order-7", "order cancelled").Return(nil)
+
+	cancelled, err := service.CancelOrder(7, Actor{ID: 1, Role: models.ActorBuyer}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusCancelled, cancelled.Status)
+	payments.AssertExpectations(t)
+	products.AssertExpectations(t)
+}
+
+func TestCancellationService_CancelOrder_SellerSettlesOnlyOwnSubOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, payments)
+	order := splitOrderFixture()
+	order.SaleClaimKey = "claim-7"
+	order.SubOrders[0].TotalAmount = 21.6
+
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	repo.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 71 && entry.ToStatus == models.OrderStatusCancelled && entry.Reason == "out of stock"
+	})).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint{10}).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint{10}).Return(nil)
+	payments.On("RefundPayment", uint(7), 21.6, "cancel-sub-71", "order cancelled").Return(nil)
+
+	_, err := service.CancelOrder(7, Actor{ID: 2, Role: models.ActorSeller}, "out of stock")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusConfirmed, order.SubOrders[1].Status)
+	repo.AssertNumberOfCalls(t, "Transition", 1)
+	products.AssertExpectations(t)
+}
+
+func TestCancellationService_CancelOrder_SellerNeedsReason(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, new(MockPaymentClient))
+
+	repo.On("GetByID", uint(7)).Return(splitOrderFixture(), nil)
+
+	_, err := service.CancelOrder(7, Actor{ID: 2, Role: models.ActorSeller}, "")
+
+	assert.ErrorIs(t, err, ErrCancelReasonRequired)
+	repo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestCancellationService_CancelOrder_RetriesSettlementOfCancelledOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, payments)
+	order := pendingOrder()
+	order.Status = models.OrderStatusCancelled
+
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	payments.On("RefundPayment", uint(7), 3000.0, "cancel-order-7", "order cancelled").Return(nil)
+
+	_, err := service.CancelOrder(7, Actor{ID: 1, Role: models.ActorBuyer}, "")
+
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "Transition", mock.Anything)
+	payments.AssertExpectations(t)
+}
+
+func TestCancellationService_CancelOrder_RefusesShippedOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, new(MockPaymentClient))
+	order := pendingOrder()
+	order.Status = models.OrderStatusShipped
+
+	repo.On("GetByID", uint(7)).Return(order, nil)
+
+	_, err := service.CancelOrder(7, Actor{ID: 1, Role: models.ActorBuyer}, "")
+
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+	products.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+}
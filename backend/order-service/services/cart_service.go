@@ -0,0 +1,341 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"order-service/models"
+	"order-service/repository"
+
+	"gorm.io/gorm"
+)
+
+// Cart size limits
+const (
+	MaxCartItems        = 50
+	MaxCartItemQuantity = 99
+)
+
+var (
+	ErrInvalidCartQuantity = errors.New("quantity must be between 1 and 99")
+	ErrCartFull            = errors.New("a cart can hold at most 50 different products")
+	ErrCartItemNotFound    = errors.New("product is not in the cart")
+	ErrProductUnavailable  = errors.New("product is not available")
+	ErrInsufficientStock   = errors.New("not enough stock available")
+	ErrCartEmpty           = errors.New("cart is empty")
+	ErrCartNeedsReview     = errors.New("cart changed since it was last viewed; review it before checking out")
+)
+
+// CartOwner identifies whose cart a request is for: a signed-in buyer, a guest holding
+// a cart token, or a new guest. Token is updated when a guest cart is created or
+// merged into the buyer's cart.
+type CartOwner struct {
+	UserID uint
+	Token  string
+}
+
+// CheckoutOptions are the buyer's delivery and payment choices, any coupon, and the
+// totals they were shown if the client sends them
+type CheckoutOptions struct {
+	ShippingMethod string
+	PaymentMethod  string
+	CouponCode     string
+	Quote          *OrderTotals
+}
+
+type CartService struct {
+	Repo      repository.CartRepository
+	Products  ProductClient
+	Checkouts *CheckoutService
+}
+
+func NewCartService(repo repository.CartRepository, products ProductClient, checkout *CheckoutService) *CartService {
+	return &CartService{Repo: repo, Products: products, Checkouts: checkout}
+}
+
+// GetCart returns the owner's cart revalidated against the catalogue. Prices shown
+// here become the prices the shopper has seen.
+func (s *CartService) GetCart(owner *CartOwner) (*models.Cart, error) {
+	cart, err := s.resolve(owner, false)
+	if err != nil || cart.ID == 0 {
+		return cart, err
+	}
+	if _, err := s.revalidate(cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// AddItem adds quantity of a product to the cart, on top of any already there
+func (s *CartService) AddItem(owner *CartOwner, productID uint, quantity int) (*models.Cart, error) {
+	if quantity < 1 || quantity > MaxCartItemQuantity {
+		return nil, ErrInvalidCartQuantity
+	}
+	cart, err := s.resolve(owner, true)
+	if err != nil {
+		return nil, err
+	}
+	if existing := findCartItem(cart, productID); existing != nil {
+		quantity += existing.Quantity
+	} else if len(cart.Items) >= MaxCartItems {
+		return nil, ErrCartFull
+	}
+	if quantity > MaxCartItemQuantity {
+		return nil, ErrInvalidCartQuantity
+	}
+	return s.setItem(cart, productID, quantity)
+}
+
+// UpdateItem sets a product's quantity; zero removes it
+func (s *CartService) UpdateItem(owner *CartOwner, productID uint, quantity int) (*models.Cart, error) {
+	if quantity == 0 {
+		return s.RemoveItem(owner, productID)
+	}
+	if quantity < 1 || quantity > MaxCartItemQuantity {
+		return nil, ErrInvalidCartQuantity
+	}
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if findCartItem(cart, productID) == nil {
+		return nil, ErrCartItemNotFound
+	}
+	return s.setItem(cart, productID, quantity)
+}
+
+// RemoveItem takes a product out of the cart
+func (s *CartService) RemoveItem(owner *CartOwner, productID uint) (*models.Cart, error) {
+	cart, err := s.resolve(owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if cart.ID == 0 {
+		return nil, ErrCartItemNotFound
+	}
+	removed, err := s.Repo.RemoveItem(cart.ID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, ErrCartItemNotFound
+	}
+	return s.GetCart(owner)
+}
+
+// Merge moves a guest cart into the buyer's own cart, typically right after login.
+// A token with no cart behind it is ignored.
+func (s *CartService) Merge(userID uint, token string) error {
+	if token == "" {
+		return nil
+	}
+	guest, err := s.Repo.GetByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cart, err := s.Repo.ForUser(userID)
+	if err != nil {
+		return err
+	}
+	return s.Repo.Merge(guest.ID, cart.ID, MaxCartItemQuantity)
+}
+
+// Checkout turns the buyer's cart into an order and empties the cart. If anything in
+// the cart changed since the buyer last saw it, the revalidated cart is returned with
+// ErrCartNeedsReview instead; checking out again after reviewing it succeeds. A
+// checkout that is rolled back cancels the order and leaves the cart as it was. An
+// error after the order was saved comes back with the order, so the caller knows it exists.
+func (s *CartService) Checkout(buyerID uint, options CheckoutOptions) (*models.Order, *models.Cart, error) {
+	cart, err := s.Repo.ForUser(buyerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, nil, ErrCartEmpty
+	}
+	changed, err := s.revalidate(cart)
+	if err != nil {
+		return nil, nil, err
+	}
+	if changed || cart.HasIssues {
+		return nil, cart, ErrCartNeedsReview
+	}
+
+	order := &models.Order{
+		BuyerID:        buyerID,
+		ShippingMethod: options.ShippingMethod,
+		PaymentMethod:  options.PaymentMethod,
+		CouponCode:     options.CouponCode,
+	}
+	for _, item := range cart.Items {
+		order.OrderItems = append(order.OrderItems, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	if err := s.Checkouts.PlaceOrder(order, options.Quote); err != nil {
+		if errors.Is(err, ErrTotalsMismatch) {
+			return order, cart, err
+		}
+		if order.ID != 0 {
+			return order, nil, err
+		}
+		return nil, nil, err
+	}
+	if status := order.Checkout.Status; status == models.SagaStatusFailed || status == models.SagaStatusCompensating {
+		return order, nil, nil
+	}
+	if err := s.Repo.Clear(cart.ID); err != nil {
+		return order, nil, fmt.Errorf("order %d placed but cart not cleared: %w", order.ID, err)
+	}
+	return order, nil, nil
+}
+
+// resolve loads the owner's cart. A signed-in buyer who still holds a guest token has
+// the guest cart merged in first. Guests without a cart get an empty, unsaved one unless
+// create is set, in which case a new guest cart and token are made.
+func (s *CartService) resolve(owner *CartOwner, create bool) (*models.Cart, error) {
+	if owner.UserID != 0 {
+		if owner.Token != "" {
+			if err := s.Merge(owner.UserID, owner.Token); err != nil {
+				return nil, err
+			}
+			owner.Token = ""
+		}
+		return s.Repo.ForUser(owner.UserID)
+	}
+
+	if owner.Token != "" {
+		cart, err := s.Repo.GetByToken(owner.Token)
+		if err == nil {
+			return cart, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if !create {
+		return &models.Cart{Items: []models.CartItem{}}, nil
+	}
+	token, err := newCartToken()
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.Repo.CreateGuest(token)
+	if err != nil {
+		return nil, err
+	}
+	owner.Token = token
+	return cart, nil
+}
+
+// setItem checks the product can be bought in that quantity, saves the line at the
+// current price and returns the revalidated cart
+func (s *CartService) setItem(cart *models.Cart, productID uint, quantity int) (*models.Cart, error) {
+	available, err := s.Products.CheckAvailability([]PriceRequestItem{{ProductID: productID, Quantity: quantity}})
+	if err != nil {
+		return nil, err
+	}
+	if len(available) != 1 || !available[0].Available {
+		return nil, ErrProductUnavailable
+	}
+	if available[0].InStock < quantity {
+		return nil, ErrInsufficientStock
+	}
+	if err := s.Repo.SetItem(cart.ID, productID, quantity, available[0].UnitPrice); err != nil {
+		return nil, err
+	}
+
+	if item := findCartItem(cart, productID); item != nil {
+		item.Quantity = quantity
+		item.SavedPrice = available[0].UnitPrice
+	} else {
+		cart.Items = append(cart.Items, models.CartItem{CartID: cart.ID, ProductID: productID, Quantity: quantity, SavedPrice: available[0].UnitPrice})
+	}
+	if _, err := s.revalidate(cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// revalidate fills in each item's current name, price and stock, flags items that can
+// no longer be bought as they are, and totals the cart. Changed prices are saved as seen;
+// the return value reports whether any price changed.
+func (s *CartService) revalidate(cart *models.Cart) (bool, error) {
+	cart.Total = 0
+	cart.HasIssues = false
+	if len(cart.Items) == 0 {
+		return false, nil
+	}
+
+	requests := make([]PriceRequestItem, len(cart.Items))
+	for i, item := range cart.Items {
+		requests[i] = PriceRequestItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	available, err := s.Products.CheckAvailability(requests)
+	if err != nil {
+		return false, err
+	}
+	if len(available) != len(cart.Items) {
+		return false, fmt.Errorf("product-service checked %d of %d cart items", len(available), len(cart.Items))
+	}
+
+	seen := make(map[uint]float64)
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		current := available[i]
+		item.ProductName = current.ProductName
+		item.UnitPrice = current.UnitPrice
+		item.InStock = current.InStock
+		item.Issue = ""
+		item.PriceChanged = false
+
+		switch {
+		case !current.Available:
+			item.Issue = models.CartIssueUnavailable
+		case current.InStock < item.Quantity:
+			item.Issue = models.CartIssueInsufficientStock
+		}
+		if item.Issue != "" {
+			cart.HasIssues = true
+			item.Subtotal = 0
+			continue
+		}
+
+		item.Subtotal = item.UnitPrice * float64(item.Quantity)
+		cart.Total += item.Subtotal
+		if item.UnitPrice != item.SavedPrice {
+			item.PriceChanged = true
+			seen[item.ProductID] = item.UnitPrice
+			item.SavedPrice = item.UnitPrice
+		}
+	}
+
+	if len(seen) > 0 {
+		if err := s.Repo.SavePrices(cart.ID, seen); err != nil {
+			return false, err
+		}
+	}
+	return len(seen) > 0, nil
+}
+
+// findCartItem returns the cart's line for a product, or nil
+func findCartItem(cart *models.Cart, productID uint) *models.CartItem {
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+// newCartToken generates an unguessable guest cart token
+func newCartToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func available(productID uint, quantity int, price float64, inStock int) ItemAvailability {
+	return ItemAvailability{
+		PricedItem: PricedItem{ProductID: productID, ProductName: "Saree", Quantity: quantity, UnitPrice: price},
+		Available:  true,
+		InStock:    inStock,
+	}
+}
+
+func TestCartService_AddItem_CreatesGuestCart(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("CreateGuest", mock.AnythingOfType("string")).Return(&models.Cart{ID: 4}, nil)
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).
+		Return([]ItemAvailability{available(10, 2, 1500, 5)}, nil)
+	carts.On("SetItem", uint(4), uint(10), 2, 1500.0).Return(nil)
+
+	owner := &CartOwner{}
+	cart, err := service.AddItem(owner, 10, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, owner.Token, 64)
+	assert.Equal(t, 3000.0, cart.Total)
+	assert.False(t, cart.Items[0].PriceChanged)
+}
+
+func TestCartService_AddItem_AddsToExistingQuantity(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 2, SavedPrice: 1500},
+	}}, nil)
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 5}}).
+		Return([]ItemAvailability{available(10, 5, 1500, 4)}, nil)
+
+	_, err := service.AddItem(&CartOwner{UserID: 1}, 10, 3)
+
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+	carts.AssertNotCalled(t, "SetItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCartService_GetCart_FlagsPriceAndStockChanges(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("GetByToken", "guest").Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 1, SavedPrice: 1500},
+		{ProductID: 11, Quantity: 3, SavedPrice: 800},
+		{ProductID: 12, Quantity: 1, SavedPrice: 300},
+	}}, nil)
+	products.On("CheckAvailability", mock.Anything).Return([]ItemAvailability{
+		available(10, 1, 1200, 5),
+		available(11, 3, 800, 2),
+		{PricedItem: PricedItem{ProductID: 12, Quantity: 1}},
+	}, nil)
+	carts.On("SavePrices", uint(4), map[uint]float64{10: 1200}).Return(nil)
+
+	cart, err := service.GetCart(&CartOwner{Token: "guest"})
+
+	assert.NoError(t, err)
+	assert.True(t, cart.Items[0].PriceChanged)
+	assert.Equal(t, 1200.0, cart.Items[0].SavedPrice)
+	assert.Equal(t, models.CartIssueInsufficientStock, cart.Items[1].Issue)
+	assert.Equal(t, models.CartIssueUnavailable, cart.Items[2].Issue)
+	assert.True(t, cart.HasIssues)
+	assert.Equal(t, 1200.0, cart.Total)
+	carts.AssertExpectations(t)
+}
+
+func TestCartService_GetCart_UnknownGuestGetsEmptyCart(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("GetByToken", "expired").Return(nil, gorm.ErrRecordNotFound)
+
+	cart, err := service.GetCart(&CartOwner{Token: "expired"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, cart.Items)
+	carts.AssertNotCalled(t, "CreateGuest", mock.Anything)
+}
+
+func TestCartService_GetCart_MergesGuestCartAfterLogin(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("GetByToken", "guest").Return(&models.Cart{ID: 3}, nil)
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4}, nil)
+	carts.On("Merge", uint(3), uint(4), MaxCartItemQuantity).Return(nil)
+
+	owner := &CartOwner{UserID: 1, Token: "guest"}
+	_, err := service.GetCart(owner)
+
+	assert.NoError(t, err)
+	assert.Empty(t, owner.Token)
+	carts.AssertExpectations(t)
+}
+
+func TestCartService_Checkout_PlacesOrderAndClearsCart(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	orders := new(repository.MockOrderRepository)
+	sagas := new(repository.MockCheckoutSagaRepository)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	checkout := &CheckoutService{
+		Repo:      sagas,
+		Orders:    NewOrderService(orders, products),
+		Products:  products,
+		Payments:  payments,
+		Shipments: shipments,
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 2, SavedPrice: 1500},
+	}}, nil)
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).
+		Return([]ItemAvailability{available(10, 2, 1500, 5)}, nil)
+	products.On("ClaimPrices", uint(1), mock.Anything, []PriceRequestItem{{ProductID: 10, Quantity: 2}}).
+		Return([]PricedItem{{ProductID: 10, ProductName: "Saree", Quantity: 2, UnitPrice: 1500}}, nil)
+	// The checkout saga is saved with the order
+	orders.On("Create", mock.MatchedBy(func(order *models.Order) bool { return order.Checkout != nil })).Return(nil)
+	products.On("RecordOrder", mock.Anything, []uint{10}).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", mock.Anything, []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return(nil)
+	payments.On("OpenPayment", mock.Anything, uint(1), mock.AnythingOfType("float64"), PaymentMethodCOD).Return(uint(4), nil)
+	orders.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+	shipments.On("RequestShipment", mock.Anything, mock.Anything, uint(1)).Return(uint(9), nil)
+	orders.On("SetSubOrderShipment", mock.Anything, uint(9)).Return(nil)
+	carts.On("Clear", uint(4)).Return(nil)
+
+	order, _, err := service.Checkout(1, CheckoutOptions{PaymentMethod: PaymentMethodCOD})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SagaStatusCompleted, order.Checkout.Status)
+	assert.Equal(t, 3000.0, order.Subtotal)
+	assert.Equal(t, 10.0, order.CODFee)
+	assert.Equal(t, "standard", order.ShippingMethod)
+	carts.AssertExpectations(t)
+	sagas.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCartService_Checkout_ReturnsSavedOrderWhenCartNotCleared(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	orders := new(repository.MockOrderRepository)
+	sagas := new(repository.MockCheckoutSagaRepository)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	checkout := &CheckoutService{
+		Repo:      sagas,
+		Orders:    NewOrderService(orders, products),
+		Products:  products,
+		Payments:  payments,
+		Shipments: shipments,
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 2, SavedPrice: 1500},
+	}}, nil)
+	products.On("CheckAvailability", mock.Anything).Return([]ItemAvailability{available(10, 2, 1500, 5)}, nil)
+	products.On("ClaimPrices", uint(1), mock.Anything, mock.Anything).
+		Return([]PricedItem{{ProductID: 10, ProductName: "Saree", Quantity: 2, UnitPrice: 1500}}, nil)
+	orders.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Order).ID = 7
+	}).Return(nil)
+	products.On("RecordOrder", mock.Anything, mock.Anything).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", mock.Anything, mock.Anything).Return(nil)
+	payments.On("OpenPayment", mock.Anything, uint(1), mock.AnythingOfType("float64"), PaymentMethodCOD).Return(uint(4), nil)
+	orders.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+	shipments.On("RequestShipment", mock.Anything, mock.Anything, uint(1)).Return(uint(9), nil)
+	orders.On("SetSubOrderShipment", mock.Anything, uint(9)).Return(nil)
+	carts.On("Clear", uint(4)).Return(errors.New("db down"))
+
+	order, _, err := service.Checkout(1, CheckoutOptions{PaymentMethod: PaymentMethodCOD})
+
+	assert.Error(t, err)
+	// The order exists, so the caller must not offer to place it again
+	assert.Equal(t, uint(7), order.ID)
+}
+
+func TestCartService_Checkout_KeepsCartWhenCheckoutFails(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	orders := new(repository.MockOrderRepository)
+	sagas := new(repository.MockCheckoutSagaRepository)
+	payments := new(MockPaymentClient)
+	checkout := &CheckoutService{
+		Repo:      sagas,
+		Orders:    NewOrderService(orders, products),
+		Products:  products,
+		Payments:  payments,
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 2, SavedPrice: 1500},
+	}}, nil)
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).
+		Return([]ItemAvailability{available(10, 2, 1500, 5)}, nil)
+	products.On("ClaimPrices", uint(1), mock.Anything, []PriceRequestItem{{ProductID: 10, Quantity: 2}}).
+		Return([]PricedItem{{ProductID: 10, ProductName: "Saree", Quantity: 2, UnitPrice: 1500}}, nil)
+	orders.On("Create", mock.Anything).Return(nil)
+	products.On("RecordOrder", mock.Anything, []uint{10}).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", mock.Anything, mock.Anything).
+		Return(&ProductServiceError{StatusCode: http.StatusConflict, Message: "insufficient stock"})
+	payments.On("VoidPayment", mock.Anything).Return(nil)
+	products.On("ReleaseStock", mock.Anything, []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", mock.Anything, []uint(nil)).Return(nil)
+	orders.On("Transition", transitionTo(models.OrderStatusCancelled)).Return(nil)
+
+	order, _, err := service.Checkout(1, CheckoutOptions{PaymentMethod: PaymentMethodCOD})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SagaStatusFailed, order.Checkout.Status)
+	carts.AssertNotCalled(t, "Clear", mock.Anything)
+}
+
+func TestCartService_Checkout_StopsWhenPriceChanged(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	orders := new(repository.MockOrderRepository)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(orders, products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4, Items: []models.CartItem{
+		{ProductID: 10, Quantity: 2, SavedPrice: 1500},
+	}}, nil)
+	products.On("CheckAvailability", mock.Anything).Return([]ItemAvailability{available(10, 2, 1700, 5)}, nil)
+	carts.On("SavePrices", uint(4), map[uint]float64{10: 1700}).Return(nil)
+
+	_, cart, err := service.Checkout(1, CheckoutOptions{})
+
+	assert.ErrorIs(t, err, ErrCartNeedsReview)
+	assert.True(t, cart.Items[0].PriceChanged)
+	orders.AssertNotCalled(t, "Create", mock.Anything)
+	carts.AssertNotCalled(t, "Clear", mock.Anything)
+}
+
+func TestCartService_Checkout_RejectsEmptyCart(t *testing.T) {
+	carts := new(repository.MockCartRepository)
+	products := new(MockProductClient)
+	checkout := &CheckoutService{
+		Repo:      new(repository.MockCheckoutSagaRepository),
+		Orders:    NewOrderService(new(repository.MockOrderRepository), products),
+		Products:  products,
+		Payments:  new(MockPaymentClient),
+		Shipments: new(MockShipmentClient),
+	}
+	service := NewCartService(carts, products, checkout)
+
+	carts.On("ForUser", uint(1)).Return(&models.Cart{ID: 4}, nil)
+
+	_, _, err := service.Checkout(1, CheckoutOptions{})
+
+	assert.ErrorIs(t, err, ErrCartEmpty)
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+)
+
+// Checkout saga retry settings
+const (
+	MaxCheckoutAttempts    = 8                // failed tries of one step before the checkout is undone
+	CheckoutResumeInterval = 30 * time.Second // how often unfinished checkouts are picked up
+	checkoutLease          = 2 * time.Minute  // how long a worker owns a saga it is running
+	checkoutRetryBase      = 30 * time.Second
+	checkoutRetryMax       = 30 * time.Minute
+	checkoutResumeBatch    = 50
+)
+
+var ErrOrderCancelled = errors.New("order was cancelled during checkout")
+
+// CheckoutService runs the checkout saga for placed orders: reserve stock, open the
+// payment, confirm the order and request its shipment. Progress is saved after every
+// step so a restarted service carries on where it stopped. A step that fails for good,
+// or keeps failing, undoes the checkout: the payment is voided, the stock released and
+// the order cancelled.
+type CheckoutService struct {
+	Repo      repository.CheckoutSagaRepository
+	Orders    *OrderService
+	Products  ProductClient
+	Payments  PaymentClient
+	Shipments ShipmentClient
+}
+
+func NewCheckoutService(repo repository.CheckoutSagaRepository, orders *OrderService, products ProductClient, payments PaymentClient, shipments ShipmentClient) *CheckoutService {
+	return &CheckoutService{Repo: repo, Orders: orders, Products: products, Payments: payments, Shipments: shipments}
+}
+
+// PlaceOrder creates the order together with its checkout saga, so no order is saved
+// without one, and runs the checkout as far as it can go now. The order's Checkout
+// reports whether it completed, failed, or will be retried.
+func (s *CheckoutService) PlaceOrder(order *models.Order, quote *OrderTotals) error {
+	order.Checkout = newCheckoutSaga()
+	if err := s.Orders.PlaceOrder(order, quote); err != nil {
+		order.Checkout = nil
+		return err
+	}
+	return s.Start(order)
+}
+
+// Start runs the checkout saga of a newly created order. Orders are saved with their
+// saga; one is created here for an order that has none.
+func (s *CheckoutService) Start(order *models.Order) error {
+	if order.Checkout == nil {
+		saga := newCheckoutSaga()
+		saga.OrderID = order.ID
+		if err := s.Repo.Create(saga); err != nil {
+			return err
+		}
+		order.Checkout = saga
+	}
+	return s.run(order.Checkout, order)
+}
+
+// newCheckoutSaga returns a saga ready for its first step. It is leased to the caller
+// so that ResumePending leaves it alone while it runs.
+func newCheckoutSaga() *models.CheckoutSaga {
+	return &models.CheckoutSaga{
+		Status:        models.SagaStatusRunning,
+		Step:          models.SagaStepReserveStock,
+		NextAttemptAt: time.Now().Add(checkoutLease),
+	}
+}
+
+// ResumePending carries on every unfinished checkout that is due, such as those
+// interrupted by a restart or waiting to retry a step
+func (s *CheckoutService) ResumePending() error {
+	due, err := s.Repo.ListDue(time.Now(), checkoutResumeBatch)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		saga := &due[i]
+		claimed, err := s.Repo.Claim(saga, time.Now().Add(checkoutLease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		order, err := s.Orders.GetOrder(saga.OrderID)
+		if err != nil {
+			log.Printf("checkout for order %d: loading order: %v", saga.OrderID, err)
+			continue
+		}
+		order.Checkout = saga
+		if err := s.run(saga, order); err != nil {
+			log.Printf("checkout for order %d: %v", saga.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// Run resumes unfinished checkouts straight away and then every interval until ctx ends
+func (s *CheckoutService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ResumePending(); err != nil {
+			log.Printf("resuming checkouts: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run drives a saga until it finishes or has to wait for a retry. Only failures to
+// save the saga itself are returned.
+func (s *CheckoutService) run(saga *models.CheckoutSaga, order *models.Order) error {
+	for {
+		switch saga.Status {
+		case models.SagaStatusRunning:
+			if saga.Step == models.SagaStepDone {
+				saga.Status = models.SagaStatusCompleted
+				return s.Repo.Save(saga)
+			}
+			err := s.step(saga, order)
+			switch {
+			case err == nil:
+				saga.Step = nextCheckoutStep(saga.Step)
+				saga.Attempts = 0
+				saga.LastError = ""
+			case isPermanentCheckoutError(err) || saga.Attempts+1 >= MaxCheckoutAttempts:
+				saga.Status = models.SagaStatusCompensating
+				saga.FailureReason = fmt.Sprintf("%s: %v", saga.Step, err)
+				saga.Attempts = 0
+				saga.LastError = ""
+			default:
+				return s.retryLater(saga, err)
+			}
+			if err := s.Repo.Save(saga); err != nil {
+				return err
+			}
+
+		case models.SagaStatusCompensating:
+			if err := s.compensate(saga, order); err != nil {
+				return s.retryLater(saga, err)
+			}
+			saga.Status = models.SagaStatusFailed
+			saga.LastError = ""
+			return s.Repo.Save(saga)
+
+		default:
+			return nil
+		}
+	}
+}
+
+// step runs the saga's current step. Every step can safely be repeated.
+func (s *CheckoutService) step(saga *models.CheckoutSaga, order *models.Order) error {
+	switch saga.Step {
+	case models.SagaStepReserveStock:
+		return s.Products.ReserveStock(order.ID, orderRequests(order))
+
+	case models.SagaStepCreatePayment:
+		if order.TotalAmount <= 0 {
+			return nil
+		}
+		method := order.PaymentMethod
+		if method == "" {
+			method = PaymentMethodCard
+		}
+		paymentID, err := s.Payments.OpenPayment(order.ID, order.BuyerID, order.TotalAmount, method)
+		if err != nil {
+			return err
+		}
+		saga.PaymentID = paymentID
+		return nil
+
+	case models.SagaStepConfirmOrder:
+		return s.confirm(order)
+
+	case models.SagaStepRequestShipment:
+		if len(order.SubOrders) > 0 {
+			return s.requestShipments(order)
+		}
+		if !shipsGoods(order) {
+			return nil
+		}
+		shipmentID, err := s.Shipments.RequestShipment(order.ID, 0, order.BuyerID)
+		if err != nil {
+			return err
+		}
+		saga.ShipmentID = shipmentID
+		return nil
+	}
+	return fmt.Errorf("unknown checkout step %q", saga.Step)
+}
+
+// requestShipments asks for one shipment per sub-order with goods to send. Sub-orders
+// already shipping, or cancelled, are skipped.
+func (s *CheckoutService) requestShipments(order *models.Order) error {
+	for i := range order.SubOrders {
+		sub := &order.SubOrders[i]
+		if sub.ShipmentID != 0 || sub.Status == models.OrderStatusCancelled || onlyDigital(order, sub) {
+			continue
+		}
+		shipmentID, err := s.Shipments.RequestShipment(order.ID, sub.SellerID, order.BuyerID)
+		if err != nil {
+			return err
+		}
+		if err := s.Orders.Repo.SetSubOrderShipment(sub.ID, shipmentID); err != nil {
+			return err
+		}
+		sub.ShipmentID = shipmentID
+	}
+	return nil
+}
+
+// confirm moves a pending order to confirmed. An order already confirmed, for example
+// by its payment completing first, needs nothing more.
+func (s *CheckoutService) confirm(order *models.Order) error {
+	switch order.Status {
+	case models.OrderStatusPending:
+	case models.OrderStatusCancelled:
+		return ErrOrderCancelled
+	default:
+		return nil
+	}
+	err := s.Orders.transition(order, models.OrderStatusConfirmed, SystemActor, "checkout completed")
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return s.reload(order, err)
+	}
+	return err
+}
+
+// compensate undoes whatever the saga may have done, and gives back the order's sale units. Each action is harmless when
+// there is nothing to undo, so all of them run whichever step failed.
+func (s *CheckoutService) compensate(saga *models.CheckoutSaga, order *models.Order) error {
+	if err := s.Payments.VoidPayment(order.ID); err != nil {
+		return err
+	}
+	if err := s.Products.ReleaseStock(order.ID, nil); err != nil {
+		return err
+	}
+	if err := s.Orders.releaseSaleClaims(order, nil); err != nil {
+		return err
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return s.Orders.releaseCoupon(order)
+	}
+	err := s.Orders.transition(order, models.OrderStatusCancelled, SystemActor, "checkout failed: "+saga.FailureReason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return s.reload(order, err)
+	}
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		// The order has moved on, e.g. it shipped, and can no longer be cancelled
+		log.Printf("checkout for order %d undone but order left %s: %v", order.ID, order.Status, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.Orders.releaseCoupon(order)
+}
+
+// reload refreshes an order whose status changed underneath the saga and returns cause
+// so the step is retried against the new status
+func (s *CheckoutService) reload(order *models.Order, cause error) error {
+	fresh, err := s.Orders.GetOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	fresh.Checkout = order.Checkout
+	*order = *fresh
+	return cause
+}
+
+// retryLater records a failed attempt and schedules the next one with backoff
+func (s *CheckoutService) retryLater(saga *models.CheckoutSaga, err error) error {
+	saga.Attempts++
+	saga.LastError = err.Error()
+	delay := checkoutRetryBase << (saga.Attempts - 1)
+	if delay > checkoutRetryMax || delay <= 0 {
+		delay = checkoutRetryMax
+	}
+	saga.NextAttemptAt = time.Now().Add(delay)
+	return s.Repo.Save(saga)
+}
+
+// nextCheckoutStep returns the step that follows step
+func nextCheckoutStep(step string) string {
+	switch step {
+	case models.SagaStepReserveStock:
+		return models.SagaStepCreatePayment
+	case models.SagaStepCreatePayment:
+		return models.SagaStepConfirmOrder
+	case models.SagaStepConfirmOrder:
+		return models.SagaStepRequestShipment
+	default:
+		return models.SagaStepDone
+	}
+}
+
+// isPermanentCheckoutError reports whether retrying a failed step cannot help: another
+// service rejected the request, or the order can no longer be confirmed
+func isPermanentCheckoutError(err error) bool {
+	var transitionErr *TransitionError
+	if errors.Is(err, ErrOrderCancelled) || errors.As(err, &transitionErr) {
+		return true
+	}
+	status := 0
+	var productErr *ProductServiceError
+	var serviceErr *ServiceError
+	switch {
+	case errors.As(err, &productErr):
+		status = productErr.StatusCode
+	case errors.As(err, &serviceErr):
+		status = serviceErr.StatusCode
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return status >= 400 && status < 500
+}
+
+// orderRequests lists an order's products and quantities
+func orderRequests(order *models.Order) []PriceRequestItem {
+	requests := make([]PriceRequestItem, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		requests[i] = PriceRequestItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	return requests
+}
+
+// shipsGoods reports whether an order has anything physical to send
+func shipsGoods(order *models.Order) bool {
+	for _, item := range order.OrderItems {
+		if !item.IsDigital {
+			return true
+		}
+	}
+	return false
+}
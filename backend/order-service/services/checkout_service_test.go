@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func pendingOrder() *models.Order {
+	return &models.Order{ID: 7, BuyerID: 1, Status: models.OrderStatusPending, TotalAmount: 3000, PaymentMethod: PaymentMethodCard, SaleClaimKey: "claim-7",
+		OrderItems: []models.OrderItem{{ProductID: 10, Quantity: 2}}}
+}
+
+func TestCheckoutService_Start_CompletesEveryStep(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	service := NewCheckoutService(sagas, NewOrderService(orders, products), products, payments, shipments)
+	order := pendingOrder()
+
+	sagas.On("Create", mock.AnythingOfType("*models.CheckoutSaga")).Return(nil)
+	sagas.On("Save", mock.AnythingOfType("*models.CheckoutSaga")).Return(nil)
+	products.On("ReserveStock", uint(7), []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return(nil)
+	payments.On("OpenPayment", uint(7), uint(1), 3000.0, PaymentMethodCard).Return(uint(4), nil)
+	orders.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+	shipments.On("RequestShipment", uint(7), uint(0), uint(1)).Return(uint(9), nil)
+
+	err := service.Start(order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SagaStatusCompleted, order.Checkout.Status)
+	assert.Equal(t, uint(4), order.Checkout.PaymentID)
+	assert.Equal(t, uint(9), order.Checkout.ShipmentID)
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+	payments.AssertNotCalled(t, "VoidPayment", mock.Anything)
+}
+
+func TestCheckoutService_Start_SkipsShipmentForDigitalOrder(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	service := NewCheckoutService(sagas, NewOrderService(orders, products), products, payments, shipments)
+	order := pendingOrder()
+	order.OrderItems[0].IsDigital = true
+
+	sagas.On("Create", mock.Anything).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", uint(7), mock.Anything).Return(nil)
+	payments.On("OpenPayment", uint(7), uint(1), 3000.0, PaymentMethodCard).Return(uint(4), nil)
+	orders.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+
+	assert.NoError(t, service.Start(order))
+	assert.Equal(t, models.SagaStatusCompleted, order.Checkout.Status)
+	shipments.AssertNotCalled(t, "RequestShipment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckoutService_Start_CompensatesWhenStockRunsOut(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCheckoutService(sagas, NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	order := pendingOrder()
+
+	sagas.On("Create", mock.Anything).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", uint(7), mock.Anything).
+		Return(&ProductServiceError{StatusCode: http.StatusConflict, Message: "insufficient stock"})
+	payments.On("VoidPayment", uint(7)).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	orders.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.ToStatus == models.OrderStatusCancelled && entry.ActorRole == models.ActorSystem
+	})).Return(nil)
+
+	err := service.Start(order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SagaStatusFailed, order.Checkout.Status)
+	assert.Contains(t, order.Checkout.FailureReason, models.SagaStepReserveStock)
+	assert.Equal(t, models.OrderStatusCancelled, order.Status)
+	payments.AssertNotCalled(t, "OpenPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	orders.AssertExpectations(t)
+	products.AssertExpectations(t)
+}
+
+func TestCheckoutService_Start_RetriesTransientFailureLater(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCheckoutService(sagas, NewOrderService(new(repository.MockOrderRepository), products), products, payments, new(MockShipmentClient))
+	order := pendingOrder()
+
+	sagas.On("Create", mock.Anything).Return(nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	products.On("ReserveStock", uint(7), mock.Anything).Return(nil)
+	payments.On("OpenPayment", uint(7), uint(1), 3000.0, PaymentMethodCard).Return(uint(0), errors.New("connection refused"))
+
+	err := service.Start(order)
+
+	assert.NoError(t, err)
+	saga := order.Checkout
+	assert.Equal(t, models.SagaStatusRunning, saga.Status)
+	assert.Equal(t, models.SagaStepCreatePayment, saga.Step)
+	assert.Equal(t, 1, saga.Attempts)
+	assert.Equal(t, "connection refused", saga.LastError)
+	assert.True(t, saga.NextAttemptAt.After(time.Now()))
+	payments.AssertNotCalled(t, "VoidPayment", mock.Anything)
+	products.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+}
+
+func TestCheckoutService_ResumePending_GivesUpAfterMaxAttempts(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	service := NewCheckoutService(sagas, NewOrderService(orders, products), products, payments, shipments)
+	saga := models.CheckoutSaga{ID: 3, OrderID: 7, Status: models.SagaStatusRunning,
+		Step: models.SagaStepRequestShipment, Attempts: MaxCheckoutAttempts - 1}
+	order := pendingOrder()
+	order.Status = models.OrderStatusConfirmed
+
+	sagas.On("ListDue", mock.AnythingOfType("time.Time"), checkoutResumeBatch).Return([]models.CheckoutSaga{saga}, nil)
+	sagas.On("Claim", mock.Anything, mock.AnythingOfType("time.Time")).Return(true, nil)
+	sagas.On("Save", mock.Anything).Return(nil)
+	orders.On("GetByID", uint(7)).Return(order, nil)
+	shipments.On("RequestShipment", uint(7), uint(0), uint(1)).Return(uint(0), errors.New("timeout"))
+	payments.On("VoidPayment", uint(7)).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	orders.On("Transition", transitionTo(models.OrderStatusCancelled)).Return(nil)
+
+	err := service.ResumePending()
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.SagaStatusFailed, order.Checkout.Status)
+	assert.Equal(t, models.OrderStatusCancelled, order.Status)
+}
+
+func TestCheckoutService_ResumePending_SkipsSagaClaimedElsewhere(t *testing.T) {
+	sagas := new(repository.MockCheckoutSagaRepository)
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewCheckoutService(sagas, NewOrderService(orders, products), products, new(MockPaymentClient), new(MockShipmentClient))
+
+	sagas.On("ListDue", mock.Anything, checkoutResumeBatch).
+		Return([]models.CheckoutSaga{{ID: 3, OrderID: 7, Status: models.SagaStatusRunning}}, nil)
+	sagas.On("Claim", mock.Anything, mock.Anything).Return(false, nil)
+
+	assert.NoError(t, service.ResumePending())
+	orders.AssertNotCalled(t, "GetByID", mock.Anything)
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"order-service/models"
+)
+
+// Page layout shared by invoices and packing slips
+const (
+	docLeft      = 40.0
+	docRight     = pdfPageWidth - 40
+	docColumn    = 330.0 // start of the right-hand column of the header
+	docBottom    = pdfPageHeight - 60
+	docRowHeight = 16.0
+)
+
+// invoiceContent is everything printed on one invoice
+type invoiceContent struct {
+	Invoice  *models.Invoice
+	Order    *models.Order
+	SubOrder *models.SubOrder
+	ShipTo   string
+}
+
+// packingSlipContent is everything printed on one packing slip
+type packingSlipContent struct {
+	Order    *models.Order
+	SubOrder *models.SubOrder
+	Shop     *ShopProfile
+	Shipment *ShipmentSummary
+}
+
+func renderInvoicePDF(content invoiceContent) ([]byte, error) {
+	invoice, order, sub := content.Invoice, content.Order, content.SubOrder
+	doc := newPDFDocument()
+	doc.AddPage()
+
+	doc.Text(docLeft, 60, 20, true, "TAX INVOICE")
+	doc.TextRight(docRight, 52, 10, true, "Invoice no. "+invoice.Number)
+	doc.TextRight(docRight, 66, 9, false, "Issued "+invoice.IssuedAt.Format("2 January 2006"))
+
+	y := 100.0
+	y = docBlock(doc, docLeft, y, "Sold by", []string{
+		invoice.SellerName,
+		invoice.SellerAddress,
+		labelled("Phone", invoice.SellerPhone),
+		labelled("BIN", invoice.SellerBIN),
+	})
+	right := docBlock(doc, docColumn, 100, "Order", []string{
+		fmt.Sprintf("Order #%d, part #%d", order.ID, sub.ID),
+		"Placed " + order.CreatedAt.Format("2 January 2006"),
+		"Payment: " + paymentLabel(order.PaymentMethod),
+		fmt.Sprintf("Buyer #%d", sub.BuyerID),
+	})
+	if content.ShipTo != "" {
+		right = docBlock(doc, docColumn, right+20, "Ship to", []string{content.ShipTo})
+	}
+	if right > y {
+		y = right
+	}
+
+	columns := []docColumnSpec{
+		{Title: "#", X: docLeft},
+		{Title: "Item", X: docLeft + 22},
+		{Title: "Qty", X: 390, Right: true},
+		{Title: "Unit price", X: 470, Right: true},
+		{Title: "Amount", X: docRight, Right: true},
+	}
+	y = docTable(doc, y+14, columns, invoiceRows(sub.Items))
+
+	y += 8
+	discount := "Discount"
+	if order.CouponCode != "" {
+		discount += " (" + order.CouponCode + ")"
+	}
+	for _, line := range []struct {
+		label  string
+		amount float64
+	}{
+		{"Subtotal", sub.Subtotal},
+		{discount, -sub.Discount},
+		{"Shipping", sub.ShippingFee},
+		{"Shipping discount", -sub.ShippingDiscount},
+		{"VAT", sub.Tax},
+		{"Cash on delivery fee", sub.CODFee},
+	} {
+		if line.amount == 0 && line.label != "Subtotal" {
+			continue
+		}
+		y = docNextRow(doc, y, nil)
+		doc.Text(370, y, 9, false, line.label)
+		doc.TextRight(docRight, y, 9, false, formatAmount(line.amount))
+	}
+	y = docNextRow(doc, y+4, nil)
+	doc.Line(370, y-12, docRight, y-12)
+	doc.Text(370, y, 10, true, "Total (BDT)")
+	doc.TextRight(docRight, y, 10, true, formatAmount(sub.TotalAmount))
+
+	if order.PaymentMethod == PaymentMethodCOD {
+		y = docNextRow(doc, y+10, nil)
+		doc.Text(docLeft, y, 10, true, "Cash to collect on delivery: BDT "+formatAmount(sub.TotalAmount))
+	}
+	y = docNextRow(doc, y+20, nil)
+	doc.Text(docLeft, y, 8, false, "Prices include the VAT shown. This invoice was issued electronically and needs no signature.")
+	return doc.Bytes()
+}
+
+func renderPackingSlipPDF(content packingSlipContent) ([]byte, error) {
+	order, sub := content.Order, content.SubOrder
+	doc := newPDFDocument()
+	doc.AddPage()
+
+	doc.Text(docLeft, 60, 20, true, "PACKING SLIP")
+	doc.TextRight(docRight, 52, 10, true, fmt.Sprintf("Order #%d, part #%d", order.ID, sub.ID))
+	if content.Shipment != nil {
+		doc.TextRight(docRight, 66, 9, false, fmt.Sprintf("Shipment #%d", content.Shipment.ID))
+	}
+
+	from := []string{fmt.Sprintf("Shop #%d", sub.ShopID)}
+	if shop := content.Shop; shop != nil {
+		from = []string{shop.Name, shop.Address, labelled("Phone", shop.Phone)}
+	}
+	y := docBlock(doc, docLeft, 100, "From", from)
+	shipTo := []string{fmt.Sprintf("Buyer #%d", sub.BuyerID)}
+	if content.Shipment != nil && content.Shipment.Address != "" {
+		shipTo = append(shipTo, content.Shipment.Address)
+	}
+	if right := docBlock(doc, docColumn, 100, "Ship to", shipTo); right > y {
+		y = right
+	}
+
+	columns := []docColumnSpec{
+		{Title: "#", X: docLeft},
+		{Title: "Item", X: docLeft + 22},
+		{Title: "Qty", X: docRight, Right: true},
+	}
+	y = docTable(doc, y+14, columns, packingRows(sub.Items))
+
+	if order.PaymentMethod == PaymentMethodCOD {
+		y = docNextRow(doc, y+14, nil)
+		doc.Text(docLeft, y, 11, true, "Collect on delivery: BDT "+formatAmount(sub.TotalAmount))
+	}
+	return doc.Bytes()
+}
+
+// docColumnSpec is one column of an item table; right-aligned columns end at X
+type docColumnSpec struct {
+	Title string
+	X     float64
+	Right bool
+}
+
+// docBlock prints a heading with lines under it, wrapping long ones, and returns
+// where it ends
+func docBlock(doc *pdfDocument, x, y float64, heading string, lines []string) float64 {
+	doc.Text(x, y, 8, true, strings.ToUpper(heading))
+	width := docColumn - docLeft - 20
+	if x >= docColumn {
+		width = docRight - docColumn
+	}
+	for _, line := range lines {
+		for _, wrapped := range wrapText(doc, line, 10, width) {
+			y += 13
+			doc.Text(x, y, 10, false, wrapped)
+		}
+	}
+	return y
+}
+
+// docTable prints an item table starting at y, continuing on new pages as needed, and
+// returns where it ends
+func docTable(doc *pdfDocument, y float64, columns []docColumnSpec, rows [][]string) float64 {
+	header := func(y float64) {
+		for _, column := range columns {
+			if column.Right {
+				doc.TextRight(column.X, y, 9, true, column.Title)
+			} else {
+				doc.Text(column.X, y, 9, true, column.Title)
+			}
+		}
+		doc.Line(docLeft, y+5, docRight, y+5)
+	}
+	header(y)
+	itemWidth := 280.0
+	if len(columns) == 3 {
+		itemWidth = docRight - docLeft - 80
+	}
+	for _, row := range rows {
+		y = docNextRow(doc, y, header)
+		for i, column := range columns {
+			text := row[i]
+			switch {
+			case column.Right:
+				doc.TextRight(column.X, y, 9, false, text)
+			case i == 1:
+				doc.Text(column.X, y, 9, false, doc.Fit(text, 9, itemWidth, false))
+			default:
+				doc.Text(column.X, y, 9, false, text)
+			}
+		}
+	}
+	doc.Line(docLeft, y+6, docRight, y+6)
+	return y + 6
+}
+
+// docNextRow moves down one row, starting a new page, with the table header when
+// there is one, once the page is full
+func docNextRow(doc *pdfDocument, y float64, header func(float64)) float64 {
+	y += docRowHeight
+	if y <= docBottom {
+		return y
+	}
+	doc.AddPage()
+	y = 60
+	if header != nil {
+		header(y)
+		y += docRowHeight
+	}
+	return y
+}
+
+func invoiceRows(items []models.OrderItem) [][]string {
+	rows := make([][]string, 0, len(items))
+	for i, item := range items {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1), item.ProductName, strconv.Itoa(item.Quantity),
+			formatAmount(item.UnitPrice), formatAmount(item.Subtotal),
+		})
+	}
+	return rows
+}
+
+// packingRows lists what goes in the parcel: physical items, with what each bundle holds
+func packingRows(items []models.OrderItem) [][]string {
+	var rows [][]string
+	number := 0
+	for _, item := range items {
+		if item.IsDigital {
+			continue
+		}
+		number++
+		rows = append(rows, []string{strconv.Itoa(number), item.ProductName, strconv.Itoa(item.Quantity)})
+		for _, component := range item.Components {
+			rows = append(rows, []string{"", "   - " + component.ProductName, strconv.Itoa(component.Quantity)})
+		}
+	}
+	return rows
+}
+
+// wrapText breaks text into lines at most width wide, at spaces where it can
+func wrapText(doc *pdfDocument, text string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := strings.TrimSpace(line + " " + word)
+			if line != "" && doc.TextWidth(candidate, size, false) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		if line != "" {
+			lines = append(lines, doc.Fit(line, size, width, false))
+		}
+	}
+	return lines
+}
+
+func labelled(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + ": " + value
+}
+
+func paymentLabel(method string) string {
+	switch method {
+	case PaymentMethodCOD:
+		return "Cash on delivery"
+	case PaymentMethodCard:
+		return "Card"
+	case PaymentMethodPayPal:
+		return "PayPal"
+	case PaymentMethodBank:
+		return "Bank transfer"
+	}
+	return method
+}
+
+// formatAmount prints an amount with two decimals and thousands separators, e.g. 1,234.50
+func formatAmount(amount float64) string {
+	text := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, cents := text[:len(text)-3], text[len(text)-3:]
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + cents
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotReady    = errors.New("an invoice is issued once the order is confirmed")
+	ErrNothingToPack      = errors.New("nothing in this part of the order is shipped")
+	ErrShopDetailsMissing = errors.New("could not fetch the shop's details for the invoice")
+)
+
+// DocumentService produces invoice and packing-slip PDFs for sub-orders. Generated
+// PDFs are kept in Blobs and served from there afterwards.
+type DocumentService struct {
+	Orders    *OrderService
+	Invoices  repository.InvoiceRepository
+	Shops     ShopClient
+	Shipments ShipmentClient
+	Blobs     BlobStore
+}
+
+func NewDocumentService(orders *OrderService, invoices repository.InvoiceRepository, shops ShopClient,
+	shipments ShipmentClient, blobs BlobStore) *DocumentService {
+	return &DocumentService{Orders: orders, Invoices: invoices, Shops: shops, Shipments: shipments, Blobs: blobs}
+}
+
+// InvoicePDF returns the invoice of a sub-order, issuing it with the seller's next
+// invoice number the first time it is asked for
+func (s *DocumentService) InvoicePDF(subOrderID uint, actor Actor) (*models.Invoice, []byte, error) {
+	order, sub, err := s.subOrderFor(subOrderID, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	invoice, err := s.invoiceFor(sub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := "invoices/" + invoice.Number + ".pdf"
+	if pdf, err := s.Blobs.Get(key); err == nil {
+		return invoice, pdf, nil
+	}
+	shipment := s.shipmentOf(sub)
+	content := invoiceContent{Invoice: invoice, Order: order, SubOrder: sub}
+	if shipment != nil {
+		content.ShipTo = shipment.Address
+	}
+	pdf, err := renderInvoicePDF(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Only an invoice with the address on it is kept, unless nothing is shipped;
+	// otherwise the next download tries again
+	if shipment != nil || len(packingRows(sub.Items)) == 0 {
+		s.store(key, pdf)
+	}
+	return invoice, pdf, nil
+}
+
+// PackingSlipPDF returns the packing slip of a sub-order's shipment
+func (s *DocumentService) PackingSlipPDF(subOrderID uint, actor Actor) ([]byte, error) {
+	order, sub, err := s.subOrderFor(subOrderID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if len(packingRows(sub.Items)) == 0 {
+		return nil, ErrNothingToPack
+	}
+
+	key := fmt.Sprintf("packing-slips/%d.pdf", sub.ID)
+	if pdf, err := s.Blobs.Get(key); err == nil {
+		return pdf, nil
+	}
+	content := packingSlipContent{Order: order, SubOrder: sub, Shipment: s.shipmentOf(sub)}
+	if sub.ShopID != 0 {
+		shop, err := s.Shops.GetShop(sub.ShopID)
+		if err != nil {
+			log.Printf("⚠️ Sub-order %d: failed to fetch shop %d for packing slip: %v", sub.ID, sub.ShopID, err)
+		}
+		content.Shop = shop
+	}
+	pdf, err := renderPackingSlipPDF(content)
+	if err != nil {
+		return nil, err
+	}
+	// A slip missing the shop or the address is not kept, so the next download tries again
+	if content.Shipment != nil && (content.Shop != nil || sub.ShopID == 0) {
+		s.store(key, pdf)
+	}
+	return pdf, nil
+}
+
+// subOrderFor loads a sub-order and its order if actor may see that part of the order:
+// buyers their own, sellers their own shop's, admins any
+func (s *DocumentService) subOrderFor(subOrderID uint, actor Actor) (*models.Order, *models.SubOrder, error) {
+	sub, err := s.Orders.Repo.GetSubOrder(subOrderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	order, actor, err := s.Orders.orderFor(sub.OrderID, actor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !actor.OwnsSubOrder(sub) {
+		return nil, nil, ErrNotOrderSeller
+	}
+	return order, sub, nil
+}
+
+// invoiceFor returns the sub-order's invoice, issuing it if there is none yet. Orders
+// not yet confirmed, or cancelled before they were invoiced, get none.
+func (s *DocumentService) invoiceFor(sub *models.SubOrder) (*models.Invoice, error) {
+	invoice, err := s.Invoices.GetBySubOrder(sub.ID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if sub.Status == models.OrderStatusPending || sub.Status == models.OrderStatusCancelled {
+		return nil, ErrInvoiceNotReady
+	}
+
+	invoice = &models.Invoice{SubOrderID: sub.ID, OrderID: sub.OrderID, SellerID: sub.SellerID, IssuedAt: time.Now()}
+	if sub.ShopID != 0 {
+		shop, err := s.Shops.GetShop(sub.ShopID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrShopDetailsMissing, err)
+		}
+		invoice.SellerName = shop.LegalName
+		if invoice.SellerName == "" {
+			invoice.SellerName = shop.Name
+		}
+		invoice.SellerAddress, invoice.SellerPhone, invoice.SellerBIN = shop.Address, shop.Phone, shop.BIN
+	}
+	err = s.Invoices.Issue(invoice)
+	if errors.Is(err, repository.ErrInvoiceExists) {
+		return s.Invoices.GetBySubOrder(sub.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// shipmentOf finds the shipment sending a sub-order; nil when there is none yet or
+// shipment-service cannot be reached
+func (s *DocumentService) shipmentOf(sub *models.SubOrder) *ShipmentSummary {
+	shipments, err := s.Shipments.ListOrderShipments(sub.OrderID)
+	if err != nil {
+		log.Printf("⚠️ Sub-order %d: failed to fetch shipments: %v", sub.ID, err)
+		return nil
+	}
+	for i := range shipments {
+		if shipments[i].ID == sub.ShipmentID || (sub.ShipmentID == 0 && shipments[i].SellerID == sub.SellerID) {
+			return &shipments[i]
+		}
+	}
+	return nil
+}
+
+// store keeps a generated PDF; failing to is not the caller's problem, the PDF is
+// simply generated again next time
+func (s *DocumentService) store(key string, pdf []byte) {
+	if err := s.Blobs.Put(key, pdf); err != nil {
+		log.Printf("⚠️ Failed to store %s: %v", key, err)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"order-service/models"
+	"order-service/repository"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// documentFixture is the split order with the first seller's sub-order holding a
+// physical and a digital item
+func documentFixture() (*models.Order, *models.SubOrder) {
+	order := splitOrderFixture()
+	order.PaymentMethod = PaymentMethodCOD
+	sub := order.SubOrders[0]
+	sub.BuyerID = 1
+	sub.Subtotal, sub.TotalAmount = 30, 34.5
+	sub.Items = []models.OrderItem{
+		{ProductID: 10, ProductName: "Jamdani saree", Quantity: 1, UnitPrice: 20, Subtotal: 20},
+		{ProductID: 12, ProductName: "Pattern e-book", Quantity: 1, UnitPrice: 10, Subtotal: 10, IsDigital: true},
+	}
+	return order, &sub
+}
+
+// pdfText returns the uncompressed content of every stream in a PDF
+func pdfText(t *testing.T, pdf []byte) string {
+	var text bytes.Buffer
+	for _, match := range regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode[^>]*>>\nstream\n`).FindAllSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(string(pdf[match[2]:match[3]]))
+		reader, err := zlib.NewReader(bytes.NewReader(pdf[match[1] : match[1]+length]))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		content, _ := io.ReadAll(reader)
+		text.Write(content)
+	}
+	return text.String()
+}
+
+func TestDocumentService_InvoicePDF_IssuesNumberWithShopDetails(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	invoices := new(repository.MockInvoiceRepository)
+	shops := new(MockShopClient)
+	shipments := new(MockShipmentClient)
+	blobs := NewLocalBlobStore(t.TempDir())
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), invoices, shops, shipments, blobs)
+	order, sub := documentFixture()
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	invoices.On("GetBySubOrder", uint(71)).Return(nil, gorm.ErrRecordNotFound)
+	shops.On("GetShop", uint(20)).Return(&ShopProfile{ID: 20, Name: "Tant Ghor", LegalName: "Tant Ghor Ltd", BIN: "000123456-0101"}, nil)
+	invoices.On("Issue", mock.MatchedBy(func(invoice *models.Invoice) bool {
+		return invoice.SubOrderID == 71 && invoice.SellerID == 2 && invoice.SellerName == "Tant Ghor Ltd" &&
+			invoice.SellerBIN == "000123456-0101"
+	})).Run(func(args mock.Arguments) {
+		invoice := args.Get(0).(*models.Invoice)
+		invoice.Sequence, invoice.Number = 42, models.InvoiceNumber(2, 42)
+	}).Return(nil)
+	shipments.On("ListOrderShipments", uint(7)).Return([]ShipmentSummary{
+		{ID: 5, SellerID: 2, Address: "22 Green Road, Dhaka"},
+	}, nil)
+
+	invoice, pdf, err := service.InvoicePDF(71, Actor{ID: 2, Role: models.ActorSeller})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2-000042", invoice.Number)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	text := pdfText(t, pdf)
+	assert.Contains(t, text, "(Invoice no. INV-2-000042)")
+	assert.Contains(t, text, "(BIN: 000123456-0101)")
+	assert.Contains(t, text, "(22 Green Road, Dhaka)")
+	assert.Contains(t, text, "(Cash to collect on delivery: BDT 34.50)")
+
+	cached, err := blobs.Get("invoices/INV-2-000042.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, pdf, cached)
+}
+
+func TestDocumentService_InvoicePDF_ServesStoredCopy(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	invoices := new(repository.MockInvoiceRepository)
+	shipments := new(MockShipmentClient)
+	blobs := NewLocalBlobStore(t.TempDir())
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), invoices, new(MockShopClient), shipments, blobs)
+	order, sub := documentFixture()
+	issued := &models.Invoice{SubOrderID: 71, SellerID: 2, Number: "INV-2-000042"}
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	invoices.On("GetBySubOrder", uint(71)).Return(issued, nil)
+	assert.NoError(t, blobs.Put("invoices/INV-2-000042.pdf", []byte("%PDF-stored")))
+
+	_, pdf, err := service.InvoicePDF(71, Actor{ID: 1, Role: models.ActorBuyer})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("%PDF-stored"), pdf)
+	invoices.AssertNotCalled(t, "Issue", mock.Anything)
+	shipments.AssertNotCalled(t, "ListOrderShipments", mock.Anything)
+}
+
+func TestDocumentService_InvoicePDF_NotBeforeConfirmation(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	invoices := new(repository.MockInvoiceRepository)
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), invoices, new(MockShopClient), new(MockShipmentClient), NewLocalBlobStore(t.TempDir()))
+	order, sub := documentFixture()
+	sub.Status = models.OrderStatusPending
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	invoices.On("GetBySubOrder", uint(71)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, _, err := service.InvoicePDF(71, Actor{ID: 1, Role: models.ActorBuyer})
+
+	assert.ErrorIs(t, err, ErrInvoiceNotReady)
+	invoices.AssertNotCalled(t, "Issue", mock.Anything)
+}
+
+func TestDocumentService_InvoicePDF_OtherSellerCannotSee(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	invoices := new(repository.MockInvoiceRepository)
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), invoices, new(MockShopClient), new(MockShipmentClient), NewLocalBlobStore(t.TempDir()))
+	order, sub := documentFixture()
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+
+	_, _, err := service.InvoicePDF(71, Actor{ID: 3, Role: models.ActorSeller})
+
+	assert.ErrorIs(t, err, ErrNotOrderSeller)
+	invoices.AssertNotCalled(t, "GetBySubOrder", mock.Anything)
+}
+
+func TestDocumentService_PackingSlipPDF_ListsShippedItemsOnly(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	shops := new(MockShopClient)
+	shipments := new(MockShipmentClient)
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), new(repository.MockInvoiceRepository), shops, shipments, NewLocalBlobStore(t.TempDir()))
+	order, sub := documentFixture()
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	shops.On("GetShop", uint(20)).Return(&ShopProfile{ID: 20, Name: "Tant Ghor"}, nil)
+	shipments.On("ListOrderShipments", uint(7)).Return([]ShipmentSummary{{ID: 5, SellerID: 2}}, nil)
+
+	pdf, err := service.PackingSlipPDF(71, Actor{ID: 1, Role: models.ActorBuyer})
+
+	assert.NoError(t, err)
+	text := pdfText(t, pdf)
+	assert.Contains(t, text, "(Jamdani saree)")
+	assert.NotContains(t, text, "Pattern e-book")
+	assert.Contains(t, text, "(Shipment #5)")
+	assert.Contains(t, text, "(Collect on delivery: BDT 34.50)")
+}
+
+func TestDocumentService_PackingSlipPDF_DigitalOnly(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewDocumentService(NewOrderService(repo, new(MockProductClient)), new(repository.MockInvoiceRepository), new(MockShopClient), new(MockShipmentClient), NewLocalBlobStore(t.TempDir()))
+	order, sub := documentFixture()
+	sub.Items = sub.Items[1:]
+
+	repo.On("GetSubOrder", uint(71)).Return(sub, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+
+	_, err := service.PackingSlipPDF(71, Actor{ID: 2, Role: models.ActorSeller})
+
+	assert.ErrorIs(t, err, ErrNothingToPack)
+}
+
+func TestPrintable_ReplacesWhatHelveticaCannotDraw(t *testing.T) {
+	assert.Equal(t, "Saree (red) ?????", printable("Saree (red) শাড়ি"))
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.00", formatAmount(0))
+	assert.Equal(t, "999.50", formatAmount(999.5))
+	assert.Equal(t, "1,234,567.89", formatAmount(1234567.89))
+	assert.Equal(t, "-1,000.00", formatAmount(-1000))
+}
@@ -20,12 +20,12 @@ func (m *MockOrderService) GetOrdersByBuyer(buyerID uint) ([]models.Order, error
 	return args.Get(0).([]models.Order), args.Error(1)
 }
 
-func (m *MockOrderService) GetOrdersBySeller(sellerID uint) ([]models.Order, error) {
+func (m *MockOrderService) GetOrdersBySeller(sellerID uint) ([]models.SubOrder, error) {
 	args := m.Called(sellerID)
-	return args.Get(0).([]models.Order), args.Error(1)
+	return args.Get(0).([]models.SubOrder), args.Error(1)
 }
 
-func (m *MockOrderService) MarkAsShipped(orderID uint) error {
-	args := m.Called(orderID)
+func (m *MockOrderService) MarkAsShipped(orderID uint, actor Actor) error {
+	args := m.Called(orderID, actor)
 	return args.Error(0)
 }
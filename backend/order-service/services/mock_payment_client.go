@@ -0,0 +1,28 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockPaymentClient struct {
+	mock.Mock
+}
+
+func (m *MockPaymentClient) OpenPayment(orderID, buyerID uint, amount float64, method string) (uint, error) {
+	args := m.Called(orderID, buyerID, amount, method)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockPaymentClient) VoidPayment(orderID uint) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentClient) RefundPayment(orderID uint, amount float64, reference, reason string) error {
+	args := m.Called(orderID, amount, reference, reason)
+	return args.Error(0)
+}
+
+func (m *MockPaymentClient) GetOrderPayment(orderID uint) (*PaymentSummary, error) {
+	args := m.Called(orderID)
+	payment, _ := args.Get(0).(*PaymentSummary)
+	return payment, args.Error(1)
+}
@@ -0,0 +1,50 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockProductClient struct {
+	mock.Mock
+}
+
+func (m *MockProductClient) ClaimPrices(buyerID uint, claimKey string, items []PriceRequestItem) ([]PricedItem, error) {
+	args := m.Called(buyerID, claimKey, items)
+	priced, _ := args.Get(0).([]PricedItem)
+	return priced, args.Error(1)
+}
+
+func (m *MockProductClient) CheckAvailability(items []PriceRequestItem) ([]ItemAvailability, error) {
+	args := m.Called(items)
+	available, _ := args.Get(0).([]ItemAvailability)
+	return available, args.Error(1)
+}
+
+func (m *MockProductClient) FulfilDigital(orderID, buyerID uint, items []PriceRequestItem) ([]DigitalEntitlement, error) {
+	args := m.Called(orderID, buyerID, items)
+	entitlements, _ := args.Get(0).([]DigitalEntitlement)
+	return entitlements, args.Error(1)
+}
+
+func (m *MockProductClient) ReserveStock(orderID uint, items []PriceRequestItem) error {
+	args := m.Called(orderID, items)
+	return args.Error(0)
+}
+
+func (m *MockProductClient) ReleaseStock(orderID uint, productIDs []uint) error {
+	args := m.Called(orderID, productIDs)
+	return args.Error(0)
+}
+
+func (m *MockProductClient) RestockReturn(orderID uint, returnKey string, items []PriceRequestItem) error {
+	args := m.Called(orderID, returnKey, items)
+	return args.Error(0)
+}
+
+func (m *MockProductClient) RecordOrder(orderID uint, productIDs []uint) error {
+	args := m.Called(orderID, productIDs)
+	return args.Error(0)
+}
+
+func (m *MockProductClient) ReleaseSaleClaims(claimKey string, productIDs []uint) error {
+	args := m.Called(claimKey, productIDs)
+	return args.Error(0)
+}
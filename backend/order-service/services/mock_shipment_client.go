@@ -0,0 +1,18 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockShipmentClient struct {
+	mock.Mock
+}
+
+func (m *MockShipmentClient) RequestShipment(orderID, sellerID, buyerID uint) (uint, error) {
+	args := m.Called(orderID, sellerID, buyerID)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockShipmentClient) ListOrderShipments(orderID uint) ([]ShipmentSummary, error) {
+	args := m.Called(orderID)
+	shipments, _ := args.Get(0).([]ShipmentSummary)
+	return shipments, args.Error(1)
+}
@@ -0,0 +1,13 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockShopClient struct {
+	mock.Mock
+}
+
+func (m *MockShopClient) GetShop(shopID uint) (*ShopProfile, error) {
+	args := m.Called(shopID)
+	shop, _ := args.Get(0).(*ShopProfile)
+	return shop, args.Error(1)
+}
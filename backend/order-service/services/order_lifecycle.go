@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+)
+
+// Order lifecycle job settings
+const (
+	DefaultUnpaidOrderTimeout = 24 * time.Hour
+	DefaultCompleteAfter      = DefaultReturnWindow
+	UnpaidOrdersInterval      = 5 * time.Minute // how often unpaid orders are looked for
+	DeliveredOrdersInterval   = time.Hour       // how often delivered orders are looked at for completion
+	lifecycleBatch            = 100
+)
+
+// LifecycleService moves orders on when nobody else will: it cancels orders whose
+// payment never arrived, and completes delivered orders once their return window has
+// closed, which makes their sellers eligible for settlement
+type LifecycleService struct {
+	Orders   *OrderService
+	Products ProductClient
+	Payments PaymentClient
+	Returns  repository.ReturnRepository
+	Policy   ReturnPolicy
+
+	UnpaidTimeout time.Duration // how long an order placed with online payment may wait for it
+	CompleteAfter time.Duration // the least time after delivery before an order completes
+}
+
+func NewLifecycleService(orders *OrderService, products ProductClient, payments PaymentClient, returns repository.ReturnRepository) *LifecycleService {
+	return &LifecycleService{
+		Orders:        orders,
+		Products:      products,
+		Payments:      payments,
+		Returns:       returns,
+		Policy:        DefaultReturnPolicy(),
+		UnpaidTimeout: DefaultUnpaidOrderTimeout,
+		CompleteAfter: DefaultCompleteAfter,
+	}
+}
+
+// Jobs are the service's work for the scheduler
+func (s *LifecycleService) Jobs() []Job {
+	return []Job{
+		{Name: "cancel-unpaid-orders", Interval: UnpaidOrdersInterval, Run: s.CancelUnpaid},
+		{Name: "complete-delivered-orders", Interval: DeliveredOrdersInterval, Run: s.CompleteDelivered},
+	}
+}
+
+// CancelUnpaid cancels orders still waiting for their online payment after
+// UnpaidTimeout, giving back their stock, sale units and coupon use. Orders paid on delivery are
+// left alone. A failure with one order is logged and the rest carry on.
+func (s *LifecycleService) CancelUnpaid(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.UnpaidTimeout)
+	var afterID uint
+	for {
+		orders, err := s.Orders.Repo.ListUnpaid(cutoff, PaymentMethodCOD, afterID, lifecycleBatch)
+		if err != nil {
+			return err
+		}
+		for i := range orders {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.cancelUnpaid(&orders[i]); err != nil {
+				log.Printf("⚠️ Cancelling unpaid order %d: %v", orders[i].ID, err)
+			}
+		}
+		if len(orders) < lifecycleBatch {
+			return nil
+		}
+		afterID = orders[len(orders)-1].ID
+	}
+}
+
+// cancelUnpaid cancels one order that looks unpaid. payment-service is asked first, so
+// an order whose payment completed without order-service hearing of it is marked paid
+// instead.
+func (s *LifecycleService) cancelUnpaid(order *models.Order) error {
+	payment, err := s.Payments.GetOrderPayment(order.ID)
+	if err != nil {
+		return err
+	}
+	if payment != nil && (payment.Status == PaymentStatusCompleted || payment.Status == PaymentStatusRefunded) {
+		_, err := s.Orders.MarkPaid(order.ID)
+		return err
+	}
+	for _, sub := range order.SubOrders {
+		if sub.Status != models.OrderStatusCancelled && statusRank[sub.Status] > statusRank[models.OrderStatusConfirmed] {
+			log.Printf("unpaid order %d left as is: sub-order %d is already %s", order.ID, sub.ID, sub.Status)
+			return nil
+		}
+	}
+
+	if err := s.Payments.VoidPayment(order.ID); err != nil {
+		return err
+	}
+	if err := s.Products.ReleaseStock(order.ID, nil); err != nil {
+		return err
+	}
+	if err := s.Orders.releaseSaleClaims(order, nil); err != nil {
+		return err
+	}
+	if err := s.Orders.transition(order, models.OrderStatusCancelled, SystemActor, "payment not received in time"); err != nil {
+		return err
+	}
+	return s.Orders.releaseCoupon(order)
+}
+
+// CompleteDelivered completes every delivered order, or sub-order, whose return
+// window has closed for all its items and that has no return under way. Split orders
+// complete shop by shop. A failure with one order is logged and the rest carry on.
+func (s *LifecycleService) CompleteDelivered(ctx context.Context) error {
+	now := time.Now()
+	var afterID uint
+	for {
+		orders, err := s.Orders.Repo.ListDelivered(now.Add(-s.CompleteAfter), afterID, lifecycleBatch)
+		if err != nil {
+			return err
+		}
+		for i := range orders {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.complete(&orders[i], now); err != nil {
+				log.Printf("⚠️ Completing order %d: %v", orders[i].ID, err)
+			}
+		}
+		if len(orders) < lifecycleBatch {
+			return nil
+		}
+		afterID = orders[len(orders)-1].ID
+	}
+}
+
+const completedReason = "return window closed"
+
+// complete completes whatever parts of a delivered order are ready to
+func (s *LifecycleService) complete(order *models.Order, now time.Time) error {
+	if len(order.SubOrders) == 0 {
+		if order.Status != models.OrderStatusDelivered {
+			return nil
+		}
+		ready, err := s.completable(order, 0, now)
+		if err != nil || !ready {
+			return err
+		}
+		return s.Orders.transition(order, models.OrderStatusCompleted, SystemActor, completedReason)
+	}
+
+	completed := false
+	var err error
+	for i := range order.SubOrders {
+		sub := &order.SubOrders[i]
+		if sub.Status != models.OrderStatusDelivered {
+			continue
+		}
+		var ready bool
+		if ready, err = s.completable(order, sub.ID, now); err != nil {
+			break
+		}
+		if !ready {
+			continue
+		}
+		if err = s.Orders.transitionSubOrder(order, sub, models.OrderStatusCompleted, SystemActor, completedReason); err != nil {
+			break
+		}
+		completed = true
+	}
+	if completed {
+		if rollUpErr := s.Orders.rollUp(order); rollUpErr != nil {
+			return rollUpErr
+		}
+	}
+	return err
+}
+
+// completable reports whether a delivered order, or one of its sub-orders, can no
+// longer be returned: CompleteAfter and every item's return window have passed since
+// delivery, and no return request is still open
+func (s *LifecycleService) completable(order *models.Order, subOrderID uint, now time.Time) (bool, error) {
+	since := now.Sub(deliveredAt(order, subOrderID))
+	if since < s.CompleteAfter {
+		return false, nil
+	}
+	for _, item := range order.OrderItems {
+		if item.SubOrderID == subOrderID && !item.IsDigital && since <= s.Policy.Window(item.CategoryID) {
+			return false, nil
+		}
+	}
+	open, err := s.Returns.CountOpen(order.ID, subOrderID)
+	if err != nil {
+		return false, err
+	}
+	return open == 0, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLifecycleService_CancelUnpaid_CancelsAndReleasesStock(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewLifecycleService(NewOrderService(orders, products), products, payments, new(repository.MockReturnRepository))
+	order := pendingOrder()
+	order.Status = models.OrderStatusConfirmed
+
+	orders.On("ListUnpaid", mock.Anything, PaymentMethodCOD, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil)
+	payments.On("GetOrderPayment", uint(7)).Return(&PaymentSummary{ID: 4, Status: "pending"}, nil)
+	payments.On("VoidPayment", uint(7)).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	orders.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.ToStatus == models.OrderStatusCancelled && entry.ActorRole == models.ActorSystem &&
+			entry.Reason == "payment not received in time"
+	})).Return(nil)
+
+	err := service.CancelUnpaid(context.Background())
+
+	assert.NoError(t, err)
+	payments.AssertExpectations(t)
+	products.AssertExpectations(t)
+	orders.AssertExpectations(t)
+}
+
+func TestLifecycleService_CancelUnpaid_MarksPaidWhenPaymentCompleted(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewLifecycleService(NewOrderService(orders, products), products, payments, new(repository.MockReturnRepository))
+	order := pendingOrder()
+
+	orders.On("ListUnpaid", mock.Anything, PaymentMethodCOD, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil)
+	payments.On("GetOrderPayment", uint(7)).Return(&PaymentSummary{ID: 4, Status: PaymentStatusCompleted}, nil)
+	orders.On("GetByID", uint(7)).Return(order, nil)
+	orders.On("SetPaid", uint(7), mock.Anything).Return(nil)
+	orders.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+
+	err := service.CancelUnpaid(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+	assert.NotNil(t, order.PaidAt)
+	payments.AssertNotCalled(t, "VoidPayment", mock.Anything)
+	products.AssertNotCalled(t, "ReleaseStock", mock.Anything, mock.Anything)
+}
+
+func TestLifecycleService_CancelUnpaid_LeavesOrdersAlreadyShipping(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewLifecycleService(NewOrderService(orders, products), products, payments, new(repository.MockReturnRepository))
+	order := splitOrderFixture()
+	order.TotalAmount = 50
+	order.SubOrders[1].Status = models.OrderStatusShipped
+
+	orders.On("ListUnpaid", mock.Anything, PaymentMethodCOD, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil)
+	payments.On("GetOrderPayment", uint(7)).Return(nil, nil)
+
+	err := service.CancelUnpaid(context.Background())
+
+	assert.NoError(t, err)
+	payments.AssertNotCalled(t, "VoidPayment", mock.Anything)
+	orders.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+// deliveredSplitOrder is splitOrderFixture with both sub-orders delivered daysAgo
+func deliveredSplitOrder(daysAgo int) *models.Order {
+	order := splitOrderFixture()
+	order.Status = models.OrderStatusDelivered
+	deliveredAt := time.Now().Add(-time.Duration(daysAgo) * 24 * time.Hour)
+	for i := range order.SubOrders {
+		sub := &order.SubOrders[i]
+		sub.Status = models.OrderStatusDelivered
+		order.OrderItems[i].SubOrderID = sub.ID
+		order.History = append(order.History, models.OrderStatusTransition{
+			OrderID: 7, SubOrderID: sub.ID, ToStatus: models.OrderStatusDelivered, CreatedAt: deliveredAt,
+		})
+	}
+	return order
+}
+
+func TestLifecycleService_CompleteDelivered_WaitsForLongerCategoryWindow(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	returns := new(repository.MockReturnRepository)
+	service := NewLifecycleService(NewOrderService(orders, products), products, new(MockPaymentClient), returns)
+	service.Policy.CategoryWindows = map[uint]time.Duration{5: 30 * 24 * time.Hour}
+	order := deliveredSplitOrder(20)
+	order.OrderItems[1].CategoryID = 5
+
+	orders.On("ListDelivered", mock.Anything, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil).Run(func(args mock.Arguments) {
+		assert.WithinDuration(t, time.Now().Add(-DefaultCompleteAfter), args.Get(0).(time.Time), time.Minute)
+	})
+	returns.On("CountOpen", uint(7), uint(71)).Return(int64(0), nil)
+	orders.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 71 && entry.ToStatus == models.OrderStatusCompleted
+	})).Return(nil)
+
+	err := service.CompleteDelivered(context.Background())
+
+	assert.NoError(t, err)
+	orders.AssertNumberOfCalls(t, "Transition", 1) // the parent stays delivered while sub-order 72 is
+	returns.AssertNotCalled(t, "CountOpen", uint(7), uint(72))
+}
+
+func TestLifecycleService_CompleteDelivered_WaitsForOpenReturn(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	returns := new(repository.MockReturnRepository)
+	service := NewLifecycleService(NewOrderService(orders, products), products, new(MockPaymentClient), returns)
+	order := deliveredSplitOrder(20)
+
+	orders.On("ListDelivered", mock.Anything, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil)
+	returns.On("CountOpen", uint(7), uint(71)).Return(int64(1), nil)
+	returns.On("CountOpen", uint(7), uint(72)).Return(int64(0), nil)
+	orders.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 72 && entry.ToStatus == models.OrderStatusCompleted
+	})).Return(nil)
+
+	err := service.CompleteDelivered(context.Background())
+
+	assert.NoError(t, err)
+	orders.AssertNumberOfCalls(t, "Transition", 1)
+}
+
+func TestLifecycleService_CompleteDelivered_RollsUpOnceEveryPartCompletes(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	returns := new(repository.MockReturnRepository)
+	service := NewLifecycleService(NewOrderService(orders, products), products, new(MockPaymentClient), returns)
+	order := deliveredSplitOrder(15)
+
+	orders.On("ListDelivered", mock.Anything, uint(0), lifecycleBatch).Return([]models.Order{*order}, nil)
+	returns.On("CountOpen", uint(7), mock.Anything).Return(int64(0), nil)
+	orders.On("Transition", transitionTo(models.OrderStatusCompleted)).Return(nil)
+
+	err := service.CompleteDelivered(context.Background())
+
+	assert.NoError(t, err)
+	orders.AssertCalled(t, "Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 0 && entry.FromStatus == models.OrderStatusDelivered && entry.ToStatus == models.OrderStatusCompleted
+	}))
+	orders.AssertNumberOfCalls(t, "Transition", 3)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+
+	"order-service/models"
+)
+
+// ErrOrderActionForbidden means the actor's role may never do that, whoever the order
+// belongs to
+var ErrOrderActionForbidden = errors.New("not allowed to do that with orders")
+
+// Things the order policy decides on
+const (
+	ActionViewOrder        = "view"
+	ActionCancelOrder      = "cancel"
+	ActionUpdateOrder      = "update_status"
+	ActionDeleteOrder      = "delete"
+	ActionListSellerOrders = "list_seller_orders"
+	ActionListAllOrders    = "list_all"
+)
+
+// policyActions lists, per role, the actions it may take at all. Whether it may take
+// them on a given order is decided by ownership in Authorize.
+var policyActions = map[string]map[string]bool{
+	models.ActorBuyer: {
+		ActionViewOrder: true, ActionCancelOrder: true, ActionUpdateOrder: true,
+	},
+	models.ActorSeller: {
+		ActionViewOrder: true, ActionCancelOrder: true, ActionUpdateOrder: true, ActionListSellerOrders: true,
+	},
+	models.ActorAdmin: {
+		ActionViewOrder: true, ActionCancelOrder: true, ActionUpdateOrder: true, ActionDeleteOrder: true,
+		ActionListSellerOrders: true, ActionListAllOrders: true,
+	},
+	models.ActorSystem: {
+		ActionViewOrder: true, ActionCancelOrder: true, ActionUpdateOrder: true,
+	},
+}
+
+// rolePrecedence is the order Authorize tries an actor's roles in, broadest first
+var rolePrecedence = []string{models.ActorAdmin, models.ActorSystem, models.ActorSeller, models.ActorBuyer}
+
+// Authorize decides whether actor may take action on order, or on orders in general
+// when order is nil. Buyers only deal with their own orders and sellers with orders
+// holding a sub-order of theirs; admins and other services deal with every order.
+// Each role the actor holds is tried, broadest first, and the actor is returned acting
+// in the first one allowed, so callers show and record what that role may. Someone
+// else's order fails with ErrNotOrderBuyer or ErrNotOrderSeller, so callers can answer
+// as if it did not exist.
+func Authorize(actor Actor, action string, order *models.Order) (Actor, error) {
+	refusal := ErrOrderActionForbidden
+	for _, role := range rolePrecedence {
+		if !actor.HasRole(role) {
+			continue
+		}
+		acting := actor.As(role)
+		err := authorizeAs(acting, action, order)
+		if err == nil {
+			return acting, nil
+		}
+		if !errors.Is(err, ErrOrderActionForbidden) {
+			refusal = err
+		}
+	}
+	return actor, refusal
+}
+
+// authorizeAs decides for the one role actor acts in
+func authorizeAs(actor Actor, action string, order *models.Order) error {
+	if !policyActions[actor.Role][action] {
+		return ErrOrderActionForbidden
+	}
+	if order == nil {
+		return nil
+	}
+	switch actor.Role {
+	case models.ActorBuyer:
+		if order.BuyerID != actor.ID {
+			return ErrNotOrderBuyer
+		}
+	case models.ActorSeller:
+		if !sellsInOrder(actor, order) {
+			return ErrNotOrderSeller
+		}
+	}
+	return nil
+}
+
+// OwnsSubOrder reports whether actor deals with one shop's part of an order: sellers
+// only with their own shop's, buyers only with parts of their own orders
+func (a Actor) OwnsSubOrder(sub *models.SubOrder) bool {
+	switch a.Role {
+	case models.ActorSeller:
+		return sub.SellerID == a.ID
+	case models.ActorBuyer:
+		return sub.BuyerID == a.ID
+	case models.ActorAdmin, models.ActorSystem:
+		return true
+	}
+	return false
+}
+
+// sellsInOrder reports whether a seller has a part in an order. Orders placed before
+// sub-orders existed are matched on their items.
+func sellsInOrder(seller Actor, order *models.Order) bool {
+	if len(order.SubOrders) == 0 {
+		for _, item := range order.OrderItems {
+			if item.SellerID == seller.ID {
+				return true
+			}
+		}
+		return false
+	}
+	for i := range order.SubOrders {
+		if seller.OwnsSubOrder(&order.SubOrders[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeOrder trims an order to what actor may see: sellers only get their own
+// sub-orders, with their items and history
+func ScopeOrder(actor Actor, order *models.Order) *models.Order {
+	if actor.Role != models.ActorSeller || len(order.SubOrders) == 0 {
+		return order
+	}
+	scoped := *order
+	scoped.SubOrders = nil
+	scoped.OrderItems = nil
+	scoped.History = nil
+	owned := map[uint]bool{}
+	for _, sub := range order.SubOrders {
+		if actor.OwnsSubOrder(&sub) {
+			owned[sub.ID] = true
+			scoped.SubOrders = append(scoped.SubOrders, sub)
+		}
+	}
+	for _, item := range order.OrderItems {
+		if owned[item.SubOrderID] {
+			scoped.OrderItems = append(scoped.OrderItems, item)
+		}
+	}
+	for _, entry := range order.History {
+		if owned[entry.SubOrderID] {
+			scoped.History = append(scoped.History, entry)
+		}
+	}
+	return &scoped
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"order-service/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	split := splitOrderFixture() // buyer 1; seller 2 with shop 20, seller 3 with shop 30
+	legacy := &models.Order{ID: 8, BuyerID: 1, OrderItems: []models.OrderItem{{ProductID: 10, SellerID: 2}}}
+
+	buyer := Actor{ID: 1, Role: models.ActorBuyer}
+	otherBuyer := Actor{ID: 9, Role: models.ActorBuyer}
+	seller := Actor{ID: 2, Role: models.ActorSeller}
+	otherSeller := Actor{ID: 4, Role: models.ActorSeller}
+	admin := Actor{ID: 6, Role: models.ActorAdmin}
+	unknown := Actor{ID: 7, Role: "guest"}
+
+	tests := []struct {
+		name   string
+		actor  Actor
+		action string
+		order  *models.Order
+		want   error
+	}{
+		{"buyer views own order", buyer, ActionViewOrder, split, nil},
+		{"buyer cancels own order", buyer, ActionCancelOrder, split, nil},
+		{"buyer cannot view another buyer's order", otherBuyer, ActionViewOrder, split, ErrNotOrderBuyer},
+		{"buyer cannot cancel another buyer's order", otherBuyer, ActionCancelOrder, split, ErrNotOrderBuyer},
+		{"buyer cannot delete orders", buyer, ActionDeleteOrder, split, ErrOrderActionForbidden},
+		{"buyer cannot list seller orders", buyer, ActionListSellerOrders, nil, ErrOrderActionForbidden},
+		{"buyer cannot list all orders", buyer, ActionListAllOrders, nil, ErrOrderActionForbidden},
+		{"seller views order with their sub-order", seller, ActionViewOrder, split, nil},
+		{"seller updates order with their sub-order", seller, ActionUpdateOrder, split, nil},
+		{"seller views legacy order with their item", seller, ActionViewOrder, legacy, nil},
+		{"seller cannot view order without their sub-order", otherSeller, ActionViewOrder, split, ErrNotOrderSeller},
+		{"seller cannot cancel order without their sub-order", otherSeller, ActionCancelOrder, split, ErrNotOrderSeller},
+		{"seller cannot update legacy order of another seller", otherSeller, ActionUpdateOrder, legacy, ErrNotOrderSeller},
+		{"seller cannot delete orders", seller, ActionDeleteOrder, split, ErrOrderActionForbidden},
+		{"seller lists seller orders", seller, ActionListSellerOrders, nil, nil},
+		{"seller cannot list all orders", seller, ActionListAllOrders, nil, ErrOrderActionForbidden},
+		{"admin views any order", admin, ActionViewOrder, split, nil},
+		{"admin cancels any order", admin, ActionCancelOrder, legacy, nil},
+		{"admin deletes orders", admin, ActionDeleteOrder, split, nil},
+		{"admin lists all orders", admin, ActionListAllOrders, nil, nil},
+		{"system updates any order", SystemActor, ActionUpdateOrder, split, nil},
+		{"system cannot delete orders", SystemActor, ActionDeleteOrder, split, ErrOrderActionForbidden},
+		{"unknown role can do nothing", unknown, ActionViewOrder, split, ErrOrderActionForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Authorize(tt.actor, tt.action, tt.order)
+			if tt.want == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize_ActsInTheRoleThatMayTakeTheAction(t *testing.T) {
+	split := splitOrderFixture() // buyer 1; seller 2 with shop 20, seller 3 with shop 30
+
+	// A seller who bought from another shop sees the order as its buyer
+	shopper := Actor{ID: 1, Role: models.ActorSeller, Roles: []string{models.ActorSeller, models.ActorBuyer}}
+	acting, err := Authorize(shopper, ActionViewOrder, split)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ActorBuyer, acting.Role)
+
+	// A buyer who also sells in the order sees it as its seller
+	seller := Actor{ID: 2, Role: models.ActorBuyer, Roles: []string{models.ActorBuyer, models.ActorSeller}}
+	acting, err = Authorize(seller, ActionViewOrder, split)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ActorSeller, acting.Role)
+
+	admin := Actor{ID: 6, Role: models.ActorSeller, Roles: []string{models.ActorSeller, models.ActorAdmin}}
+	acting, err = Authorize(admin, ActionDeleteOrder, split)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ActorAdmin, acting.Role)
+
+	stranger := Actor{ID: 9, Role: models.ActorBuyer, Roles: []string{models.ActorBuyer, models.ActorSeller}}
+	_, err = Authorize(stranger, ActionViewOrder, split)
+	assert.True(t, errors.Is(err, ErrNotOrderBuyer) || errors.Is(err, ErrNotOrderSeller))
+}
+
+func TestActor_OwnsSubOrder(t *testing.T) {
+	sub := &models.SubOrder{ID: 71, BuyerID: 1, SellerID: 2, ShopID: 20}
+
+	tests := []struct {
+		name  string
+		actor Actor
+		want  bool
+	}{
+		{"seller of the sub-order", Actor{ID: 2, Role: models.ActorSeller}, true},
+		{"another seller", Actor{ID: 3, Role: models.ActorSeller}, false},
+		{"buyer of the order", Actor{ID: 1, Role: models.ActorBuyer}, true},
+		{"another buyer", Actor{ID: 9, Role: models.ActorBuyer}, false},
+		{"admin", Actor{ID: 6, Role: models.ActorAdmin}, true},
+		{"system", SystemActor, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.actor.OwnsSubOrder(sub))
+		})
+	}
+}
+
+func TestScopeOrder_SellerSeesOnlyOwnSubOrders(t *testing.T) {
+	order := splitOrderFixture()
+	order.OrderItems[0].SubOrderID = 71
+	order.OrderItems[1].SubOrderID = 72
+	order.History = []models.OrderStatusTransition{{SubOrderID: 71}, {SubOrderID: 72}, {}}
+
+	scoped := ScopeOrder(Actor{ID: 2, Role: models.ActorSeller}, order)
+
+	assert.Len(t, scoped.SubOrders, 1)
+	assert.Equal(t, uint(71), scoped.SubOrders[0].ID)
+	assert.Len(t, scoped.OrderItems, 1)
+	assert.Len(t, scoped.History, 1)
+	assert.Len(t, order.SubOrders, 2, "the loaded order is left alone")
+	assert.Same(t, order, ScopeOrder(Actor{ID: 1, Role: models.ActorBuyer}, order))
+}
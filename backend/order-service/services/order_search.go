@@ -0,0 +1,273 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+)
+
+// Page sizes for order listings
+const (
+	DefaultOrderPageSize = 20
+	MaxOrderPageSize     = 100
+	exportBatchSize      = 500
+)
+
+// MaxExportRange is the longest period one CSV export may cover
+const MaxExportRange = 366 * 24 * time.Hour
+
+var (
+	ErrInvalidOrderCursor = errors.New("cursor does not belong to this listing")
+	ErrInvalidOrderSort   = errors.New("sort must be created_at or total_amount")
+	ErrExportRange        = errors.New("export needs a from and to date, at most a year apart")
+)
+
+// OrderPage is one page of an order listing
+type OrderPage struct {
+	Orders     []models.Order `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// SubOrderPage is one page of a seller's sub-orders
+type SubOrderPage struct {
+	SubOrders  []models.SubOrder `json:"sub_orders"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// OrderDetail is an order with what payment-service and shipment-service know of it
+type OrderDetail struct {
+	*models.Order
+	Payment   *PaymentSummary   `json:"payment,omitempty"`
+	Shipments []ShipmentSummary `json:"shipments,omitempty"`
+}
+
+// orderCursor is the opaque position handed out as next_cursor
+type orderCursor struct {
+	SortBy      string    `json:"s"`
+	Ascending   bool      `json:"a,omitempty"`
+	CreatedAt   time.Time `json:"c"`
+	TotalAmount float64   `json:"t"`
+	ID          uint      `json:"i"`
+}
+
+// SearchBuyerOrders pages through the buyer's own orders
+func (s *OrderService) SearchBuyerOrders(buyer Actor, filter repository.OrderFilter, cursor string) (*OrderPage, error) {
+	filter.BuyerID = buyer.ID
+	return s.searchOrders(filter, cursor)
+}
+
+// SearchAllOrders pages through every order; admins only
+func (s *OrderService) SearchAllOrders(actor Actor, filter repository.OrderFilter, cursor string) (*OrderPage, error) {
+	if _, err := Authorize(actor, ActionListAllOrders, nil); err != nil {
+		return nil, err
+	}
+	return s.searchOrders(filter, cursor)
+}
+
+// SearchSellerOrders pages through a seller's sub-orders. Admins may look at any
+// seller's, or at every sub-order when filter names no seller.
+func (s *OrderService) SearchSellerOrders(actor Actor, filter repository.OrderFilter, cursor string) (*SubOrderPage, error) {
+	actor, err := Authorize(actor, ActionListSellerOrders, nil)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role == models.ActorSeller {
+		filter.SellerID = actor.ID
+	}
+	filter.BuyerID = 0
+	limit, err := preparePage(&filter, cursor)
+	if err != nil {
+		return nil, err
+	}
+	subOrders, err := s.Repo.SearchSubOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &SubOrderPage{SubOrders: subOrders}
+	if len(subOrders) > limit {
+		page.SubOrders = subOrders[:limit]
+		last := page.SubOrders[limit-1]
+		page.NextCursor = encodeOrderCursor(filter, last.CreatedAt, last.TotalAmount, last.ID)
+	}
+	return page, nil
+}
+
+func (s *OrderService) searchOrders(filter repository.OrderFilter, cursor string) (*OrderPage, error) {
+	limit, err := preparePage(&filter, cursor)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.Repo.SearchOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = encodeOrderCursor(filter, last.CreatedAt, last.TotalAmount, last.ID)
+	}
+	return page, nil
+}
+
+// preparePage checks the sort, clamps the page size and applies the cursor. The filter
+// asks for one row more than the page, which tells whether another page follows.
+func preparePage(filter *repository.OrderFilter, cursor string) (int, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = repository.SortByCreatedAt
+	}
+	if filter.SortBy != repository.SortByCreatedAt && filter.SortBy != repository.SortByTotalAmount {
+		return 0, ErrInvalidOrderSort
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultOrderPageSize
+	}
+	if limit > MaxOrderPageSize {
+		limit = MaxOrderPageSize
+	}
+	filter.Limit = limit + 1
+
+	filter.After = nil
+	if cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return 0, ErrInvalidOrderCursor
+		}
+		var position orderCursor
+		if err := json.Unmarshal(raw, &position); err != nil ||
+			position.SortBy != filter.SortBy || position.Ascending != filter.Ascending {
+			return 0, ErrInvalidOrderCursor
+		}
+		filter.After = &repository.OrderCursor{CreatedAt: position.CreatedAt, TotalAmount: position.TotalAmount, ID: position.ID}
+	}
+	return limit, nil
+}
+
+func encodeOrderCursor(filter repository.OrderFilter, createdAt time.Time, total float64, id uint) string {
+	raw, _ := json.Marshal(orderCursor{
+		SortBy:      filter.SortBy,
+		Ascending:   filter.Ascending,
+		CreatedAt:   createdAt,
+		TotalAmount: total,
+		ID:          id,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// GetOrderDetail returns an order as actor may see it, with its payment and shipments.
+// Sellers only get their own shipments and no payment; a summary that cannot be
+// fetched is left out rather than failing the whole order.
+func (s *OrderService) GetOrderDetail(orderID uint, actor Actor) (*OrderDetail, error) {
+	order, actor, err := s.orderFor(orderID, actor)
+	if err != nil {
+		return nil, err
+	}
+	detail := &OrderDetail{Order: order}
+
+	if s.Payments != nil && actor.Role != models.ActorSeller {
+		if detail.Payment, err = s.Payments.GetOrderPayment(order.ID); err != nil {
+			log.Printf("⚠️ Order %d: failed to fetch payment: %v", order.ID, err)
+		}
+	}
+	if s.Shipments != nil {
+		shipments, err := s.Shipments.ListOrderShipments(order.ID)
+		if err != nil {
+			log.Printf("⚠️ Order %d: failed to fetch shipments: %v", order.ID, err)
+		}
+		for _, shipment := range shipments {
+			if actor.Role != models.ActorSeller || shipment.SellerID == actor.ID {
+				detail.Shipments = append(detail.Shipments, shipment)
+			}
+		}
+	}
+	return detail, nil
+}
+
+// ExportSellerOrders writes a seller's sub-orders created in [from, to) as CSV, one row
+// per item, oldest first. Admins pass the seller to export, or 0 for every seller.
+// Nothing reaches out until the request has been checked and the first batch read, so
+// those failures can still be answered as errors; rows are flushed batch by batch.
+func (s *OrderService) ExportSellerOrders(actor Actor, sellerID uint, from, to time.Time, out io.Writer) error {
+	actor, err := Authorize(actor, ActionListSellerOrders, nil)
+	if err != nil {
+		return err
+	}
+	if actor.Role == models.ActorSeller {
+		sellerID = actor.ID
+	}
+	if from.IsZero() || to.IsZero() || !to.After(from) || to.Sub(from) > MaxExportRange {
+		return ErrExportRange
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Write([]string{
+		"order_id", "sub_order_id", "created_at", "status", "buyer_id", "shop_id",
+		"product_id", "product_name", "quantity", "unit_price", "line_subtotal", "line_discount",
+		"sub_order_subtotal", "sub_order_discount", "sub_order_shipping_fee", "sub_order_shipping_discount",
+		"sub_order_tax", "sub_order_cod_fee", "sub_order_total",
+	})
+	filter := repository.OrderFilter{
+		SellerID:    sellerID,
+		CreatedFrom: from,
+		CreatedTo:   to,
+		SortBy:      repository.SortByCreatedAt,
+		Ascending:   true,
+		Limit:       exportBatchSize,
+	}
+	for {
+		subOrders, err := s.Repo.SearchSubOrders(filter)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subOrders {
+			for _, item := range sub.Items {
+				writer.Write([]string{
+					uintField(sub.OrderID), uintField(sub.ID), sub.CreatedAt.UTC().Format(time.RFC3339), sub.Status,
+					uintField(sub.BuyerID), uintField(sub.ShopID),
+					uintField(item.ProductID), textField(item.ProductName), strconv.Itoa(item.Quantity),
+					amountField(item.UnitPrice), amountField(item.Subtotal), amountField(item.Discount),
+					amountField(sub.Subtotal), amountField(sub.Discount), amountField(sub.ShippingFee),
+					amountField(sub.ShippingDiscount), amountField(sub.Tax), amountField(sub.CODFee),
+					amountField(sub.TotalAmount),
+				})
+			}
+		}
+		// Each batch goes out as it is read, so large exports are never held whole
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if len(subOrders) < exportBatchSize {
+			return nil
+		}
+		last := subOrders[len(subOrders)-1]
+		filter.After = &repository.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// textField keeps free text such as a product name from being read as a formula when
+// the export is opened in a spreadsheet
+func textField(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func uintField(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func amountField(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
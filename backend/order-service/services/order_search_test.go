@@ -0,0 +1,188 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"order-service/models"
+	"order-service/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrderService_SearchBuyerOrders_PagesWithCursor(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.On("SearchOrders", mock.MatchedBy(func(filter repository.OrderFilter) bool {
+		return filter.BuyerID == 1 && filter.Limit == 3 && filter.After == nil &&
+			filter.SortBy == repository.SortByCreatedAt
+	})).Return([]models.Order{
+		{ID: 9, CreatedAt: created},
+		{ID: 8, CreatedAt: created.Add(-time.Hour)},
+		{ID: 7, CreatedAt: created.Add(-2 * time.Hour)},
+	}, nil).Once()
+
+	page, err := service.SearchBuyerOrders(Actor{ID: 1, Role: models.ActorBuyer},
+		repository.OrderFilter{BuyerID: 5, Limit: 2}, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Orders, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	repo.On("SearchOrders", mock.MatchedBy(func(filter repository.OrderFilter) bool {
+		return filter.After != nil && filter.After.ID == 8 && filter.After.CreatedAt.Equal(created.Add(-time.Hour))
+	})).Return([]models.Order{{ID: 7, CreatedAt: created.Add(-2 * time.Hour)}}, nil).Once()
+
+	next, err := service.SearchBuyerOrders(Actor{ID: 1, Role: models.ActorBuyer},
+		repository.OrderFilter{Limit: 2}, page.NextCursor)
+
+	assert.NoError(t, err)
+	assert.Len(t, next.Orders, 1)
+	assert.Empty(t, next.NextCursor)
+}
+
+func TestOrderService_SearchBuyerOrders_RejectsCursorFromOtherSort(t *testing.T) {
+	service := NewOrderService(new(repository.MockOrderRepository), new(MockProductClient))
+	cursor := encodeOrderCursor(repository.OrderFilter{SortBy: repository.SortByTotalAmount}, time.Time{}, 50, 3)
+
+	_, err := service.SearchBuyerOrders(Actor{ID: 1, Role: models.ActorBuyer}, repository.OrderFilter{}, cursor)
+	assert.ErrorIs(t, err, ErrInvalidOrderCursor)
+
+	_, err = service.SearchBuyerOrders(Actor{ID: 1, Role: models.ActorBuyer}, repository.OrderFilter{}, "not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidOrderCursor)
+}
+
+func TestOrderService_SearchBuyerOrders_RejectsUnknownSort(t *testing.T) {
+	service := NewOrderService(new(repository.MockOrderRepository), new(MockProductClient))
+
+	_, err := service.SearchBuyerOrders(Actor{ID: 1, Role: models.ActorBuyer}, repository.OrderFilter{SortBy: "buyer_id"}, "")
+	assert.ErrorIs(t, err, ErrInvalidOrderSort)
+}
+
+func TestOrderService_SearchSellerOrders_ScopesToSeller(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	repo.On("SearchSubOrders", mock.MatchedBy(func(filter repository.OrderFilter) bool {
+		return filter.SellerID == 2 && filter.BuyerID == 0 && filter.Limit == MaxOrderPageSize+1
+	})).Return([]models.SubOrder{{ID: 71, SellerID: 2}}, nil)
+
+	page, err := service.SearchSellerOrders(Actor{ID: 2, Role: models.ActorSeller},
+		repository.OrderFilter{SellerID: 3, BuyerID: 1, Limit: 500}, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, page.SubOrders, 1)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestOrderService_SearchAllOrders_AdminsOnly(t *testing.T) {
+	service := NewOrderService(new(repository.MockOrderRepository), new(MockProductClient))
+
+	_, err := service.SearchAllOrders(Actor{ID: 1, Role: models.ActorBuyer}, repository.OrderFilter{}, "")
+	assert.ErrorIs(t, err, ErrOrderActionForbidden)
+}
+
+func TestOrderService_GetOrderDetail_SellerSeesOwnShipmentsOnly(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	service := NewOrderService(repo, new(MockProductClient))
+	service.Payments, service.Shipments = payments, shipments
+
+	repo.On("GetByID", uint(7)).Return(splitOrderFixture(), nil)
+	shipments.On("ListOrderShipments", uint(7)).Return([]ShipmentSummary{
+		{ID: 1, SellerID: 2, Status: "shipped"},
+		{ID: 2, SellerID: 3, Status: "pending"},
+	}, nil)
+
+	detail, err := service.GetOrderDetail(7, Actor{ID: 2, Role: models.ActorSeller})
+
+	assert.NoError(t, err)
+	assert.Nil(t, detail.Payment)
+	assert.Equal(t, []ShipmentSummary{{ID: 1, SellerID: 2, Status: "shipped"}}, detail.Shipments)
+	assert.Len(t, detail.SubOrders, 1)
+	payments.AssertNotCalled(t, "GetOrderPayment", mock.Anything)
+}
+
+func TestOrderService_GetOrderDetail_BuyerGetsPaymentDespiteShipmentFailure(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	shipments := new(MockShipmentClient)
+	service := NewOrderService(repo, new(MockProductClient))
+	service.Payments, service.Shipments = payments, shipments
+
+	repo.On("GetByID", uint(7)).Return(splitOrderFixture(), nil)
+	payments.On("GetOrderPayment", uint(7)).Return(&PaymentSummary{ID: 4, Status: "completed"}, nil)
+	shipments.On("ListOrderShipments", uint(7)).Return(nil, errors.New("shipment-service unavailable"))
+
+	detail, err := service.GetOrderDetail(7, Actor{ID: 1, Role: models.ActorBuyer})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", detail.Payment.Status)
+	assert.Empty(t, detail.Shipments)
+}
+
+func TestOrderService_ExportSellerOrders_WritesOneRowPerItem(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+
+	repo.On("SearchSubOrders", mock.MatchedBy(func(filter repository.OrderFilter) bool {
+		return filter.SellerID == 2 && filter.CreatedFrom.Equal(from) && filter.CreatedTo.Equal(to) && filter.Ascending
+	})).Return([]models.SubOrder{{
+		ID: 71, OrderID: 7, BuyerID: 1, ShopID: 20, Status: models.OrderStatusDelivered,
+		CreatedAt: from.Add(time.Hour), Subtotal: 35, ShippingFee: 5, TotalAmount: 40,
+		Items: []models.OrderItem{
+			{ProductID: 10, ProductName: "Mug, large", Quantity: 1, UnitPrice: 20, Subtotal: 20},
+			{ProductID: 12, ProductName: "Plate", Quantity: 3, UnitPrice: 5, Subtotal: 15},
+		},
+	}}, nil)
+
+	var out bytes.Buffer
+	err := service.ExportSellerOrders(Actor{ID: 2, Role: models.ActorSeller}, 9, from, to, &out)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, `7,71,2026-01-01T01:00:00Z,delivered,1,20,10,"Mug, large",1,20.00,20.00,0.00,35.00,0.00,5.00,0.00,0.00,0.00,40.00`, lines[1])
+}
+
+func TestOrderService_ExportSellerOrders_DefusesFormulas(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.On("SearchSubOrders", mock.Anything).Return([]models.SubOrder{{
+		ID: 71, OrderID: 7, CreatedAt: from,
+		Items: []models.OrderItem{
+			{ProductID: 10, ProductName: "=HYPERLINK(\"http://x\")"},
+			{ProductID: 11, ProductName: "@SUM(A1)"},
+			{ProductID: 12, ProductName: "-5 off"},
+		},
+	}}, nil)
+
+	var out bytes.Buffer
+	err := service.ExportSellerOrders(Actor{ID: 2, Role: models.ActorSeller}, 0, from, from.AddDate(0, 1, 0), &out)
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, lines[1], `"'=HYPERLINK(""http://x"")"`)
+	assert.Contains(t, lines[2], ",'@SUM(A1),")
+	assert.Contains(t, lines[3], ",'-5 off,")
+}
+
+func TestOrderService_ExportSellerOrders_NeedsBoundedRange(t *testing.T) {
+	service := NewOrderService(new(repository.MockOrderRepository), new(MockProductClient))
+	seller := Actor{ID: 2, Role: models.ActorSeller}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.ErrorIs(t, service.ExportSellerOrders(seller, 0, from, time.Time{}, &bytes.Buffer{}), ErrExportRange)
+	assert.ErrorIs(t, service.ExportSellerOrders(seller, 0, from, from.AddDate(2, 0, 0), &bytes.Buffer{}), ErrExportRange)
+	assert.ErrorIs(t, service.ExportSellerOrders(Actor{ID: 1, Role: models.ActorBuyer}, 0, from, from.AddDate(0, 1, 0), &bytes.Buffer{}), ErrOrderActionForbidden)
+}
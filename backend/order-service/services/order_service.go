@@ -1,71 +1,406 @@
 package services
 
 import (
-	"bytes"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
 	"time"
 
 	"order-service/repository"
 	"order-service/models"
 )
 
+var (
+	ErrEmptyOrder      = errors.New("order must contain at least one item")
+	ErrOrderNotPayable = errors.New("order is cancelled and cannot be paid")
+)
+
 type OrderService struct {
-	Repo repository.OrderRepository
+	Repo     repository.OrderRepository
+	Products ProductClient
+	Pricing  PricingPolicy
+
+	// Promotions evaluates coupons; without it orders cannot use one
+	Promotions *PromotionService
+
+	// Payments and Shipments fill in order details; either may be left nil
+	Payments  PaymentClient
+	Shipments ShipmentClient
 }
 
-func NewOrderService(repo repository.OrderRepository) *OrderService {
-	return &OrderService{repo}
+func NewOrderService(repo repository.OrderRepository, products ProductClient) *OrderService {
+	return &OrderService{Repo: repo, Products: products, Pricing: DefaultPricingPolicy()}
 }
 
+// CreateOrder prices every item from product-service and snapshots the result,
+// ignoring any prices or totals submitted by the client
 func (s *OrderService) CreateOrder(order *models.Order) error {
-	order.Status = "pending"
+	return s.PlaceOrder(order, nil)
+}
+
+// PlaceOrder creates an order priced from the catalogue, with shipping, tax and any
+// cash-on-delivery fee added and the coupon it names taken off. When the client quoted
+// totals, they are checked against current prices before any sale quantity is claimed;
+// on a mismatch the order carries the correct breakdown and ErrTotalsMismatch is
+// returned. A coupon the buyer cannot use is refused at the same point. The claimed
+// prices are checked against the quote again, and sale units claimed for an order that
+// then cannot be placed are given back.
+func (s *OrderService) PlaceOrder(order *models.Order, quote *OrderTotals) error {
+	if len(order.OrderItems) == 0 {
+		return ErrEmptyOrder
+	}
+	if order.ShippingMethod == "" {
+		order.ShippingMethod = DefaultShippingMethod
+	}
+	order.CouponCode = NormalizeCouponCode(order.CouponCode)
+	order.Redemption = nil
+
+	requests := priceRequests(order)
+	if quote != nil || order.CouponCode != "" {
+		totals, err := s.quote(order, requests)
+		if err != nil {
+			return err
+		}
+		if quote != nil && !totals.Matches(*quote) {
+			applyTotals(order, totals)
+			return ErrTotalsMismatch
+		}
+	}
+
+	claimKey, err := newClaimKey()
+	if err != nil {
+		return err
+	}
+	order.SaleClaimKey = claimKey
+	priced, err := s.Products.ClaimPrices(order.BuyerID, claimKey, requests)
+	if err == nil {
+		err = s.placeClaimed(order, quote, priced)
+	}
+	if err != nil {
+		// Nothing was placed, so the sale units claimed for it go back
+		if releaseErr := s.releaseSaleClaims(order, nil); releaseErr != nil {
+			log.Printf("⚠️ Releasing sale units of an unplaced order for buyer %d: %v", order.BuyerID, releaseErr)
+		}
+		return err
+	}
+	// Analytics are best effort; a placed order stands without them
+	if err := s.Products.RecordOrder(order.ID, orderProductIDs(order)); err != nil {
+		log.Printf("⚠️ Recording order %d in product analytics: %v", order.ID, err)
+	}
+	return nil
+}
+
+// placeClaimed snapshots an order's claimed prices, works out its totals and saves it.
+// Prices can change between the quote check and the claim, so a quote is checked
+// again against the claimed totals.
+func (s *OrderService) placeClaimed(order *models.Order, quote *OrderTotals, priced []PricedItem) error {
+	if len(priced) != len(order.OrderItems) {
+		return fmt.Errorf("product-service priced %d of %d items", len(priced), len(order.OrderItems))
+	}
+
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		item.ProductName = priced[i].ProductName
+		item.UnitPrice = priced[i].UnitPrice
+		item.Subtotal = priced[i].UnitPrice * float64(item.Quantity)
+		item.IsDigital = priced[i].IsDigital
+		item.SellerID = priced[i].SellerID
+		item.ShopID = priced[i].ShopID
+		item.CategoryID = priced[i].CategoryID
+		item.Components = nil
+		for _, component := range priced[i].Components {
+			item.Components = append(item.Components, models.OrderItemComponent{
+				ProductID:   component.ProductID,
+				ProductName: component.ProductName,
+				Quantity:    component.Quantity,
+			})
+		}
+	}
+	promotion, err := s.promotion(order, priced)
+	if err != nil {
+		return err
+	}
+	totals, err := s.totals(order, priced, promotion)
+	if err != nil {
+		return err
+	}
+	applyTotals(order, totals)
+	if quote != nil && !totals.Matches(*quote) {
+		return ErrTotalsMismatch
+	}
+	promotion.discountItems(order.OrderItems)
+
+	order.Status = models.OrderStatusPending
+	order.SubOrders = splitOrder(order)
+	promotion.waiveShipping(order.SubOrders)
+	if promotion != nil {
+		order.Redemption = &models.CouponRedemption{
+			CouponID: promotion.Coupon.ID,
+			UserID:   order.BuyerID,
+			Discount: roundAmount(order.Discount + order.ShippingDiscount),
+		}
+	}
+	order.ShopID = 0
+	if len(order.SubOrders) == 1 {
+		order.ShopID = order.SubOrders[0].ShopID
+	}
+	order.History = []models.OrderStatusTransition{{
+		ToStatus:  models.OrderStatusPending,
+		ActorID:   order.BuyerID,
+		ActorRole: models.ActorBuyer,
+	}}
 	return s.Repo.Create(order)
 }
 
+// newClaimKey generates the key an order's sale claims are recorded under
+func newClaimKey() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// orderProductIDs lists the products an order was placed for
+func orderProductIDs(order *models.Order) []uint {
+	ids := make([]uint, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		ids[i] = item.ProductID
+	}
+	return ids
+}
+
+// Quote works out an order's totals from current catalogue prices, with its coupon
+// applied, without claiming anything
+func (s *OrderService) Quote(order *models.Order) (OrderTotals, error) {
+	if len(order.OrderItems) == 0 {
+		return OrderTotals{}, ErrEmptyOrder
+	}
+	if order.ShippingMethod == "" {
+		order.ShippingMethod = DefaultShippingMethod
+	}
+	order.CouponCode = NormalizeCouponCode(order.CouponCode)
+	return s.quote(order, priceRequests(order))
+}
+
+// quote prices requests at what product-service currently charges
+func (s *OrderService) quote(order *models.Order, requests []PriceRequestItem) (OrderTotals, error) {
+	available, err := s.Products.CheckAvailability(requests)
+	if err != nil {
+		return OrderTotals{}, err
+	}
+	lines := make([]PricedItem, len(available))
+	for i, item := range available {
+		if !item.Available {
+			return OrderTotals{}, fmt.Errorf("product %d: %w", item.ProductID, ErrProductUnavailable)
+		}
+		lines[i] = item.PricedItem
+	}
+	promotion, err := s.promotion(order, lines)
+	if err != nil {
+		return OrderTotals{}, err
+	}
+	return s.totals(order, lines, promotion)
+}
+
+func priceRequests(order *models.Order) []PriceRequestItem {
+	requests := make([]PriceRequestItem, len(order.OrderItems))
+	for i, item := range order.OrderItems {
+		requests[i] = PriceRequestItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	return requests
+}
+
+// promotion evaluates the order's coupon against its priced lines; nil when it has none
+func (s *OrderService) promotion(order *models.Order, lines []PricedItem) (*Promotion, error) {
+	if order.CouponCode == "" {
+		return nil, nil
+	}
+	if s.Promotions == nil {
+		return nil, ErrCouponNotFound
+	}
+	return s.Promotions.Evaluate(order.CouponCode, order.BuyerID, lines)
+}
+
+// totals applies the pricing policy to an order's priced lines. Fees and tax are
+// charged on the subtotal after the coupon discount.
+func (s *OrderService) totals(order *models.Order, lines []PricedItem, promotion *Promotion) (OrderTotals, error) {
+	subtotal := 0.0
+	shipsGoods := false
+	for _, line := range lines {
+		subtotal += line.UnitPrice * float64(line.Quantity)
+		if !line.IsDigital {
+			shipsGoods = true
+		}
+	}
+	if promotion == nil {
+		return s.Pricing.Totals(subtotal, shipsGoods, order.ShippingMethod, order.PaymentMethod)
+	}
+	totals, err := s.Pricing.Totals(subtotal-promotion.discount(), shipsGoods, order.ShippingMethod, order.PaymentMethod)
+	if err != nil {
+		return totals, err
+	}
+	totals.Subtotal = roundAmount(subtotal)
+	totals.Discount = promotion.discount()
+	totals.ShippingDiscount = promotion.shippingDiscount(totals.ShippingFee, lines)
+	totals.Total = roundAmount(totals.Total - totals.ShippingDiscount)
+	return totals, nil
+}
+
+// applyTotals stores a price breakdown on the order
+func applyTotals(order *models.Order, totals OrderTotals) {
+	order.Subtotal = totals.Subtotal
+	order.ShippingFee = totals.ShippingFee
+	order.Tax = totals.Tax
+	order.CODFee = totals.CODFee
+	order.Discount = totals.Discount
+	order.ShippingDiscount = totals.ShippingDiscount
+	order.TotalAmount = totals.Total
+}
+
+// releaseSaleClaims gives back the sale units an order claimed for the given products,
+// or for all of them when productIDs is empty. Orders placed before claims were keyed
+// have nothing to release.
+func (s *OrderService) releaseSaleClaims(order *models.Order, productIDs []uint) error {
+	if order.SaleClaimKey == "" {
+		return nil
+	}
+	return s.Products.ReleaseSaleClaims(order.SaleClaimKey, productIDs)
+}
+
+// releaseCoupon gives back the coupon use of an order that ended up cancelled
+func (s *OrderService) releaseCoupon(order *models.Order) error {
+	if order.CouponCode == "" || s.Promotions == nil || order.Status != models.OrderStatusCancelled {
+		return nil
+	}
+	return s.Promotions.Release(order.ID)
+}
+
 func (s *OrderService) GetOrdersByBuyer(buyerID uint) ([]models.Order, error) {
 	return s.Repo.GetByBuyerID(buyerID)
 }
 
-func (s *OrderService) GetOrdersBySeller(sellerID uint) ([]models.Order, error) {
+// GetOrdersBySeller lists the seller's sub-orders; other shops' parts of the same
+// orders are not included
+func (s *OrderService) GetOrdersBySeller(sellerID uint) ([]models.SubOrder, error) {
 	return s.Repo.GetBySellerID(sellerID)
 }
 
-func (s *OrderService) MarkAsShipped(orderID uint) error {
-	return s.Repo.UpdateStatus(orderID, "shipped")
+// MarkAsShipped moves a confirmed or processing order to shipped
+func (s *OrderService) MarkAsShipped(orderID uint, actor Actor) error {
+	_, err := s.TransitionOrder(orderID, models.OrderStatusShipped, actor, "")
+	return err
 }
 
+// GetOrder returns an order with its items
+func (s *OrderService) GetOrder(orderID uint) (*models.Order, error) {
+	return s.Repo.GetByID(orderID)
+}
 
-func adjustProductStock(productID uint, quantity int) error {
-	url := "http://product-service:8082/api/products/adjust-stock"
-	payload := map[string]interface{}{
-		"product_id": productID,
-		"quantity":   quantity,
-	}
-	jsonValue, _ := json.Marshal(payload)
+// GetOrderFor returns an order as actor may see it: buyers their own orders, sellers
+// their own sub-orders of an order, admins anything
+func (s *OrderService) GetOrderFor(orderID uint, actor Actor) (*models.Order, error) {
+	order, _, err := s.orderFor(orderID, actor)
+	return order, err
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
+// orderFor is GetOrderFor that also returns the actor in the role they see the order in
+func (s *OrderService) orderFor(orderID uint, actor Actor) (*models.Order, Actor, error) {
+	order, err := s.Repo.GetByID(orderID)
 	if err != nil {
-		return err
+		return nil, actor, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	actor, err = Authorize(actor, ActionViewOrder, order)
+	if err != nil {
+		return nil, actor, err
+	}
+	return ScopeOrder(actor, order), actor, nil
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+// MarkPaid records that an order was paid, confirms it if still pending and delivers
+// its digital items. An order with only digital items is complete once they are delivered;
+// physical items still wait for shipment. Calling it again retries delivery without
+// duplicating it.
+func (s *OrderService) MarkPaid(orderID uint) (*models.Order, error) {
+	order, err := s.Repo.GetByID(orderID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return nil, ErrOrderNotPayable
+	}
+	if order.PaidAt == nil {
+		paidAt := time.Now()
+		if err := s.Repo.SetPaid(order.ID, paidAt); err != nil {
+			return nil, err
+		}
+		order.PaidAt = &paidAt
+	}
+	if order.Status == models.OrderStatusPending {
+		if err := s.transition(order, models.OrderStatusConfirmed, SystemActor, "payment completed"); err != nil {
+			return nil, err
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("stock update failed: %s", string(body))
+	var digital []PriceRequestItem
+	for _, item := range order.OrderItems {
+		if item.IsDigital {
+			digital = append(digital, PriceRequestItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
 	}
-	return nil
+	if len(digital) == 0 {
+		return order, nil
+	}
+	if _, err := s.Products.FulfilDigital(order.ID, order.BuyerID, digital); err != nil {
+		return nil, err
+	}
+	if len(order.SubOrders) > 0 {
+		return order, s.deliverDigitalSubOrders(order)
+	}
+	if len(digital) == len(order.OrderItems) && order.Status == models.OrderStatusConfirmed {
+		if err := s.transition(order, models.OrderStatusDelivered, SystemActor, "digital items delivered"); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
 }
 
+// GetDeliveredItem is used by product-service to verify review eligibility
+func (s *OrderService) GetDeliveredItem(buyerID, productID uint) (*models.OrderItem, error) {
+	return s.Repo.FindDeliveredItem(buyerID, productID)
+}
+
+// Page size bounds for the completed items and settlement feeds
+const (
+	defaultCompletedItemsLimit = 500
+	maxCompletedItemsLimit     = 1000
+)
+
+// ListCompletedItems feeds delivered order lines to product-service recommendations
+func (s *OrderService) ListCompletedItems(since time.Time, afterID uint, limit int) ([]repository.CompletedItem, error) {
+	if limit <= 0 {
+		limit = defaultCompletedItemsLimit
+	}
+	if limit > maxCompletedItemsLimit {
+		limit = maxCompletedItemsLimit
+	}
+	return s.Repo.ListCompletedItems(since, afterID, limit)
+}
+
+// ListSettleable feeds sub-orders whose sellers can now be paid to settlement
+func (s *OrderService) ListSettleable(since time.Time, afterID uint, limit int) ([]models.SubOrder, error) {
+	if limit <= 0 {
+		limit = defaultCompletedItemsLimit
+	}
+	if limit > maxCompletedItemsLimit {
+		limit = maxCompletedItemsLimit
+	}
+	return s.Repo.ListSettleable(since, afterID, limit)
+}
+
+
 func (s *OrderService) DeleteOrder(id string) error {
     return s.Repo.DeleteOrder(id)
 }
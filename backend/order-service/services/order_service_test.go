@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOrderService_CreateOrder_SnapshotsCataloguePrices(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{
+		BuyerID:     1,
+		TotalAmount: 1, // client-submitted values must be ignored
+		OrderItems: []models.OrderItem{
+			{ProductID: 10, Quantity: 2, UnitPrice: 0.5},
+			{ProductID: 11, Quantity: 1, UnitPrice: 0.5},
+		},
+	}
+
+	products.On("ClaimPrices", uint(1), mock.Anything, []PriceRequestItem{
+		{ProductID: 10, Quantity: 2},
+		{ProductID: 11, Quantity: 1},
+	}).Return([]PricedItem{
+		{ProductID: 10, ProductName: "Saree", Quantity: 2, UnitPrice: 1500, RegularPrice: 2000},
+		{ProductID: 11, ProductName: "Shawl", Quantity: 1, UnitPrice: 800, RegularPrice: 800},
+	}, nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, []uint{10, 11}).Return(nil)
+
+	err := service.CreateOrder(order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1500.0, order.OrderItems[0].UnitPrice)
+	assert.Equal(t, 3000.0, order.OrderItems[0].Subtotal)
+	assert.Equal(t, "Shawl", order.OrderItems[1].ProductName)
+	assert.Equal(t, 3800.0, order.Subtotal)
+	assert.Equal(t, 304.0, order.Tax)
+	assert.Equal(t, 4104.0, order.TotalAmount)
+	assert.Equal(t, "pending", order.Status)
+	assert.Equal(t, models.ActorBuyer, order.History[0].ActorRole)
+	repo.AssertExpectations(t)
+	products.AssertExpectations(t)
+}
+
+func TestOrderService_CreateOrder_RejectsEmptyOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	err := service.CreateOrder(&models.Order{BuyerID: 1})
+
+	assert.ErrorIs(t, err, ErrEmptyOrder)
+	products.AssertNotCalled(t, "ClaimPrices", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestOrderService_PlaceOrder_RejectsStaleQuoteBeforeClaiming(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{
+		BuyerID:       1,
+		PaymentMethod: PaymentMethodCard,
+		OrderItems:    []models.OrderItem{{ProductID: 10, Quantity: 2}},
+	}
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return([]ItemAvailability{
+		{PricedItem: PricedItem{ProductID: 10, Quantity: 2, UnitPrice: 25}, Available: true, InStock: 5},
+	}, nil)
+
+	err := service.PlaceOrder(order, &OrderTotals{Total: 50})
+
+	assert.ErrorIs(t, err, ErrTotalsMismatch)
+	assert.Equal(t, 50.0, order.Subtotal)
+	assert.Equal(t, 0.0, order.ShippingFee)
+	assert.Equal(t, 54.0, order.TotalAmount)
+	products.AssertNotCalled(t, "ClaimPrices", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestOrderService_PlaceOrder_RejectsQuoteTheClaimedPricesMiss(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{
+		BuyerID:       1,
+		PaymentMethod: PaymentMethodCard,
+		OrderItems:    []models.OrderItem{{ProductID: 10, Quantity: 2}},
+	}
+	products.On("CheckAvailability", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return([]ItemAvailability{
+		{PricedItem: PricedItem{ProductID: 10, Quantity: 2, UnitPrice: 20}, Available: true, InStock: 5},
+	}, nil)
+	// The flash sale sold out between the check and the claim
+	products.On("ClaimPrices", uint(1), mock.AnythingOfType("string"), []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return([]PricedItem{
+		{ProductID: 10, Quantity: 2, UnitPrice: 25},
+	}, nil)
+	products.On("ReleaseSaleClaims", mock.AnythingOfType("string"), []uint(nil)).Return(nil)
+
+	err := service.PlaceOrder(order, &OrderTotals{Total: 53.19})
+
+	assert.ErrorIs(t, err, ErrTotalsMismatch)
+	assert.Equal(t, 54.0, order.TotalAmount)
+	products.AssertCalled(t, "ReleaseSaleClaims", order.SaleClaimKey, []uint(nil))
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestOrderService_CreateOrder_ReleasesSaleClaimWhenNotSaved(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{BuyerID: 1, OrderItems: []models.OrderItem{{ProductID: 10, Quantity: 1}}}
+	products.On("ClaimPrices", uint(1), mock.AnythingOfType("string"), []PriceRequestItem{{ProductID: 10, Quantity: 1}}).Return([]PricedItem{
+		{ProductID: 10, ProductName: "Saree", Quantity: 1, UnitPrice: 1500},
+	}, nil)
+	repo.On("Create", order).Return(errors.New("db down"))
+	products.On("ReleaseSaleClaims", mock.AnythingOfType("string"), []uint(nil)).Return(nil)
+
+	err := service.CreateOrder(order)
+
+	assert.EqualError(t, err, "db down")
+	assert.NotEmpty(t, order.SaleClaimKey)
+	products.AssertCalled(t, "ReleaseSaleClaims", order.SaleClaimKey, []uint(nil))
+	products.AssertNotCalled(t, "RecordOrder", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_CreateOrder_SnapshotsDigitalItems(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{BuyerID: 1, OrderItems: []models.OrderItem{{ProductID: 12, Quantity: 1}}}
+	products.On("ClaimPrices", uint(1), mock.Anything, []PriceRequestItem{{ProductID: 12, Quantity: 1}}).Return([]PricedItem{
+		{ProductID: 12, ProductName: "E-book", Quantity: 1, UnitPrice: 300, IsDigital: true},
+	}, nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, mock.Anything).Return(nil)
+
+	err := service.CreateOrder(order)
+
+	assert.NoError(t, err)
+	assert.True(t, order.OrderItems[0].IsDigital)
+}
+
+func TestOrderService_CreateOrder_RecordsBundleComponents(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{BuyerID: 1, OrderItems: []models.OrderItem{{
+		ProductID:  20,
+		Quantity:   2,
+		Components: []models.OrderItemComponent{{ProductID: 99, Quantity: 50}}, // client-submitted contents must be ignored
+	}}}
+	products.On("ClaimPrices", uint(1), mock.Anything, []PriceRequestItem{{ProductID: 20, Quantity: 2}}).Return([]PricedItem{
+		{ProductID: 20, ProductName: "Phone combo", Quantity: 2, UnitPrice: 25000, Components: []PricedComponent{
+			{ProductID: 1, ProductName: "Phone", Quantity: 2},
+			{ProductID: 2, ProductName: "Cover", Quantity: 4},
+		}},
+	}, nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, mock.Anything).Return(nil)
+
+	err := service.CreateOrder(order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.0, order.Subtotal)
+	assert.Equal(t, []models.OrderItemComponent{
+		{ProductID: 1, ProductName: "Phone", Quantity: 2},
+		{ProductID: 2, ProductName: "Cover", Quantity: 4},
+	}, order.OrderItems[0].Components)
+}
+
+func TestOrderService_MarkPaid_DeliversDigitalOnlyOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	repo.On("GetByID", uint(7)).Return(&models.Order{ID: 7, BuyerID: 1, Status: "pending", OrderItems: []models.OrderItem{
+		{ProductID: 12, Quantity: 2, IsDigital: true},
+	}}, nil)
+	repo.On("SetPaid", uint(7), mock.Anything).Return(nil)
+	repo.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+	products.On("FulfilDigital", uint(7), uint(1), []PriceRequestItem{{ProductID: 12, Quantity: 2}}).
+		Return([]DigitalEntitlement{{ID: 3, ProductID: 12, Quantity: 2}}, nil)
+	repo.On("Transition", transitionTo(models.OrderStatusDelivered)).Return(nil)
+
+	order, err := service.MarkPaid(7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "delivered", order.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestOrderService_MarkPaid_MixedOrderAwaitsShipment(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	repo.On("GetByID", uint(7)).Return(&models.Order{ID: 7, BuyerID: 1, Status: "pending", OrderItems: []models.OrderItem{
+		{ProductID: 10, Quantity: 1},
+		{ProductID: 12, Quantity: 1, IsDigital: true},
+	}}, nil)
+	repo.On("SetPaid", uint(7), mock.Anything).Return(nil)
+	repo.On("Transition", transitionTo(models.OrderStatusConfirmed)).Return(nil)
+	products.On("FulfilDigital", uint(7), uint(1), []PriceRequestItem{{ProductID: 12, Quantity: 1}}).Return([]DigitalEntitlement{}, nil)
+
+	order, err := service.MarkPaid(7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+	repo.AssertNotCalled(t, "Transition", transitionTo(models.OrderStatusDelivered))
+}
+
+func TestOrderService_MarkPaid_RejectsCancelledOrder(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	repo.On("GetByID", uint(7)).Return(&models.Order{ID: 7, Status: "cancelled"}, nil)
+
+	_, err := service.MarkPaid(7)
+
+	assert.ErrorIs(t, err, ErrOrderNotPayable)
+	products.AssertNotCalled(t, "FulfilDigital", mock.Anything, mock.Anything, mock.Anything)
+}
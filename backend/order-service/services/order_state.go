@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"order-service/models"
+)
+
+var (
+	ErrIllegalTransition   = errors.New("order cannot move to that status")
+	ErrTransitionForbidden = errors.New("not allowed to move the order to that status")
+	ErrNotOrderBuyer       = errors.New("order belongs to another buyer")
+	// Cancelling releases stock and refunds payment, which a bare status change would skip
+	ErrCancelThroughCancellation = errors.New("orders are cancelled through POST /api/orders/:id/cancel")
+)
+
+// Actor is whoever asks for an order's status to change. Role is the role they act in;
+// Roles lists every role their token grants, and Authorize picks among them per order.
+type Actor struct {
+	ID    uint
+	Role  string
+	Roles []string
+}
+
+// HasRole reports whether the actor holds role
+func (a Actor) HasRole(role string) bool {
+	for _, held := range a.roles() {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+// As returns the actor acting in role
+func (a Actor) As(role string) Actor {
+	a.Role = role
+	return a
+}
+
+// roles lists the actor's roles; an actor built with only a Role holds just that one
+func (a Actor) roles() []string {
+	if len(a.Roles) == 0 {
+		return []string{a.Role}
+	}
+	return a.Roles
+}
+
+// SystemActor stands for another service calling the internal API
+var SystemActor = Actor{Role: models.ActorSystem}
+
+// TransitionError explains why a status change was refused. It matches
+// ErrIllegalTransition when no role may make the change, and ErrTransitionForbidden
+// when the actor's role may not.
+type TransitionError struct {
+	From string
+	To   string
+	Role string
+	Err  error
+}
+
+func (e *TransitionError) Error() string {
+	if errors.Is(e.Err, ErrTransitionForbidden) {
+		return fmt.Sprintf("%s may not move an order from %s to %s", e.Role, e.From, e.To)
+	}
+	return fmt.Sprintf("order cannot move from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// orderTransitions lists, for each status, the statuses it can move to and the roles
+// allowed to make each move. Anything not listed is illegal; delivered orders leave that
+// status through a return or, once the return window has closed, by being completed.
+// Completed, cancelled and returned orders are final.
+var orderTransitions = map[string]map[string][]string{
+	models.OrderStatusPending: {
+		models.OrderStatusConfirmed: {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+		models.OrderStatusCancelled: {models.ActorBuyer, models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+	},
+	models.OrderStatusConfirmed: {
+		models.OrderStatusProcessing: {models.ActorSeller, models.ActorAdmin},
+		models.OrderStatusShipped:    {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+		models.OrderStatusDelivered:  {models.ActorSystem}, // digital-only orders
+		models.OrderStatusCancelled:  {models.ActorBuyer, models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+	},
+	models.OrderStatusProcessing: {
+		models.OrderStatusShipped:   {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+		models.OrderStatusCancelled: {models.ActorSeller, models.ActorAdmin},
+	},
+	models.OrderStatusShipped: {
+		models.OrderStatusOutForDelivery: {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+		models.OrderStatusDelivered:      {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+	},
+	models.OrderStatusOutForDelivery: {
+		models.OrderStatusDelivered: {models.ActorSeller, models.ActorAdmin, models.ActorSystem},
+	},
+	models.OrderStatusDelivered: {
+		models.OrderStatusCompleted: {models.ActorAdmin, models.ActorSystem},
+		models.OrderStatusReturned:  {models.ActorAdmin, models.ActorSystem},
+	},
+}
+
+// CanTransition checks whether role may move an order from one status to another,
+// returning a *TransitionError if not
+func CanTransition(from, to, role string) error {
+	roles, ok := orderTransitions[from][to]
+	if !ok {
+		return &TransitionError{From: from, To: to, Role: role, Err: ErrIllegalTransition}
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to, Role: role, Err: ErrTransitionForbidden}
+}
+
+// NextStatuses lists the statuses role may move an order on to from its current status
+func NextStatuses(from, role string) []string {
+	var next []string
+	for _, to := range []string{
+		models.OrderStatusConfirmed, models.OrderStatusProcessing, models.OrderStatusShipped,
+		models.OrderStatusOutForDelivery, models.OrderStatusDelivered, models.OrderStatusCompleted,
+		models.OrderStatusCancelled, models.OrderStatusReturned,
+	} {
+		if CanTransition(from, to, role) == nil {
+			next = append(next, to)
+		}
+	}
+	return next
+}
+
+// TransitionOrder moves an order to a new status on behalf of actor and records who
+// did it and why. Buyers can only act on their own orders. Cancelling is left to the
+// CancellationService.
+func (s *OrderService) TransitionOrder(orderID uint, to string, actor Actor, reason string) (*models.Order, error) {
+	if to == models.OrderStatusCancelled {
+		return nil, ErrCancelThroughCancellation
+	}
+	order, err := s.Repo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transition(order, to, actor, reason); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// transition applies a status change to a loaded order. Split orders pass it on to
+// their sub-orders.
+func (s *OrderService) transition(order *models.Order, to string, actor Actor, reason string) error {
+	actor, err := Authorize(actor, ActionUpdateOrder, order)
+	if err != nil {
+		return err
+	}
+	if len(order.SubOrders) > 0 {
+		return s.transitionSubOrders(order, to, actor, reason)
+	}
+	if err := CanTransition(order.Status, to, actor.Role); err != nil {
+		return err
+	}
+	entry := &models.OrderStatusTransition{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   to,
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Reason:     reason,
+	}
+	if err := s.Repo.Transition(entry); err != nil {
+		return err
+	}
+	order.Status = to
+	order.History = append(order.History, *entry)
+	return nil
+}
+
+// OrderHistory returns an order with its status changes, oldest first, and the actor in
+// the role they may see it in. Sellers only get the changes to their own sub-orders.
+func (s *OrderService) OrderHistory(orderID uint, actor Actor) (*models.Order, Actor, error) {
+	return s.orderFor(orderID, actor)
+}
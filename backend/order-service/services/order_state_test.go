@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// transitionTo matches a recorded transition by its target status
+func transitionTo(status string) interface{} {
+	return mock.MatchedBy(func(entry *models.OrderStatusTransition) bool { return entry.ToStatus == status })
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.NoError(t, CanTransition(models.OrderStatusPending, models.OrderStatusCancelled, models.ActorBuyer))
+	assert.NoError(t, CanTransition(models.OrderStatusShipped, models.OrderStatusOutForDelivery, models.ActorSystem))
+
+	err := CanTransition(models.OrderStatusDelivered, models.OrderStatusPending, models.ActorAdmin)
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+
+	err = CanTransition(models.OrderStatusProcessing, models.OrderStatusCancelled, models.ActorBuyer)
+	assert.ErrorIs(t, err, ErrTransitionForbidden)
+	var transitionErr *TransitionError
+	assert.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, models.OrderStatusProcessing, transitionErr.From)
+
+	assert.Empty(t, NextStatuses(models.OrderStatusCancelled, models.ActorAdmin))
+	assert.Empty(t, NextStatuses(models.OrderStatusCompleted, models.ActorAdmin))
+	assert.Equal(t, []string{models.OrderStatusCompleted, models.OrderStatusReturned},
+		NextStatuses(models.OrderStatusDelivered, models.ActorSystem))
+	assert.Empty(t, NextStatuses(models.OrderStatusDelivered, models.ActorSeller))
+	assert.Equal(t, []string{models.OrderStatusCancelled}, NextStatuses(models.OrderStatusConfirmed, models.ActorBuyer))
+}
+
+func TestOrderService_TransitionOrder_RecordsActorAndReason(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	repo.On("GetByID", uint(7)).Return(&models.Order{ID: 7, BuyerID: 1, Status: models.OrderStatusConfirmed,
+		OrderItems: []models.OrderItem{{ProductID: 10, SellerID: 3}}}, nil)
+	repo.On("Transition", &models.OrderStatusTransition{
+		OrderID:    7,
+		FromStatus: models.OrderStatusConfirmed,
+		ToStatus:   models.OrderStatusProcessing,
+		ActorID:    3,
+		ActorRole:  models.ActorSeller,
+		Reason:     "packing",
+	}).Return(nil)
+
+	order, err := service.TransitionOrder(7, models.OrderStatusProcessing, Actor{ID: 3, Role: models.ActorSeller}, "packing")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusProcessing, order.Status)
+	assert.Len(t, order.History, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestOrderService_TransitionOrder_RejectsOtherBuyersAndIllegalMoves(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	repo.On("GetByID", uint(7)).Return(&models.Order{ID: 7, BuyerID: 1, Status: models.OrderStatusShipped}, nil)
+
+	_, err := service.TransitionOrder(7, models.OrderStatusCompleted, Actor{ID: 2, Role: models.ActorBuyer}, "")
+	assert.ErrorIs(t, err, ErrNotOrderBuyer)
+
+	_, err = service.TransitionOrder(7, models.OrderStatusCompleted, Actor{ID: 1, Role: models.ActorBuyer}, "")
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+
+	repo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestOrderService_TransitionOrder_LeavesCancellingToCancellationService(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	_, err := service.TransitionOrder(7, models.OrderStatusCancelled, Actor{ID: 1, Role: models.ActorBuyer}, "")
+	assert.ErrorIs(t, err, ErrCancelThroughCancellation)
+
+	_, err = service.TransitionSubOrder(71, models.OrderStatusCancelled, Actor{ID: 2, Role: models.ActorSeller}, "out of stock")
+	assert.ErrorIs(t, err, ErrCancelThroughCancellation)
+
+	repo.AssertNotCalled(t, "GetByID", mock.Anything)
+	repo.AssertNotCalled(t, "Transition", mock.Anything)
+}
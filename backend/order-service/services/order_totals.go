@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"order-service/models"
+)
+
+// Payment methods buyers can choose at checkout
+const (
+	PaymentMethodCard   = "card"
+	PaymentMethodPayPal = "paypal"
+	PaymentMethodBank   = "bank"
+	PaymentMethodCOD    = "cod"
+)
+
+// DefaultShippingMethod applies when an order does not name one
+const DefaultShippingMethod = "standard"
+
+var (
+	ErrUnknownShippingMethod = errors.New("unknown shipping method")
+	ErrUnknownPaymentMethod  = errors.New("payment method must be card, paypal, bank or cod")
+	ErrCODUnavailable        = errors.New("cash on delivery is not available for this order")
+	ErrTotalsMismatch        = errors.New("order totals do not match current prices")
+)
+
+// ShippingOption is a delivery speed offered at checkout
+type ShippingOption struct {
+	Price       float64
+	FreeOver    float64 // subtotal from which shipping is free; 0 for never
+	SupportsCOD bool
+}
+
+// PricingPolicy holds the rates and fees charged on top of catalogue prices
+type PricingPolicy struct {
+	TaxRate  float64
+	CODRate  float64 // share of the subtotal, clamped to CODMin..CODMax
+	CODMin   float64
+	CODMax   float64
+	Shipping map[string]ShippingOption
+}
+
+// DefaultPricingPolicy holds flat checkout rates: a fixed price per shipping method,
+// with standard shipping free from a subtotal of 50 as in the storefront. The
+// storefront's own shipping estimate also weighs parcel size and distance, so it is
+// only a preview; what an order costs is what Quote returns. SHIPPING_RATES and
+// TAX_RATE override these defaults.
+func DefaultPricingPolicy() PricingPolicy {
+	return PricingPolicy{
+		TaxRate: 0.08,
+		CODRate: 0.02,
+		CODMin:  2,
+		CODMax:  10,
+		Shipping: map[string]ShippingOption{
+			"standard":  {Price: 9.99, FreeOver: 50, SupportsCOD: true},
+			"express":   {Price: 19.99, SupportsCOD: true},
+			"overnight": {Price: 39.99},
+		},
+	}
+}
+
+// ParseShippingRates overrides the policy's shipping prices from a spec of
+// "method:price[:free_over],..."; methods not named keep their rates
+func (p *PricingPolicy) ParseShippingRates(spec string) error {
+	shipping := make(map[string]ShippingOption, len(p.Shipping))
+	for method, option := range p.Shipping {
+		shipping[method] = option
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("shipping rate %q: want method:price[:free_over]", entry)
+		}
+		option, ok := shipping[parts[0]]
+		if !ok {
+			return fmt.Errorf("shipping rate %q: %w", entry, ErrUnknownShippingMethod)
+		}
+		price, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("shipping rate %q: price must be a non-negative amount", entry)
+		}
+		option.Price = price
+		option.FreeOver = 0
+		if len(parts) == 3 {
+			freeOver, err := strconv.ParseFloat(parts[2], 64)
+			if err != nil || freeOver < 0 {
+				return fmt.Errorf("shipping rate %q: free_over must be a non-negative amount", entry)
+			}
+			option.FreeOver = freeOver
+		}
+		shipping[parts[0]] = option
+	}
+	p.Shipping = shipping
+	return nil
+}
+
+// OrderTotals is the breakdown of what a buyer pays for an order. The total is the
+// subtotal and fees less the discounts.
+type OrderTotals struct {
+	Subtotal         float64 `json:"subtotal"`
+	ShippingFee      float64 `json:"shipping_fee"`
+	Tax              float64 `json:"tax"`
+	CODFee           float64 `json:"cod_fee"`
+	Discount         float64 `json:"discount"`
+	ShippingDiscount float64 `json:"shipping_discount"`
+	Total            float64 `json:"total_amount"`
+}
+
+// QuotedTotals returns the totals a client submitted with an order, or nil if it sent none
+func QuotedTotals(order *models.Order) *OrderTotals {
+	quote := OrderTotals{
+		Subtotal:         order.Subtotal,
+		ShippingFee:      order.ShippingFee,
+		Tax:              order.Tax,
+		CODFee:           order.CODFee,
+		Discount:         order.Discount,
+		ShippingDiscount: order.ShippingDiscount,
+		Total:            order.TotalAmount,
+	}
+	if quote == (OrderTotals{}) {
+		return nil
+	}
+	return &quote
+}
+
+// Matches reports whether a client's quote agrees with these totals to the cent. The
+// total must always agree; parts of the breakdown the client left out are not compared.
+func (t OrderTotals) Matches(quote OrderTotals) bool {
+	parts := [][2]float64{
+		{t.Subtotal, quote.Subtotal},
+		{t.ShippingFee, quote.ShippingFee},
+		{t.Tax, quote.Tax},
+		{t.CODFee, quote.CODFee},
+		{t.Discount, quote.Discount},
+		{t.ShippingDiscount, quote.ShippingDiscount},
+	}
+	for _, part := range parts {
+		if part[1] != 0 && !sameAmount(part[0], part[1]) {
+			return false
+		}
+	}
+	return sameAmount(t.Total, quote.Total)
+}
+
+// Totals prices the fees on a subtotal. Orders with nothing to ship pay no shipping and
+// cannot be paid on delivery.
+func (p PricingPolicy) Totals(subtotal float64, shipsGoods bool, shippingMethod, paymentMethod string) (OrderTotals, error) {
+	totals := OrderTotals{Subtotal: roundAmount(subtotal)}
+
+	switch paymentMethod {
+	case "", PaymentMethodCard, PaymentMethodPayPal, PaymentMethodBank, PaymentMethodCOD:
+	default:
+		return totals, ErrUnknownPaymentMethod
+	}
+
+	cod := paymentMethod == PaymentMethodCOD
+	if shipsGoods {
+		option, ok := p.Shipping[shippingMethod]
+		if !ok {
+			return totals, ErrUnknownShippingMethod
+		}
+		if cod && !option.SupportsCOD {
+			return totals, ErrCODUnavailable
+		}
+		if option.FreeOver == 0 || totals.Subtotal < option.FreeOver {
+			totals.ShippingFee = option.Price
+		}
+	} else if cod {
+		return totals, ErrCODUnavailable
+	}
+
+	totals.Tax = roundAmount(totals.Subtotal * p.TaxRate)
+	if cod {
+		totals.CODFee = roundAmount(math.Min(math.Max(totals.Subtotal*p.CODRate, p.CODMin), p.CODMax))
+	}
+	totals.Total = roundAmount(totals.Subtotal + totals.ShippingFee + totals.Tax + totals.CODFee)
+	return totals, nil
+}
+
+// roundAmount rounds money to whole cents
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// sameAmount compares two amounts to the cent
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingPolicy_Totals_FreeStandardShippingOverThreshold(t *testing.T) {
+	policy := DefaultPricingPolicy()
+
+	totals, err := policy.Totals(60, true, "standard", PaymentMethodCard)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, totals.ShippingFee)
+	assert.Equal(t, 4.8, totals.Tax)
+	assert.Equal(t, 64.8, totals.Total)
+
+	totals, err = policy.Totals(40, true, "standard", PaymentMethodCard)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9.99, totals.ShippingFee)
+	assert.Equal(t, 53.19, totals.Total)
+
+	// Free from the threshold itself, as the storefront shows it
+	totals, err = policy.Totals(50, true, "standard", PaymentMethodCard)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, totals.ShippingFee)
+}
+
+func TestPricingPolicy_ParseShippingRates(t *testing.T) {
+	policy := DefaultPricingPolicy()
+
+	err := policy.ParseShippingRates("standard:5.99:100, express:14.99")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ShippingOption{Price: 5.99, FreeOver: 100, SupportsCOD: true}, policy.Shipping["standard"])
+	assert.Equal(t, ShippingOption{Price: 14.99, SupportsCOD: true}, policy.Shipping["express"])
+	assert.Equal(t, 39.99, policy.Shipping["overnight"].Price)
+	assert.Equal(t, 9.99, DefaultPricingPolicy().Shipping["standard"].Price)
+
+	assert.ErrorIs(t, policy.ParseShippingRates("drone:5"), ErrUnknownShippingMethod)
+	assert.Error(t, policy.ParseShippingRates("standard"))
+	assert.Error(t, policy.ParseShippingRates("standard:-1"))
+}
+
+func TestPricingPolicy_Totals_ClampsCODFee(t *testing.T) {
+	policy := DefaultPricingPolicy()
+
+	small, err := policy.Totals(20, true, "express", PaymentMethodCOD)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, small.CODFee)
+
+	large, err := policy.Totals(1000, true, "express", PaymentMethodCOD)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, large.CODFee)
+}
+
+func TestPricingPolicy_Totals_RejectsUnsupportedChoices(t *testing.T) {
+	policy := DefaultPricingPolicy()
+
+	_, err := policy.Totals(100, true, "overnight", PaymentMethodCOD)
+	assert.ErrorIs(t, err, ErrCODUnavailable)
+
+	_, err = policy.Totals(100, true, "drone", PaymentMethodCard)
+	assert.ErrorIs(t, err, ErrUnknownShippingMethod)
+
+	_, err = policy.Totals(100, true, "standard", "barter")
+	assert.ErrorIs(t, err, ErrUnknownPaymentMethod)
+}
+
+func TestPricingPolicy_Totals_DigitalOnlyOrder(t *testing.T) {
+	policy := DefaultPricingPolicy()
+
+	totals, err := policy.Totals(10, false, "overnight", PaymentMethodCard)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, totals.ShippingFee)
+	assert.Equal(t, 10.8, totals.Total)
+
+	_, err = policy.Totals(10, false, "standard", PaymentMethodCOD)
+	assert.ErrorIs(t, err, ErrCODUnavailable)
+}
+
+func TestOrderTotals_Matches(t *testing.T) {
+	totals := OrderTotals{Subtotal: 40, ShippingFee: 9.99, Tax: 3.2, Total: 53.19}
+
+	assert.True(t, totals.Matches(OrderTotals{Total: 53.19}))
+	assert.True(t, totals.Matches(OrderTotals{Subtotal: 40, Total: 53.19}))
+	assert.False(t, totals.Matches(OrderTotals{Total: 43.2}))
+	assert.False(t, totals.Matches(OrderTotals{ShippingFee: 0.01, Total: 53.19}))
+}
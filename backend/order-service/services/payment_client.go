@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"order-service/config"
+)
+
+// PaymentClient is the subset of payment-service that order-service depends on
+type PaymentClient interface {
+	OpenPayment(orderID, buyerID uint, amount float64, method string) (uint, error)
+	VoidPayment(orderID uint) error
+	RefundPayment(orderID uint, amount float64, reference, reason string) error
+	GetOrderPayment(orderID uint) (*PaymentSummary, error)
+}
+
+// PaymentSummary is what order details show of an order's payment
+type PaymentSummary struct {
+	ID             uint       `json:"id"`
+	Status         string     `json:"status"`
+	Method         string     `json:"method"`
+	Amount         float64    `json:"amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	PaymentTime    *time.Time `json:"payment_time,omitempty"`
+}
+
+// Payment statuses in which the buyer's money was taken
+const (
+	PaymentStatusCompleted = "completed"
+	PaymentStatusRefunded  = "refunded"
+)
+
+type httpPaymentClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewPaymentClient creates a PaymentClient that talks to payment-service over HTTP
+func NewPaymentClient() PaymentClient {
+	return &httpPaymentClient{
+		baseURL: config.GetPaymentServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// OpenPayment creates the order's pending payment and returns its id. An order only
+// ever gets one payment, however often this is called.
+func (c *httpPaymentClient) OpenPayment(orderID, buyerID uint, amount float64, method string) (uint, error) {
+	var result struct {
+		Payment struct {
+			ID uint `json:"id"`
+		} `json:"payment"`
+	}
+	payload := map[string]interface{}{"order_id": orderID, "buyer_id": buyerID, "amount": amount, "method": method}
+	if err := postJSON(c.client, "payment-service", c.baseURL, c.apiKey, "/internal/payments/", payload, &result); err != nil {
+		return 0, err
+	}
+	return result.Payment.ID, nil
+}
+
+// VoidPayment voids the order's payment, or refunds it if it was already completed
+func (c *httpPaymentClient) VoidPayment(orderID uint) error {
+	path := fmt.Sprintf("/internal/payments/order/%d/void", orderID)
+	return postJSON(c.client, "payment-service", c.baseURL, c.apiKey, path, struct{}{}, &struct{}{})
+}
+
+// RefundPayment gives back part of the order's payment. The reference identifies the
+// refund, so retrying with the same one never refunds twice.
+func (c *httpPaymentClient) RefundPayment(orderID uint, amount float64, reference, reason string) error {
+	path := fmt.Sprintf("/internal/payments/order/%d/refund", orderID)
+	payload := map[string]interface{}{"amount": amount, "reference": reference, "reason": reason}
+	return postJSON(c.client, "payment-service", c.baseURL, c.apiKey, path, payload, &struct{}{})
+}
+
+// GetOrderPayment fetches the order's payment, or nil if it has none
+func (c *httpPaymentClient) GetOrderPayment(orderID uint) (*PaymentSummary, error) {
+	var result struct {
+		Payment PaymentSummary `json:"payment"`
+	}
+	path := fmt.Sprintf("/internal/payments/order/%d", orderID)
+	err := getJSON(c.client, "payment-service", c.baseURL, c.apiKey, path, &result)
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result.Payment, nil
+}
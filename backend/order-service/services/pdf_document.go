@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 in PDF points
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+)
+
+// pdfDocument draws text and rules on A4 pages and writes them out as a PDF file.
+// Positions are in points from the top left corner of the page. Text is set in the
+// built-in Helvetica, which covers ASCII only; anything else, Bangla included, prints
+// as "?". Bangla would need a shaping engine and an embedded, subset font.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{}
+}
+
+// AddPage starts a new page; drawing goes to it from then on
+func (d *pdfDocument) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// printable replaces what Helvetica cannot draw with "?"
+func printable(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 0x20 && r < 0x7f {
+			return r
+		}
+		return '?'
+	}, text)
+}
+
+// TextWidth measures text as Text would draw it
+func (d *pdfDocument) TextWidth(text string, size float64, bold bool) float64 {
+	width := 0.0
+	text = printable(text)
+	for i := 0; i < len(text); i++ {
+		width += helveticaWidth(text[i], bold)
+	}
+	return width * size / 1000
+}
+
+// Text draws text with its baseline at y, starting at x
+func (d *pdfDocument) Text(x, y, size float64, bold bool, text string) {
+	if text == "" {
+		return
+	}
+	base := "/F1"
+	if bold {
+		base = "/F2"
+	}
+	fmt.Fprintf(d.page, "BT %.2f %.2f Td %s %.1f Tf (%s) Tj ET\n", x, pdfPageHeight-y, base, size, escapePDFString(printable(text)))
+}
+
+// TextRight draws text so that it ends at right
+func (d *pdfDocument) TextRight(right, y, size float64, bold bool, text string) {
+	d.Text(right-d.TextWidth(text, size, bold), y, size, bold, text)
+}
+
+// Fit shortens text with "..." until it is at most width wide
+func (d *pdfDocument) Fit(text string, size, width float64, bold bool) string {
+	if d.TextWidth(text, size, bold) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if shortened := string(runes) + "..."; d.TextWidth(shortened, size, bold) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
+
+// Line draws a thin rule from (x1, y1) to (x2, y2)
+func (d *pdfDocument) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// Bytes writes out the whole document
+func (d *pdfDocument) Bytes() ([]byte, error) {
+	var objects [][]byte
+	add := func(body []byte) int {
+		objects = append(objects, body)
+		return len(objects)
+	}
+	catalog := add(nil)
+	pages := add(nil)
+	fonts := fmt.Sprintf("/F1 %d 0 R /F2 %d 0 R",
+		add([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")),
+		add([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")))
+
+	var kids []string
+	for _, content := range d.pages {
+		stream, err := pdfStream(content.Bytes(), "")
+		if err != nil {
+			return nil, err
+		}
+		page := add([]byte(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pages, pdfPageWidth, pdfPageHeight, fonts, add(stream))))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	objects[pages-1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	objects[catalog-1] = []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(body)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, xref)
+	return out.Bytes(), nil
+}
+
+// pdfStream compresses data into a stream object, adding extra to its dictionary
+func pdfStream(data []byte, extra string) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "<< /Length %d /Filter /FlateDecode%s >>\nstream\n", compressed.Len(), extra)
+	out.Write(compressed.Bytes())
+	out.WriteString("\nendstream")
+	return out.Bytes(), nil
+}
+
+func escapePDFString(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
+
+// helveticaWidth is the advance of an ASCII character in Helvetica, in 1/1000 em
+func helveticaWidth(c byte, bold bool) float64 {
+	if c < 0x20 || c > 0x7e {
+		return 556
+	}
+	if bold {
+		return float64(helveticaBoldWidths[c-0x20])
+	}
+	return float64(helveticaWidths[c-0x20])
+}
+
+// Advances of ' ' through '~' from the standard Helvetica metrics
+var helveticaWidths = [95]uint16{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]uint16{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"order-service/config"
+)
+
+// PriceRequestItem is a product and quantity to be priced by product-service
+type PriceRequestItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// PricedItem is product-service's authoritative price for a line item
+type PricedItem struct {
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	SellerID        uint    `json:"seller_id"`
+	ShopID          uint    `json:"shop_id"`
+	CategoryID      uint    `json:"category_id"`
+	Quantity        int     `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	RegularPrice    float64 `json:"regular_price"`
+	PriceScheduleID *uint   `json:"price_schedule_id,omitempty"`
+	IsDigital       bool    `json:"is_digital"`
+	// Products shipped for a bundle line, with quantities for the whole line
+	Components []PricedComponent `json:"components,omitempty"`
+}
+
+// PricedComponent is one product contained in a priced bundle line
+type PricedComponent struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+// ItemAvailability is product-service's current price and stock for a cart item.
+// Available is false once the product is no longer sold.
+type ItemAvailability struct {
+	PricedItem
+	Available bool `json:"available"`
+	InStock   int  `json:"in_stock"`
+}
+
+// DigitalEntitlement is product-service's record of a delivered digital item
+type DigitalEntitlement struct {
+	ID          uint `json:"id"`
+	ProductID   uint `json:"product_id"`
+	Quantity    int  `json:"quantity"`
+	KeysPending int  `json:"keys_pending"`
+}
+
+// ProductServiceError is a non-200 response from product-service
+type ProductServiceError struct {
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *ProductServiceError) Error() string {
+	return fmt.Sprintf("product-service %s failed (%d): %s", e.Path, e.StatusCode, e.Message)
+}
+
+// ProductClient is the subset of product-service that order-service depends on
+type ProductClient interface {
+	ClaimPrices(buyerID uint, claimKey string, items []PriceRequestItem) ([]PricedItem, error)
+	ReleaseSaleClaims(claimKey string, productIDs []uint) error
+	CheckAvailability(items []PriceRequestItem) ([]ItemAvailability, error)
+	FulfilDigital(orderID, buyerID uint, items []PriceRequestItem) ([]DigitalEntitlement, error)
+	ReserveStock(orderID uint, items []PriceRequestItem) error
+	ReleaseStock(orderID uint, productIDs []uint) error
+	RestockReturn(orderID uint, returnKey string, items []PriceRequestItem) error
+	RecordOrder(orderID uint, productIDs []uint) error
+}
+
+type httpProductClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewProductClient creates a ProductClient that talks to product-service over HTTP
+func NewProductClient() ProductClient {
+	return &httpProductClient{
+		baseURL: config.GetProductServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// ClaimPrices resolves effective prices for an order and reserves any sale quantity
+// used under claimKey
+func (c *httpProductClient) ClaimPrices(buyerID uint, claimKey string, items []PriceRequestItem) ([]PricedItem, error) {
+	var result struct {
+		Items []PricedItem `json:"items"`
+	}
+	payload := map[string]interface{}{"buyer_id": buyerID, "claim_key": claimKey, "items": items}
+	if err := c.post("/internal/products/prices/claim", payload, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// ReleaseSaleClaims gives back the sale quantity claimed under claimKey for the given
+// products, or for all of them when productIDs is empty; repeating it is harmless
+func (c *httpProductClient) ReleaseSaleClaims(claimKey string, productIDs []uint) error {
+	payload := map[string]interface{}{"claim_key": claimKey, "product_ids": productIDs}
+	return c.post("/internal/products/prices/release", payload, &struct{}{})
+}
+
+// CheckAvailability fetches current prices and stock without reserving anything
+func (c *httpProductClient) CheckAvailability(items []PriceRequestItem) ([]ItemAvailability, error) {
+	var result struct {
+		Items []ItemAvailability `json:"items"`
+	}
+	payload := map[string]interface{}{"items": items}
+	if err := c.post("/internal/products/availability", payload, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// FulfilDigital asks product-service to deliver the digital items of a paid order.
+// Repeating the call for the same order delivers nothing twice.
+func (c *httpProductClient) FulfilDigital(orderID, buyerID uint, items []PriceRequestItem) ([]DigitalEntitlement, error) {
+	var result struct {
+		Entitlements []DigitalEntitlement `json:"entitlements"`
+	}
+	payload := map[string]interface{}{"order_id": orderID, "buyer_id": buyerID, "items": items}
+	if err := c.post("/internal/products/digital/fulfil", payload, &result); err != nil {
+		return nil, err
+	}
+	return result.Entitlements, nil
+}
+
+// ReserveStock holds stock for all of an order's items, or fails without holding any.
+// Reserving the same order again holds nothing more.
+func (c *httpProductClient) ReserveStock(orderID uint, items []PriceRequestItem) error {
+	payload := map[string]interface{}{"order_id": orderID, "items": items}
+	return c.post("/internal/products/stock/reserve", payload, &struct{}{})
+}
+
+// ReleaseStock returns whatever stock an order still holds for the given products, or
+// for all of them when productIDs is empty; repeating it is harmless
+func (c *httpProductClient) ReleaseStock(orderID uint, productIDs []uint) error {
+	payload := map[string]interface{}{"order_id": orderID, "product_ids": productIDs}
+	return c.post("/internal/products/stock/release", payload, &struct{}{})
+}
+
+// RestockReturn puts goods that came back in a return of an order into stock again.
+// Restocking the same return key again puts nothing more back.
+func (c *httpProductClient) RestockReturn(orderID uint, returnKey string, items []PriceRequestItem) error {
+	payload := map[string]interface{}{"order_id": orderID, "return_key": returnKey, "items": items}
+	return c.post("/internal/products/stock/restock", payload, &struct{}{})
+}
+
+// RecordOrder counts a placed order towards its products' analytics
+func (c *httpProductClient) RecordOrder(orderID uint, productIDs []uint) error {
+	payload := map[string]interface{}{"order_id": orderID, "product_ids": productIDs}
+	return c.post("/internal/products/events/order", payload, &struct{}{})
+}
+
+// post sends a JSON request to product-service and decodes a JSON response
+func (c *httpProductClient) post(path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		message := string(respBody)
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		return &ProductServiceError{Path: path, StatusCode: resp.StatusCode, Message: message}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCouponNotFound      = errors.New("coupon code not recognised")
+	ErrCouponNotActive     = errors.New("coupon is not valid at this time")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to any item in this order")
+	ErrCouponMinimum       = errors.New("order does not reach the coupon's minimum")
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrCouponCodeTaken     = errors.New("coupon code already in use")
+	ErrCouponForbidden     = errors.New("not allowed to manage this coupon")
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// PromotionService runs coupons: shop owners and admins manage them, and checkout
+// works out what the one a buyer enters takes off their order
+type PromotionService struct {
+	Repo  repository.CouponRepository
+	Shops ShopClient
+}
+
+func NewPromotionService(repo repository.CouponRepository, shops ShopClient) *PromotionService {
+	return &PromotionService{Repo: repo, Shops: shops}
+}
+
+// NormalizeCouponCode writes a code the way coupons are stored, so buyers can type it
+// in any case
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CreateCoupon saves a new, active coupon. Sellers create coupons for shops they own;
+// only admins create platform-wide ones.
+func (s *PromotionService) CreateCoupon(coupon *models.Coupon, actor Actor) error {
+	coupon.Code = NormalizeCouponCode(coupon.Code)
+	if err := s.authorize(actor, coupon.ShopID); err != nil {
+		return err
+	}
+	if coupon.StartsAt.IsZero() {
+		coupon.StartsAt = time.Now()
+	}
+	if err := validateCoupon(coupon); err != nil {
+		return err
+	}
+	_, err := s.Repo.GetByCode(coupon.Code)
+	if err == nil {
+		return ErrCouponCodeTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	coupon.ID, coupon.UsedCount, coupon.CreatedBy, coupon.Active = 0, 0, actor.ID, true
+	return s.Repo.Create(coupon)
+}
+
+// UpdateCoupon replaces a coupon's terms, including whether it is active. Its code, shop
+// and usage stay as they are.
+func (s *PromotionService) UpdateCoupon(id uint, changes *models.Coupon, actor Actor) (*models.Coupon, error) {
+	coupon, err := s.GetCoupon(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	coupon.Type = changes.Type
+	coupon.Value = changes.Value
+	coupon.MaxDiscount = changes.MaxDiscount
+	coupon.MinOrderValue = changes.MinOrderValue
+	coupon.UsageLimit = changes.UsageLimit
+	coupon.PerUserLimit = changes.PerUserLimit
+	if !changes.StartsAt.IsZero() {
+		coupon.StartsAt = changes.StartsAt
+	}
+	coupon.EndsAt = changes.EndsAt
+	coupon.ProductIDs = changes.ProductIDs
+	coupon.CategoryIDs = changes.CategoryIDs
+	coupon.Active = changes.Active
+	if err := validateCoupon(coupon); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Update(coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// DeactivateCoupon stops a coupon from being used. Orders that used it keep their
+// discount.
+func (s *PromotionService) DeactivateCoupon(id uint, actor Actor) (*models.Coupon, error) {
+	coupon, err := s.GetCoupon(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	coupon.Active = false
+	if err := s.Repo.Update(coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// GetCoupon returns a coupon actor manages
+func (s *PromotionService) GetCoupon(id uint, actor Actor) (*models.Coupon, error) {
+	coupon, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actor, coupon.ShopID); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// ListCoupons returns a shop's coupons. Admins may pass 0 for every coupon; sellers
+// must name a shop of theirs.
+func (s *PromotionService) ListCoupons(shopID uint, actor Actor) ([]models.Coupon, error) {
+	if shopID != 0 || !actor.HasRole(models.ActorAdmin) {
+		if err := s.authorize(actor, shopID); err != nil {
+			return nil, err
+		}
+	}
+	return s.Repo.List(shopID)
+}
+
+// authorize decides whether actor manages the coupons of a shop, or the platform-wide
+// ones when shopID is 0
+func (s *PromotionService) authorize(actor Actor, shopID uint) error {
+	switch {
+	case actor.HasRole(models.ActorAdmin):
+		return nil
+	case actor.HasRole(models.ActorSeller):
+		if shopID == 0 {
+			return ErrCouponForbidden
+		}
+		// Only shop-service knows who owns a shop
+		shop, err := s.Shops.GetShop(shopID)
+		if err != nil {
+			return err
+		}
+		if shop.OwnerID != actor.ID {
+			return ErrCouponForbidden
+		}
+		return nil
+	}
+	return ErrCouponForbidden
+}
+
+func validateCoupon(coupon *models.Coupon) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s", ErrInvalidCoupon, reason)
+	}
+	if !couponCodePattern.MatchString(coupon.Code) {
+		return invalid("code must be 3 to 32 letters, digits, dashes or underscores")
+	}
+	switch coupon.Type {
+	case models.CouponTypePercentage:
+		if coupon.Value <= 0 || coupon.Value > 100 {
+			return invalid("a percentage must be above 0 and at most 100")
+		}
+	case models.CouponTypeFixed:
+		if coupon.Value <= 0 {
+			return invalid("value must be above 0")
+		}
+	case models.CouponTypeFreeShipping:
+		coupon.Value, coupon.MaxDiscount = 0, 0
+	default:
+		return invalid("type must be percentage, fixed or free_shipping")
+	}
+	if coupon.MaxDiscount < 0 || coupon.MinOrderValue < 0 || coupon.UsageLimit < 0 || coupon.PerUserLimit < 0 {
+		return invalid("amounts and limits cannot be negative")
+	}
+	if coupon.EndsAt != nil && !coupon.EndsAt.After(coupon.StartsAt) {
+		return invalid("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// Promotion is what a coupon takes off one order
+type Promotion struct {
+	Coupon   *models.Coupon
+	Discount float64 // off the items
+	// Discount shared between the priced lines in proportion to their subtotals, so
+	// refunds of a line give back its price less its share
+	LineDiscounts []float64
+	freeShipping  map[[2]uint]bool // sellers and shops whose shipping is waived
+}
+
+// Evaluate works out what a coupon takes off an order of priced lines for buyerID,
+// after checking the buyer can use it now. The use is only counted when the order is
+// saved, which checks the limits again.
+func (s *PromotionService) Evaluate(code string, buyerID uint, lines []PricedItem) (*Promotion, error) {
+	coupon, err := s.Repo.GetByCode(NormalizeCouponCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCouponNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !coupon.Active || now.Before(coupon.StartsAt) || (coupon.EndsAt != nil && !now.Before(*coupon.EndsAt)) {
+		return nil, ErrCouponNotActive
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return nil, repository.ErrCouponExhausted
+	}
+	if coupon.PerUserLimit > 0 {
+		used, err := s.Repo.CountRedemptions(coupon.ID, buyerID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(coupon.PerUserLimit) {
+			return nil, repository.ErrCouponUserLimit
+		}
+	}
+	return applyCoupon(coupon, lines)
+}
+
+// Release gives back the coupon use of a cancelled order
+func (s *PromotionService) Release(orderID uint) error {
+	return s.Repo.Release(orderID)
+}
+
+// applyCoupon works out a coupon's discount on the lines it covers. The minimum order
+// value is measured on those lines alone.
+func applyCoupon(coupon *models.Coupon, lines []PricedItem) (*Promotion, error) {
+	promotion := &Promotion{Coupon: coupon, freeShipping: map[[2]uint]bool{}}
+	weights := make([]float64, len(lines))
+	eligible := 0.0
+	for i, line := range lines {
+		if !couponCovers(coupon, line) {
+			continue
+		}
+		weights[i] = line.UnitPrice * float64(line.Quantity)
+		eligible += weights[i]
+		if !line.IsDigital {
+			promotion.freeShipping[[2]uint{line.SellerID, line.ShopID}] = true
+		}
+	}
+	eligible = roundAmount(eligible)
+	if eligible <= 0 || (coupon.Type == models.CouponTypeFreeShipping && len(promotion.freeShipping) == 0) {
+		return nil, ErrCouponNotApplicable
+	}
+	if eligible < coupon.MinOrderValue {
+		return nil, fmt.Errorf("%w of %.2f", ErrCouponMinimum, coupon.MinOrderValue)
+	}
+
+	switch coupon.Type {
+	case models.CouponTypePercentage:
+		promotion.Discount = roundAmount(eligible * coupon.Value / 100)
+		if coupon.MaxDiscount > 0 && promotion.Discount > coupon.MaxDiscount {
+			promotion.Discount = coupon.MaxDiscount
+		}
+	case models.CouponTypeFixed:
+		promotion.Discount = roundAmount(math.Min(coupon.Value, eligible))
+	}
+	if coupon.Type != models.CouponTypeFreeShipping {
+		promotion.freeShipping = nil
+	}
+	promotion.LineDiscounts = allocate(promotion.Discount, weights)
+	return promotion, nil
+}
+
+// couponCovers reports whether a coupon applies to a priced line
+func couponCovers(coupon *models.Coupon, line PricedItem) bool {
+	if coupon.ShopID != 0 && line.ShopID != coupon.ShopID {
+		return false
+	}
+	if len(coupon.ProductIDs) > 0 && !slices.Contains(coupon.ProductIDs, line.ProductID) {
+		return false
+	}
+	return len(coupon.CategoryIDs) == 0 || slices.Contains(coupon.CategoryIDs, line.CategoryID)
+}
+
+// discount is what the promotion takes off the items; nothing without a promotion
+func (p *Promotion) discount() float64 {
+	if p == nil {
+		return 0
+	}
+	return p.Discount
+}
+
+// shippingDiscount is the part of a shipping fee a free-shipping coupon waives: the
+// shares of the shops it covers, shared out as splitOrder will
+func (p *Promotion) shippingDiscount(fee float64, lines []PricedItem) float64 {
+	if p == nil || len(p.freeShipping) == 0 {
+		return 0
+	}
+	var keys [][2]uint
+	var weights []float64
+	index := map[[2]uint]int{}
+	for _, line := range lines {
+		key := [2]uint{line.SellerID, line.ShopID}
+		i, ok := index[key]
+		if !ok {
+			i = len(keys)
+			index[key] = i
+			keys = append(keys, key)
+			weights = append(weights, 0)
+		}
+		if !line.IsDigital {
+			weights[i] += line.UnitPrice * float64(line.Quantity)
+		}
+	}
+	waived := 0.0
+	for i, share := range allocate(fee, weights) {
+		if p.freeShipping[keys[i]] {
+			waived += share
+		}
+	}
+	return roundAmount(waived)
+}
+
+// discountItems gives each item of a priced order its share of the discount
+func (p *Promotion) discountItems(items []models.OrderItem) {
+	for i := range items {
+		items[i].Discount = 0
+		if p != nil {
+			items[i].Discount = p.LineDiscounts[i]
+		}
+	}
+}
+
+// waiveShipping takes the shipping fee off the sub-orders of the shops a free-shipping
+// coupon covers
+func (p *Promotion) waiveShipping(subOrders []models.SubOrder) {
+	if p == nil {
+		return
+	}
+	for i := range subOrders {
+		sub := &subOrders[i]
+		if p.freeShipping[[2]uint{sub.SellerID, sub.ShopID}] {
+			sub.ShippingDiscount = sub.ShippingFee
+			sub.TotalAmount = subOrderTotal(sub)
+		}
+	}
+}
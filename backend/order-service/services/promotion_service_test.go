@@ -0,0 +1,284 @@
+package services
+
+import (
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// couponLines are two shops' goods and a digital item of the first shop
+func couponLines() []PricedItem {
+	return []PricedItem{
+		{ProductID: 10, SellerID: 2, ShopID: 20, CategoryID: 4, Quantity: 1, UnitPrice: 20},
+		{ProductID: 11, SellerID: 3, ShopID: 30, CategoryID: 5, Quantity: 2, UnitPrice: 7.5},
+		{ProductID: 12, SellerID: 2, ShopID: 20, CategoryID: 6, Quantity: 1, UnitPrice: 10, IsDigital: true},
+	}
+}
+
+func activeCoupon(coupon models.Coupon) *models.Coupon {
+	coupon.ID = 9
+	coupon.Active = true
+	coupon.StartsAt = time.Now().Add(-time.Hour)
+	return &coupon
+}
+
+func TestApplyCoupon_PercentageIsCappedAndSharedByLine(t *testing.T) {
+	coupon := activeCoupon(models.Coupon{Code: "EID10", Type: models.CouponTypePercentage, Value: 10, MaxDiscount: 3})
+
+	promotion, err := applyCoupon(coupon, couponLines())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, promotion.Discount)
+	assert.Equal(t, []float64{1.33, 1, 0.67}, promotion.LineDiscounts)
+}
+
+func TestApplyCoupon_ShopCouponOnlyCoversThatShop(t *testing.T) {
+	coupon := activeCoupon(models.Coupon{Code: "TANT50", ShopID: 30, Type: models.CouponTypeFixed, Value: 50})
+
+	promotion, err := applyCoupon(coupon, couponLines())
+
+	assert.NoError(t, err)
+	// A fixed discount never exceeds what the covered items cost
+	assert.Equal(t, 15.0, promotion.Discount)
+	assert.Equal(t, []float64{0, 15, 0}, promotion.LineDiscounts)
+}
+
+func TestApplyCoupon_ProductAndCategoryEligibility(t *testing.T) {
+	byCategory := activeCoupon(models.Coupon{Code: "SAREE", Type: models.CouponTypeFixed, Value: 5, CategoryIDs: []uint{4}})
+	promotion, err := applyCoupon(byCategory, couponLines())
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{5, 0, 0}, promotion.LineDiscounts)
+
+	byProduct := activeCoupon(models.Coupon{Code: "EBOOK", Type: models.CouponTypeFixed, Value: 5, ProductIDs: []uint{99}})
+	_, err = applyCoupon(byProduct, couponLines())
+	assert.ErrorIs(t, err, ErrCouponNotApplicable)
+}
+
+func TestApplyCoupon_MinimumCountsCoveredItemsOnly(t *testing.T) {
+	coupon := activeCoupon(models.Coupon{Code: "TANT5", ShopID: 30, Type: models.CouponTypeFixed, Value: 5, MinOrderValue: 20})
+
+	_, err := applyCoupon(coupon, couponLines())
+
+	assert.ErrorIs(t, err, ErrCouponMinimum)
+}
+
+func TestApplyCoupon_FreeShippingNeedsShippedGoods(t *testing.T) {
+	coupon := activeCoupon(models.Coupon{Code: "SHIPFREE", Type: models.CouponTypeFreeShipping, ProductIDs: []uint{12}})
+
+	_, err := applyCoupon(coupon, couponLines())
+
+	assert.ErrorIs(t, err, ErrCouponNotApplicable)
+}
+
+func TestPromotionService_Evaluate_ChecksValidityAndLimits(t *testing.T) {
+	ended := time.Now().Add(-time.Minute)
+	cases := []struct {
+		name   string
+		coupon *models.Coupon
+		used   int64
+		err    error
+	}{
+		{"inactive", &models.Coupon{ID: 9, Type: models.CouponTypeFixed, Value: 5}, 0, ErrCouponNotActive},
+		{"not started", &models.Coupon{ID: 9, Active: true, StartsAt: time.Now().Add(time.Hour), Type: models.CouponTypeFixed, Value: 5}, 0, ErrCouponNotActive},
+		{"ended", &models.Coupon{ID: 9, Active: true, EndsAt: &ended, Type: models.CouponTypeFixed, Value: 5}, 0, ErrCouponNotActive},
+		{"used up", activeCoupon(models.Coupon{Type: models.CouponTypeFixed, Value: 5, UsageLimit: 100, UsedCount: 100}), 0, repository.ErrCouponExhausted},
+		{"buyer limit", activeCoupon(models.Coupon{Type: models.CouponTypeFixed, Value: 5, PerUserLimit: 1}), 1, repository.ErrCouponUserLimit},
+		{"usable", activeCoupon(models.Coupon{Type: models.CouponTypeFixed, Value: 5, UsageLimit: 100, UsedCount: 99, PerUserLimit: 2}), 1, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			coupons := new(repository.MockCouponRepository)
+			service := NewPromotionService(coupons, new(MockShopClient))
+			coupons.On("GetByCode", "EID10").Return(tc.coupon, nil)
+			coupons.On("CountRedemptions", uint(9), uint(1)).Return(tc.used, nil)
+
+			promotion, err := service.Evaluate(" eid10 ", 1, couponLines())
+
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 5.0, promotion.Discount)
+		})
+	}
+}
+
+func TestPromotionService_Evaluate_UnknownCode(t *testing.T) {
+	coupons := new(repository.MockCouponRepository)
+	service := NewPromotionService(coupons, new(MockShopClient))
+	coupons.On("GetByCode", "NOPE").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.Evaluate("nope", 1, couponLines())
+
+	assert.ErrorIs(t, err, ErrCouponNotFound)
+}
+
+func TestOrderService_PlaceOrder_AppliesPercentageCoupon(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+	coupons := new(repository.MockCouponRepository)
+	promotions := NewPromotionService(coupons, new(MockShopClient))
+	service.Promotions = promotions
+
+	order := &models.Order{BuyerID: 1, PaymentMethod: PaymentMethodCard, CouponCode: "eid10",
+		OrderItems: []models.OrderItem{{ProductID: 10, Quantity: 1}, {ProductID: 11, Quantity: 2}, {ProductID: 12, Quantity: 1}}}
+	available := make([]ItemAvailability, 0, 3)
+	for _, line := range couponLines() {
+		available = append(available, ItemAvailability{PricedItem: line, Available: true})
+	}
+	products.On("CheckAvailability", mock.Anything).Return(available, nil)
+	products.On("ClaimPrices", uint(1), mock.Anything, mock.Anything).Return(couponLines(), nil)
+	coupons.On("GetByCode", "EID10").Return(activeCoupon(models.Coupon{Code: "EID10", Type: models.CouponTypePercentage, Value: 10}), nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, mock.Anything).Return(nil)
+
+	err := service.PlaceOrder(order, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "EID10", order.CouponCode)
+	assert.Equal(t, 45.0, order.Subtotal)
+	assert.Equal(t, 4.5, order.Discount)
+	// Tax is charged on the discounted subtotal
+	assert.Equal(t, 3.24, order.Tax)
+	assert.Equal(t, 53.73, order.TotalAmount)
+	assert.Equal(t, []float64{2, 1.5, 1}, []float64{order.OrderItems[0].Discount, order.OrderItems[1].Discount, order.OrderItems[2].Discount})
+
+	shop20, shop30 := order.SubOrders[0], order.SubOrders[1]
+	assert.Equal(t, 3.0, shop20.Discount)
+	assert.Equal(t, 1.5, shop30.Discount)
+	assert.InDelta(t, order.Tax, shop20.Tax+shop30.Tax, 0.001)
+	assert.InDelta(t, order.TotalAmount, shop20.TotalAmount+shop30.TotalAmount, 0.001)
+	assert.Equal(t, &models.CouponRedemption{CouponID: 9, UserID: 1, Discount: 4.5}, order.Redemption)
+	// Evaluated once before claiming prices and once on the claimed prices
+	coupons.AssertNumberOfCalls(t, "GetByCode", 2)
+}
+
+func TestOrderService_PlaceOrder_FreeShippingWaivesCoveredShopsShare(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+	coupons := new(repository.MockCouponRepository)
+	promotions := NewPromotionService(coupons, new(MockShopClient))
+	service.Promotions = promotions
+
+	order := &models.Order{BuyerID: 1, PaymentMethod: PaymentMethodCard, CouponCode: "TANTSHIP",
+		OrderItems: []models.OrderItem{{ProductID: 10, Quantity: 1}, {ProductID: 11, Quantity: 2}, {ProductID: 12, Quantity: 1}}}
+	available := make([]ItemAvailability, 0, 3)
+	for _, line := range couponLines() {
+		available = append(available, ItemAvailability{PricedItem: line, Available: true})
+	}
+	products.On("CheckAvailability", mock.Anything).Return(available, nil)
+	products.On("ClaimPrices", uint(1), mock.Anything, mock.Anything).Return(couponLines(), nil)
+	coupons.On("GetByCode", "TANTSHIP").Return(activeCoupon(models.Coupon{Code: "TANTSHIP", ShopID: 20, Type: models.CouponTypeFreeShipping}), nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, mock.Anything).Return(nil)
+
+	err := service.PlaceOrder(order, &OrderTotals{Total: 52.88})
+
+	assert.NoError(t, err)
+	assert.Zero(t, order.Discount)
+	assert.Equal(t, 9.99, order.ShippingFee)
+	assert.Equal(t, 5.71, order.ShippingDiscount)
+	assert.Equal(t, 52.88, order.TotalAmount)
+	shop20, shop30 := order.SubOrders[0], order.SubOrders[1]
+	assert.Equal(t, shop20.ShippingFee, shop20.ShippingDiscount)
+	assert.Zero(t, shop30.ShippingDiscount)
+	assert.InDelta(t, order.TotalAmount, shop20.TotalAmount+shop30.TotalAmount, 0.001)
+	assert.Equal(t, 5.71, order.Redemption.Discount)
+}
+
+func TestOrderService_PlaceOrder_RefusesCouponBeforeClaiming(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+	coupons := new(repository.MockCouponRepository)
+	promotions := NewPromotionService(coupons, new(MockShopClient))
+	service.Promotions = promotions
+
+	order := &models.Order{BuyerID: 1, CouponCode: "TANT5", OrderItems: []models.OrderItem{{ProductID: 11, Quantity: 2}}}
+	products.On("CheckAvailability", mock.Anything).Return([]ItemAvailability{{PricedItem: couponLines()[1], Available: true}}, nil)
+	coupons.On("GetByCode", "TANT5").Return(activeCoupon(models.Coupon{Code: "TANT5", ShopID: 30, Type: models.CouponTypeFixed, Value: 5, MinOrderValue: 20}), nil)
+
+	err := service.PlaceOrder(order, nil)
+
+	assert.ErrorIs(t, err, ErrCouponMinimum)
+	products.AssertNotCalled(t, "ClaimPrices", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCancellationService_CancelOrder_ReleasesCoupon(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	payments := new(MockPaymentClient)
+	service := NewCancellationService(NewOrderService(repo, products), products, payments)
+	coupons := new(repository.MockCouponRepository)
+	promotions := NewPromotionService(coupons, new(MockShopClient))
+	service.Orders.Promotions = promotions
+	order := pendingOrder()
+	order.CouponCode = "EID10"
+
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	repo.On("Transition", transitionTo(models.OrderStatusCancelled)).Return(nil)
+	products.On("ReleaseStock", uint(7), []uint(nil)).Return(nil)
+	products.On("ReleaseSaleClaims", "claim-7", []uint(nil)).Return(nil)
+	payments.On("RefundPayment", uint(7), 3000.0, "cancel-order-7", "order cancelled").Return(nil)
+	coupons.On("Release", uint(7)).Return(nil)
+
+	_, err := service.CancelOrder(7, Actor{ID: 1, Role: models.ActorBuyer}, "")
+
+	assert.NoError(t, err)
+	coupons.AssertExpectations(t)
+}
+
+func TestPromotionService_CreateCoupon_SellerOnlyForOwnShop(t *testing.T) {
+	coupons := new(repository.MockCouponRepository)
+	shops := new(MockShopClient)
+	service := NewPromotionService(coupons, shops)
+	shops.On("GetShop", uint(20)).Return(&ShopProfile{ID: 20, OwnerID: 2}, nil)
+	coupons.On("GetByCode", "TANT10").Return(nil, gorm.ErrRecordNotFound)
+	coupons.On("Create", mock.AnythingOfType("*models.Coupon")).Return(nil)
+
+	coupon := &models.Coupon{Code: "tant10", ShopID: 20, Type: models.CouponTypePercentage, Value: 10, UsedCount: 50}
+	err := service.CreateCoupon(coupon, Actor{ID: 2, Role: models.ActorSeller})
+	assert.NoError(t, err)
+	assert.Equal(t, "TANT10", coupon.Code)
+	assert.True(t, coupon.Active)
+	assert.Zero(t, coupon.UsedCount)
+	assert.Equal(t, uint(2), coupon.CreatedBy)
+
+	other := &models.Coupon{Code: "TANT20", ShopID: 20, Type: models.CouponTypePercentage, Value: 20}
+	assert.ErrorIs(t, service.CreateCoupon(other, Actor{ID: 3, Role: models.ActorSeller}), ErrCouponForbidden)
+
+	platform := &models.Coupon{Code: "EID10", Type: models.CouponTypePercentage, Value: 10}
+	assert.ErrorIs(t, service.CreateCoupon(platform, Actor{ID: 2, Role: models.ActorSeller}), ErrCouponForbidden)
+	assert.ErrorIs(t, service.CreateCoupon(platform, Actor{ID: 1, Role: models.ActorBuyer}), ErrCouponForbidden)
+}
+
+func TestPromotionService_CreateCoupon_ValidatesTerms(t *testing.T) {
+	coupons := new(repository.MockCouponRepository)
+	service := NewPromotionService(coupons, new(MockShopClient))
+	coupons.On("GetByCode", "EID10").Return(&models.Coupon{ID: 1, Code: "EID10"}, nil)
+	admin := Actor{ID: 9, Role: models.ActorAdmin}
+	starts := time.Now()
+	ends := starts.Add(-time.Hour)
+
+	for _, coupon := range []*models.Coupon{
+		{Code: "EID", Type: models.CouponTypePercentage, Value: 150},
+		{Code: "EID", Type: models.CouponTypeFixed},
+		{Code: "EID", Type: "bogo", Value: 1},
+		{Code: "E!", Type: models.CouponTypeFixed, Value: 1},
+		{Code: "EID", Type: models.CouponTypeFixed, Value: 1, StartsAt: starts, EndsAt: &ends},
+	} {
+		assert.ErrorIs(t, service.CreateCoupon(coupon, admin), ErrInvalidCoupon)
+	}
+	taken := &models.Coupon{Code: "EID10", Type: models.CouponTypeFixed, Value: 1}
+	assert.ErrorIs(t, service.CreateCoupon(taken, admin), ErrCouponCodeTaken)
+	coupons.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/models"
+	"order-service/repository"
+)
+
+// DefaultReturnWindow is how long after delivery buyers can ask for a return, unless
+// the item's category has a window of its own
+const DefaultReturnWindow = 14 * 24 * time.Hour
+
+// MaxReturnEvidence caps the photos attached to one return request
+const MaxReturnEvidence = 5
+
+var (
+	ErrReturnItemsRequired     = errors.New("a return needs at least one item")
+	ErrInvalidReturnResolution = errors.New("resolution must be refund or replace")
+	ErrInvalidReturnEvidence   = errors.New("evidence must be at most 5 http(s) photo URLs")
+	ErrNotReturnable           = errors.New("item cannot be returned")
+	ErrNotDelivered            = errors.New("only delivered items can be returned")
+	ErrReturnWindowClosed      = errors.New("the return window has closed")
+	ErrReturnQuantity          = errors.New("return quantity exceeds what was delivered and not yet returned")
+	ErrReturnMixedShops        = errors.New("items from different shops must be returned separately")
+	ErrReturnNoteRequired      = errors.New("a note is required to turn a return down")
+	ErrReturnStatus            = errors.New("return request cannot do that in its current status")
+	ErrNotReturnParty          = errors.New("return request belongs to someone else")
+)
+
+// ReturnPolicy decides how long after delivery each item can be returned
+type ReturnPolicy struct {
+	DefaultWindow   time.Duration
+	CategoryWindows map[uint]time.Duration // a zero window means the category cannot be returned
+}
+
+// DefaultReturnPolicy allows returns of anything within DefaultReturnWindow
+func DefaultReturnPolicy() ReturnPolicy {
+	return ReturnPolicy{DefaultWindow: DefaultReturnWindow}
+}
+
+// Window is the return window for items of a category
+func (p ReturnPolicy) Window(categoryID uint) time.Duration {
+	if window, ok := p.CategoryWindows[categoryID]; ok {
+		return window
+	}
+	return p.DefaultWindow
+}
+
+// ParseReturnWindows reads per-category return windows written as
+// "category_id:days,...", e.g. "3:30,7:0"
+func ParseReturnWindows(spec string) (map[uint]time.Duration, error) {
+	windows := map[uint]time.Duration{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("return window %q: want category_id:days", entry)
+		}
+		categoryID, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("return window %q: %w", entry, err)
+		}
+		days, err := strconv.Atoi(parts[1])
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("return window %q: days must be a whole number", entry)
+		}
+		windows[uint(categoryID)] = time.Duration(days) * 24 * time.Hour
+	}
+	return windows, nil
+}
+
+// ReturnItemInput is a quantity of one order line the buyer wants to send back
+type ReturnItemInput struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// ReturnInput is a buyer's return request as submitted
+type ReturnInput struct {
+	Items      []ReturnItemInput `json:"items" binding:"required,dive"`
+	Reason     string            `json:"reason" binding:"required"`
+	Resolution string            `json:"resolution"` // refund (default) or replace
+	Evidence   []string          `json:"evidence"`
+}
+
+// ReturnService runs returns of delivered goods: request, review, return shipment,
+// inspection, and then a refund through payment-service or a free replacement order
+type ReturnService struct {
+	Repo     repository.ReturnRepository
+	Orders   *OrderService
+	Payments PaymentClient
+	Checkout *CheckoutService
+	Policy   ReturnPolicy
+}
+
+func NewReturnService(repo repository.ReturnRepository, orders *OrderService, payments PaymentClient, checkout *CheckoutService) *ReturnService {
+	return &ReturnService{Repo: repo, Orders: orders, Payments: payments, Checkout: checkout, Policy: DefaultReturnPolicy()}
+}
+
+// RequestReturn opens a return for delivered items of one shop's part of the buyer's
+// order. Each item must be physical, within its category's return window, and not
+// returned already.
+func (s *ReturnService) RequestReturn(orderID uint, buyer Actor, input ReturnInput) (*models.ReturnRequest, error) {
+	if len(input.Items) == 0 {
+		return nil, ErrReturnItemsRequired
+	}
+	if input.Resolution == "" {
+		input.Resolution = models.ReturnResolutionRefund
+	}
+	if input.Resolution != models.ReturnResolutionRefund && input.Resolution != models.ReturnResolutionReplace {
+		return nil, ErrInvalidReturnResolution
+	}
+	if !validEvidence(input.Evidence) {
+		return nil, ErrInvalidReturnEvidence
+	}
+
+	order, err := s.Orders.Repo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.BuyerID != buyer.ID {
+		return nil, ErrNotOrderBuyer
+	}
+	returned, err := s.Repo.ReturnedQuantities(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &models.ReturnRequest{
+		OrderID:    order.ID,
+		BuyerID:    order.BuyerID,
+		Status:     models.ReturnStatusRequested,
+		Resolution: input.Resolution,
+		Reason:     input.Reason,
+		Evidence:   input.Evidence,
+	}
+	for i, wanted := range input.Items {
+		item := findOrderItem(order, wanted.OrderItemID)
+		if item == nil || item.IsDigital {
+			return nil, fmt.Errorf("order item %d: %w", wanted.OrderItemID, ErrNotReturnable)
+		}
+		if i == 0 {
+			request.SubOrderID = item.SubOrderID
+			request.SellerID = item.SellerID
+			if err := s.checkReturnable(order, item.SubOrderID); err != nil {
+				return nil, err
+			}
+		} else if item.SubOrderID != request.SubOrderID {
+			return nil, ErrReturnMixedShops
+		}
+		if err := s.checkWindow(order, item); err != nil {
+			return nil, err
+		}
+		returned[item.ID] += wanted.Quantity
+		if returned[item.ID] > item.Quantity {
+			return nil, fmt.Errorf("order item %d: %w", item.ID, ErrReturnQuantity)
+		}
+		request.Items = append(request.Items, models.ReturnItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Quantity:    wanted.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    roundAmount(item.Discount * float64(wanted.Quantity) / float64(item.Quantity)),
+		})
+	}
+
+	if err := s.Repo.Create(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// checkReturnable makes sure the part of the order holding the items was delivered
+func (s *ReturnService) checkReturnable(order *models.Order, subOrderID uint) error {
+	status := order.Status
+	if subOrderID != 0 {
+		sub := findSubOrder(order, subOrderID)
+		if sub == nil {
+			return ErrNotReturnable
+		}
+		status = sub.Status
+	}
+	if status != models.OrderStatusDelivered {
+		return ErrNotDelivered
+	}
+	return nil
+}
+
+// checkWindow makes sure an item is still within its category's return window
+func (s *ReturnService) checkWindow(order *models.Order, item *models.OrderItem) error {
+	window := s.Policy.Window(item.CategoryID)
+	if window <= 0 {
+		return fmt.Errorf("order item %d: %w", item.ID, ErrNotReturnable)
+	}
+	if time.Since(deliveredAt(order, item.SubOrderID)) > window {
+		return fmt.Errorf("order item %d: %w", item.ID, ErrReturnWindowClosed)
+	}
+	return nil
+}
+
+// ListReturns returns the return requests the actor deals with: a buyer's own, those
+// sent to a seller, both for someone who is both, or all of them for admins
+func (s *ReturnService) ListReturns(actor Actor) ([]models.ReturnRequest, error) {
+	if actor.HasRole(models.ActorAdmin) {
+		return s.Repo.ListAll()
+	}
+	var requests []models.ReturnRequest
+	listed := map[uint]bool{}
+	if actor.HasRole(models.ActorSeller) {
+		sent, err := s.Repo.ListBySeller(actor.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, request := range sent {
+			listed[request.ID] = true
+		}
+		requests = append(requests, sent...)
+	}
+	if actor.HasRole(models.ActorBuyer) || !actor.HasRole(models.ActorSeller) {
+		own, err := s.Repo.ListByBuyer(actor.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, request := range own {
+			if !listed[request.ID] {
+				requests = append(requests, request)
+			}
+		}
+	}
+	return requests, nil
+}
+
+// GetReturn fetches a return request for its buyer, its seller or an admin
+func (s *ReturnService) GetReturn(id uint, actor Actor) (*models.ReturnRequest, error) {
+	request, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isReturnBuyer(request, actor) && !isReturnHandler(request, actor) {
+		return nil, ErrNotReturnParty
+	}
+	return request, nil
+}
+
+// ReviewReturn lets the seller or an admin approve a requested return, or turn it
+// down with a note for the buyer
+func (s *ReturnService) ReviewReturn(id uint, actor Actor, approve bool, note string) (*models.ReturnRequest, error) {
+	request, err := s.handledReturn(id, actor, models.ReturnStatusRequested)
+	if err != nil {
+		return nil, err
+	}
+	status := models.ReturnStatusApproved
+	if !approve {
+		if note == "" {
+			return nil, ErrReturnNoteRequired
+		}
+		status = models.ReturnStatusRejected
+	}
+	request.ReviewNote = note
+	return request, s.move(request, status)
+}
+
+// ShipReturn records that the buyer sent an approved return back
+func (s *ReturnService) ShipReturn(id uint, buyer Actor, trackingCode string) (*models.ReturnRequest, error) {
+	request, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isReturnBuyer(request, buyer) {
+		return nil, ErrNotReturnParty
+	}
+	if request.Status != models.ReturnStatusApproved {
+		return nil, ErrReturnStatus
+	}
+	request.TrackingCode = trackingCode
+	return request, s.move(request, models.ReturnStatusInTransit)
+}
+
+// InspectReturn records what the seller or an admin found in the returned parcel.
+// Goods that pass are refunded or replaced straight away; inspecting an accepted
+// return again retries a refund or replacement that failed.
+func (s *ReturnService) InspectReturn(id uint, actor Actor, passed bool, note string) (*models.ReturnRequest, error) {
+	request, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isReturnHandler(request, actor) {
+		return nil, ErrNotReturnParty
+	}
+	switch request.Status {
+	case models.ReturnStatusAccepted:
+	case models.ReturnStatusInTransit:
+		request.InspectionNote = note
+		if !passed {
+			return request, s.move(request, models.ReturnStatusInspectionFailed)
+		}
+		if err := s.move(request, models.ReturnStatusAccepted); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrReturnStatus
+	}
+	return request, s.resolve(request)
+}
+
+// resolve puts the goods of an accepted return back in stock, refunds or replaces them,
+// then marks the order's part returned once everything in it has come back
+func (s *ReturnService) resolve(request *models.ReturnRequest) error {
+	order, err := s.Orders.Repo.GetByID(request.OrderID)
+	if err != nil {
+		return err
+	}
+	allBack, err := s.allReturned(order, request.SubOrderID)
+	if err != nil {
+		return err
+	}
+	restock := make([]PriceRequestItem, len(request.Items))
+	for i, item := range request.Items {
+		restock[i] = PriceRequestItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	if err := s.Orders.Products.RestockReturn(request.OrderID, returnKey(request), restock); err != nil {
+		return err
+	}
+
+	status := models.ReturnStatusRefunded
+	if request.Resolution == models.ReturnResolutionReplace {
+		replaced, err := s.replace(order, request)
+		if err != nil {
+			return err
+		}
+		if replaced {
+			status = models.ReturnStatusReplaced
+		}
+	}
+	if status == models.ReturnStatusRefunded {
+		request.RefundAmount = refundAmount(order, request, allBack)
+		if request.RefundAmount > 0 {
+			err := s.Payments.RefundPayment(order.ID, request.RefundAmount,
+				returnKey(request), fmt.Sprintf("return %d", request.ID))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.move(request, status); err != nil {
+		return err
+	}
+	if !allBack {
+		return nil
+	}
+	return s.markReturned(order, request.SubOrderID)
+}
+
+// refundAmount is what a return gives back: the items' price less their discount, the
+// tax charged on that and, when the return brings back the last of what the shop sent,
+// the shipping paid for it
+func refundAmount(order *models.Order, request *models.ReturnRequest, allBack bool) float64 {
+	net := 0.0
+	for _, item := range request.Items {
+		net += item.UnitPrice*float64(item.Quantity) - item.Discount
+	}
+	// Tax and shipping were charged on the order, or on the shop's sub-order when split
+	subtotal, discount, tax := order.Subtotal, order.Discount, order.Tax
+	shipping := order.ShippingFee - order.ShippingDiscount
+	if sub := findSubOrder(order, request.SubOrderID); sub != nil {
+		subtotal, discount, tax = sub.Subtotal, sub.Discount, sub.Tax
+		shipping = sub.ShippingFee - sub.ShippingDiscount
+	}
+	amount := net
+	if taxable := subtotal - discount; taxable > 0 {
+		amount += tax * net / taxable
+	}
+	if allBack && shipping > 0 {
+		amount += shipping
+	}
+	return roundAmount(amount)
+}
+
+// replace sends the returned goods again in a free order of their own. It reports
+// false, so the buyer is refunded instead, when the replacement could not be checked
+// out, e.g. because the goods have sold out.
+func (s *ReturnService) replace(order *models.Order, request *models.ReturnRequest) (bool, error) {
+	if request.ReplacementOrderID != 0 {
+		replacement, err := s.Orders.Repo.GetByID(request.ReplacementOrderID)
+		if err != nil {
+			return false, err
+		}
+		if replacement.Checkout == nil {
+			if err := s.Checkout.Start(replacement); err != nil {
+				return false, err
+			}
+		}
+		return replacement.Checkout.Status != models.SagaStatusFailed, nil
+	}
+
+	replacement := &models.Order{
+		BuyerID:        order.BuyerID,
+		ShippingMethod: order.ShippingMethod,
+		PaymentMethod:  order.PaymentMethod,
+		Status:         models.OrderStatusPending,
+	}
+	for _, returned := range request.Items {
+		line := findOrderItem(order, returned.OrderItemID)
+		if line == nil {
+			return false, fmt.Errorf("order item %d: %w", returned.OrderItemID, ErrNotReturnable)
+		}
+		item := models.OrderItem{
+			SellerID:    line.SellerID,
+			ShopID:      line.ShopID,
+			CategoryID:  line.CategoryID,
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+			Quantity:    returned.Quantity,
+		}
+		for _, component := range line.Components {
+			item.Components = append(item.Components, models.OrderItemComponent{
+				ProductID:   component.ProductID,
+				ProductName: component.ProductName,
+				Quantity:    component.Quantity * returned.Quantity / line.Quantity,
+			})
+		}
+		replacement.OrderItems = append(replacement.OrderItems, item)
+	}
+	replacement.SubOrders = splitOrder(replacement)
+	replacement.ShopID = replacement.SubOrders[0].ShopID
+	replacement.History = []models.OrderStatusTransition{{
+		ToStatus:  models.OrderStatusPending,
+		ActorRole: models.ActorSystem,
+		Reason:    fmt.Sprintf("replacement for return %d", request.ID),
+	}}
+	replacement.Checkout = newCheckoutSaga()
+	if err := s.Orders.Repo.Create(replacement); err != nil {
+		return false, err
+	}
+	// Remember the replacement before checking it out so a retry never sends it twice
+	request.ReplacementOrderID = replacement.ID
+	if err := s.Repo.Update(request, request.Status); err != nil {
+		return false, err
+	}
+	if err := s.Checkout.Start(replacement); err != nil {
+		return false, err
+	}
+	return replacement.Checkout.Status != models.SagaStatusFailed, nil
+}
+
+// allReturned reports whether every physical item of an order, or of one of its
+// sub-orders, is covered by a return that was not turned down
+func (s *ReturnService) allReturned(order *models.Order, subOrderID uint) (bool, error) {
+	returned, err := s.Repo.ReturnedQuantities(order.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range order.OrderItems {
+		if item.SubOrderID == subOrderID && !item.IsDigital && returned[item.ID] < item.Quantity {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// markReturned moves a delivered order, or one of its sub-orders, to returned once
+// every physical item in it has come back
+func (s *ReturnService) markReturned(order *models.Order, subOrderID uint) error {
+	reason := "all items returned"
+	var err error
+	if subOrderID == 0 {
+		err = s.Orders.transition(order, models.OrderStatusReturned, SystemActor, reason)
+	} else if sub := findSubOrder(order, subOrderID); sub != nil {
+		err = s.Orders.transitionSubOrder(order, sub, models.OrderStatusReturned, SystemActor, reason)
+		if err == nil {
+			err = s.Orders.rollUp(order)
+		}
+	}
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		return nil
+	}
+	return err
+}
+
+// handledReturn loads a return request for its seller or an admin, checking it is in
+// the expected status
+func (s *ReturnService) handledReturn(id uint, actor Actor, status string) (*models.ReturnRequest, error) {
+	request, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isReturnHandler(request, actor) {
+		return nil, ErrNotReturnParty
+	}
+	if request.Status != status {
+		return nil, ErrReturnStatus
+	}
+	return request, nil
+}
+
+// move saves a return request in its new status
+func (s *ReturnService) move(request *models.ReturnRequest, status string) error {
+	from := request.Status
+	request.Status = status
+	if err := s.Repo.Update(request, from); err != nil {
+		request.Status = from
+		return err
+	}
+	return nil
+}
+
+// returnKey names a return towards product-service and payment-service, so retried
+// restocks and refunds are applied once
+func returnKey(request *models.ReturnRequest) string {
+	return fmt.Sprintf("rma-%d", request.ID)
+}
+
+func isReturnBuyer(request *models.ReturnRequest, actor Actor) bool {
+	return actor.HasRole(models.ActorBuyer) && request.BuyerID == actor.ID
+}
+
+// isReturnHandler reports whether actor reviews and inspects the return
+func isReturnHandler(request *models.ReturnRequest, actor Actor) bool {
+	return actor.HasRole(models.ActorAdmin) || (actor.HasRole(models.ActorSeller) && request.SellerID == actor.ID)
+}
+
+// validEvidence accepts up to MaxReturnEvidence absolute http(s) URLs
+func validEvidence(urls []string) bool {
+	if len(urls) > MaxReturnEvidence {
+		return false
+	}
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// deliveredAt is when an order, or one of its sub-orders, was last delivered
+func deliveredAt(order *models.Order, subOrderID uint) time.Time {
+	delivered := order.UpdatedAt
+	for _, entry := range order.History {
+		if entry.ToStatus == models.OrderStatusDelivered && entry.SubOrderID == subOrderID {
+			delivered = entry.CreatedAt
+		}
+	}
+	return delivered
+}
+
+func findOrderItem(order *models.Order, id uint) *models.OrderItem {
+	for i := range order.OrderItems {
+		if order.OrderItems[i].ID == id {
+			return &order.OrderItems[i]
+		}
+	}
+	return nil
+}
+
+func findSubOrder(order *models.Order, id uint) *models.SubOrder {
+	for i := range order.SubOrders {
+		if order.SubOrders[i].ID == id {
+			return &order.SubOrders[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// deliveredOrder is a split order whose first shop delivered deliveredAgo
+func deliveredOrder(deliveredAgo time.Duration) *models.Order {
+	order := splitOrderFixture()
+	order.OrderItems[0].ID = 100
+	order.OrderItems[0].SubOrderID = 71
+	order.OrderItems[0].Quantity = 2
+	order.OrderItems[0].UnitPrice = 12.5
+	order.OrderItems[0].CategoryID = 4
+	order.OrderItems[1].ID = 101
+	order.OrderItems[1].SubOrderID = 72
+	order.SubOrders[0].Status = models.OrderStatusDelivered
+	order.History = []models.OrderStatusTransition{{
+		SubOrderID: 71, ToStatus: models.OrderStatusDelivered, CreatedAt: time.Now().Add(-deliveredAgo),
+	}}
+	return order
+}
+
+func TestReturnService_RequestReturn_OpensRequestForDeliveredItems(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	orders.On("GetByID", uint(7)).Return(deliveredOrder(48*time.Hour), nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{}, nil)
+	returns.On("Create", mock.AnythingOfType("*models.ReturnRequest")).Return(nil)
+
+	request, err := service.RequestReturn(7, Actor{ID: 1, Role: models.ActorBuyer}, ReturnInput{
+		Items:    []ReturnItemInput{{OrderItemID: 100, Quantity: 1}},
+		Reason:   "arrived damaged",
+		Evidence: []string{"https://cdn.example.com/damage.jpg"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnStatusRequested, request.Status)
+	assert.Equal(t, models.ReturnResolutionRefund, request.Resolution)
+	assert.Equal(t, uint(71), request.SubOrderID)
+	assert.Equal(t, uint(2), request.SellerID)
+	assert.Equal(t, 12.5, request.Items[0].UnitPrice)
+}
+
+func TestReturnService_RequestReturn_TakesShareOfLineDiscount(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	order := deliveredOrder(48 * time.Hour)
+	order.OrderItems[0].Discount = 3
+	orders.On("GetByID", uint(7)).Return(order, nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{}, nil)
+	returns.On("Create", mock.AnythingOfType("*models.ReturnRequest")).Return(nil)
+
+	request, err := service.RequestReturn(7, Actor{ID: 1, Role: models.ActorBuyer}, ReturnInput{
+		Items: []ReturnItemInput{{OrderItemID: 100, Quantity: 1}}, Reason: "arrived damaged",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, request.Items[0].Discount)
+}
+
+func TestReturnService_RequestReturn_EnforcesCategoryWindow(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	service.Policy.CategoryWindows = map[uint]time.Duration{4: 24 * time.Hour}
+	orders.On("GetByID", uint(7)).Return(deliveredOrder(48*time.Hour), nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{}, nil)
+
+	_, err := service.RequestReturn(7, Actor{ID: 1, Role: models.ActorBuyer}, ReturnInput{
+		Items: []ReturnItemInput{{OrderItemID: 100, Quantity: 1}}, Reason: "changed my mind",
+	})
+
+	assert.ErrorIs(t, err, ErrReturnWindowClosed)
+	returns.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReturnService_RequestReturn_CapsQuantityByEarlierReturns(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	orders.On("GetByID", uint(7)).Return(deliveredOrder(time.Hour), nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{100: 1}, nil)
+
+	_, err := service.RequestReturn(7, Actor{ID: 1, Role: models.ActorBuyer}, ReturnInput{
+		Items: []ReturnItemInput{{OrderItemID: 100, Quantity: 2}}, Reason: "too small",
+	})
+
+	assert.ErrorIs(t, err, ErrReturnQuantity)
+}
+
+func TestReturnService_RequestReturn_RefusesUndeliveredItems(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	orders.On("GetByID", uint(7)).Return(deliveredOrder(time.Hour), nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{}, nil)
+
+	_, err := service.RequestReturn(7, Actor{ID: 1, Role: models.ActorBuyer}, ReturnInput{
+		Items: []ReturnItemInput{{OrderItemID: 101, Quantity: 1}}, Reason: "not wanted",
+	})
+
+	assert.ErrorIs(t, err, ErrNotDelivered)
+}
+
+func TestReturnService_ReviewReturn_RejectionNeedsNote(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(new(repository.MockOrderRepository), products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	returns.On("GetByID", uint(5)).Return(&models.ReturnRequest{ID: 5, SellerID: 2, Status: models.ReturnStatusRequested}, nil)
+
+	_, err := service.ReviewReturn(5, Actor{ID: 2, Role: models.ActorSeller}, false, "")
+
+	assert.ErrorIs(t, err, ErrReturnNoteRequired)
+	returns.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReturnService_ReviewReturn_OtherSellerCannotSeeIt(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(new(repository.MockOrderRepository), products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	returns.On("GetByID", uint(5)).Return(&models.ReturnRequest{ID: 5, SellerID: 2, Status: models.ReturnStatusRequested}, nil)
+
+	_, err := service.ReviewReturn(5, Actor{ID: 3, Role: models.ActorSeller}, true, "")
+
+	assert.ErrorIs(t, err, ErrNotReturnParty)
+}
+
+func TestReturnService_InspectReturn_RefundsAndMarksSubOrderReturned(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	order := deliveredOrder(time.Hour)
+	order.SubOrders[0].Subtotal = 25
+	order.SubOrders[0].Tax = 2
+	order.SubOrders[0].ShippingFee = 5
+	request := &models.ReturnRequest{ID: 5, OrderID: 7, SubOrderID: 71, SellerID: 2, Status: models.ReturnStatusInTransit,
+		Resolution: models.ReturnResolutionRefund,
+		Items:      []models.ReturnItem{{OrderItemID: 100, ProductID: 10, Quantity: 2, UnitPrice: 12.5}}}
+
+	returns.On("GetByID", uint(5)).Return(request, nil)
+	returns.On("Update", request, models.ReturnStatusInTransit).Return(nil)
+	returns.On("Update", request, models.ReturnStatusAccepted).Return(nil)
+	orders.On("GetByID", uint(7)).Return(order, nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{100: 2}, nil)
+	products.On("RestockReturn", uint(7), "rma-5", []PriceRequestItem{{ProductID: 10, Quantity: 2}}).Return(nil)
+	// Everything the shop sent came back, so its tax and shipping are refunded too
+	payments.On("RefundPayment", uint(7), 32.0, "rma-5", "return 5").Return(nil)
+	orders.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 71 && entry.ToStatus == models.OrderStatusReturned
+	})).Return(nil)
+
+	inspected, err := service.InspectReturn(5, Actor{ID: 2, Role: models.ActorSeller}, true, "as described")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnStatusRefunded, inspected.Status)
+	assert.Equal(t, 32.0, inspected.RefundAmount)
+	assert.Equal(t, models.OrderStatusReturned, order.SubOrders[0].Status)
+	orders.AssertNumberOfCalls(t, "Transition", 1)
+	products.AssertExpectations(t)
+}
+
+func TestReturnService_InspectReturn_RefundsLessCouponDiscountWithTaxShare(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	order := deliveredOrder(time.Hour)
+	order.SubOrders[0].Subtotal = 25
+	order.SubOrders[0].Discount = 3
+	order.SubOrders[0].Tax = 1.76
+	order.SubOrders[0].ShippingFee = 5
+	request := &models.ReturnRequest{ID: 5, OrderID: 7, SubOrderID: 71, SellerID: 2, Status: models.ReturnStatusInTransit,
+		Resolution: models.ReturnResolutionRefund,
+		Items:      []models.ReturnItem{{OrderItemID: 100, ProductID: 10, Quantity: 1, UnitPrice: 12.5, Discount: 1.5}}}
+
+	returns.On("GetByID", uint(5)).Return(request, nil)
+	returns.On("Update", request, mock.Anything).Return(nil)
+	orders.On("GetByID", uint(7)).Return(order, nil)
+	products.On("RestockReturn", uint(7), "rma-5", []PriceRequestItem{{ProductID: 10, Quantity: 1}}).Return(nil)
+	// Half the shop's goods are still with the buyer, so shipping is kept
+	payments.On("RefundPayment", uint(7), 11.88, "rma-5", "return 5").Return(nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{100: 1}, nil)
+
+	inspected, err := service.InspectReturn(5, Actor{ID: 2, Role: models.ActorSeller}, true, "as described")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 11.88, inspected.RefundAmount)
+	payments.AssertExpectations(t)
+}
+
+func TestReturnService_InspectReturn_RestockFailureRefundsNothingYet(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	orders := new(repository.MockOrderRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	request := &models.ReturnRequest{ID: 5, OrderID: 7, SubOrderID: 71, SellerID: 2, Status: models.ReturnStatusAccepted,
+		Resolution: models.ReturnResolutionRefund,
+		Items:      []models.ReturnItem{{OrderItemID: 100, ProductID: 10, Quantity: 2, UnitPrice: 12.5}}}
+
+	returns.On("GetByID", uint(5)).Return(request, nil)
+	orders.On("GetByID", uint(7)).Return(deliveredOrder(time.Hour), nil)
+	returns.On("ReturnedQuantities", uint(7)).Return(map[uint]int{100: 2}, nil)
+	products.On("RestockReturn", uint(7), "rma-5", mock.Anything).Return(&ProductServiceError{StatusCode: 503})
+
+	_, err := service.InspectReturn(5, Actor{ID: 2, Role: models.ActorSeller}, true, "")
+
+	assert.Error(t, err)
+	assert.Equal(t, models.ReturnStatusAccepted, request.Status)
+	payments.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReturnService_InspectReturn_FailedInspectionRefundsNothing(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	payments := new(MockPaymentClient)
+	products := new(MockProductClient)
+	checkout := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(new(repository.MockOrderRepository), products), products, payments, new(MockShipmentClient))
+	service := NewReturnService(returns, checkout.Orders, payments, checkout)
+	request := &models.ReturnRequest{ID: 5, OrderID: 7, SellerID: 2, Status: models.ReturnStatusInTransit}
+	returns.On("GetByID", uint(5)).Return(request, nil)
+	returns.On("Update", request, models.ReturnStatusInTransit).Return(nil)
+
+	inspected, err := service.InspectReturn(5, Actor{ID: 2, Role: models.ActorSeller}, false, "item was used")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnStatusInspectionFailed, inspected.Status)
+	payments.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParseReturnWindows(t *testing.T) {
+	windows, err := ParseReturnWindows("3:30, 7:0")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, windows[3])
+	assert.Equal(t, time.Duration(0), windows[7])
+
+	_, err = ParseReturnWindows("3")
+	assert.Error(t, err)
+}
+
+func TestReturnService_ListReturns_CoversEveryRoleHeld(t *testing.T) {
+	returns := new(repository.MockReturnRepository)
+	service := &ReturnService{Repo: returns}
+	returns.On("ListBySeller", uint(2)).Return([]models.ReturnRequest{{ID: 5, SellerID: 2}}, nil)
+	returns.On("ListByBuyer", uint(2)).Return([]models.ReturnRequest{{ID: 6, BuyerID: 2}}, nil)
+
+	requests, err := service.ListReturns(Actor{ID: 2, Role: models.ActorSeller, Roles: []string{models.ActorSeller, models.ActorBuyer}})
+
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	returns.AssertNotCalled(t, "ListAll")
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"order-service/repository"
+)
+
+// Job is background work run every Interval by whichever instance of the service holds
+// its lock
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// lease is how long a run holds its job's lock. It is a little shorter than the
+// interval so the next tick, on this instance or another, finds the lock free.
+func (j Job) lease() time.Duration {
+	return j.Interval - j.Interval/10
+}
+
+// Scheduler runs jobs on a timer. Every instance of the service runs a scheduler; a lock
+// in the database makes sure only one of them runs each round of a job.
+type Scheduler struct {
+	Locks  repository.JobLockRepository
+	Jobs   []Job
+	Holder string // names this instance in the locks it takes
+}
+
+func NewScheduler(locks repository.JobLockRepository, jobs ...Job) *Scheduler {
+	return &Scheduler{Locks: locks, Jobs: jobs, Holder: schedulerHolder()}
+}
+
+// Run runs every job straight away and then every interval until ctx ends
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.Jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+			for {
+				if _, err := s.runJob(ctx, job); err != nil {
+					log.Printf("⚠️ Job %s failed: %v", job.Name, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(job)
+	}
+	wg.Wait()
+}
+
+// runJob runs one round of a job if this instance gets its lock. It reports whether the
+// job ran; a run is cut short once its lease is up.
+func (s *Scheduler) runJob(ctx context.Context, job Job) (bool, error) {
+	now := time.Now()
+	acquired, err := s.Locks.Acquire(job.Name, s.Holder, now, now.Add(job.lease()))
+	if err != nil || !acquired {
+		return false, err
+	}
+	runCtx, cancel := context.WithTimeout(ctx, job.lease())
+	defer cancel()
+	runErr := job.Run(runCtx)
+	lastErr := ""
+	if runErr != nil {
+		lastErr = runErr.Error()
+	}
+	if err := s.Locks.Finish(job.Name, s.Holder, lastErr); err != nil {
+		log.Printf("⚠️ Recording run of job %s: %v", job.Name, err)
+	}
+	return true, runErr
+}
+
+// schedulerHolder names this process uniquely among the service's instances
+func schedulerHolder() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
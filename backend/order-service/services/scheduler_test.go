@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"order-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduler_RunJob_SkipsWhileAnotherInstanceHoldsTheLock(t *testing.T) {
+	locks := new(repository.MockJobLockRepository)
+	ran := false
+	job := Job{Name: "cancel-unpaid-orders", Interval: time.Minute, Run: func(context.Context) error {
+		ran = true
+		return nil
+	}}
+	scheduler := NewScheduler(locks, job)
+
+	locks.On("Acquire", "cancel-unpaid-orders", scheduler.Holder, mock.Anything, mock.Anything).Return(false, nil)
+
+	didRun, err := scheduler.runJob(context.Background(), job)
+
+	assert.NoError(t, err)
+	assert.False(t, didRun)
+	assert.False(t, ran)
+	locks.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestScheduler_RunJob_LeasesLessThanAnIntervalAndRecordsFailure(t *testing.T) {
+	locks := new(repository.MockJobLockRepository)
+	job := Job{Name: "complete-delivered-orders", Interval: time.Hour, Run: func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(54*time.Minute), deadline, time.Minute)
+		return errors.New("order-service database unavailable")
+	}}
+	scheduler := NewScheduler(locks, job)
+
+	locks.On("Acquire", "complete-delivered-orders", scheduler.Holder, mock.Anything, mock.MatchedBy(func(until time.Time) bool {
+		return until.Before(time.Now().Add(time.Hour))
+	})).Return(true, nil)
+	locks.On("Finish", "complete-delivered-orders", scheduler.Holder, "order-service database unavailable").Return(nil)
+
+	didRun, err := scheduler.runJob(context.Background(), job)
+
+	assert.True(t, didRun)
+	assert.EqualError(t, err, "order-service database unavailable")
+	locks.AssertExpectations(t)
+}
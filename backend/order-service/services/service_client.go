@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ServiceError is a non-200 response from another service's internal API
+type ServiceError struct {
+	Service    string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("%s %s failed (%d): %s", e.Service, e.Path, e.StatusCode, e.Message)
+}
+
+// postJSON sends a JSON request to another service's internal API and decodes a JSON response
+func postJSON(client *http.Client, service, baseURL, apiKey, path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return sendJSON(client, service, http.MethodPost, baseURL, apiKey, path, bytes.NewBuffer(body), out)
+}
+
+// getJSON fetches a JSON resource from another service's internal API
+func getJSON(client *http.Client, service, baseURL, apiKey, path string, out interface{}) error {
+	return sendJSON(client, service, http.MethodGet, baseURL, apiKey, path, nil, out)
+}
+
+// sendJSON makes a request to another service's internal API and decodes a JSON response
+func sendJSON(client *http.Client, service, method, baseURL, apiKey, path string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequest(method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		message := string(respBody)
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		return &ServiceError{Service: service, Path: path, StatusCode: resp.StatusCode, Message: message}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"order-service/config"
+)
+
+// ShipmentClient is the subset of shipment-service that order-service depends on
+type ShipmentClient interface {
+	RequestShipment(orderID, sellerID, buyerID uint) (uint, error)
+	ListOrderShipments(orderID uint) ([]ShipmentSummary, error)
+}
+
+// ShipmentSummary is what order details show of one shipment of an order
+type ShipmentSummary struct {
+	ID           uint      `json:"id"`
+	SellerID     uint      `json:"seller_id"`
+	Status       string    `json:"status"`
+	TrackingCode string    `json:"tracking_code,omitempty"`
+	Address      string    `json:"address,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type httpShipmentClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewShipmentClient creates a ShipmentClient that talks to shipment-service over HTTP
+func NewShipmentClient() ShipmentClient {
+	return &httpShipmentClient{
+		baseURL: config.GetShipmentServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// RequestShipment starts the shipment of a seller's part of an order and returns its id.
+// Each seller of an order only ever gets one shipment, however often this is called.
+func (c *httpShipmentClient) RequestShipment(orderID, sellerID, buyerID uint) (uint, error) {
+	var result struct {
+		ID uint `json:"id"`
+	}
+	payload := map[string]interface{}{"order_id": orderID, "seller_id": sellerID, "buyer_id": buyerID}
+	if err := postJSON(c.client, "shipment-service", c.baseURL, c.apiKey, "/internal/shipments/", payload, &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+// ListOrderShipments fetches every shipment of an order
+func (c *httpShipmentClient) ListOrderShipments(orderID uint) ([]ShipmentSummary, error) {
+	var result struct {
+		Shipments []ShipmentSummary `json:"shipments"`
+	}
+	path := fmt.Sprintf("/internal/shipments/order/%d", orderID)
+	if err := getJSON(c.client, "shipment-service", c.baseURL, c.apiKey, path, &result); err != nil {
+		return nil, err
+	}
+	return result.Shipments, nil
+}
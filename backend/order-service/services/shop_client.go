@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"order-service/config"
+)
+
+// ShopClient is the subset of shop-service that order-service depends on
+type ShopClient interface {
+	GetShop(shopID uint) (*ShopProfile, error)
+}
+
+// ShopProfile is what invoices print about the shop that sold the goods, and who owns it
+type ShopProfile struct {
+	ID        uint   `json:"id"`
+	OwnerID   uint   `json:"owner_id"`
+	Name      string `json:"name"`
+	LegalName string `json:"legal_name"`
+	Address   string `json:"address"`
+	Phone     string `json:"phone"`
+	BIN       string `json:"bin"` // VAT Business Identification Number
+}
+
+type httpShopClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewShopClient creates a ShopClient that talks to shop-service over HTTP
+func NewShopClient() ShopClient {
+	return &httpShopClient{
+		baseURL: config.GetShopServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetShop fetches a shop's business details
+func (c *httpShopClient) GetShop(shopID uint) (*ShopProfile, error) {
+	var shop ShopProfile
+	path := fmt.Sprintf("/internal/shops/%d", shopID)
+	if err := getJSON(c.client, "shop-service", c.baseURL, c.apiKey, path, &shop); err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+
+	"order-service/models"
+)
+
+var ErrNotOrderSeller = errors.New("order belongs to another seller")
+
+// statusRank orders the lifecycle so a parent order can report how far its slowest
+// sub-order has got
+var statusRank = map[string]int{
+	models.OrderStatusPending:        0,
+	models.OrderStatusConfirmed:      1,
+	models.OrderStatusProcessing:     2,
+	models.OrderStatusShipped:        3,
+	models.OrderStatusOutForDelivery: 4,
+	models.OrderStatusDelivered:      5,
+	models.OrderStatusCompleted:      6,
+	models.OrderStatusReturned:       7,
+}
+
+// splitOrder groups an order's priced items into one sub-order per seller and shop,
+// in the order the shops first appear, and shares the order's fees between them
+func splitOrder(order *models.Order) []models.SubOrder {
+	var subOrders []models.SubOrder
+	index := map[[2]uint]int{}
+	shipping := make([]float64, 0)
+	for _, item := range order.OrderItems {
+		key := [2]uint{item.SellerID, item.ShopID}
+		i, ok := index[key]
+		if !ok {
+			i = len(subOrders)
+			index[key] = i
+			subOrders = append(subOrders, models.SubOrder{
+				BuyerID:  order.BuyerID,
+				SellerID: item.SellerID,
+				ShopID:   item.ShopID,
+				Status:   order.Status,
+			})
+			shipping = append(shipping, 0)
+		}
+		subOrders[i].Subtotal += item.Subtotal
+		subOrders[i].Discount += item.Discount
+		if !item.IsDigital {
+			shipping[i] += item.Subtotal
+		}
+	}
+
+	// Tax and the cash-on-delivery fee were charged on the discounted subtotal
+	netSubtotals := make([]float64, len(subOrders))
+	for i := range subOrders {
+		subOrders[i].Subtotal = roundAmount(subOrders[i].Subtotal)
+		subOrders[i].Discount = roundAmount(subOrders[i].Discount)
+		netSubtotals[i] = subOrders[i].Subtotal - subOrders[i].Discount
+	}
+	// Only shops sending goods share the shipping fee
+	shippingFees := allocate(order.ShippingFee, shipping)
+	taxes := allocate(order.Tax, netSubtotals)
+	codFees := allocate(order.CODFee, netSubtotals)
+	for i := range subOrders {
+		sub := &subOrders[i]
+		sub.ShippingFee = shippingFees[i]
+		sub.Tax = taxes[i]
+		sub.CODFee = codFees[i]
+		sub.TotalAmount = subOrderTotal(sub)
+	}
+	return subOrders
+}
+
+// subOrderTotal is what a sub-order's share of the payment comes to
+func subOrderTotal(sub *models.SubOrder) float64 {
+	return roundAmount(sub.Subtotal - sub.Discount + sub.ShippingFee - sub.ShippingDiscount + sub.Tax + sub.CODFee)
+}
+
+// allocate shares amount in proportion to weights, rounded to cents. The last weighted
+// share takes the rounding difference so the shares always add up to amount.
+func allocate(amount float64, weights []float64) []float64 {
+	shares := make([]float64, len(weights))
+	total := 0.0
+	last := -1
+	for i, weight := range weights {
+		total += weight
+		if weight > 0 {
+			last = i
+		}
+	}
+	if last < 0 || amount == 0 {
+		return shares
+	}
+	remaining := amount
+	for i, weight := range weights {
+		if i == last {
+			shares[i] = roundAmount(remaining)
+			break
+		}
+		shares[i] = roundAmount(amount * weight / total)
+		remaining -= shares[i]
+	}
+	return shares
+}
+
+// rolledUpStatus is the status a parent order shows for its sub-orders: that of the
+// least advanced one still going, or cancelled once every one is cancelled
+func rolledUpStatus(subOrders []models.SubOrder) string {
+	status := models.OrderStatusCancelled
+	for _, sub := range subOrders {
+		if sub.Status == models.OrderStatusCancelled {
+			continue
+		}
+		if status == models.OrderStatusCancelled || statusRank[sub.Status] < statusRank[status] {
+			status = sub.Status
+		}
+	}
+	return status
+}
+
+// TransitionSubOrder moves one shop's part of an order to a new status. Sellers can
+// only act on their own sub-orders and buyers on their own orders. The parent order's
+// status follows its sub-orders. Cancelling is left to the CancellationService.
+func (s *OrderService) TransitionSubOrder(subOrderID uint, to string, actor Actor, reason string) (*models.SubOrder, error) {
+	if to == models.OrderStatusCancelled {
+		return nil, ErrCancelThroughCancellation
+	}
+	sub, err := s.Repo.GetSubOrder(subOrderID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.Repo.GetByID(sub.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range order.SubOrders {
+		if order.SubOrders[i].ID != sub.ID {
+			continue
+		}
+		if err := s.transitionSubOrder(order, &order.SubOrders[i], to, actor, reason); err != nil {
+			return nil, err
+		}
+		sub.Status = order.SubOrders[i].Status
+		if err := s.rollUp(order); err != nil {
+			return nil, err
+		}
+		return sub, nil
+	}
+	return nil, ErrNotOrderSeller
+}
+
+// transitionSubOrders applies a status change asked of a split order to every sub-order
+// the actor may move there, then updates the parent. It fails only if none could move.
+func (s *OrderService) transitionSubOrders(order *models.Order, to string, actor Actor, reason string) error {
+	moved, owned := 0, 0
+	var refusal error
+	for i := range order.SubOrders {
+		sub := &order.SubOrders[i]
+		if actor.Role == models.ActorSeller && !actor.OwnsSubOrder(sub) {
+			continue
+		}
+		owned++
+		if sub.Status == to {
+			continue
+		}
+		err := s.transitionSubOrder(order, sub, to, actor, reason)
+		var transitionErr *TransitionError
+		if errors.As(err, &transitionErr) {
+			if refusal == nil {
+				refusal = err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		moved++
+	}
+	if moved == 0 {
+		if refusal != nil {
+			return refusal
+		}
+		if owned == 0 {
+			return ErrNotOrderSeller
+		}
+		return &TransitionError{From: order.Status, To: to, Role: actor.Role, Err: ErrIllegalTransition}
+	}
+	return s.rollUp(order)
+}
+
+// transitionSubOrder applies a status change to one sub-order of a loaded order
+func (s *OrderService) transitionSubOrder(order *models.Order, sub *models.SubOrder, to string, actor Actor, reason string) error {
+	actor, err := Authorize(actor, ActionUpdateOrder, order)
+	if err != nil {
+		return err
+	}
+	if actor.Role == models.ActorSeller && !actor.OwnsSubOrder(sub) {
+		return ErrNotOrderSeller
+	}
+	if err := CanTransition(sub.Status, to, actor.Role); err != nil {
+		return err
+	}
+	entry := &models.OrderStatusTransition{
+		OrderID:    order.ID,
+		SubOrderID: sub.ID,
+		FromStatus: sub.Status,
+		ToStatus:   to,
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Reason:     reason,
+	}
+	if err := s.Repo.Transition(entry); err != nil {
+		return err
+	}
+	sub.Status = to
+	order.History = append(order.History, *entry)
+	return nil
+}
+
+// deliverDigitalSubOrders completes the confirmed sub-orders of a paid order that only
+// hold digital items, which were delivered along with the payment
+func (s *OrderService) deliverDigitalSubOrders(order *models.Order) error {
+	delivered := false
+	for i := range order.SubOrders {
+		sub := &order.SubOrders[i]
+		if sub.Status != models.OrderStatusConfirmed || !onlyDigital(order, sub) {
+			continue
+		}
+		if err := s.transitionSubOrder(order, sub, models.OrderStatusDelivered, SystemActor, "digital items delivered"); err != nil {
+			return err
+		}
+		delivered = true
+	}
+	if !delivered {
+		return nil
+	}
+	return s.rollUp(order)
+}
+
+// onlyDigital reports whether every item of a sub-order is digital
+func onlyDigital(order *models.Order, sub *models.SubOrder) bool {
+	found := false
+	for _, item := range order.OrderItems {
+		if item.SellerID != sub.SellerID || item.ShopID != sub.ShopID {
+			continue
+		}
+		if !item.IsDigital {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// rollUp brings a split order's own status in line with its sub-orders
+func (s *OrderService) rollUp(order *models.Order) error {
+	status := rolledUpStatus(order.SubOrders)
+	if status == order.Status {
+		return nil
+	}
+	entry := &models.OrderStatusTransition{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   status,
+		ActorRole:  models.ActorSystem,
+		Reason:     "sub-orders updated",
+	}
+	if err := s.Repo.Transition(entry); err != nil {
+		return err
+	}
+	order.Status = status
+	order.History = append(order.History, *entry)
+	return nil
+}
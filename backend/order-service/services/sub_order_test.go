@@ -0,0 +1,145 @@
+package services
+
+import (
+	"order-service/models"
+	"order-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func splitOrderFixture() *models.Order {
+	return &models.Order{ID: 7, BuyerID: 1, Status: models.OrderStatusConfirmed,
+		OrderItems: []models.OrderItem{
+			{ProductID: 10, SellerID: 2, ShopID: 20, Quantity: 1},
+			{ProductID: 11, SellerID: 3, ShopID: 30, Quantity: 1},
+		},
+		SubOrders: []models.SubOrder{
+			{ID: 71, OrderID: 7, SellerID: 2, ShopID: 20, Status: models.OrderStatusConfirmed},
+			{ID: 72, OrderID: 7, SellerID: 3, ShopID: 30, Status: models.OrderStatusConfirmed},
+		},
+	}
+}
+
+func TestOrderService_PlaceOrder_SplitsOrderByShop(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	service := NewOrderService(repo, products)
+
+	order := &models.Order{BuyerID: 1, PaymentMethod: PaymentMethodCOD, OrderItems: []models.OrderItem{
+		{ProductID: 10, Quantity: 1},
+		{ProductID: 11, Quantity: 2},
+		{ProductID: 12, Quantity: 1},
+	}}
+	products.On("ClaimPrices", uint(1), mock.Anything, mock.Anything).Return([]PricedItem{
+		{ProductID: 10, SellerID: 2, ShopID: 20, Quantity: 1, UnitPrice: 20},
+		{ProductID: 11, SellerID: 3, ShopID: 30, Quantity: 2, UnitPrice: 7.5},
+		{ProductID: 12, SellerID: 2, ShopID: 20, Quantity: 1, UnitPrice: 10, IsDigital: true},
+	}, nil)
+	repo.On("Create", order).Return(nil)
+	products.On("RecordOrder", order.ID, mock.Anything).Return(nil)
+
+	err := service.PlaceOrder(order, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, order.SubOrders, 2)
+	assert.Zero(t, order.ShopID)
+	shop20, shop30 := order.SubOrders[0], order.SubOrders[1]
+	assert.Equal(t, uint(20), shop20.ShopID)
+	assert.Equal(t, 30.0, shop20.Subtotal)
+	assert.Equal(t, 15.0, shop30.Subtotal)
+	assert.Equal(t, models.OrderStatusPending, shop30.Status)
+	// Every fee is shared out in full
+	assert.InDelta(t, order.ShippingFee, shop20.ShippingFee+shop30.ShippingFee, 0.001)
+	assert.InDelta(t, order.Tax, shop20.Tax+shop30.Tax, 0.001)
+	assert.InDelta(t, order.CODFee, shop20.CODFee+shop30.CODFee, 0.001)
+	assert.InDelta(t, order.TotalAmount, shop20.TotalAmount+shop30.TotalAmount, 0.001)
+}
+
+func TestAllocate_SharesByWeightAndKeepsTotal(t *testing.T) {
+	assert.Equal(t, []float64{3.33, 0, 6.67}, allocate(10, []float64{1, 0, 2}))
+	assert.Equal(t, []float64{0, 0}, allocate(5, []float64{0, 0}))
+}
+
+func TestRolledUpStatus(t *testing.T) {
+	assert.Equal(t, models.OrderStatusProcessing, rolledUpStatus([]models.SubOrder{
+		{Status: models.OrderStatusShipped}, {Status: models.OrderStatusProcessing}, {Status: models.OrderStatusCancelled},
+	}))
+	assert.Equal(t, models.OrderStatusCancelled, rolledUpStatus([]models.SubOrder{
+		{Status: models.OrderStatusCancelled}, {Status: models.OrderStatusCancelled},
+	}))
+}
+
+func TestOrderService_TransitionSubOrder_SellerMovesOwnPart(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	order := splitOrderFixture()
+	repo.On("GetSubOrder", uint(71)).Return(&models.SubOrder{ID: 71, OrderID: 7, SellerID: 2}, nil)
+	repo.On("GetByID", uint(7)).Return(order, nil)
+	repo.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 71 && entry.ToStatus == models.OrderStatusProcessing
+	})).Return(nil)
+
+	sub, err := service.TransitionSubOrder(71, models.OrderStatusProcessing, Actor{ID: 2, Role: models.ActorSeller}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusProcessing, sub.Status)
+	// The other shop has not started, so the order as a whole is still confirmed
+	assert.Equal(t, models.OrderStatusConfirmed, order.Status)
+	repo.AssertNumberOfCalls(t, "Transition", 1)
+}
+
+func TestOrderService_TransitionSubOrder_RejectsOtherSeller(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	repo.On("GetSubOrder", uint(72)).Return(&models.SubOrder{ID: 72, OrderID: 7, SellerID: 3}, nil)
+	repo.On("GetByID", uint(7)).Return(splitOrderFixture(), nil)
+
+	_, err := service.TransitionSubOrder(72, models.OrderStatusShipped, Actor{ID: 2, Role: models.ActorSeller}, "")
+
+	assert.ErrorIs(t, err, ErrNotOrderSeller)
+	repo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestOrderService_Transition_BuyerCancelsWhatHasNotShipped(t *testing.T) {
+	repo := new(repository.MockOrderRepository)
+	service := NewOrderService(repo, new(MockProductClient))
+
+	order := splitOrderFixture()
+	order.SubOrders[0].Status = models.OrderStatusShipped
+	repo.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 72 && entry.ToStatus == models.OrderStatusCancelled
+	})).Return(nil)
+	repo.On("Transition", mock.MatchedBy(func(entry *models.OrderStatusTransition) bool {
+		return entry.SubOrderID == 0 && entry.ToStatus == models.OrderStatusShipped && entry.ActorRole == models.ActorSystem
+	})).Return(nil)
+
+	err := service.transition(order, models.OrderStatusCancelled, Actor{ID: 1, Role: models.ActorBuyer}, "changed my mind")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusShipped, order.SubOrders[0].Status)
+	assert.Equal(t, models.OrderStatusCancelled, order.SubOrders[1].Status)
+	assert.Equal(t, models.OrderStatusShipped, order.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestCheckoutService_RequestsShipmentPerShop(t *testing.T) {
+	orders := new(repository.MockOrderRepository)
+	products := new(MockProductClient)
+	shipments := new(MockShipmentClient)
+	service := NewCheckoutService(new(repository.MockCheckoutSagaRepository), NewOrderService(orders, products), products, new(MockPaymentClient), shipments)
+	order := splitOrderFixture()
+	order.OrderItems[1].IsDigital = true
+
+	shipments.On("RequestShipment", uint(7), uint(2), uint(1)).Return(uint(9), nil)
+	orders.On("SetSubOrderShipment", uint(71), uint(9)).Return(nil)
+
+	err := service.step(&models.CheckoutSaga{Step: models.SagaStepRequestShipment}, order)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), order.SubOrders[0].ShipmentID)
+	shipments.AssertNotCalled(t, "RequestShipment", uint(7), uint(3), uint(1))
+}
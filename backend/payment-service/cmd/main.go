@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"gorm.io/driver/postgres"
@@ -12,6 +13,7 @@ import (
 	"payment-service/services"
 	"payment-service/controllers"
 	"payment-service/routes"
+	"shared/idempotency"
 	"payment-service/seed"
 )
 
@@ -26,7 +28,7 @@ func main() {
     }
 
     // Auto migrate Order model
-	if err := db.AutoMigrate(&models.Payment{}); err != nil {
+	if err := db.AutoMigrate(&models.Payment{}, &models.Refund{}, &idempotency.Record{}); err != nil {
         log.Fatalf("❌ Auto migration failed: %v", err)
     }
     seed.SeedPayments(db)
@@ -34,14 +36,24 @@ func main() {
 
     // Initialize repository, service, and controller
     paymentRepo := repository.NewPaymentRepository(db)
-	paymentService := services.NewPaymentService(paymentRepo)
+	paymentService := services.NewPaymentService(paymentRepo, services.NewOrderClient())
 	paymentController := controllers.NewPaymentController(paymentService)
 
+	// Retried payment creations with the same Idempotency-Key get the first response
+	idempotencyService := idempotency.NewService(idempotency.NewRepository(db), config.GetIdempotencyKeyTTL())
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := idempotencyService.PurgeExpired(); err != nil {
+				log.Printf("⚠️ Failed to purge expired idempotency keys: %v", err)
+			}
+		}
+	}()
+
     // Initialize Gin router
     router := gin.Default()
 
     // Register routes
-    routes.RegisterPaymentRoutes(router, paymentController)
+    routes.RegisterPaymentRoutes(router, paymentController, idempotency.Middleware(idempotencyService))
 
     log.Printf("Starting Order Service on port %s", cfg.Port)
 
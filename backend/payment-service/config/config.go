@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -71,7 +72,20 @@ func LoadConfig() Config {
 	}
 }
 
+// GetOrderServiceURL fetches the order service URL from env vars
+func GetOrderServiceURL() string {
+	return os.Getenv("ORDER_SERVICE_URL")
+}
 
+// GetIdempotencyKeyTTL fetches how long Idempotency-Keys are kept (e.g. "24h"); zero
+// means the default
+func GetIdempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil {
+		return 0
+	}
+	return ttl
+}
 
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
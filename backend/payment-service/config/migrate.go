@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"payment-service/models"
 	"log"
+	"shared/idempotency"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +13,8 @@ import (
 func MigrateDB(db *gorm.DB) {
 	err := db.AutoMigrate(
 		&models.Payment{},
+		&models.Refund{},
+		&idempotency.Record{},
 	)
 
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 )
 
 type PaymentController struct {
@@ -158,9 +159,129 @@ func (pc *PaymentController) CompletePayment(c *gin.Context) {
 
 	// You can also check here if the seller actually owns this payment (optional)
 	if err := pc.Service.CompletePayment(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		case errors.Is(err, services.ErrPaymentNotCompletable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Payment marked as completed"})
 }
+
+// ==========================================
+// 🧾 POST /internal/payments (order-service)
+// ==========================================
+func (pc *PaymentController) OpenOrderPayment(c *gin.Context) {
+	var payload struct {
+		OrderID  uint    `json:"order_id" binding:"required"`
+		BuyerID  uint    `json:"buyer_id" binding:"required"`
+		SellerID uint    `json:"seller_id"`
+		Amount   float64 `json:"amount" binding:"required"`
+		Method   string  `json:"method"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := pc.Service.OpenForOrder(&models.Payment{
+		OrderID:  payload.OrderID,
+		BuyerID:  payload.BuyerID,
+		SellerID: payload.SellerID,
+		Amount:   payload.Amount,
+		Method:   payload.Method,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOrderPayment) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": payment})
+}
+
+// =========================================================
+// 🔎 GET /internal/payments/order/:order_id (order-service order details)
+// =========================================================
+func (pc *PaymentController) GetOrderPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	payment, err := pc.Service.GetForOrder(uint(orderID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": payment})
+}
+
+// =========================================================
+// 🚫 POST /internal/payments/order/:order_id/void (order-service)
+// =========================================================
+func (pc *PaymentController) VoidOrderPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	payment, err := pc.Service.VoidForOrder(uint(orderID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment": payment})
+}
+
+// =========================================================
+// 💸 POST /internal/payments/order/:order_id/refund (order-service)
+// Body: {"amount", "reference", "reason"?}
+// =========================================================
+func (pc *PaymentController) RefundOrderPayment(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+	var payload struct {
+		Amount    float64 `json:"amount" binding:"required"`
+		Reference string  `json:"reference" binding:"required"`
+		Reason    string  `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := pc.Service.RefundForOrder(uint(orderID), payload.Amount, payload.Reference, payload.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRefund):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRefundExceedsPayment):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refund": refund})
+}
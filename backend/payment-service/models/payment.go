@@ -14,6 +14,7 @@ const (
 	StatusCompleted = "completed"
 	StatusFailed    = "failed"
 	StatusRefunded  = "refunded"
+	StatusVoided    = "voided" // cancelled before any money moved
 )
 // ================================
 // ENUMS: Payment Method
@@ -32,13 +33,14 @@ const (
 type Payment struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	Amount      float64        `gorm:"not null" json:"amount" validate:"required"`
-	Status      string         `gorm:"type:varchar(20);default:'pending'" json:"status" validate:"required,oneof=pending completed failed refunded"`
+	Status      string         `gorm:"type:varchar(20);default:'pending'" json:"status" validate:"required,oneof=pending completed failed refunded voided"`
 	OrderID     uint           `gorm:"not null" json:"order_id" validate:"required"`
 	BuyerID     uint           `gorm:"not null" json:"buyer_id" validate:"required"`
 	SellerID    uint           `gorm:"not null" json:"seller_id" validate:"required"`
 	Method        string       `gorm:"type:varchar(20);not null" json:"method"` // enum: bkash, cod, stripe, etc.
     TransactionID string       `gorm:"type:varchar(100);uniqueIndex" json:"transaction_id,omitempty"`
 	PaymentTime *time.Time     `json:"payment_time,omitempty"`
+	RefundedAmount float64     `gorm:"default:0" json:"refunded_amount"` // given back so far by refunds
 	Description   string       `gorm:"type:text" json:"description,omitempty"`
 
 	CreatedAt   time.Time      `json:"created_at"`
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ================================
+// Refund Model
+// ================================
+// Refund gives back part or all of a payment, e.g. for a cancelled shop's part of an
+// order or an accepted return. Reference is chosen by the caller and identifies the
+// refund, so repeating a request never refunds twice.
+type Refund struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PaymentID uint      `gorm:"not null;index" json:"payment_id"`
+	OrderID   uint      `gorm:"not null;index" json:"order_id"`
+	Reference string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"reference"`
+	Amount    float64   `gorm:"not null" json:"amount"`
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,17 +1,26 @@
 package repository
 
 import (
+	"errors"
 	"payment-service/models"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrStatusChanged means the payment's status moved on before an update could be saved
+var ErrStatusChanged = errors.New("payment status changed concurrently")
+
 type PaymentRepository interface {
 	Create(*models.Payment) error
+	GetByID(uint) (*models.Payment, error)
+	GetByOrderID(uint) (*models.Payment, error)
 	GetByBuyer(uint) ([]models.Payment, error)
 	GetBySeller(uint) ([]models.Payment, error)
-	UpdateStatus(uint, string) error
+	UpdateStatus(id uint, from, to string) error
+	GetRefundByReference(reference string) (*models.Refund, error)
+	ApplyRefund(refund *models.Refund, apply func(payment *models.Payment) error) error
 }
 
 type paymentRepo struct {
@@ -27,6 +36,24 @@ func (r *paymentRepo) Create(p *models.Payment) error {
 	return r.db.Create(p).Error
 }
 
+// GetByID fetches a single payment
+func (r *paymentRepo) GetByID(id uint) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.db.First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByOrderID fetches the most recent payment for an order
+func (r *paymentRepo) GetByOrderID(orderID uint) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.db.Where("order_id = ?", orderID).Order("id DESC").First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
 // GetByBuyer returns payments made by a buyer
 func (r *paymentRepo) GetByBuyer(buyerID uint) ([]models.Payment, error) {
 	var payments []models.Payment
@@ -41,15 +68,56 @@ func (r *paymentRepo) GetBySeller(sellerID uint) ([]models.Payment, error) {
 	return payments, err
 }
 
-// UpdateStatus changes the status of a payment
-func (r *paymentRepo) UpdateStatus(id uint, status string) error {
+// UpdateStatus moves a payment from one status to another. It fails with
+// ErrStatusChanged if the payment is no longer in the from status.
+func (r *paymentRepo) UpdateStatus(id uint, from, to string) error {
 	updateFields := map[string]interface{}{
-		"status": status,
+		"status": to,
 	}
-	if status == models.StatusCompleted {
+	if to == models.StatusCompleted {
 		updateFields["payment_time"] = time.Now()
 	}
-	return r.db.Model(&models.Payment{}).
-		Where("id = ?", id).
-		Updates(updateFields).Error
+	result := r.db.Model(&models.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updateFields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+// GetRefundByReference fetches the refund made under a caller's reference
+func (r *paymentRepo) GetRefundByReference(reference string) (*models.Refund, error) {
+	var refund models.Refund
+	if err := r.db.Where("reference = ?", reference).First(&refund).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// ApplyRefund records a refund against its payment in one transaction. The payment row
+// is locked and read again first; apply checks the refund against that copy and sets the
+// new amount, refunded amount and status, so concurrent refunds each see what the
+// others left. An error from apply leaves everything unchanged.
+func (r *paymentRepo) ApplyRefund(refund *models.Refund, apply func(payment *models.Payment) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, refund.PaymentID).Error; err != nil {
+			return err
+		}
+		if err := apply(&payment); err != nil {
+			return err
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Payment{}).Where("id = ?", payment.ID).Updates(map[string]interface{}{
+			"amount":          payment.Amount,
+			"refunded_amount": payment.RefundedAmount,
+			"status":          payment.Status,
+		}).Error
+	})
 }
@@ -7,12 +7,12 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-func RegisterPaymentRoutes(router *gin.Engine, paymentController *controllers.PaymentController) {
+func RegisterPaymentRoutes(router *gin.Engine, paymentController *controllers.PaymentController, idempotent gin.HandlerFunc) {
     payment := router.Group("/api/payments")
     payment.Use(middleware.RequireAuth())
     {
         // Buyer endpoints
-        payment.POST("/",paymentController.CreatePayment)                   // POST: Create a new payment (buyer)
+        payment.POST("/",idempotent,paymentController.CreatePayment)        // POST: Create a new payment (buyer, Idempotency-Key aware)
         payment.GET("/buyer",paymentController.GetPaymentsByBuyer)          // GET: List payments made by buyer
         // Seller endpoints
         payment.GET("/seller",paymentController.GetPaymentsBySeller)        // GET: List payments received by seller
@@ -21,4 +21,14 @@ func RegisterPaymentRoutes(router *gin.Engine, paymentController *controllers.Pa
 
     }
 
+    // Internal service-to-service routes
+    internal := router.Group("/internal/payments")
+    internal.Use(middleware.RequireAPIKey())
+    {
+        internal.POST("/", paymentController.OpenOrderPayment)                         // Open an order's payment (order-service checkout)
+        internal.GET("/order/:order_id", paymentController.GetOrderPayment)            // An order's payment (order-service order details)
+        internal.POST("/order/:order_id/void", paymentController.VoidOrderPayment)     // Void or refund it (order-service compensation)
+        internal.POST("/order/:order_id/refund", paymentController.RefundOrderPayment) // Give back part of it (order-service cancellations and returns)
+    }
+
 }
@@ -0,0 +1,12 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockOrderClient struct {
+	mock.Mock
+}
+
+func (m *MockOrderClient) MarkPaid(orderID uint) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"payment-service/config"
+)
+
+// OrderClient is the subset of order-service that payment-service depends on
+type OrderClient interface {
+	MarkPaid(orderID uint) error
+}
+
+type httpOrderClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOrderClient creates an OrderClient that talks to order-service over HTTP
+func NewOrderClient() OrderClient {
+	return &httpOrderClient{
+		baseURL: config.GetOrderServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// MarkPaid tells order-service that an order's payment has completed
+func (c *httpOrderClient) MarkPaid(orderID uint) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/internal/orders/%d/paid", c.baseURL, orderID), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		message := string(body)
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		return fmt.Errorf("order-service returned %d: %s", resp.StatusCode, message)
+	}
+	return nil
+}
@@ -2,8 +2,22 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"payment-service/models"
 	"payment-service/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentNotCompletable = errors.New("only pending payments can be completed")
+	ErrInvalidOrderPayment   = errors.New("order payment needs an order, a buyer and a positive amount")
+	ErrInvalidRefund         = errors.New("refund needs a reference and a positive amount")
+	ErrRefundExceedsPayment  = errors.New("refund is more than is left of the payment")
+
+	// errNothingToRefund stops a refund of a payment that has no money to give back
+	errNothingToRefund = errors.New("payment has nothing to refund")
 )
 
 type PaymentService interface {
@@ -11,14 +25,19 @@ type PaymentService interface {
 	GetByBuyer(buyerID uint) ([]models.Payment, error)
 	GetBySeller(sellerID uint) ([]models.Payment, error)
 	CompletePayment(paymentID uint) error
+	OpenForOrder(payment *models.Payment) (*models.Payment, error)
+	VoidForOrder(orderID uint) (*models.Payment, error)
+	RefundForOrder(orderID uint, amount float64, reference, reason string) (*models.Refund, error)
+	GetForOrder(orderID uint) (*models.Payment, error)
 }
 
 type paymentService struct {
-	repo repository.PaymentRepository
+	repo   repository.PaymentRepository
+	orders OrderClient
 }
 
-func NewPaymentService(r repository.PaymentRepository) PaymentService {
-	return &paymentService{repo: r}
+func NewPaymentService(r repository.PaymentRepository, orders OrderClient) PaymentService {
+	return &paymentService{repo: r, orders: orders}
 }
 
 // Create initializes a new payment with status "pending"
@@ -40,7 +59,160 @@ func (s *paymentService) GetBySeller(sellerID uint) ([]models.Payment, error) {
 	return s.repo.GetBySeller(sellerID)
 }
 
-// CompletePayment marks a payment as completed and sets payment_time
+// CompletePayment marks a payment as completed, sets payment_time and tells order-service
+// the order is paid, which delivers its digital items. Completing an already completed
+// payment only repeats the notification, so a failed notification can be retried. A
+// payment voided meanwhile, e.g. by a checkout being undone, stays voided.
 func (s *paymentService) CompletePayment(paymentID uint) error {
-	return s.repo.UpdateStatus(paymentID, models.StatusCompleted)
+	payment, err := s.repo.GetByID(paymentID)
+	if err != nil {
+		return err
+	}
+	switch payment.Status {
+	case models.StatusPending:
+		err := s.repo.UpdateStatus(paymentID, models.StatusPending, models.StatusCompleted)
+		if errors.Is(err, repository.ErrStatusChanged) {
+			// A payment never returns to pending, so this runs at most once more
+			return s.CompletePayment(paymentID)
+		}
+		if err != nil {
+			return err
+		}
+	case models.StatusCompleted:
+	default:
+		return ErrPaymentNotCompletable
+	}
+
+	if err := s.orders.MarkPaid(payment.OrderID); err != nil {
+		return fmt.Errorf("payment completed but order %d was not updated: %w", payment.OrderID, err)
+	}
+	return nil
+}
+
+// OpenForOrder creates the pending payment for an order placed through checkout. An
+// order that already has a payment gets that one back, so retries never charge twice.
+func (s *paymentService) OpenForOrder(p *models.Payment) (*models.Payment, error) {
+	if p.OrderID == 0 || p.BuyerID == 0 || p.Amount <= 0 {
+		return nil, ErrInvalidOrderPayment
+	}
+	existing, err := s.repo.GetByOrderID(p.OrderID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	p.Status = models.StatusPending
+	// The order is the payment's reference until a gateway assigns one; being unique it
+	// also stops concurrent retries from opening a second payment
+	p.TransactionID = fmt.Sprintf("order-%d", p.OrderID)
+	if err := s.repo.Create(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetForOrder returns an order's payment
+func (s *paymentService) GetForOrder(orderID uint) (*models.Payment, error) {
+	return s.repo.GetByOrderID(orderID)
+}
+
+// VoidForOrder undoes an order's payment when its checkout is abandoned: a pending
+// payment is voided and a completed one refunded. Anything else is left alone, so
+// repeating the call is harmless. An order without a payment has nothing to undo.
+func (s *paymentService) VoidForOrder(orderID uint) (*models.Payment, error) {
+	payment, err := s.repo.GetByOrderID(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := ""
+	switch payment.Status {
+	case models.StatusPending:
+		status = models.StatusVoided
+	case models.StatusCompleted:
+		status = models.StatusRefunded
+	default:
+		return payment, nil
+	}
+	err = s.repo.UpdateStatus(payment.ID, payment.Status, status)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		// Completed meanwhile, so it is refunded instead, or already undone
+		return s.VoidForOrder(orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	payment.Status = status
+	return payment, nil
+}
+
+// RefundForOrder gives back part of an order's payment. A completed payment is refunded,
+// becoming refunded once nothing is left; a pending one is reduced so the buyer is
+// charged less, and voided once nothing is left. A reference that was already used
+// returns its refund again. Orders without money to give back, whose payment is
+// missing, voided or failed, have nothing to refund.
+func (s *paymentService) RefundForOrder(orderID uint, amount float64, reference, reason string) (*models.Refund, error) {
+	if reference == "" || amount <= 0 {
+		return nil, ErrInvalidRefund
+	}
+	existing, err := s.repo.GetRefundByReference(reference)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	payment, err := s.repo.GetByOrderID(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	amount = math.Round(amount*100) / 100
+	refund := &models.Refund{PaymentID: payment.ID, OrderID: orderID, Reference: reference, Amount: amount, Reason: reason}
+	err = s.repo.ApplyRefund(refund, func(locked *models.Payment) error {
+		return refundPayment(locked, amount)
+	})
+	if errors.Is(err, errNothingToRefund) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// refundPayment takes amount off a payment as it stands in the database, checking it
+// against what is left
+func refundPayment(payment *models.Payment, amount float64) error {
+	switch payment.Status {
+	case models.StatusPending:
+		if amount > payment.Amount+0.005 {
+			return ErrRefundExceedsPayment
+		}
+		payment.Amount = math.Round((payment.Amount-amount)*100) / 100
+		if payment.Amount <= 0 {
+			payment.Amount = 0
+			payment.Status = models.StatusVoided
+		}
+	case models.StatusCompleted:
+		left := payment.Amount - payment.RefundedAmount
+		if amount > left+0.005 {
+			return ErrRefundExceedsPayment
+		}
+		payment.RefundedAmount = math.Round((payment.RefundedAmount+amount)*100) / 100
+		if payment.RefundedAmount >= payment.Amount {
+			payment.Status = models.StatusRefunded
+		}
+	default:
+		return errNothingToRefund
+	}
+	return nil
 }
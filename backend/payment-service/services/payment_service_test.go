@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // Mock repository
@@ -15,10 +16,22 @@ type MockPaymentRepo struct {
 	mock.Mock
 }
 
+var _ repository.PaymentRepository = (*MockPaymentRepo)(nil)
+
 func (m *MockPaymentRepo) Create(p *models.Payment) error {
 	args := m.Called(p)
 	return args.Error(0)
 }
+func (m *MockPaymentRepo) GetByID(id uint) (*models.Payment, error) {
+	args := m.Called(id)
+	payment, _ := args.Get(0).(*models.Payment)
+	return payment, args.Error(1)
+}
+func (m *MockPaymentRepo) GetByOrderID(orderID uint) (*models.Payment, error) {
+	args := m.Called(orderID)
+	payment, _ := args.Get(0).(*models.Payment)
+	return payment, args.Error(1)
+}
 func (m *MockPaymentRepo) GetByBuyer(b uint) ([]models.Payment, error) {
 	args := m.Called(b)
 	return args.Get(0).([]models.Payment), args.Error(1)
@@ -27,14 +40,28 @@ func (m *MockPaymentRepo) GetBySeller(s uint) ([]models.Payment, error) {
 	args := m.Called(s)
 	return args.Get(0).([]models.Payment), args.Error(1)
 }
-func (m *MockPaymentRepo) UpdateStatus(id uint, s string) error {
-	args := m.Called(id, s)
+func (m *MockPaymentRepo) UpdateStatus(id uint, from, to string) error {
+	args := m.Called(id, from, to)
 	return args.Error(0)
 }
+func (m *MockPaymentRepo) GetRefundByReference(reference string) (*models.Refund, error) {
+	args := m.Called(reference)
+	refund, _ := args.Get(0).(*models.Refund)
+	return refund, args.Error(1)
+}
+func (m *MockPaymentRepo) ApplyRefund(r *models.Refund, apply func(*models.Payment) error) error {
+	args := m.Called(r)
+	if payment, ok := args.Get(0).(*models.Payment); ok {
+		if err := apply(payment); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
 
 func TestCreatePayment(t *testing.T) {
 	mockRepo := new(MockPaymentRepo)
-	svc := NewPaymentService(mockRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
 
 	p := &models.Payment{Amount: 100.0}
 	mockRepo.On("Create", p).Return(nil)
@@ -47,7 +74,7 @@ func TestCreatePayment(t *testing.T) {
 
 func TestGetByBuyer(t *testing.T) {
 	mockRepo := new(MockPaymentRepo)
-	svc := NewPaymentService(mockRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
 
 	expected := []models.Payment{{ID: 1, BuyerID: 2, Amount: 10.0}}
 	mockRepo.On("GetByBuyer", uint(2)).Return(expected, nil)
@@ -60,7 +87,7 @@ func TestGetByBuyer(t *testing.T) {
 
 func TestGetBySeller(t *testing.T) {
 	mockRepo := new(MockPaymentRepo)
-	svc := NewPaymentService(mockRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
 
 	expected := []models.Payment{{ID: 1, SellerID: 3, Amount: 15.0}}
 	mockRepo.On("GetBySeller", uint(3)).Return(expected, nil)
@@ -73,22 +100,185 @@ func TestGetBySeller(t *testing.T) {
 
 func TestCompletePayment(t *testing.T) {
 	mockRepo := new(MockPaymentRepo)
-	svc := NewPaymentService(mockRepo)
+	mockOrders := new(MockOrderClient)
+	svc := NewPaymentService(mockRepo, mockOrders)
 
-	mockRepo.On("UpdateStatus", uint(1), "completed").Return(nil)
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "pending"}, nil)
+	mockRepo.On("UpdateStatus", uint(1), "pending", "completed").Return(nil)
+	mockOrders.On("MarkPaid", uint(7)).Return(nil)
 
 	err := svc.CompletePayment(1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockOrders.AssertExpectations(t)
 }
 
 func TestCompletePayment_Error(t *testing.T) {
 	mockRepo := new(MockPaymentRepo)
-	svc := NewPaymentService(mockRepo)
+	mockOrders := new(MockOrderClient)
+	svc := NewPaymentService(mockRepo, mockOrders)
 
-	mockRepo.On("UpdateStatus", uint(1), "completed").Return(errors.New("update error"))
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "pending"}, nil)
+	mockRepo.On("UpdateStatus", uint(1), "pending", "completed").Return(errors.New("update error"))
 
 	err := svc.CompletePayment(1)
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
+	mockOrders.AssertNotCalled(t, "MarkPaid", mock.Anything)
+}
+
+func TestCompletePayment_RetriesOrderNotification(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	mockOrders := new(MockOrderClient)
+	svc := NewPaymentService(mockRepo, mockOrders)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "completed"}, nil)
+	mockOrders.On("MarkPaid", uint(7)).Return(nil)
+
+	err := svc.CompletePayment(1)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCompletePayment_RejectsRefundedPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "refunded"}, nil)
+
+	err := svc.CompletePayment(1)
+	assert.ErrorIs(t, err, ErrPaymentNotCompletable)
+}
+
+func TestCompletePayment_LeavesPaymentVoidedMeanwhile(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	mockOrders := new(MockOrderClient)
+	svc := NewPaymentService(mockRepo, mockOrders)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "pending"}, nil).Once()
+	mockRepo.On("UpdateStatus", uint(1), "pending", "completed").Return(repository.ErrStatusChanged)
+	mockRepo.On("GetByID", uint(1)).Return(&models.Payment{ID: 1, OrderID: 7, Status: "voided"}, nil).Once()
+
+	err := svc.CompletePayment(1)
+	assert.ErrorIs(t, err, ErrPaymentNotCompletable)
+	mockOrders.AssertNotCalled(t, "MarkPaid", mock.Anything)
+}
+
+func TestOpenForOrder_CreatesPendingPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetByOrderID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Payment")).Return(nil)
+
+	payment, err := svc.OpenForOrder(&models.Payment{OrderID: 7, BuyerID: 2, Amount: 53.19, Method: "card"})
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", payment.Status)
+	assert.Equal(t, "order-7", payment.TransactionID)
+}
+
+func TestOpenForOrder_ReturnsExistingPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	existing := &models.Payment{ID: 4, OrderID: 7, Status: "pending"}
+	mockRepo.On("GetByOrderID", uint(7)).Return(existing, nil)
+
+	payment, err := svc.OpenForOrder(&models.Payment{OrderID: 7, BuyerID: 2, Amount: 53.19})
+	assert.NoError(t, err)
+	assert.Equal(t, existing, payment)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestVoidForOrder(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetByOrderID", uint(7)).Return(&models.Payment{ID: 4, OrderID: 7, Status: "pending"}, nil)
+	mockRepo.On("UpdateStatus", uint(4), "pending", "voided").Return(nil)
+	mockRepo.On("GetByOrderID", uint(8)).Return(&models.Payment{ID: 5, OrderID: 8, Status: "completed"}, nil)
+	mockRepo.On("UpdateStatus", uint(5), "completed", "refunded").Return(nil)
+	mockRepo.On("GetByOrderID", uint(9)).Return(&models.Payment{ID: 6, OrderID: 9, Status: "voided"}, nil)
+
+	voided, err := svc.VoidForOrder(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "voided", voided.Status)
+
+	refunded, err := svc.VoidForOrder(8)
+	assert.NoError(t, err)
+	assert.Equal(t, "refunded", refunded.Status)
+
+	_, err = svc.VoidForOrder(9)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", uint(6), mock.Anything, mock.Anything)
+}
+
+func TestVoidForOrder_RefundsPaymentCompletedMeanwhile(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetByOrderID", uint(7)).Return(&models.Payment{ID: 4, OrderID: 7, Status: "pending"}, nil).Once()
+	mockRepo.On("UpdateStatus", uint(4), "pending", "voided").Return(repository.ErrStatusChanged)
+	mockRepo.On("GetByOrderID", uint(7)).Return(&models.Payment{ID: 4, OrderID: 7, Status: "completed"}, nil).Once()
+	mockRepo.On("UpdateStatus", uint(4), "completed", "refunded").Return(nil)
+
+	payment, err := svc.VoidForOrder(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "refunded", payment.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefundForOrder_PartlyRefundsCompletedPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetRefundByReference", "cancel-sub-3").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("GetByOrderID", uint(7)).Return(&models.Payment{ID: 4, OrderID: 7, Amount: 100, Status: "completed"}, nil)
+	// Another refund took 30 after the payment was first read
+	locked := &models.Payment{ID: 4, OrderID: 7, Amount: 100, RefundedAmount: 30, Status: "completed"}
+	mockRepo.On("ApplyRefund", mock.AnythingOfType("*models.Refund")).Return(locked, nil)
+
+	refund, err := svc.RefundForOrder(7, 40, "cancel-sub-3", "shop cancelled")
+	assert.NoError(t, err)
+	assert.Equal(t, 40.0, refund.Amount)
+	assert.Equal(t, uint(4), refund.PaymentID)
+	assert.Equal(t, 70.0, locked.RefundedAmount)
+	assert.Equal(t, "completed", locked.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefundForOrder_ReducesPendingPayment(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	mockRepo.On("GetRefundByReference", "cancel-sub-3").Return(nil, gorm.ErrRecordNotFound)
+	payment := &models.Payment{ID: 4, OrderID: 7, Amount: 40, Status: "pending"}
+	mockRepo.On("GetByOrderID", uint(7)).Return(payment, nil)
+	mockRepo.On("ApplyRefund", mock.Anything).Return(payment, nil)
+
+	_, err := svc.RefundForOrder(7, 40, "cancel-sub-3", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, payment.Amount)
+	assert.Equal(t, "voided", payment.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefundForOrder_RepeatedReferenceAndOverRefund(t *testing.T) {
+	mockRepo := new(MockPaymentRepo)
+	svc := NewPaymentService(mockRepo, new(MockOrderClient))
+
+	earlier := &models.Refund{ID: 2, Reference: "rma-5", Amount: 20}
+	mockRepo.On("GetRefundByReference", "rma-5").Return(earlier, nil)
+	mockRepo.On("GetRefundByReference", "rma-6").Return(nil, gorm.ErrRecordNotFound)
+	payment := &models.Payment{ID: 4, Amount: 100, RefundedAmount: 90, Status: "completed"}
+	mockRepo.On("GetByOrderID", uint(7)).Return(payment, nil)
+	mockRepo.On("ApplyRefund", mock.Anything).Return(payment, nil)
+
+	refund, err := svc.RefundForOrder(7, 20, "rma-5", "")
+	assert.NoError(t, err)
+	assert.Equal(t, earlier, refund)
+
+	_, err = svc.RefundForOrder(7, 20, "rma-6", "")
+	assert.ErrorIs(t, err, ErrRefundExceedsPayment)
+	assert.Equal(t, 90.0, payment.RefundedAmount)
 }
@@ -8,4 +8,5 @@ const (
 	StatusCompleted = "completed"
 	StatusFailed    = "failed"
 	StatusRefunded  = "refunded"
+	StatusVoided    = "voided"
 )
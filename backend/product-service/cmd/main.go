@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -25,20 +26,128 @@ func main() {
     }
 
     // Auto migrate Product model
-	if err := db.AutoMigrate(&models.Product{}, &models.Category{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.Product{},
+		&models.Category{},
+		&models.Review{},
+		&models.PriceSchedule{},
+		&models.SalePurchase{},
+		&models.ProductStatusLog{},
+		&models.PurchaseRecord{},
+		&models.ProductView{},
+		&models.ProductAssociation{},
+		&models.BuyerRecommendation{},
+		&models.SyncState{},
+		&models.ProductDailyStat{},
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.StockSubscription{},
+		&models.Question{},
+		&models.QuestionVote{},
+		&models.QuestionReport{},
+		&models.ProductTranslation{},
+		&models.CategoryTranslation{},
+		&models.DigitalFile{},
+		&models.LicenseKey{},
+		&models.DigitalEntitlement{},
+		&models.BundleComponent{},
+		&models.StockReservation{},
+		&models.StockReservationComponent{},
+		&models.StockReturn{},
+		&models.JobLock{},
+	); err != nil {
         log.Fatalf("❌ Auto migration failed: %v", err)
     }
 
     // Initialize repository, service, and controller
     productRepo := repository.NewProductRepository(db)
-	productService := services.NewProductService(productRepo)
-	productController := controllers.NewProductController(productService)
+	priceRepo := repository.NewPriceRepository(db)
+	bundleRepo := repository.NewBundleRepository(db)
+	pricingService := services.NewPricingService(priceRepo, productRepo, bundleRepo)
+
+	// Notifications go to a webhook when one is configured, otherwise to the log
+	stockNotifier := services.NewLogStockNotifier()
+	if url := config.GetStockAlertWebhookURL(); url != "" {
+		stockNotifier = services.NewWebhookStockNotifier(url)
+	}
+	buyerNotifier := services.NewLogBuyerNotifier()
+	if url := config.GetBuyerNotificationWebhookURL(); url != "" {
+		buyerNotifier = services.NewWebhookBuyerNotifier(url)
+	}
+	sellerNotifier := services.NewLogSellerNotifier()
+	if url := config.GetSellerNotificationWebhookURL(); url != "" {
+		sellerNotifier = services.NewWebhookSellerNotifier(url)
+	}
+	wishlistRepo := repository.NewWishlistRepository(db)
+	wishlistService := services.NewWishlistService(wishlistRepo, productRepo, pricingService, buyerNotifier)
+	wishlistController := controllers.NewWishlistController(wishlistService)
+
+	// Stock events reach the seller and any buyers waiting for a restock
+	productService := services.NewProductService(productRepo, services.NewShopClient(), services.StockNotifiers(stockNotifier, wishlistService))
+	moderationRepo := repository.NewModerationRepository(db)
+	moderationService := services.NewModerationService(moderationRepo, productRepo, services.NewKeywordFilter(cfg.BannedKeywords))
+
+	// Product events are buffered and written to daily stats in batches
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	eventRecorder := services.NewEventRecorder(analyticsRepo)
+	go eventRecorder.Run(context.Background())
+	analyticsService := services.NewAnalyticsService(analyticsRepo, productRepo)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+
+	translationRepo := repository.NewTranslationRepository(db)
+	localizationService := services.NewLocalizationService(translationRepo, productRepo)
+	localizationController := controllers.NewLocalizationController(localizationService)
+
+	// Digital goods: files on local disk, keys from a per-product pool
+	digitalRepo := repository.NewDigitalRepository(db)
+	digitalService := services.NewDigitalService(digitalRepo, productRepo, productService, services.NewLocalFileStore(cfg.DigitalFilesDir), cfg.DownloadSigningKey, cfg.DownloadLinkTTL)
+	digitalController := controllers.NewDigitalController(digitalService)
+
+	// Bundles sell their components' stock, so their events reach the same notifiers
+	bundleService := services.NewBundleService(bundleRepo, productRepo, services.StockNotifiers(stockNotifier, wishlistService))
+	bundleController := controllers.NewBundleController(bundleService)
+
+	// Checkouts hold stock until they complete or are compensated
+	stockReservationService := services.NewStockReservationService(repository.NewStockReservationRepository(db), services.StockNotifiers(stockNotifier, wishlistService))
+	stockController := controllers.NewStockController(stockReservationService)
+
+	productController := controllers.NewProductController(productService, pricingService, moderationService, eventRecorder, localizationService, bundleService)
+	moderationController := controllers.NewModerationController(moderationService, productService)
+	pricingController := controllers.NewPricingController(pricingService, eventRecorder)
+
+	reviewRepo := repository.NewReviewRepository(db)
+	orderClient := services.NewOrderClient()
+	reviewService := services.NewReviewService(reviewRepo, productRepo, orderClient)
+	reviewController := controllers.NewReviewController(reviewService)
+
+	questionRepo := repository.NewQuestionRepository(db)
+	questionService := services.NewQuestionService(questionRepo, productRepo, sellerNotifier, cfg.QuestionAnswerSLA)
+	questionController := controllers.NewQuestionController(questionService)
+
+	recommendationRepo := repository.NewRecommendationRepository(db)
+	recommendationService := services.NewRecommendationService(recommendationRepo, productRepo, orderClient)
+	recommendationController := controllers.NewRecommendationController(recommendationService, pricingService, eventRecorder)
+
+	// Background jobs run on one instance at a time
+	leases := services.NewJobLeases(repository.NewJobLockRepository(db))
+	// Recompute recommendations in the background
+	go services.RunEvery(context.Background(), cfg.RecommendationInterval, "recommendations",
+		leases.Leased("recommendations", cfg.RecommendationInterval, recommendationService.Recompute))
+	// Refresh the score behind the popularity sort
+	go services.RunEvery(context.Background(), cfg.PopularityInterval, "popularity",
+		leases.Leased("popularity", cfg.PopularityInterval, analyticsService.RefreshPopularity))
+	// Alert buyers when saved products get cheaper
+	go services.RunEvery(context.Background(), cfg.PriceDropInterval, "price drops",
+		leases.Leased("price-drops", cfg.PriceDropInterval, wishlistService.DetectPriceDrops))
+	// Remind sellers of questions left unanswered past the SLA
+	go services.RunEvery(context.Background(), cfg.QuestionCheckInterval, "question reminders",
+		leases.Leased("question-reminders", cfg.QuestionCheckInterval, questionService.RemindOverdue))
 
     // Initialize Gin router
     router := gin.Default()
 
     // Register routes
-    routes.RegisterProductRoutes(router, productController)
+    routes.RegisterProductRoutes(router, productController, reviewController, pricingController, moderationController, recommendationController, analyticsController, wishlistController, questionController, localizationController, digitalController, bundleController, stockController)
 
     log.Printf("Starting Product Service on port %s", cfg.Port)
 
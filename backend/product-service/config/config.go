@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -19,6 +21,15 @@ type Config struct {
 	APIKey    string
 	Address   string
 	DB        *gorm.DB
+	BannedKeywords []string
+	RecommendationInterval time.Duration
+	PopularityInterval     time.Duration
+	PriceDropInterval      time.Duration
+	QuestionAnswerSLA      time.Duration
+	QuestionCheckInterval  time.Duration
+	DigitalFilesDir        string
+	DownloadSigningKey     string
+	DownloadLinkTTL        time.Duration
 }
 
 // LoadConfig loads environment variables, connects to DB, and returns config
@@ -44,6 +55,16 @@ func LoadConfig() Config {
 	// Optional with default
 	port := getEnv("PORT", "8085")
 	address := getEnv("ADDRESS", ":"+port)
+	bannedKeywords := splitList(getEnv("BANNED_KEYWORDS", ""))
+	recommendationInterval := getDuration("RECOMMENDATION_INTERVAL", time.Hour)
+	popularityInterval := getDuration("POPULARITY_INTERVAL", 15*time.Minute)
+	priceDropInterval := getDuration("PRICE_DROP_INTERVAL", time.Hour)
+	questionAnswerSLA := getDuration("QUESTION_ANSWER_SLA", 24*time.Hour)
+	questionCheckInterval := getDuration("QUESTION_CHECK_INTERVAL", time.Hour)
+
+	digitalFilesDir := getEnv("DIGITAL_FILES_DIR", "./data/digital")
+	downloadSigningKey := getEnv("DOWNLOAD_SIGNING_KEY", jwtSecret)
+	downloadLinkTTL := getDuration("DOWNLOAD_LINK_TTL", 15*time.Minute)
 
 	// Construct DSN
 	dsn := fmt.Sprintf(
@@ -68,10 +89,42 @@ func LoadConfig() Config {
 		APIKey:    apiKey,
 		Address:   address,
 		DB:        db,
+		BannedKeywords: bannedKeywords,
+		RecommendationInterval: recommendationInterval,
+		PopularityInterval:     popularityInterval,
+		PriceDropInterval:      priceDropInterval,
+		QuestionAnswerSLA:      questionAnswerSLA,
+		QuestionCheckInterval:  questionCheckInterval,
+		DigitalFilesDir:        digitalFilesDir,
+		DownloadSigningKey:     downloadSigningKey,
+		DownloadLinkTTL:        downloadLinkTTL,
 	}
 }
 
+// GetOrderServiceURL fetches the order service URL from env vars
+func GetOrderServiceURL() string {
+	return os.Getenv("ORDER_SERVICE_URL")
+}
+
+// GetShopServiceURL fetches the shop service URL from env vars
+func GetShopServiceURL() string {
+	return os.Getenv("SHOP_SERVICE_URL")
+}
+
+// GetStockAlertWebhookURL fetches the optional URL that receives stock alerts
+func GetStockAlertWebhookURL() string {
+	return os.Getenv("STOCK_ALERT_WEBHOOK_URL")
+}
 
+// GetBuyerNotificationWebhookURL fetches the optional URL that receives buyer notifications
+func GetBuyerNotificationWebhookURL() string {
+	return os.Getenv("BUYER_NOTIFICATION_WEBHOOK_URL")
+}
+
+// GetSellerNotificationWebhookURL fetches the optional URL that receives seller reminders
+func GetSellerNotificationWebhookURL() string {
+	return os.Getenv("SELLER_NOTIFICATION_WEBHOOK_URL")
+}
 
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
@@ -90,3 +143,29 @@ func getEnv(key, fallback string) string {
 	}
 	return value
 }
+
+// getDuration fetches a positive duration env variable (e.g. "15m"), falling back
+// when it is unset, unreadable, zero or negative
+func getDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Printf("⚠️ Invalid %s %q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
+
+// splitList parses a comma-separated env value, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -10,14 +10,52 @@ import (
 
 // migrateDB performs auto migration for all models
 func MigrateDB(db *gorm.DB) {
+	// Products created before the lifecycle existed went live immediately
+	backfillStatus := db.Migrator().HasTable(&models.Product{}) &&
+		!db.Migrator().HasColumn(&models.Product{}, "status")
+
 	err := db.AutoMigrate(
 		&models.Product{},
 		&models.Category{},
+		&models.Review{},
+		&models.PriceSchedule{},
+		&models.SalePurchase{},
+		&models.ProductStatusLog{},
+		&models.PurchaseRecord{},
+		&models.ProductView{},
+		&models.ProductAssociation{},
+		&models.BuyerRecommendation{},
+		&models.SyncState{},
+		&models.ProductDailyStat{},
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.StockSubscription{},
+		&models.Question{},
+		&models.QuestionVote{},
+		&models.QuestionReport{},
+		&models.ProductTranslation{},
+		&models.CategoryTranslation{},
+		&models.DigitalFile{},
+		&models.LicenseKey{},
+		&models.DigitalEntitlement{},
+		&models.BundleComponent{},
+		&models.StockReservation{},
+		&models.StockReservationComponent{},
+		&models.StockReturn{},
+		&models.JobLock{},
 	)
 
 	if err != nil {
 		log.Fatalf("❌ Migration failed: %v", err)
 	}
 
+	if backfillStatus {
+		if err := db.Model(&models.Product{}).
+			Where("is_active = ?", true).
+			UpdateColumn("status", models.ProductStatusActive).Error; err != nil {
+			log.Fatalf("❌ Product status backfill failed: %v", err)
+		}
+	}
+
 	fmt.Println("✅ Database migration completed successfully!")
 }
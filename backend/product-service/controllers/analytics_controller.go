@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"product-service/services"
+)
+
+type AnalyticsController struct {
+	Service services.AnalyticsService
+}
+
+func NewAnalyticsController(service services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{Service: service}
+}
+
+// 📊 Product Analytics (Seller: own products, Admin: all)
+// Query: from, to (YYYY-MM-DD, default last 30 days), optional product_id
+func (analyticsController *AnalyticsController) ProductReport(contxt *gin.Context) {
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" && role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers and admins can view analytics"})
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to, err := parseDay(contxt.Query("to"), today)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected YYYY-MM-DD"})
+		return
+	}
+	from, err := parseDay(contxt.Query("from"), to.AddDate(0, 0, -29))
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected YYYY-MM-DD"})
+		return
+	}
+	var productID uint64
+	if raw := contxt.Query("product_id"); raw != "" {
+		if productID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product_id"})
+			return
+		}
+	}
+
+	report, err := analyticsController.Service.Report(role, userID, uint(productID), from, to)
+	switch {
+	case errors.Is(err, services.ErrInvalidDateRange):
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrNotProductOwner):
+		contxt.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	contxt.JSON(http.StatusOK, gin.H{
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"products": report,
+	})
+}
+
+// parseDay reads a YYYY-MM-DD date, falling back when empty
+func parseDay(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/models"
+	"product-service/services"
+)
+
+type BundleController struct {
+	Service services.BundleService
+}
+
+func NewBundleController(service services.BundleService) *BundleController {
+	return &BundleController{Service: service}
+}
+
+// bundleErrorStatus maps bundle service errors to HTTP status codes
+func bundleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotBundle),
+		errors.Is(err, services.ErrBundleComponentsRequired),
+		errors.Is(err, services.ErrTooManyBundleComponents),
+		errors.Is(err, services.ErrInvalidComponentQuantity),
+		errors.Is(err, services.ErrComponentNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotProductOwner), errors.Is(err, services.ErrComponentNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 🧩 Set the Products in a Bundle (Seller owner or Admin)
+// Body: {"components": [{"product_id": 1, "quantity": 2}, ...]}
+func (bundleController *BundleController) SetComponents(contxt *gin.Context) {
+	bundleID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payload struct {
+		Components []models.BundleComponent `json:"components" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	components, err := bundleController.Service.SetComponents(uint(bundleID), payload.Components, role, userID)
+	if err != nil {
+		contxt.JSON(bundleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"components": components})
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/services"
+)
+
+type DigitalController struct {
+	Service services.DigitalService
+}
+
+func NewDigitalController(service services.DigitalService) *DigitalController {
+	return &DigitalController{Service: service}
+}
+
+// digitalErrorStatus maps digital delivery errors to HTTP status codes
+func digitalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotDigitalProduct),
+		errors.Is(err, services.ErrKeysNotUsed),
+		errors.Is(err, services.ErrNoLicenseKeys):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrNotProductOwner), errors.Is(err, services.ErrInvalidDownloadLink):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrDigitalFileNotFound),
+		errors.Is(err, services.ErrEntitlementNotFound),
+		errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrDownloadLinkExpired):
+		return http.StatusGone
+	case errors.Is(err, services.ErrDownloadLimitReached):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 📎 Upload a File to a Digital Product (Seller owner or Admin)
+// Multipart form field: file
+func (digitalController *DigitalController) UploadFile(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	header, err := contxt.FormFile("file")
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+	content, err := header.Open()
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	file, err := digitalController.Service.AddFile(uint(productID), role, userID,
+		header.Filename, header.Header.Get("Content-Type"), header.Size, content)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusCreated, gin.H{"message": "File uploaded", "file": file})
+}
+
+// 📂 List a Digital Product's Files (Seller owner or Admin)
+func (digitalController *DigitalController) ListFiles(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	files, err := digitalController.Service.ListFiles(uint(productID), role, userID)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, files)
+}
+
+// ❌ Remove a File from a Digital Product (Seller owner or Admin)
+func (digitalController *DigitalController) DeleteFile(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	fileID, err := strconv.ParseUint(contxt.Param("file_id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := digitalController.Service.DeleteFile(uint(productID), uint(fileID), role, userID); err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "File removed"})
+}
+
+// 🔑 Add License Keys to a Product's Pool (Seller owner or Admin)
+func (digitalController *DigitalController) AddKeys(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		Keys []string `json:"keys" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pool, err := digitalController.Service.AddKeys(uint(productID), role, userID, payload.Keys)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Keys added", "pool": pool})
+}
+
+// 🔢 License Key Pool Summary (Seller owner or Admin)
+func (digitalController *DigitalController) KeyPool(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pool, err := digitalController.Service.KeyPool(uint(productID), role, userID)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, pool)
+}
+
+// 📚 My Digital Purchases with Keys and Files (Buyer)
+func (digitalController *DigitalController) Library(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	entitlements, err := digitalController.Service.Library(userID)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, entitlements)
+}
+
+// 🔗 Create a Signed Download Link (Buyer, own purchase)
+func (digitalController *DigitalController) CreateDownloadLink(contxt *gin.Context) {
+	entitlementID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase ID"})
+		return
+	}
+	fileID, err := strconv.ParseUint(contxt.Param("file_id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := digitalController.Service.CreateDownloadLink(uint(entitlementID), uint(fileID), userID)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, link)
+}
+
+// ⬇️ Download a File through a Signed Link (Public, the signature is the credential)
+func (digitalController *DigitalController) Download(contxt *gin.Context) {
+	entitlementID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase ID"})
+		return
+	}
+	fileID, err := strconv.ParseUint(contxt.Param("file_id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+	expires, err := strconv.ParseInt(contxt.Query("expires"), 10, 64)
+	if err != nil {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": services.ErrInvalidDownloadLink.Error()})
+		return
+	}
+
+	file, path, err := digitalController.Service.Download(uint(entitlementID), uint(fileID), expires, contxt.Query("signature"))
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.Header("Cache-Control", "private, no-store")
+	contxt.FileAttachment(path, file.FileName)
+}
+
+// 📦 Deliver the Digital Items of a Paid Order (internal, called by order-service)
+func (digitalController *DigitalController) Fulfil(contxt *gin.Context) {
+	var payload struct {
+		OrderID uint                  `json:"order_id" binding:"required"`
+		BuyerID uint                  `json:"buyer_id" binding:"required"`
+		Items   []services.FulfilItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entitlements, err := digitalController.Service.Fulfil(payload.OrderID, payload.BuyerID, payload.Items)
+	if err != nil {
+		contxt.JSON(digitalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"entitlements": entitlements})
+}
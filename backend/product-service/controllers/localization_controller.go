@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/services"
+)
+
+type LocalizationController struct {
+	Service services.LocalizationService
+}
+
+func NewLocalizationController(service services.LocalizationService) *LocalizationController {
+	return &LocalizationController{Service: service}
+}
+
+// requestLocales resolves the locale chain from Accept-Language and marks the response
+// as varying by it
+func requestLocales(contxt *gin.Context) []string {
+	locales := services.ResolveLocales(contxt.GetHeader("Accept-Language"))
+	contxt.Header("Vary", "Accept-Language")
+	contxt.Header("Content-Language", locales[0])
+	return locales
+}
+
+// localizationErrorStatus maps localization service errors to HTTP status codes
+func localizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedLocale), errors.Is(err, services.ErrInvalidTranslation):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrTranslationNotFound),
+		errors.Is(err, services.ErrCategoryNotFound),
+		errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 🗂️ List Categories in the Requested Language (Public)
+func (localizationController *LocalizationController) ListCategories(contxt *gin.Context) {
+	categories, err := localizationController.Service.ListCategories(requestLocales(contxt))
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, categories)
+}
+
+// 🌐 List a Product's Translations (Seller owner or Admin)
+func (localizationController *LocalizationController) ListProductTranslations(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	translations, err := localizationController.Service.ListProductTranslations(uint(productID), role, userID)
+	if err != nil {
+		contxt.JSON(localizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, translations)
+}
+
+// ✏️ Create or Replace a Product Translation (Seller owner or Admin)
+func (localizationController *LocalizationController) SetProductTranslation(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	translation, err := localizationController.Service.SetProductTranslation(uint(productID), role, userID, contxt.Param("locale"), payload.Name, payload.Description)
+	if err != nil {
+		contxt.JSON(localizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Translation saved", "translation": translation})
+}
+
+// ❌ Delete a Product Translation (Seller owner or Admin)
+func (localizationController *LocalizationController) DeleteProductTranslation(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := localizationController.Service.DeleteProductTranslation(uint(productID), role, userID, contxt.Param("locale")); err != nil {
+		contxt.JSON(localizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Translation deleted"})
+}
+
+// 🏷️ Create or Replace a Category Translation (Admin only)
+func (localizationController *LocalizationController) SetCategoryTranslation(contxt *gin.Context) {
+	categoryID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var payload struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only admins can translate categories"})
+		return
+	}
+
+	translation, err := localizationController.Service.SetCategoryTranslation(uint(categoryID), contxt.Param("locale"), payload.Name)
+	if err != nil {
+		contxt.JSON(localizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Translation saved", "translation": translation})
+}
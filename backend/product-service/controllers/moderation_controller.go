@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/repository"
+	"product-service/services"
+)
+
+type ModerationController struct {
+	Service  services.ModerationService
+	Products services.ProductService
+}
+
+func NewModerationController(service services.ModerationService, products services.ProductService) *ModerationController {
+	return &ModerationController{Service: service, Products: products}
+}
+
+// moderationErrorStatus maps lifecycle errors to HTTP status codes
+func moderationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidTransition),
+		errors.Is(err, repository.ErrStatusChanged):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrReasonRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCannotManage):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type statusChangeRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// 🔄 Change Product Status (Seller owner or Admin)
+func (moderationController *ModerationController) ChangeStatus(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload statusChangeRequest
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := moderationController.Service.Transition(uint(productID), payload.Status, role, userID, payload.Reason)
+	if err != nil {
+		contxt.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Product status updated", "product": product})
+}
+
+// 📜 Product Status History (Seller owner or Admin)
+func (moderationController *ModerationController) History(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" && role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers and admins can view product history"})
+		return
+	}
+	if _, err := moderationController.Products.GetByID(uint(productID), role, userID); err != nil {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Cannot access this product"})
+		return
+	}
+
+	history, err := moderationController.Service.History(uint(productID))
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, history)
+}
+
+// 🗂️ Moderation Queue (internal, used by admin-service)
+func (moderationController *ModerationController) Queue(contxt *gin.Context) {
+	flaggedOnly := contxt.Query("flagged") == "true"
+	offset, _ := strconv.Atoi(contxt.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(contxt.DefaultQuery("limit", "20"))
+
+	products, err := moderationController.Service.Queue(flaggedOnly, offset, limit)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, products)
+}
+
+// 🛡️ Admin Status Change (internal, used by admin-service)
+func (moderationController *ModerationController) AdminChangeStatus(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		statusChangeRequest
+		AdminID uint `json:"admin_id" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := moderationController.Service.Transition(uint(productID), payload.Status, "admin", payload.AdminID, payload.Reason)
+	if err != nil {
+		contxt.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Product status updated", "product": product})
+}
+
+// 🏪 Seller Shop Visibility (internal, used by shop-service)
+func (moderationController *ModerationController) SetShopVisibility(contxt *gin.Context) {
+	var payload struct {
+		ShopID  uint  `json:"shop_id" binding:"required"`
+		OwnerID uint  `json:"owner_id"`
+		Visible *bool `json:"visible" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := moderationController.Service.SetShopVisibility(payload.ShopID, payload.OwnerID, *payload.Visible); err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Shop visibility updated"})
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/models"
+	"product-service/services"
+)
+
+type PricingController struct {
+	Service   services.PricingService
+	Analytics services.AnalyticsRecorder
+}
+
+func NewPricingController(service services.PricingService, analytics services.AnalyticsRecorder) *PricingController {
+	return &PricingController{Service: service, Analytics: analytics}
+}
+
+// pricingErrorStatus maps pricing service errors to HTTP status codes
+func pricingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidSaleWindow),
+		errors.Is(err, services.ErrInvalidSalePrice),
+		errors.Is(err, services.ErrInvalidSaleLimits),
+		errors.Is(err, services.ErrInvalidQuantity),
+		errors.Is(err, services.ErrClaimKeyRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrProductInactive):
+		return http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 🏷️ Create Price Schedule (Seller owner or Admin)
+func (pricingController *PricingController) CreateSchedule(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		SalePrice     float64   `json:"sale_price" binding:"required"`
+		StartsAt      time.Time `json:"starts_at" binding:"required"`
+		EndsAt        time.Time `json:"ends_at" binding:"required"`
+		IsFlashSale   bool      `json:"is_flash_sale"`
+		QuantityCap   int       `json:"quantity_cap"`
+		PerBuyerLimit int       `json:"per_buyer_limit"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" && role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers and admins can schedule prices"})
+		return
+	}
+
+	schedule := models.PriceSchedule{
+		ProductID:     uint(productID),
+		SalePrice:     payload.SalePrice,
+		StartsAt:      payload.StartsAt,
+		EndsAt:        payload.EndsAt,
+		IsFlashSale:   payload.IsFlashSale,
+		QuantityCap:   payload.QuantityCap,
+		PerBuyerLimit: payload.PerBuyerLimit,
+	}
+	if err := pricingController.Service.CreateSchedule(&schedule, role, userID); err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	contxt.JSON(http.StatusCreated, gin.H{"message": "Price schedule created", "schedule": schedule})
+}
+
+// 📅 List Price Schedules of a Product (Public)
+func (pricingController *PricingController) ListSchedules(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	schedules, err := pricingController.Service.ListSchedules(uint(productID))
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, schedules)
+}
+
+// ❌ Delete Price Schedule (Seller owner or Admin)
+func (pricingController *PricingController) DeleteSchedule(contxt *gin.Context) {
+	scheduleID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" && role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers and admins can delete price schedules"})
+		return
+	}
+
+	if err := pricingController.Service.DeleteSchedule(uint(scheduleID), role, userID); err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Price schedule deleted"})
+}
+
+// ⚡ List Live Flash Sales (Public)
+func (pricingController *PricingController) ListFlashSales(contxt *gin.Context) {
+	limit, _ := strconv.Atoi(contxt.DefaultQuery("limit", "12"))
+
+	products, err := pricingController.Service.ListFlashSales(limit)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, product := range products {
+		pricingController.Analytics.Record(product.ID, models.EventImpression)
+	}
+	contxt.JSON(http.StatusOK, products)
+}
+
+// 🧾 Quote Prices (internal, no sale quantity reserved)
+func (pricingController *PricingController) Quote(contxt *gin.Context) {
+	var payload struct {
+		Items []services.PriceRequestItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := pricingController.Service.Quote(payload.Items)
+	if err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// 🛒 Price and Stock for Cart Items (internal, called by order-service carts)
+func (pricingController *PricingController) Availability(contxt *gin.Context) {
+	var payload struct {
+		Items []services.PriceRequestItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := pricingController.Service.Availability(payload.Items)
+	if err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// 🧾 Claim Prices (internal, called by order-service when placing an order)
+func (pricingController *PricingController) Claim(contxt *gin.Context) {
+	var payload struct {
+		BuyerID  uint                        `json:"buyer_id" binding:"required"`
+		ClaimKey string                      `json:"claim_key"` // lets the order give its sale units back
+		Items    []services.PriceRequestItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := pricingController.Service.Claim(payload.BuyerID, payload.ClaimKey, payload.Items)
+	if err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// ↩️ Release Claimed Sale Units (internal, called by order-service when an order is undone)
+func (pricingController *PricingController) ReleaseClaims(contxt *gin.Context) {
+	var payload struct {
+		ClaimKey   string `json:"claim_key" binding:"required"`
+		ProductIDs []uint `json:"product_ids"` // empty releases the whole order
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pricingController.Service.ReleaseClaims(payload.ClaimKey, payload.ProductIDs); err != nil {
+		contxt.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Sale claims released"})
+}
@@ -7,20 +7,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"product-service/models"
+	"product-service/repository"
 	"product-service/services"
 )
 
 type ProductController struct {
-	Service services.ProductService
+	Service    services.ProductService
+	Pricing    services.PricingService
+	Moderation services.ModerationService
+	Analytics  services.AnalyticsRecorder
+	Localizer  services.LocalizationService
+	Bundles    services.BundleService
 }
 
-func NewProductController(service services.ProductService) *ProductController {
-	return &ProductController{Service: service}
+func NewProductController(service services.ProductService, pricing services.PricingService, moderation services.ModerationService, analytics services.AnalyticsRecorder, localizer services.LocalizationService, bundles services.BundleService) *ProductController {
+	return &ProductController{Service: service, Pricing: pricing, Moderation: moderation, Analytics: analytics, Localizer: localizer, Bundles: bundles}
 }
 
 // 🔐 Helper to extract user info from context
 func getAuthUser(c *gin.Context) (uint, string, error) {
-	uidRaw, ok := c.Get("id")
+	uidRaw, ok := c.Get("userID")
 	if !ok {
 		return 0, "", errors.New("user ID not found in context")
 	}
@@ -41,6 +47,22 @@ func getAuthUser(c *gin.Context) (uint, string, error) {
 	return uid, role, nil
 }
 
+// productErrorStatus maps product service errors to HTTP status codes
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrShopRequired), errors.Is(err, services.ErrInvalidThreshold),
+		errors.Is(err, services.ErrInvalidProductType), errors.Is(err, services.ErrInvalidDownloadLimit),
+		errors.Is(err, services.ErrBundleTypeChange):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrShopNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrShopNotFound), errors.Is(err, services.ErrShopUnavailable):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // ✅ Create Product (Seller only)
 func (productController *ProductController) CreateProduct(contxt *gin.Context) {
 	var product models.Product
@@ -61,31 +83,128 @@ func (productController *ProductController) CreateProduct(contxt *gin.Context) {
 
 	product.SellerID = userID
 	if err := productController.Service.CreateProduct(&product, role); err != nil {
-		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		contxt.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	contxt.JSON(http.StatusCreated, gin.H{"message": "Product created", "product": product})
 }
 
+// parseListQuery reads paging, sorting and filter params shared by listing endpoints
+func parseListQuery(contxt *gin.Context) (models.ProductListQuery, error) {
+	query := models.ProductListQuery{
+		Name:      contxt.Query("name"),
+		Sort:      contxt.DefaultQuery("sort", models.ProductSortNewest),
+		Cursor:    contxt.Query("cursor"),
+		WithTotal: contxt.Query("include_total") == "true",
+	}
+
+	if raw := contxt.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, errors.New("invalid limit")
+		}
+		query.Limit = limit
+	}
+	// offset is deprecated in favour of cursor but still honoured for older clients
+	if raw := contxt.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return query, errors.New("invalid offset")
+		}
+		if query.Cursor != "" && offset > 0 {
+			return query, errors.New("use either cursor or offset, not both")
+		}
+		query.Offset = offset
+	}
+	if raw := contxt.Query("category_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return query, errors.New("invalid category_id")
+		}
+		query.CategoryID = uint(id)
+	}
+	if raw := contxt.Query("seller_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return query, errors.New("invalid seller_id")
+		}
+		query.ShopOwner = uint(id)
+	}
+	if raw := contxt.Query("shop_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return query, errors.New("invalid shop_id")
+		}
+		query.ShopID = uint(id)
+	}
+	if raw := contxt.Query("min_price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return query, errors.New("invalid min_price")
+		}
+		query.MinPrice = &price
+	}
+	if raw := contxt.Query("max_price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return query, errors.New("invalid max_price")
+		}
+		query.MaxPrice = &price
+	}
+	return query, nil
+}
+
+// present prices products, lists bundle contents and localizes them for the request's Accept-Language
+func (productController *ProductController) present(contxt *gin.Context, products []models.Product) error {
+	if err := productController.Pricing.ApplyEffectivePrices(products); err != nil {
+		return err
+	}
+	if err := productController.Bundles.AttachComponents(products); err != nil {
+		return err
+	}
+	return productController.Localizer.Localize(products, requestLocales(contxt))
+}
+
+// listProducts loads a page, prices and localizes it and writes the envelope response
+func (productController *ProductController) listProducts(contxt *gin.Context, query models.ProductListQuery) {
+	page, err := productController.Service.ListProducts(query)
+	if errors.Is(err, repository.ErrInvalidCursor) || errors.Is(err, repository.ErrInvalidSort) {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := productController.present(contxt, page.Items); err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, product := range page.Items {
+		productController.Analytics.Record(product.ID, models.EventImpression)
+	}
+	contxt.JSON(http.StatusOK, page)
+}
+
 // 🔍 Get All Products (Public)
-// GetAll supports pagination and RBAC filtering
+// GetAll supports cursor pagination, sorting, filters and RBAC scoping
 func (productController *ProductController) GetAll(contxt *gin.Context) {
-    userID, role, err := getAuthUser(contxt)
-    if err != nil {
-        userID = 0
-        role = "public"
-    }
-    // Pagination params
-	offset, _ := strconv.Atoi(contxt.DefaultQuery("offset", "0"))
-	limit, _ := strconv.Atoi(contxt.DefaultQuery("limit", "10"))
-
-	products, err := productController.Service.GetAll(role, userID, offset, limit)
+	userID, role, err := getAuthUser(contxt)
 	if err != nil {
-		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		userID = 0
+		role = "public"
+	}
+
+	query, err := parseListQuery(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	contxt.JSON(http.StatusOK, products)
+	query.Role = role
+	query.SellerID = userID
+
+	productController.listProducts(contxt, query)
 }
 
 // 🔍 Get Product by ID (Public)
@@ -110,7 +229,14 @@ func (productController *ProductController) GetByID(contxt *gin.Context) {
 		return
 	}
 
-	contxt.JSON(http.StatusOK, product)
+	priced := []models.Product{*product}
+	if err := productController.present(contxt, priced); err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	productController.Analytics.Record(id, models.EventDetailView)
+	contxt.JSON(http.StatusOK, priced[0])
 }
 
 // ✏️ Update Product (Seller only, must own product)
@@ -146,12 +272,43 @@ func (productController *ProductController) UpdateProduct(contxt *gin.Context) {
 	}
 	product.ID = id
     product.SellerID = existing.SellerID // maintain original sellerID
+	product.CreatedAt = existing.CreatedAt
+	// Moving a product to another shop re-checks ownership and shop status
+	if product.ShopID == 0 || product.ShopID == existing.ShopID {
+		product.ShopID = existing.ShopID
+	} else if err := productController.Service.AssignShop(&product, role); err != nil {
+		contxt.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	// Lifecycle, rating and popularity fields only change through their own workflows
+	product.Status = existing.Status
+	product.IsActive = existing.IsActive
+	if product.ShopID == existing.ShopID {
+		product.ShopVisible = existing.ShopVisible
+	}
+	product.Flagged = existing.Flagged
+	product.FlagReasons = existing.FlagReasons
+	product.ModerationNote = existing.ModerationNote
+	product.Rating = existing.Rating
+	product.ReviewCount = existing.ReviewCount
+	product.PopularityScore = existing.PopularityScore
+	if product.Type == "" {
+		product.Type = existing.Type
+	}
 
     if err := productController.Service.UpdateProduct(&product, role, userID); err != nil {
-		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		contxt.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	// Edits to live listings are re-screened for banned keywords
+	if rechecked, err := productController.Moderation.RecheckAfterEdit(id); err == nil {
+		product.Status = rechecked.Status
+		product.IsActive = rechecked.IsActive
+		product.Flagged = rechecked.Flagged
+		product.FlagReasons = rechecked.FlagReasons
+	}
+
 	contxt.JSON(http.StatusOK, gin.H{"message": "Product updated", "product": product})
 }
 
@@ -217,6 +374,26 @@ func (productController *ProductController) AdjustStock(contxt *gin.Context) {
 	contxt.JSON(http.StatusOK, gin.H{"message": "Stock adjusted successfully"})
 }
 
+// 📉 List Low-Stock Products (Seller: own products, Admin: all)
+func (productController *ProductController) ListLowStock(contxt *gin.Context) {
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" && role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers and admins can view low-stock products"})
+		return
+	}
+
+	products, err := productController.Service.ListLowStock(role, userID)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": products})
+}
+
 
 
 // 🔍 Search Product by ID or Name (Public)
@@ -227,30 +404,101 @@ func (productController *ProductController) SearchProduct(contxt *gin.Context) {
 		return
 	}
 
-	// Extract auth info from context
-    // 	userID := contxt.GetUint("userID")
-	shopID := contxt.GetUint("shopID")
-	role := contxt.GetString("role")
-
-    userID, exists := contxt.Get("userID")
-	if exists {
-		_ = userID // TODO: Use this if needed for logging/audit/etc.
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		userID = 0
+		role = "public"
 	}
+
 	// Try to parse query as ID first
 	if id, err := strconv.Atoi(query); err == nil {
-		product, err := productController.Service.GetByID(uint(id), role, shopID)
+		product, err := productController.Service.GetByID(uint(id), role, userID)
 		if err == nil {
-			contxt.JSON(http.StatusOK, []models.Product{*product})
+			products := []models.Product{*product}
+			if err := productController.present(contxt, products); err != nil {
+				contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			contxt.JSON(http.StatusOK, models.ProductPage{Items: products})
 			return
 		}
 	}
 
 	// If not found by ID or not numeric, search by name
-	products, err := productController.Service.SearchByName(query)
-	if err != nil || len(products) == 0 {
-		contxt.JSON(http.StatusNotFound, gin.H{"error": "No product found matching query"})
+	listQuery, err := parseListQuery(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	listQuery.Role = "public"
+	listQuery.Name = query
 
-	contxt.JSON(http.StatusOK, products)
+	productController.listProducts(contxt, listQuery)
+}
+
+// 🏪 List a Shop's Products (Public)
+func (productController *ProductController) ListShopProducts(contxt *gin.Context) {
+	shopID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shop ID"})
+		return
+	}
+
+	query, err := parseListQuery(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Role = "public"
+	query.ShopID = uint(shopID)
+
+	productController.listProducts(contxt, query)
+}
+
+// 📈 Track a Client-side Product Event (Public)
+// Impressions, detail views and orders are recorded server-side; clients only report cart adds.
+func (productController *ProductController) TrackEvent(contxt *gin.Context) {
+	id, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		Type string `json:"type" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload.Type != models.EventAddToCart {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported event type"})
+		return
+	}
+
+	productController.Analytics.Record(uint(id), payload.Type)
+	contxt.JSON(http.StatusAccepted, gin.H{"message": "Event recorded"})
+}
+
+// 🧾 Record a Placed Order (internal, called by order-service once the order is saved)
+func (productController *ProductController) RecordOrder(contxt *gin.Context) {
+	var payload struct {
+		OrderID    uint   `json:"order_id" binding:"required"`
+		ProductIDs []uint `json:"product_ids" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// An order counts once per product, however many lines it has for it
+	seen := make(map[uint]bool, len(payload.ProductIDs))
+	for _, productID := range payload.ProductIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+		productController.Analytics.Record(productID, models.EventOrder)
+	}
+	contxt.JSON(http.StatusAccepted, gin.H{"message": "Order recorded"})
 }
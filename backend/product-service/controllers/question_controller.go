@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/repository"
+	"product-service/services"
+)
+
+type QuestionController struct {
+	Service services.QuestionService
+}
+
+func NewQuestionController(service services.QuestionService) *QuestionController {
+	return &QuestionController{Service: service}
+}
+
+// questionErrorStatus maps question service errors to HTTP status codes
+func questionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidQuestion),
+		errors.Is(err, services.ErrInvalidAnswer),
+		errors.Is(err, services.ErrInvalidReportReason),
+		errors.Is(err, services.ErrInvalidQuestionStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrQuestionNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyVoted), errors.Is(err, services.ErrNotVoted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ❓ Ask a Question about a Product (Buyer only)
+func (questionController *QuestionController) Ask(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "buyer" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only buyers can ask questions"})
+		return
+	}
+
+	question, err := questionController.Service.Ask(uint(productID), userID, payload.Body)
+	if err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusCreated, gin.H{"message": "Question posted", "question": question})
+}
+
+// 📝 List Questions for a Product (Public)
+// Query: sort=top|newest, answered=true|false, offset, limit
+func (questionController *QuestionController) List(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	filter := repository.QuestionFilter{Sort: contxt.DefaultQuery("sort", repository.QuestionSortTop)}
+	if filter.Sort != repository.QuestionSortTop && filter.Sort != repository.QuestionSortNewest {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort, expected top or newest"})
+		return
+	}
+	if raw := contxt.Query("answered"); raw != "" {
+		answered, err := strconv.ParseBool(raw)
+		if err != nil {
+			contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid answered"})
+			return
+		}
+		filter.Answered = &answered
+	}
+	filter.Offset, _ = strconv.Atoi(contxt.DefaultQuery("offset", "0"))
+	filter.Limit, _ = strconv.Atoi(contxt.DefaultQuery("limit", "10"))
+
+	questions, err := questionController.Service.List(uint(productID), filter)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, questions)
+}
+
+// 💬 Answer a Question (Seller only, must own product)
+func (questionController *QuestionController) Answer(contxt *gin.Context) {
+	questionID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	var payload struct {
+		Answer string `json:"answer" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers can answer questions"})
+		return
+	}
+
+	question, err := questionController.Service.Answer(uint(questionID), userID, payload.Answer)
+	if err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Answer saved", "question": question})
+}
+
+// 👍 Upvote a Question (Authenticated)
+func (questionController *QuestionController) Upvote(contxt *gin.Context) {
+	questionID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := questionController.Service.Upvote(uint(questionID), userID); err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Question upvoted"})
+}
+
+// 👎 Remove Upvote (Authenticated)
+func (questionController *QuestionController) RemoveUpvote(contxt *gin.Context) {
+	questionID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := questionController.Service.RemoveUpvote(uint(questionID), userID); err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Upvote removed"})
+}
+
+// 🚩 Report a Question (Authenticated)
+func (questionController *QuestionController) Report(contxt *gin.Context) {
+	questionID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	var payload struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := questionController.Service.Report(uint(questionID), userID, payload.Reason); err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Report received"})
+}
+
+// 🛡️ Moderate a Question (Admin only)
+func (questionController *QuestionController) Moderate(contxt *gin.Context) {
+	questionID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only admins can moderate questions"})
+		return
+	}
+
+	question, err := questionController.Service.Moderate(uint(questionID), payload.Status, payload.Note)
+	if err != nil {
+		contxt.JSON(questionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Question moderated", "question": question})
+}
+
+// 📥 List Unanswered Questions on My Products (Seller only)
+func (questionController *QuestionController) ListUnanswered(contxt *gin.Context) {
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers can list unanswered questions"})
+		return
+	}
+	offset, _ := strconv.Atoi(contxt.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(contxt.DefaultQuery("limit", "20"))
+
+	questions, err := questionController.Service.ListUnanswered(userID, offset, limit)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, questions)
+}
+
+// 🗂️ List Reported Questions (Admin only)
+func (questionController *QuestionController) ListReported(contxt *gin.Context) {
+	_, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only admins can review reported questions"})
+		return
+	}
+	offset, _ := strconv.Atoi(contxt.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(contxt.DefaultQuery("limit", "20"))
+
+	questions, err := questionController.Service.ListReported(offset, limit)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, questions)
+}
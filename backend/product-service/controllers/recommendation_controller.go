@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/models"
+	"product-service/services"
+)
+
+// Recommendation list size bounds
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+type RecommendationController struct {
+	Service   services.RecommendationService
+	Pricing   services.PricingService
+	Analytics services.AnalyticsRecorder
+}
+
+func NewRecommendationController(service services.RecommendationService, pricing services.PricingService, analytics services.AnalyticsRecorder) *RecommendationController {
+	return &RecommendationController{Service: service, Pricing: pricing, Analytics: analytics}
+}
+
+// recommendationLimit reads the optional limit query param within bounds
+func recommendationLimit(contxt *gin.Context) int {
+	limit, err := strconv.Atoi(contxt.Query("limit"))
+	if err != nil || limit <= 0 {
+		return defaultRecommendationLimit
+	}
+	if limit > maxRecommendationLimit {
+		return maxRecommendationLimit
+	}
+	return limit
+}
+
+// respond prices the recommended products and writes them
+func (recommendationController *RecommendationController) respond(contxt *gin.Context, products []models.Product, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		contxt.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := recommendationController.Pricing.ApplyEffectivePrices(products); err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, product := range products {
+		recommendationController.Analytics.Record(product.ID, models.EventImpression)
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": products})
+}
+
+// 🛒 Frequently Bought Together (Public)
+func (recommendationController *RecommendationController) FrequentlyBoughtTogether(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	products, err := recommendationController.Service.FrequentlyBoughtTogether(uint(productID), recommendationLimit(contxt))
+	recommendationController.respond(contxt, products, err)
+}
+
+// 🏆 Bestsellers (Public, optional category)
+func (recommendationController *RecommendationController) Bestsellers(contxt *gin.Context) {
+	var categoryID uint64
+	if raw := contxt.Query("category_id"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return
+		}
+		categoryID = parsed
+	}
+
+	products, err := recommendationController.Service.Bestsellers(uint(categoryID), recommendationLimit(contxt))
+	recommendationController.respond(contxt, products, err)
+}
+
+// 💡 Products For You (Authenticated)
+func (recommendationController *RecommendationController) ForYou(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	products, err := recommendationController.Service.ForBuyer(userID, recommendationLimit(contxt))
+	recommendationController.respond(contxt, products, err)
+}
+
+// 👀 Record a Product View (Authenticated, feeds browsing history)
+func (recommendationController *RecommendationController) RecordView(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = recommendationController.Service.RecordView(userID, uint(productID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		contxt.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "View recorded"})
+}
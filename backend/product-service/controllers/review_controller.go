@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/models"
+	"product-service/services"
+)
+
+// Review page size bounds
+const (
+	defaultReviewLimit = 10
+	maxReviewLimit     = 50
+)
+
+type ReviewController struct {
+	Service services.ReviewService
+}
+
+func NewReviewController(service services.ReviewService) *ReviewController {
+	return &ReviewController{Service: service}
+}
+
+// reviewErrorStatus maps review service errors to HTTP status codes
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidRating),
+		errors.Is(err, services.ErrTooManyPhotos),
+		errors.Is(err, services.ErrInvalidReviewStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrNotDelivered),
+		errors.Is(err, services.ErrNotProductOwner):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAlreadyReviewed):
+		return http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// reviewPage reads the optional offset and limit query params within bounds
+func reviewPage(contxt *gin.Context) (int, int) {
+	offset, err := strconv.Atoi(contxt.Query("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	limit, err := strconv.Atoi(contxt.Query("limit"))
+	if err != nil || limit <= 0 {
+		return offset, defaultReviewLimit
+	}
+	if limit > maxReviewLimit {
+		return offset, maxReviewLimit
+	}
+	return offset, limit
+}
+
+// ⭐ Create Review (Buyer only, delivered purchases)
+func (reviewController *ReviewController) CreateReview(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var payload struct {
+		Rating  int      `json:"rating" binding:"required"`
+		Comment string   `json:"comment"`
+		Photos  []string `json:"photos"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "buyer" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only buyers can review products"})
+		return
+	}
+
+	review := models.Review{
+		ProductID: uint(productID),
+		BuyerID:   userID,
+		Rating:    payload.Rating,
+		Comment:   payload.Comment,
+		Photos:    payload.Photos,
+	}
+	if err := reviewController.Service.CreateReview(&review); err != nil {
+		contxt.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	contxt.JSON(http.StatusCreated, gin.H{"message": "Review submitted", "review": review})
+}
+
+// 📝 List Reviews for a Product (Public)
+func (reviewController *ReviewController) ListReviews(contxt *gin.Context) {
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+	offset, limit := reviewPage(contxt)
+
+	reviews, err := reviewController.Service.ListReviews(uint(productID), offset, limit)
+	if err != nil {
+		contxt.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, reviews)
+}
+
+// 💬 Reply to Review (Seller only, must own product)
+func (reviewController *ReviewController) ReplyToReview(contxt *gin.Context) {
+	reviewID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var payload struct {
+		Reply string `json:"reply" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "seller" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only sellers can reply to reviews"})
+		return
+	}
+
+	review, err := reviewController.Service.ReplyToReview(uint(reviewID), userID, payload.Reply)
+	if err != nil {
+		contxt.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Reply saved", "review": review})
+}
+
+// 🛡️ Moderate Review (Admin only)
+func (reviewController *ReviewController) ModerateReview(contxt *gin.Context) {
+	reviewID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var payload struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, role, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if role != "admin" {
+		contxt.JSON(http.StatusForbidden, gin.H{"error": "Only admins can moderate reviews"})
+		return
+	}
+
+	review, err := reviewController.Service.ModerateReview(uint(reviewID), payload.Status, payload.Note)
+	if err != nil {
+		contxt.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Review moderated", "review": review})
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"product-service/repository"
+	"product-service/services"
+)
+
+type StockController struct {
+	Service services.StockReservationService
+}
+
+func NewStockController(service services.StockReservationService) *StockController {
+	return &StockController{Service: service}
+}
+
+// stockErrorStatus maps stock reservation errors to HTTP status codes
+func stockErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNothingToReserve), errors.Is(err, services.ErrInvalidQuantity),
+		errors.Is(err, services.ErrReturnKeyRequired), errors.Is(err, repository.ErrEmptyBundle):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 📦 Reserve Stock for an Order (order-service)
+func (stockController *StockController) Reserve(contxt *gin.Context) {
+	var payload struct {
+		OrderID uint                       `json:"order_id" binding:"required"`
+		Items   []services.ReservationItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := stockController.Service.Reserve(payload.OrderID, payload.Items); err != nil {
+		contxt.JSON(stockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Stock reserved"})
+}
+
+// ↩️ Release an Order's Reserved Stock (order-service)
+func (stockController *StockController) Release(contxt *gin.Context) {
+	var payload struct {
+		OrderID    uint   `json:"order_id" binding:"required"`
+		ProductIDs []uint `json:"product_ids"` // empty releases the whole order
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := stockController.Service.Release(payload.OrderID, payload.ProductIDs); err != nil {
+		contxt.JSON(stockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Stock released"})
+}
+
+// 🔄 Restock Returned Goods (order-service)
+func (stockController *StockController) Restock(contxt *gin.Context) {
+	var payload struct {
+		OrderID   uint                       `json:"order_id" binding:"required"`
+		ReturnKey string                     `json:"return_key" binding:"required"`
+		Items     []services.ReservationItem `json:"items" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := stockController.Service.Restock(payload.OrderID, payload.ReturnKey, payload.Items); err != nil {
+		contxt.JSON(stockErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Stock returned"})
+}
@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"product-service/services"
+)
+
+type WishlistController struct {
+	Service services.WishlistService
+}
+
+func NewWishlistController(service services.WishlistService) *WishlistController {
+	return &WishlistController{Service: service}
+}
+
+// wishlistErrorStatus maps wishlist service errors to HTTP status codes
+func wishlistErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidWishlistName):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrWishlistNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTooManyWishlists),
+		errors.Is(err, services.ErrWishlistFull),
+		errors.Is(err, services.ErrProductInStock):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// wishlistParams reads the authenticated buyer and the :id wishlist param
+func wishlistParams(contxt *gin.Context) (uint, uint, bool) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wishlist ID"})
+		return 0, 0, false
+	}
+	return uint(id), userID, true
+}
+
+// 💖 Create Wishlist (Authenticated)
+func (wishlistController *WishlistController) Create(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	var payload struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist, err := wishlistController.Service.Create(userID, payload.Name)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusCreated, gin.H{"message": "Wishlist created", "wishlist": wishlist})
+}
+
+// 📋 List My Wishlists (Authenticated)
+func (wishlistController *WishlistController) List(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlists, err := wishlistController.Service.List(userID)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"items": wishlists})
+}
+
+// 🔍 Get Wishlist (Owner)
+func (wishlistController *WishlistController) Get(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+
+	wishlist, err := wishlistController.Service.Get(id, userID)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, wishlist)
+}
+
+// ✏️ Rename Wishlist (Owner)
+func (wishlistController *WishlistController) Rename(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+	var payload struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wishlist, err := wishlistController.Service.Rename(id, userID, payload.Name)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Wishlist renamed", "wishlist": wishlist})
+}
+
+// ❌ Delete Wishlist (Owner)
+func (wishlistController *WishlistController) Delete(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+
+	if err := wishlistController.Service.Delete(id, userID); err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Wishlist deleted"})
+}
+
+// ➕ Save Product to Wishlist (Owner)
+func (wishlistController *WishlistController) AddItem(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+	var payload struct {
+		ProductID uint `json:"product_id" binding:"required"`
+	}
+	if err := contxt.ShouldBindJSON(&payload); err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := wishlistController.Service.AddItem(id, userID, payload.ProductID)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusCreated, gin.H{"message": "Product saved", "item": item})
+}
+
+// ➖ Remove Product from Wishlist (Owner)
+func (wishlistController *WishlistController) RemoveItem(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+	productID, err := strconv.ParseUint(contxt.Param("product_id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	if err := wishlistController.Service.RemoveItem(id, userID, uint(productID)); err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Product removed"})
+}
+
+// 🔗 Share Wishlist (Owner)
+func (wishlistController *WishlistController) Share(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+
+	token, err := wishlistController.Service.Share(id, userID)
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"share_token": token, "share_path": "/api/wishlists/shared/" + token})
+}
+
+// 🚫 Stop Sharing Wishlist (Owner)
+func (wishlistController *WishlistController) Unshare(contxt *gin.Context) {
+	id, userID, ok := wishlistParams(contxt)
+	if !ok {
+		return
+	}
+
+	if err := wishlistController.Service.Unshare(id, userID); err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Wishlist is no longer shared"})
+}
+
+// 👀 View Shared Wishlist (Public, anyone with the link)
+func (wishlistController *WishlistController) GetShared(contxt *gin.Context) {
+	wishlist, err := wishlistController.Service.GetShared(contxt.Param("token"))
+	if err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{
+		"name":  wishlist.Name,
+		"items": wishlist.Items,
+	})
+}
+
+// 🔔 Subscribe to Back-in-Stock Notification (Authenticated)
+func (wishlistController *WishlistController) SubscribeStock(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	if err := wishlistController.Service.Subscribe(userID, uint(productID)); err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "You will be notified when this product is back in stock"})
+}
+
+// 🔕 Cancel Back-in-Stock Notification (Authenticated)
+func (wishlistController *WishlistController) UnsubscribeStock(contxt *gin.Context) {
+	userID, _, err := getAuthUser(contxt)
+	if err != nil {
+		contxt.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	productID, err := strconv.ParseUint(contxt.Param("id"), 10, 32)
+	if err != nil {
+		contxt.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	if err := wishlistController.Service.Unsubscribe(userID, uint(productID)); err != nil {
+		contxt.JSON(wishlistErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	contxt.JSON(http.StatusOK, gin.H{"message": "Back-in-stock notification cancelled"})
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Product analytics event types
+const (
+	EventImpression = "impression"
+	EventDetailView = "detail_view"
+	EventAddToCart  = "add_to_cart"
+	EventOrder      = "order"
+)
+
+// ProductDailyStat holds one product's event counts for one UTC day
+type ProductDailyStat struct {
+	ProductID   uint      `gorm:"primaryKey" json:"product_id"`
+	Day         time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Impressions int64     `gorm:"default:0" json:"impressions"`
+	Views       int64     `gorm:"default:0" json:"views"`
+	AddToCarts  int64     `gorm:"default:0" json:"add_to_carts"`
+	Orders      int64     `gorm:"default:0" json:"orders"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ViewRate is the share of impressions that led to a detail view
+func (s *ProductDailyStat) ViewRate() float64 {
+	return ratio(s.Views, s.Impressions)
+}
+
+// ConversionRate is the share of detail views that led to an order
+func (s *ProductDailyStat) ConversionRate() float64 {
+	return ratio(s.Orders, s.Views)
+}
+
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
@@ -0,0 +1,13 @@
+package models
+
+// ProductTypeBundle is a product sold as a fixed set of other products. Its quantity is
+// derived from its components and never set directly.
+const ProductTypeBundle = "bundle"
+
+// BundleComponent is one product, and how many of it, contained in a bundle
+type BundleComponent struct {
+	BundleID    uint     `gorm:"primaryKey" json:"-"`
+	ComponentID uint     `gorm:"primaryKey;index" json:"product_id"`
+	Quantity    int      `gorm:"not null" json:"quantity"`
+	Component   *Product `gorm:"foreignKey:ComponentID" json:"product,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Product types
+const (
+	ProductTypePhysical = "physical"
+	ProductTypeDigital  = "digital"
+)
+
+// DefaultDownloadLimit applies to digital products that do not set their own limit
+const DefaultDownloadLimit = 5
+
+// DigitalFile is a downloadable file attached to a digital product
+type DigitalFile struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ProductID   uint      `gorm:"not null;index" json:"product_id"`
+	FileName    string    `gorm:"not null" json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StoragePath string    `gorm:"not null" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LicenseKey is one key in a digital product's pool, assigned to at most one purchase
+type LicenseKey struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ProductID     uint       `gorm:"not null;uniqueIndex:idx_license_key_product_key" json:"product_id"`
+	Key           string     `gorm:"not null;uniqueIndex:idx_license_key_product_key" json:"key"`
+	EntitlementID *uint      `gorm:"index" json:"-"`
+	AssignedAt    *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// DigitalEntitlement gives a buyer access to a digital product bought in an order
+type DigitalEntitlement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	OrderID       uint      `gorm:"not null;uniqueIndex:idx_entitlement_order_product" json:"order_id"`
+	ProductID     uint      `gorm:"not null;uniqueIndex:idx_entitlement_order_product" json:"product_id"`
+	BuyerID       uint      `gorm:"not null;index" json:"buyer_id"`
+	SellerID      uint      `gorm:"not null;index" json:"seller_id"`
+	ProductName   string    `json:"product_name"`
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	DownloadLimit int       `gorm:"not null" json:"download_limit"`
+	DownloadCount int       `gorm:"default:0" json:"download_count"`
+	KeysPending   int       `gorm:"default:0;index" json:"keys_pending"` // keys still owed because the pool ran out
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	Keys  []LicenseKey  `gorm:"foreignKey:EntitlementID" json:"keys,omitempty"`
+	Files []DigitalFile `gorm:"-" json:"files,omitempty"`
+}
+
+// DownloadsLeft is how many more downloads the entitlement allows
+func (e *DigitalEntitlement) DownloadsLeft() int {
+	if left := e.DownloadLimit - e.DownloadCount; left > 0 {
+		return left
+	}
+	return 0
+}
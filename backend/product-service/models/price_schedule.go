@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// PriceSchedule is a time-boxed sale price for a product. Flash sales are
+// schedules flagged for the storefront's flash sale section.
+type PriceSchedule struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ProductID     uint      `gorm:"not null;index" json:"product_id"`
+	SalePrice     float64   `gorm:"not null" json:"sale_price"`
+	StartsAt      time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt        time.Time `gorm:"not null;index" json:"ends_at"`
+	IsFlashSale   bool      `gorm:"default:false" json:"is_flash_sale"`
+	QuantityCap   int       `gorm:"default:0" json:"quantity_cap"`    // 0 = unlimited
+	PerBuyerLimit int       `gorm:"default:0" json:"per_buyer_limit"` // 0 = unlimited
+	SoldCount     int       `gorm:"default:0" json:"sold_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IsLiveAt reports whether the schedule applies at the given time and still has stock
+func (s *PriceSchedule) IsLiveAt(t time.Time) bool {
+	if t.Before(s.StartsAt) || !t.Before(s.EndsAt) {
+		return false
+	}
+	return s.QuantityCap == 0 || s.SoldCount < s.QuantityCap
+}
+
+// SalePurchase records units a buyer bought under a price schedule,
+// used to enforce per-buyer limits. ClaimKey names the order the units were
+// claimed for; released purchases no longer count against the sale.
+type SalePurchase struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	PriceScheduleID uint       `gorm:"not null;index:idx_sale_buyer" json:"price_schedule_id"`
+	BuyerID         uint       `gorm:"not null;index:idx_sale_buyer" json:"buyer_id"`
+	Quantity        int        `gorm:"not null" json:"quantity"`
+	ClaimKey        string     `gorm:"size:64;index" json:"claim_key,omitempty"`
+	ReleasedAt      *time.Time `json:"released_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// ActiveSale is the sale summary attached to products in API responses
+type ActiveSale struct {
+	PriceScheduleID uint      `json:"price_schedule_id"`
+	SalePrice       float64   `json:"sale_price"`
+	EndsAt          time.Time `json:"ends_at"`
+	IsFlashSale     bool      `json:"is_flash_sale"`
+	Remaining       *int      `json:"remaining,omitempty"`
+	PerBuyerLimit   int       `json:"per_buyer_limit,omitempty"`
+}
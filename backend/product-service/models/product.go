@@ -14,8 +14,20 @@ type Category struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Locale of the translated Name, empty when untranslated; never persisted
+	Locale string `gorm:"-" json:"locale,omitempty"`
 }
 
+// Product lifecycle states
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusActive        = "active"
+	ProductStatusSuspended     = "suspended"
+	ProductStatusArchived      = "archived"
+)
+
 // Product represents the product with foreign key to Category
 type Product struct {
 	gorm.Model
@@ -25,13 +37,51 @@ type Product struct {
 	Quantity    int            `gorm:"not null" json:"quantity"`
 	ImageURL    string         `json:"image_url"`
 	Stock       int            `json:"stock"`
+	ReorderThreshold   int     `gorm:"default:0" json:"reorder_threshold"`
+	AutoHideOutOfStock bool    `gorm:"default:false" json:"auto_hide_out_of_stock"`
+	StockHidden        bool    `gorm:"default:false;index" json:"stock_hidden"` // set while auto-hidden at zero quantity
+	Type               string  `gorm:"type:varchar(20);default:'physical'" json:"type"`
+	DownloadLimit      int     `gorm:"default:0" json:"download_limit,omitempty"`   // digital only: downloads per purchase
+	UsesLicenseKeys    bool    `gorm:"default:false" json:"uses_license_keys"`      // digital only: each unit gets a key from the pool
 	CategoryID  uint           `gorm:"not null;index" json:"category_id"`
 	Category    Category       `gorm:"foreignKey:CategoryID" json:"category"`
 	SellerID    uint           `gorm:"index;not null" json:"seller_id"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	ShopID      uint           `gorm:"index" json:"shop_id"`
+	IsActive    bool           `gorm:"default:false" json:"is_active"` // mirrors Status == active
+	Status      string         `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	ShopVisible bool           `gorm:"default:true;index" json:"shop_visible"`
+	Flagged     bool           `gorm:"default:false;index" json:"flagged"`
+	FlagReasons []string       `gorm:"serializer:json" json:"flag_reasons,omitempty"`
+	ModerationNote string      `json:"moderation_note,omitempty"`
+	Rating      float64        `gorm:"default:0" json:"rating"`
+	ReviewCount int            `gorm:"default:0" json:"review_count"`
+	PopularityScore float64    `gorm:"default:0;index" json:"popularity_score"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Resolved at read time from active price schedules, never persisted
+	EffectivePrice float64     `gorm:"-" json:"effective_price"`
+	ActiveSale     *ActiveSale `gorm:"-" json:"active_sale,omitempty"`
+	// Locale of the translated Name, empty when the product's own name is shown; never persisted
+	Locale string `gorm:"-" json:"locale,omitempty"`
+	// Contents of a bundle, loaded on demand; never persisted with the product
+	Components []BundleComponent `gorm:"-" json:"components,omitempty"`
+}
+
+// IsDigital reports whether the product is delivered electronically instead of shipped
+func (p *Product) IsDigital() bool {
+	return p.Type == ProductTypeDigital
+}
+
+// IsBundle reports whether the product is a set of other products with derived stock
+func (p *Product) IsBundle() bool {
+	return p.Type == ProductTypeBundle
+}
+
+// IsListed reports whether buyers can see the product
+func (p *Product) IsListed() bool {
+	return p.Status == ProductStatusActive && p.ShopVisible && !p.StockHidden
 }
 
 // BeforeCreate validates that the CategoryID exists before inserting product
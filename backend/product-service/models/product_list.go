@@ -0,0 +1,35 @@
+package models
+
+// Product listing sort keys
+const (
+	ProductSortNewest     = "newest"
+	ProductSortPriceAsc   = "price_asc"
+	ProductSortPriceDesc  = "price_desc"
+	ProductSortRating     = "rating"
+	ProductSortPopularity = "popularity"
+)
+
+// ProductListQuery describes one page of a product listing.
+// Role and SellerID scope the listing the same way as GetByID.
+type ProductListQuery struct {
+	Role       string
+	SellerID   uint
+	Name       string
+	CategoryID uint
+	ShopOwner  uint // seller_id filter, applied on top of the role scope
+	ShopID     uint
+	MinPrice   *float64
+	MaxPrice   *float64
+	Sort       string
+	Cursor     string
+	Offset     int // deprecated: skips rows when no cursor is given
+	Limit      int
+	WithTotal  bool
+}
+
+// ProductPage is a page of products with the cursor for the next one
+type ProductPage struct {
+	Items      []Product `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      *int64    `json:"total,omitempty"`
+}
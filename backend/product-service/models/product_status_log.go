@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ProductStatusLog records every lifecycle transition of a product
+type ProductStatusLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProductID  uint      `gorm:"not null;index" json:"product_id"`
+	FromStatus string    `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus   string    `gorm:"type:varchar(20);not null" json:"to_status"`
+	ActorID    uint      `json:"actor_id"`
+	ActorRole  string    `gorm:"type:varchar(20)" json:"actor_role"`
+	Reason     string    `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Question moderation states
+const (
+	QuestionStatusPublished = "published"
+	QuestionStatusHidden    = "hidden"
+	QuestionStatusRejected  = "rejected"
+)
+
+// Question is a buyer's pre-purchase question about a product, answered by its seller
+type Question struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	ProductID      uint           `gorm:"not null;index" json:"product_id"`
+	BuyerID        uint           `gorm:"not null;index" json:"buyer_id"`
+	Body           string         `gorm:"type:text;not null" json:"body"`
+	Answer         string         `gorm:"type:text" json:"answer,omitempty"`
+	AnsweredBy     uint           `json:"answered_by,omitempty"`
+	AnsweredAt     *time.Time     `json:"answered_at,omitempty"`
+	IsAnswered     bool           `gorm:"default:false;index" json:"is_answered"`
+	Upvotes        int            `gorm:"default:0" json:"upvotes"`
+	ReportCount    int            `gorm:"default:0" json:"report_count"`
+	Status         string         `gorm:"type:varchar(20);default:'published';index" json:"status"`
+	ModerationNote string         `json:"moderation_note,omitempty"`
+	SLANotifiedAt  *time.Time     `json:"-"` // seller was reminded that the question is unanswered
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// QuestionVote is one user's upvote of a question
+type QuestionVote struct {
+	QuestionID uint      `gorm:"primaryKey" json:"question_id"`
+	UserID     uint      `gorm:"primaryKey" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QuestionReport is one user's abuse report against a question
+type QuestionReport struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	QuestionID uint      `gorm:"not null;uniqueIndex:idx_question_reporter" json:"question_id"`
+	ReporterID uint      `gorm:"not null;uniqueIndex:idx_question_reporter" json:"reporter_id"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NotificationUnansweredQuestions reminds a seller of questions past the answer SLA
+const NotificationUnansweredQuestions = "unanswered_questions"
+
+// SellerNotification tells a seller about questions waiting for an answer
+type SellerNotification struct {
+	Type        string    `json:"type"`
+	SellerID    uint      `json:"seller_id"`
+	QuestionIDs []uint    `json:"question_ids"`
+	OldestAt    time.Time `json:"oldest_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
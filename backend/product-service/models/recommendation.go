@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// PurchaseRecord is a delivered order line imported from order-service,
+// the input for co-purchase associations and purchase history
+type PurchaseRecord struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OrderItemID uint      `gorm:"uniqueIndex;not null" json:"order_item_id"`
+	OrderID     uint      `gorm:"not null;index" json:"order_id"`
+	BuyerID     uint      `gorm:"not null;index" json:"buyer_id"`
+	ProductID   uint      `gorm:"not null;index" json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	PurchasedAt time.Time `gorm:"index" json:"purchased_at"`
+}
+
+// ProductView is a buyer's browsing history for one product
+type ProductView struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	BuyerID      uint      `gorm:"not null;uniqueIndex:idx_product_view" json:"buyer_id"`
+	ProductID    uint      `gorm:"not null;uniqueIndex:idx_product_view" json:"product_id"`
+	ViewCount    int       `gorm:"default:1" json:"view_count"`
+	LastViewedAt time.Time `gorm:"index" json:"last_viewed_at"`
+}
+
+// ProductAssociation scores how often two products are bought in the same order
+type ProductAssociation struct {
+	ProductID        uint      `gorm:"primaryKey" json:"product_id"`
+	RelatedProductID uint      `gorm:"primaryKey" json:"related_product_id"`
+	Score            float64   `gorm:"not null" json:"score"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// BuyerRecommendation is a precomputed "products for you" entry
+type BuyerRecommendation struct {
+	BuyerID   uint      `gorm:"primaryKey" json:"buyer_id"`
+	ProductID uint      `gorm:"primaryKey" json:"product_id"`
+	Score     float64   `gorm:"not null" json:"score"`
+	Rank      int       `gorm:"not null" json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SyncState stores a named high-water mark for background imports
+type SyncState struct {
+	Name      string    `gorm:"primaryKey;type:varchar(64)" json:"name"`
+	Value     time.Time `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
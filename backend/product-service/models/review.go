@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review moderation states
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHidden    = "hidden"
+	ReviewStatusRejected  = "rejected"
+)
+
+// Review is a buyer's rating of a product they received
+type Review struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	ProductID       uint           `gorm:"not null;index;uniqueIndex:idx_review_purchase" json:"product_id"`
+	BuyerID         uint           `gorm:"not null;index;uniqueIndex:idx_review_purchase" json:"buyer_id"`
+	OrderID         uint           `gorm:"not null;uniqueIndex:idx_review_purchase" json:"order_id"`
+	Rating          int            `gorm:"not null" json:"rating"`
+	Comment         string         `gorm:"type:text" json:"comment"`
+	Photos          []string       `gorm:"serializer:json" json:"photos"`
+	SellerReply     string         `gorm:"type:text" json:"seller_reply,omitempty"`
+	SellerRepliedAt *time.Time     `json:"seller_replied_at,omitempty"`
+	Status          string         `gorm:"type:varchar(20);default:'published';index" json:"status"`
+	ModerationNote  string         `json:"moderation_note,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
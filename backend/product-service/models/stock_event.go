@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Stock event types
+const (
+	StockEventLow         = "low_stock"
+	StockEventOut         = "out_of_stock"
+	StockEventBackInStock = "back_in_stock"
+)
+
+// StockEvent is raised when a stock change crosses a product's reorder threshold or zero
+type StockEvent struct {
+	Type       string    `json:"type"`
+	ProductID  uint      `json:"product_id"`
+	ShopID     uint      `json:"shop_id"`
+	SellerID   uint      `json:"seller_id"`
+	Name       string    `json:"name"`
+	Quantity   int       `json:"quantity"`
+	Threshold  int       `json:"reorder_threshold"`
+	Hidden     bool      `json:"hidden"` // listing is auto-hidden after this change
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StockReservation is stock taken off a product for an order. Released reservations
+// have had their stock put back.
+type StockReservation struct {
+	ID         uint                        `gorm:"primaryKey" json:"id"`
+	OrderID    uint                        `gorm:"not null;uniqueIndex:idx_stock_reservation_order_product" json:"order_id"`
+	ProductID  uint                        `gorm:"not null;uniqueIndex:idx_stock_reservation_order_product" json:"product_id"`
+	Quantity   int                         `gorm:"not null" json:"quantity"`
+	Components []StockReservationComponent `gorm:"foreignKey:ReservationID" json:"components,omitempty"`
+	ReleasedAt *time.Time                  `json:"released_at,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+}
+
+// StockReservationComponent is what one unit of a reserved bundle held when it was
+// reserved. Releasing or restocking the bundle moves exactly these, even after the
+// seller changed what the bundle contains.
+type StockReservationComponent struct {
+	ID            uint `gorm:"primaryKey" json:"id"`
+	ReservationID uint `gorm:"not null;index" json:"reservation_id"`
+	ComponentID   uint `gorm:"not null" json:"component_id"`
+	Quantity      int  `gorm:"not null" json:"quantity"` // units of the component in one bundle
+}
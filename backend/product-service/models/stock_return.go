@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// StockReturn is stock put back on a product when returned goods passed inspection.
+// The return key stops a repeated restock from counting the same goods twice; the order
+// tells which components a returned bundle held when it was sold.
+type StockReturn struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ReturnKey string    `gorm:"size:64;not null;uniqueIndex:idx_stock_return_key_product" json:"return_key"`
+	OrderID   uint      `gorm:"index" json:"order_id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_stock_return_key_product" json:"product_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Supported content locales. Untranslated content falls back to DefaultLocale, then
+// to the product's own fields.
+const (
+	LocaleEnglish = "en"
+	LocaleBangla  = "bn"
+	DefaultLocale = LocaleEnglish
+)
+
+// SupportedLocales lists the locales translations may be written in
+var SupportedLocales = []string{LocaleEnglish, LocaleBangla}
+
+// ProductTranslation holds a product's name and description in one locale
+type ProductTranslation struct {
+	ProductID   uint      `gorm:"primaryKey" json:"product_id"`
+	Locale      string    `gorm:"primaryKey;type:varchar(10)" json:"locale"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CategoryTranslation holds a category's name in one locale
+type CategoryTranslation struct {
+	CategoryID uint      `gorm:"primaryKey" json:"category_id"`
+	Locale     string    `gorm:"primaryKey;type:varchar(10)" json:"locale"`
+	Name       string    `gorm:"not null" json:"name"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
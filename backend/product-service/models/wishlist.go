@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Wishlist is a buyer's named list of saved products. ShareToken is set while the
+// list is shared and lets anyone with the link view it.
+type Wishlist struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	BuyerID    uint           `gorm:"not null;index" json:"buyer_id"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	ShareToken *string        `gorm:"type:varchar(64);uniqueIndex" json:"share_token,omitempty"`
+	Items      []WishlistItem `gorm:"constraint:OnDelete:CASCADE" json:"items"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// WishlistItem is a product saved to a wishlist with the price it had when saved
+type WishlistItem struct {
+	ID         uint    `gorm:"primaryKey" json:"id"`
+	WishlistID uint    `gorm:"not null;uniqueIndex:idx_wishlist_product" json:"wishlist_id"`
+	ProductID  uint    `gorm:"not null;uniqueIndex:idx_wishlist_product;index" json:"product_id"`
+	SavedPrice float64 `gorm:"not null" json:"saved_price"`
+	// NotifiedPrice is the lowest price the buyer was already alerted about
+	NotifiedPrice float64   `gorm:"default:0" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Resolved at read time, never persisted
+	Product      *Product `gorm:"-" json:"product,omitempty"`
+	PriceDropped bool     `gorm:"-" json:"price_dropped"`
+}
+
+// StockSubscription asks for a notification when an out-of-stock product returns
+type StockSubscription struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BuyerID   uint      `gorm:"not null;uniqueIndex:idx_stock_subscription" json:"buyer_id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_stock_subscription;index" json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Buyer notification types
+const (
+	NotificationPriceDrop   = "price_drop"
+	NotificationBackInStock = "back_in_stock"
+)
+
+// BuyerNotification is a message for a buyer about a product they follow
+type BuyerNotification struct {
+	Type      string    `json:"type"`
+	BuyerID   uint      `json:"buyer_id"`
+	ProductID uint      `json:"product_id"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	OldPrice  float64   `json:"old_price,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PopularityWeights are the per-event weights of a product's popularity score
+type PopularityWeights struct {
+	View      float64
+	AddToCart float64
+	Order     float64
+}
+
+// AnalyticsRepository defines the contract for product analytics data access
+type AnalyticsRepository interface {
+	IncrementDailyStats(deltas []models.ProductDailyStat) error
+	ListDailyStats(sellerID, productID uint, from, to time.Time) ([]models.ProductDailyStat, error)
+	RefreshPopularity(since time.Time, weights PopularityWeights) error
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository creates a new AnalyticsRepository instance
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// IncrementDailyStats adds each delta to its product's row for that day
+func (r *analyticsRepository) IncrementDailyStats(deltas []models.ProductDailyStat) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"impressions":  gorm.Expr("product_daily_stats.impressions + excluded.impressions"),
+			"views":        gorm.Expr("product_daily_stats.views + excluded.views"),
+			"add_to_carts": gorm.Expr("product_daily_stats.add_to_carts + excluded.add_to_carts"),
+			"orders":       gorm.Expr("product_daily_stats.orders + excluded.orders"),
+			"updated_at":   gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&deltas).Error
+}
+
+// ListDailyStats returns daily rows between from and to (inclusive), optionally
+// limited to one seller's products and/or a single product
+func (r *analyticsRepository) ListDailyStats(sellerID, productID uint, from, to time.Time) ([]models.ProductDailyStat, error) {
+	query := r.db.Model(&models.ProductDailyStat{}).
+		Where("product_daily_stats.day BETWEEN ? AND ?", from, to)
+	if sellerID != 0 {
+		query = query.
+			Joins("JOIN products ON products.id = product_daily_stats.product_id").
+			Where("products.seller_id = ?", sellerID)
+	}
+	if productID != 0 {
+		query = query.Where("product_daily_stats.product_id = ?", productID)
+	}
+
+	var stats []models.ProductDailyStat
+	err := query.
+		Order("product_daily_stats.product_id, product_daily_stats.day").
+		Find(&stats).Error
+	return stats, err
+}
+
+// RefreshPopularity recomputes every product's popularity score from daily stats since the given day
+func (r *analyticsRepository) RefreshPopularity(since time.Time, weights PopularityWeights) error {
+	return r.db.Exec(`
+		UPDATE products SET popularity_score = COALESCE((
+			SELECT SUM(s.views * ? + s.add_to_carts * ? + s.orders * ?)
+			FROM product_daily_stats s
+			WHERE s.product_id = products.id AND s.day >= ?
+		), 0)`, weights.View, weights.AddToCart, weights.Order, since).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BundleRepository defines the contract for bundle contents
+type BundleRepository interface {
+	SetComponents(bundleID uint, components []models.BundleComponent) ([]StockChange, error)
+	ListComponents(bundleIDs []uint) ([]models.BundleComponent, error)
+}
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+// NewBundleRepository creates a new BundleRepository instance
+func NewBundleRepository(db *gorm.DB) BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+// SetComponents replaces a bundle's contents and recalculates its quantity, returning
+// the bundle as a stock change when the quantity moved
+func (r *bundleRepository) SetComponents(bundleID uint, components []models.BundleComponent) ([]StockChange, error) {
+	var changes []StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var bundle models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "quantity").
+			First(&bundle, bundleID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bundle_id = ?", bundleID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		for i := range components {
+			components[i].BundleID = bundleID
+		}
+		if err := tx.Omit(clause.Associations).Create(&components).Error; err != nil {
+			return err
+		}
+
+		var err error
+		changes, err = recalculateBundles(tx, []models.Product{bundle})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// ListComponents returns the contents of the given bundles with each component product loaded
+func (r *bundleRepository) ListComponents(bundleIDs []uint) ([]models.BundleComponent, error) {
+	var components []models.BundleComponent
+	if len(bundleIDs) == 0 {
+		return components, nil
+	}
+	err := r.db.Preload("Component").
+		Where("bundle_id IN ?", bundleIDs).
+		Order("bundle_id, component_id").
+		Find(&components).Error
+	return components, err
+}
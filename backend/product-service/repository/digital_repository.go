@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigitalRepository defines the contract for digital files, license keys and entitlements
+type DigitalRepository interface {
+	AddFile(file *models.DigitalFile) error
+	GetFile(id uint) (*models.DigitalFile, error)
+	ListFiles(productID uint) ([]models.DigitalFile, error)
+	ListFilesByProducts(productIDs []uint) ([]models.DigitalFile, error)
+	DeleteFile(productID, fileID uint) (*models.DigitalFile, error)
+
+	AddKeys(productID uint, keys []string) (int, error)
+	CountKeys(productID uint) (available, assigned int64, err error)
+	AssignKeys(entitlementID uint, at time.Time) (int, error)
+	ReservedQuantity(orderID, productID uint) (int, error)
+
+	CreateEntitlement(entitlement *models.DigitalEntitlement) (bool, error)
+	FindEntitlement(orderID, productID uint) (*models.DigitalEntitlement, error)
+	GetEntitlement(id uint) (*models.DigitalEntitlement, error)
+	ListEntitlements(buyerID uint) ([]models.DigitalEntitlement, error)
+	ListAwaitingKeys(productID uint) ([]models.DigitalEntitlement, error)
+	UseDownload(entitlementID uint) (bool, error)
+}
+
+type digitalRepository struct {
+	db *gorm.DB
+}
+
+// NewDigitalRepository creates a new DigitalRepository instance
+func NewDigitalRepository(db *gorm.DB) DigitalRepository {
+	return &digitalRepository{db: db}
+}
+
+// AddFile records an uploaded file
+func (r *digitalRepository) AddFile(file *models.DigitalFile) error {
+	return r.db.Create(file).Error
+}
+
+// GetFile fetches a single file
+func (r *digitalRepository) GetFile(id uint) (*models.DigitalFile, error) {
+	var file models.DigitalFile
+	if err := r.db.First(&file, id).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// ListFiles returns a product's files, oldest first
+func (r *digitalRepository) ListFiles(productID uint) ([]models.DigitalFile, error) {
+	var files []models.DigitalFile
+	err := r.db.Where("product_id = ?", productID).Order("id").Find(&files).Error
+	return files, err
+}
+
+// ListFilesByProducts returns the files of several products, oldest first
+func (r *digitalRepository) ListFilesByProducts(productIDs []uint) ([]models.DigitalFile, error) {
+	var files []models.DigitalFile
+	if len(productIDs) == 0 {
+		return files, nil
+	}
+	err := r.db.Where("product_id IN ?", productIDs).Order("id").Find(&files).Error
+	return files, err
+}
+
+// DeleteFile removes a product's file record and returns it so the caller can remove the content
+func (r *digitalRepository) DeleteFile(productID, fileID uint) (*models.DigitalFile, error) {
+	var file models.DigitalFile
+	result := r.db.Clauses(clause.Returning{}).
+		Where("id = ? AND product_id = ?", fileID, productID).
+		Delete(&file)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &file, nil
+}
+
+// AddKeys adds keys to a product's pool, skipping duplicates, and returns how many were new
+func (r *digitalRepository) AddKeys(productID uint, keys []string) (int, error) {
+	rows := make([]models.LicenseKey, len(keys))
+	for i, key := range keys {
+		rows[i] = models.LicenseKey{ProductID: productID, Key: key}
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+	return int(result.RowsAffected), result.Error
+}
+
+// CountKeys reports how many of a product's keys are unassigned and assigned
+func (r *digitalRepository) CountKeys(productID uint) (available, assigned int64, err error) {
+	var counts struct {
+		Available int64
+		Assigned  int64
+	}
+	err = r.db.Model(&models.LicenseKey{}).
+		Select("COUNT(*) FILTER (WHERE entitlement_id IS NULL) AS available, COUNT(*) FILTER (WHERE entitlement_id IS NOT NULL) AS assigned").
+		Where("product_id = ?", productID).
+		Scan(&counts).Error
+	return counts.Available, counts.Assigned, err
+}
+
+// AssignKeys gives an entitlement as many of the keys it is still owed as the pool
+// allows and returns how many it got. Concurrent assignments never share a key or
+// over-fill an entitlement.
+func (r *digitalRepository) AssignKeys(entitlementID uint, at time.Time) (int, error) {
+	assigned := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var entitlement models.DigitalEntitlement
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "product_id", "keys_pending").
+			First(&entitlement, entitlementID).Error; err != nil {
+			return err
+		}
+		if entitlement.KeysPending <= 0 {
+			return nil
+		}
+
+		result := tx.Exec(`UPDATE license_keys SET entitlement_id = ?, assigned_at = ?
+			WHERE id IN (
+				SELECT id FROM license_keys
+				WHERE product_id = ? AND entitlement_id IS NULL
+				ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED
+			)`, entitlementID, at, entitlement.ProductID, entitlement.KeysPending)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		assigned = int(result.RowsAffected)
+		return tx.Model(&models.DigitalEntitlement{}).
+			Where("id = ?", entitlementID).
+			UpdateColumn("keys_pending", gorm.Expr("keys_pending - ?", assigned)).Error
+	})
+	return assigned, err
+}
+
+// ReservedQuantity returns how much of a product an order still holds in stock
+// reservations, i.e. stock already taken for it at checkout
+func (r *digitalRepository) ReservedQuantity(orderID, productID uint) (int, error) {
+	var reserved int
+	err := r.db.Model(&models.StockReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("order_id = ? AND product_id = ? AND released_at IS NULL", orderID, productID).
+		Scan(&reserved).Error
+	return reserved, err
+}
+
+// CreateEntitlement inserts an entitlement; it reports false when the order already has
+// one for the product
+func (r *digitalRepository) CreateEntitlement(entitlement *models.DigitalEntitlement) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Keys").Create(entitlement)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindEntitlement fetches the entitlement for a product bought in an order, with its keys
+func (r *digitalRepository) FindEntitlement(orderID, productID uint) (*models.DigitalEntitlement, error) {
+	var entitlement models.DigitalEntitlement
+	if err := r.withKeys(r.db).
+		Where("order_id = ? AND product_id = ?", orderID, productID).
+		First(&entitlement).Error; err != nil {
+		return nil, err
+	}
+	return &entitlement, nil
+}
+
+// GetEntitlement fetches a single entitlement with its keys
+func (r *digitalRepository) GetEntitlement(id uint) (*models.DigitalEntitlement, error) {
+	var entitlement models.DigitalEntitlement
+	if err := r.withKeys(r.db).First(&entitlement, id).Error; err != nil {
+		return nil, err
+	}
+	return &entitlement, nil
+}
+
+// ListEntitlements returns a buyer's digital purchases with their keys, newest first
+func (r *digitalRepository) ListEntitlements(buyerID uint) ([]models.DigitalEntitlement, error) {
+	var entitlements []models.DigitalEntitlement
+	err := r.withKeys(r.db).
+		Where("buyer_id = ?", buyerID).
+		Order("id DESC").
+		Find(&entitlements).Error
+	return entitlements, err
+}
+
+// ListAwaitingKeys returns a product's entitlements still owed keys, oldest first
+func (r *digitalRepository) ListAwaitingKeys(productID uint) ([]models.DigitalEntitlement, error) {
+	var entitlements []models.DigitalEntitlement
+	err := r.db.
+		Where("product_id = ? AND keys_pending > 0", productID).
+		Order("id").
+		Find(&entitlements).Error
+	return entitlements, err
+}
+
+// UseDownload counts one download; it reports false once the limit is reached
+func (r *digitalRepository) UseDownload(entitlementID uint) (bool, error) {
+	result := r.db.Model(&models.DigitalEntitlement{}).
+		Where("id = ? AND download_count < download_limit", entitlementID).
+		UpdateColumn("download_count", gorm.Expr("download_count + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// withKeys preloads assigned keys in assignment order
+func (r *digitalRepository) withKeys(db *gorm.DB) *gorm.DB {
+	return db.Preload("Keys", func(db *gorm.DB) *gorm.DB {
+		return db.Order("license_keys.id")
+	})
+}
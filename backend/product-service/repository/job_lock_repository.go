@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+)
+
+// JobLockRepository defines the contract for the locks that keep scheduled jobs to one
+// instance at a time
+type JobLockRepository interface {
+	Acquire(name, holder string, now, until time.Time) (bool, error)
+	Finish(name, holder string, lastErr string) error
+}
+
+type jobLockRepo struct {
+	db *gorm.DB
+}
+
+func NewJobLockRepository(db *gorm.DB) JobLockRepository {
+	return &jobLockRepo{db}
+}
+
+// Acquire takes the named lock for holder until the given time, provided nobody holds
+// it past now. It reports false if another instance still holds it.
+func (r *jobLockRepo) Acquire(name, holder string, now, until time.Time) (bool, error) {
+	result := r.db.Exec(`INSERT INTO job_locks (name, holder, locked_until, last_run_at, last_error, updated_at)
+		VALUES (?, ?, ?, ?, '', ?)
+		ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, locked_until = EXCLUDED.locked_until,
+			updated_at = EXCLUDED.updated_at
+		WHERE job_locks.locked_until <= ?`,
+		name, holder, until, time.Time{}, now, now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Finish records how the holder's run of a job went. The lock stays taken until it
+// expires, so the job runs at most once per lease whichever instance picks it up next.
+func (r *jobLockRepo) Finish(name, holder string, lastErr string) error {
+	return r.db.Model(&models.JobLock{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Updates(map[string]interface{}{"last_run_at": time.Now(), "last_error": lastErr, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) IncrementDailyStats(deltas []models.ProductDailyStat) error {
+	args := m.Called(deltas)
+	return args.Error(0)
+}
+
+func (m *MockAnalyticsRepository) ListDailyStats(sellerID, productID uint, from, to time.Time) ([]models.ProductDailyStat, error) {
+	args := m.Called(sellerID, productID, from, to)
+	stats, _ := args.Get(0).([]models.ProductDailyStat)
+	return stats, args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) RefreshPopularity(since time.Time, weights PopularityWeights) error {
+	args := m.Called(since, weights)
+	return args.Error(0)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBundleRepository struct {
+	mock.Mock
+}
+
+func (m *MockBundleRepository) SetComponents(bundleID uint, components []models.BundleComponent) ([]StockChange, error) {
+	args := m.Called(bundleID, components)
+	changes, _ := args.Get(0).([]StockChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockBundleRepository) ListComponents(bundleIDs []uint) ([]models.BundleComponent, error) {
+	args := m.Called(bundleIDs)
+	components, _ := args.Get(0).([]models.BundleComponent)
+	return components, args.Error(1)
+}
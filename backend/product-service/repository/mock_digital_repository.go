@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDigitalRepository struct {
+	mock.Mock
+}
+
+func (m *MockDigitalRepository) AddFile(file *models.DigitalFile) error {
+	args := m.Called(file)
+	return args.Error(0)
+}
+
+func (m *MockDigitalRepository) GetFile(id uint) (*models.DigitalFile, error) {
+	args := m.Called(id)
+	file, _ := args.Get(0).(*models.DigitalFile)
+	return file, args.Error(1)
+}
+
+func (m *MockDigitalRepository) ListFiles(productID uint) ([]models.DigitalFile, error) {
+	args := m.Called(productID)
+	files, _ := args.Get(0).([]models.DigitalFile)
+	return files, args.Error(1)
+}
+
+func (m *MockDigitalRepository) ListFilesByProducts(productIDs []uint) ([]models.DigitalFile, error) {
+	args := m.Called(productIDs)
+	files, _ := args.Get(0).([]models.DigitalFile)
+	return files, args.Error(1)
+}
+
+func (m *MockDigitalRepository) DeleteFile(productID, fileID uint) (*models.DigitalFile, error) {
+	args := m.Called(productID, fileID)
+	file, _ := args.Get(0).(*models.DigitalFile)
+	return file, args.Error(1)
+}
+
+func (m *MockDigitalRepository) AddKeys(productID uint, keys []string) (int, error) {
+	args := m.Called(productID, keys)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDigitalRepository) CountKeys(productID uint) (int64, int64, error) {
+	args := m.Called(productID)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDigitalRepository) AssignKeys(entitlementID uint, at time.Time) (int, error) {
+	args := m.Called(entitlementID, at)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDigitalRepository) ReservedQuantity(orderID, productID uint) (int, error) {
+	args := m.Called(orderID, productID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDigitalRepository) CreateEntitlement(entitlement *models.DigitalEntitlement) (bool, error) {
+	args := m.Called(entitlement)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDigitalRepository) FindEntitlement(orderID, productID uint) (*models.DigitalEntitlement, error) {
+	args := m.Called(orderID, productID)
+	entitlement, _ := args.Get(0).(*models.DigitalEntitlement)
+	return entitlement, args.Error(1)
+}
+
+func (m *MockDigitalRepository) GetEntitlement(id uint) (*models.DigitalEntitlement, error) {
+	args := m.Called(id)
+	entitlement, _ := args.Get(0).(*models.DigitalEntitlement)
+	return entitlement, args.Error(1)
+}
+
+func (m *MockDigitalRepository) ListEntitlements(buyerID uint) ([]models.DigitalEntitlement, error) {
+	args := m.Called(buyerID)
+	entitlements, _ := args.Get(0).([]models.DigitalEntitlement)
+	return entitlements, args.Error(1)
+}
+
+func (m *MockDigitalRepository) ListAwaitingKeys(productID uint) ([]models.DigitalEntitlement, error) {
+	args := m.Called(productID)
+	entitlements, _ := args.Get(0).([]models.DigitalEntitlement)
+	return entitlements, args.Error(1)
+}
+
+func (m *MockDigitalRepository) UseDownload(entitlementID uint) (bool, error) {
+	args := m.Called(entitlementID)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobLockRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobLockRepository) Acquire(name, holder string, now, until time.Time) (bool, error) {
+	args := m.Called(name, holder, now, until)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobLockRepository) Finish(name, holder string, lastErr string) error {
+	args := m.Called(name, holder, lastErr)
+	return args.Error(0)
+}
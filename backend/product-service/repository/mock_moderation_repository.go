@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockModerationRepository struct {
+	mock.Mock
+}
+
+func (m *MockModerationRepository) Transition(p *models.Product, entry *models.ProductStatusLog) error {
+	args := m.Called(p, entry)
+	return args.Error(0)
+}
+
+func (m *MockModerationRepository) ListQueue(flaggedOnly bool, offset, limit int) ([]models.Product, error) {
+	args := m.Called(flaggedOnly, offset, limit)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockModerationRepository) ListStatusLogs(productID uint) ([]models.ProductStatusLog, error) {
+	args := m.Called(productID)
+	return args.Get(0).([]models.ProductStatusLog), args.Error(1)
+}
+
+func (m *MockModerationRepository) SetShopVisibility(shopID, sellerID uint, visible bool) error {
+	args := m.Called(shopID, sellerID, visible)
+	return args.Error(0)
+}
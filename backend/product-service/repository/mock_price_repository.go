@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPriceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceRepository) Create(s *models.PriceSchedule) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) GetByID(id uint) (*models.PriceSchedule, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.PriceSchedule), args.Error(1)
+}
+
+func (m *MockPriceRepository) ListByProduct(productID uint) ([]models.PriceSchedule, error) {
+	args := m.Called(productID)
+	return args.Get(0).([]models.PriceSchedule), args.Error(1)
+}
+
+func (m *MockPriceRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) ListLive(productIDs []uint, at time.Time) ([]models.PriceSchedule, error) {
+	args := m.Called(productIDs, at)
+	return args.Get(0).([]models.PriceSchedule), args.Error(1)
+}
+
+func (m *MockPriceRepository) ListLiveFlashSales(at time.Time, limit int) ([]models.PriceSchedule, error) {
+	args := m.Called(at, limit)
+	return args.Get(0).([]models.PriceSchedule), args.Error(1)
+}
+
+func (m *MockPriceRepository) ClaimSale(scheduleID, buyerID uint, quantity int, claimKey string) error {
+	args := m.Called(scheduleID, buyerID, quantity, claimKey)
+	return args.Error(0)
+}
+
+func (m *MockPriceRepository) ReleaseClaims(claimKey string, productIDs []uint) error {
+	args := m.Called(claimKey, productIDs)
+	return args.Error(0)
+}
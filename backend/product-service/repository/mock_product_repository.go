@@ -15,22 +15,47 @@ func (m *MockProductRepository) Create(p *models.Product) error {
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) GetAll() ([]models.Product, error) {
-	args := m.Called()
-	return args.Get(0).([]models.Product), args.Error(1)
+func (m *MockProductRepository) List(query models.ProductListQuery) (*models.ProductPage, error) {
+	args := m.Called(query)
+	page, _ := args.Get(0).(*models.ProductPage)
+	return page, args.Error(1)
 }
 
-func (m *MockProductRepository) GetByID(id uint) (*models.Product, error) {
-	args := m.Called(id)
+func (m *MockProductRepository) GetByID(id uint, role string, sellerID uint) (*models.Product, error) {
+	args := m.Called(id, role, sellerID)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Update(p *models.Product) error {
-	args := m.Called(p)
+func (m *MockProductRepository) GetVisibleByIDs(ids []uint) ([]models.Product, error) {
+	args := m.Called(ids)
+	products, _ := args.Get(0).([]models.Product)
+	return products, args.Error(1)
+}
+
+func (m *MockProductRepository) Update(p *models.Product, role string, sellerID uint) error {
+	args := m.Called(p, role, sellerID)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockProductRepository) Delete(id uint, role string, sellerID uint) error {
+	args := m.Called(id, role, sellerID)
 	return args.Error(0)
 }
+
+func (m *MockProductRepository) AdjustStock(productID uint, delta int) (*models.Product, error) {
+	args := m.Called(productID, delta)
+	product, _ := args.Get(0).(*models.Product)
+	return product, args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustBundleStock(bundleID uint, delta int) ([]StockChange, error) {
+	args := m.Called(bundleID, delta)
+	changes, _ := args.Get(0).([]StockChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockProductRepository) ListLowStock(sellerID uint) ([]models.Product, error) {
+	args := m.Called(sellerID)
+	products, _ := args.Get(0).([]models.Product)
+	return products, args.Error(1)
+}
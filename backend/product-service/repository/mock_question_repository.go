@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuestionRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuestionRepository) Create(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) GetByID(id uint) (*models.Question, error) {
+	args := m.Called(id)
+	question, _ := args.Get(0).(*models.Question)
+	return question, args.Error(1)
+}
+
+func (m *MockQuestionRepository) SaveAnswer(question *models.Question) error {
+	args := m.Called(question)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) SetStatus(id uint, status, note string) error {
+	args := m.Called(id, status, note)
+	return args.Error(0)
+}
+
+func (m *MockQuestionRepository) ListByProduct(productID uint, filter QuestionFilter) ([]models.Question, error) {
+	args := m.Called(productID, filter)
+	questions, _ := args.Get(0).([]models.Question)
+	return questions, args.Error(1)
+}
+
+func (m *MockQuestionRepository) ListUnansweredBySeller(sellerID uint, offset, limit int) ([]models.Question, error) {
+	args := m.Called(sellerID, offset, limit)
+	questions, _ := args.Get(0).([]models.Question)
+	return questions, args.Error(1)
+}
+
+func (m *MockQuestionRepository) ListReported(offset, limit int) ([]models.Question, error) {
+	args := m.Called(offset, limit)
+	questions, _ := args.Get(0).([]models.Question)
+	return questions, args.Error(1)
+}
+
+func (m *MockQuestionRepository) AddVote(questionID, userID uint) (bool, error) {
+	args := m.Called(questionID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockQuestionRepository) RemoveVote(questionID, userID uint) (bool, error) {
+	args := m.Called(questionID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockQuestionRepository) AddReport(report *models.QuestionReport) (int, error) {
+	args := m.Called(report)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQuestionRepository) ListOverdue(askedBefore time.Time, limit int) ([]OverdueQuestion, error) {
+	args := m.Called(askedBefore, limit)
+	overdue, _ := args.Get(0).([]OverdueQuestion)
+	return overdue, args.Error(1)
+}
+
+func (m *MockQuestionRepository) MarkSLANotified(ids []uint, at time.Time) error {
+	args := m.Called(ids, at)
+	return args.Error(0)
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRecommendationRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecommendationRepository) SavePurchases(records []models.PurchaseRecord) error {
+	args := m.Called(records)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) GetSyncState(name string) (time.Time, error) {
+	args := m.Called(name)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) SetSyncState(name string, value time.Time) error {
+	args := m.Called(name, value)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) RecordView(buyerID, productID uint, at time.Time) error {
+	args := m.Called(buyerID, productID, at)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) RebuildAssociations() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) ListAssociations(productIDs []uint, limit int) ([]models.ProductAssociation, error) {
+	args := m.Called(productIDs, limit)
+	associations, _ := args.Get(0).([]models.ProductAssociation)
+	return associations, args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ListActiveBuyers(since time.Time) ([]uint, error) {
+	args := m.Called(since)
+	buyerIDs, _ := args.Get(0).([]uint)
+	return buyerIDs, args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ListPurchasedProductIDs(buyerID uint) ([]uint, error) {
+	args := m.Called(buyerID)
+	productIDs, _ := args.Get(0).([]uint)
+	return productIDs, args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ListRecentViews(buyerID uint, limit int) ([]models.ProductView, error) {
+	args := m.Called(buyerID, limit)
+	views, _ := args.Get(0).([]models.ProductView)
+	return views, args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ReplaceBuyerRecommendations(buyerID uint, recs []models.BuyerRecommendation) error {
+	args := m.Called(buyerID, recs)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) ListBuyerRecommendations(buyerID uint, limit int) ([]models.BuyerRecommendation, error) {
+	args := m.Called(buyerID, limit)
+	recs, _ := args.Get(0).([]models.BuyerRecommendation)
+	return recs, args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ListBestsellers(categoryID uint, limit int) ([]uint, error) {
+	args := m.Called(categoryID, limit)
+	productIDs, _ := args.Get(0).([]uint)
+	return productIDs, args.Error(1)
+}
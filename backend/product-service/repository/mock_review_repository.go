@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(r *models.Review) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) GetByID(id uint) (*models.Review, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) ListByProduct(productID uint, status string, offset, limit int) ([]models.Review, error) {
+	args := m.Called(productID, status, offset, limit)
+	return args.Get(0).([]models.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) ExistsForPurchase(productID, buyerID, orderID uint) (bool, error) {
+	args := m.Called(productID, buyerID, orderID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReviewRepository) Update(r *models.Review) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) RecalculateProductRating(productID uint) error {
+	args := m.Called(productID)
+	return args.Error(0)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockReservationRepository) Reserve(orderID uint, items []models.StockReservation) ([]StockChange, error) {
+	args := m.Called(orderID, items)
+	changes, _ := args.Get(0).([]StockChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockStockReservationRepository) Release(orderID uint, productIDs []uint) ([]StockChange, error) {
+	args := m.Called(orderID, productIDs)
+	changes, _ := args.Get(0).([]StockChange)
+	return changes, args.Error(1)
+}
+
+func (m *MockStockReservationRepository) Restock(returnKey string, items []models.StockReturn) ([]StockChange, error) {
+	args := m.Called(returnKey, items)
+	changes, _ := args.Get(0).([]StockChange)
+	return changes, args.Error(1)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTranslationRepository struct {
+	mock.Mock
+}
+
+func (m *MockTranslationRepository) ListProductTranslations(productIDs []uint, locales []string) ([]models.ProductTranslation, error) {
+	args := m.Called(productIDs, locales)
+	translations, _ := args.Get(0).([]models.ProductTranslation)
+	return translations, args.Error(1)
+}
+
+func (m *MockTranslationRepository) ListByProduct(productID uint) ([]models.ProductTranslation, error) {
+	args := m.Called(productID)
+	translations, _ := args.Get(0).([]models.ProductTranslation)
+	return translations, args.Error(1)
+}
+
+func (m *MockTranslationRepository) SaveProductTranslation(translation *models.ProductTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
+
+func (m *MockTranslationRepository) DeleteProductTranslation(productID uint, locale string) (bool, error) {
+	args := m.Called(productID, locale)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTranslationRepository) ListCategories(ids []uint) ([]models.Category, error) {
+	args := m.Called(ids)
+	categories, _ := args.Get(0).([]models.Category)
+	return categories, args.Error(1)
+}
+
+func (m *MockTranslationRepository) ListCategoryTranslations(categoryIDs []uint, locales []string) ([]models.CategoryTranslation, error) {
+	args := m.Called(categoryIDs, locales)
+	translations, _ := args.Get(0).([]models.CategoryTranslation)
+	return translations, args.Error(1)
+}
+
+func (m *MockTranslationRepository) SaveCategoryTranslation(translation *models.CategoryTranslation) error {
+	args := m.Called(translation)
+	return args.Error(0)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) Create(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetByID(id uint) (*models.Wishlist, error) {
+	args := m.Called(id)
+	wishlist, _ := args.Get(0).(*models.Wishlist)
+	return wishlist, args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetByShareToken(token string) (*models.Wishlist, error) {
+	args := m.Called(token)
+	wishlist, _ := args.Get(0).(*models.Wishlist)
+	return wishlist, args.Error(1)
+}
+
+func (m *MockWishlistRepository) ListByBuyer(buyerID uint) ([]models.Wishlist, error) {
+	args := m.Called(buyerID)
+	wishlists, _ := args.Get(0).([]models.Wishlist)
+	return wishlists, args.Error(1)
+}
+
+func (m *MockWishlistRepository) CountByBuyer(buyerID uint) (int64, error) {
+	args := m.Called(buyerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWishlistRepository) Update(wishlist *models.Wishlist) error {
+	args := m.Called(wishlist)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) AddItem(item *models.WishlistItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) CountItems(wishlistID uint) (int64, error) {
+	args := m.Called(wishlistID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWishlistRepository) RemoveItem(wishlistID, productID uint) error {
+	args := m.Called(wishlistID, productID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) ListItemsForPriceCheck(afterID uint, limit int) ([]WishlistItemRef, error) {
+	args := m.Called(afterID, limit)
+	refs, _ := args.Get(0).([]WishlistItemRef)
+	return refs, args.Error(1)
+}
+
+func (m *MockWishlistRepository) SetNotifiedPrice(itemIDs []uint, price float64) error {
+	args := m.Called(itemIDs, price)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Subscribe(buyerID, productID uint) error {
+	args := m.Called(buyerID, productID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Unsubscribe(buyerID, productID uint) error {
+	args := m.Called(buyerID, productID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) ListSubscribers(productID uint) ([]uint, error) {
+	args := m.Called(productID)
+	buyerIDs, _ := args.Get(0).([]uint)
+	return buyerIDs, args.Error(1)
+}
+
+func (m *MockWishlistRepository) DeleteSubscriptions(productID uint, buyerIDs []uint) error {
+	args := m.Called(productID, buyerIDs)
+	return args.Error(0)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusChanged is returned when a product's status changed between read and transition
+var ErrStatusChanged = errors.New("product status was changed by another request")
+
+// ModerationRepository defines the contract for product lifecycle data access
+type ModerationRepository interface {
+	Transition(product *models.Product, entry *models.ProductStatusLog) error
+	ListQueue(flaggedOnly bool, offset, limit int) ([]models.Product, error)
+	ListStatusLogs(productID uint) ([]models.ProductStatusLog, error)
+	SetShopVisibility(shopID, sellerID uint, visible bool) error
+}
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+// NewModerationRepository creates a new ModerationRepository instance
+func NewModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// Transition persists the product's new lifecycle fields and its log entry in one transaction.
+// The update only applies if the product is still in entry.FromStatus.
+func (r *moderationRepository) Transition(product *models.Product, entry *models.ProductStatusLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND status = ?", product.ID, entry.FromStatus).
+			Select("status", "is_active", "flagged", "flag_reasons", "moderation_note").
+			UpdateColumns(product)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+// ListQueue returns products awaiting review, flagged ones first, oldest first
+func (r *moderationRepository) ListQueue(flaggedOnly bool, offset, limit int) ([]models.Product, error) {
+	var products []models.Product
+	query := r.db.Where("status = ?", models.ProductStatusPendingReview)
+	if flaggedOnly {
+		query = query.Where("flagged = ?", true)
+	}
+	err := query.
+		Order("flagged DESC, updated_at ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// ListStatusLogs returns a product's lifecycle history, oldest first
+func (r *moderationRepository) ListStatusLogs(productID uint) ([]models.ProductStatusLog, error) {
+	var logs []models.ProductStatusLog
+	err := r.db.Where("product_id = ?", productID).Order("created_at ASC").Find(&logs).Error
+	return logs, err
+}
+
+// SetShopVisibility shows or hides every product of a shop when it is approved, blocked or removed.
+// Products listed before shops existed have no shop_id; they follow only when sellerID is
+// given, which shop-service does for the seller's oldest shop alone.
+func (r *moderationRepository) SetShopVisibility(shopID, sellerID uint, visible bool) error {
+	return r.db.Model(&models.Product{}).
+		Where("shop_id = ? OR (shop_id = 0 AND seller_id = ? AND seller_id <> 0)", shopID, sellerID).
+		UpdateColumn("shop_visible", visible).Error
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSaleUnavailable is returned when a sale's quantity cap or the buyer's limit is exhausted
+var ErrSaleUnavailable = errors.New("sale quantity or buyer limit exhausted")
+
+// PriceRepository defines the contract for price schedule data access
+type PriceRepository interface {
+	Create(schedule *models.PriceSchedule) error
+	GetByID(id uint) (*models.PriceSchedule, error)
+	ListByProduct(productID uint) ([]models.PriceSchedule, error)
+	Delete(id uint) error
+	ListLive(productIDs []uint, at time.Time) ([]models.PriceSchedule, error)
+	ListLiveFlashSales(at time.Time, limit int) ([]models.PriceSchedule, error)
+	ClaimSale(scheduleID, buyerID uint, quantity int, claimKey string) error
+	ReleaseClaims(claimKey string, productIDs []uint) error
+}
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceRepository creates a new PriceRepository instance
+func NewPriceRepository(db *gorm.DB) PriceRepository {
+	return &priceRepository{db: db}
+}
+
+// Create inserts a new price schedule
+func (r *priceRepository) Create(schedule *models.PriceSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// GetByID fetches a price schedule by ID
+func (r *priceRepository) GetByID(id uint) (*models.PriceSchedule, error) {
+	var schedule models.PriceSchedule
+	if err := r.db.First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListByProduct returns every schedule of a product, soonest first
+func (r *priceRepository) ListByProduct(productID uint) ([]models.PriceSchedule, error) {
+	var schedules []models.PriceSchedule
+	err := r.db.Where("product_id = ?", productID).Order("starts_at ASC").Find(&schedules).Error
+	return schedules, err
+}
+
+// Delete removes a price schedule
+func (r *priceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PriceSchedule{}, id).Error
+}
+
+// liveScope limits a query to schedules running at the given time with stock left
+func liveScope(at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("starts_at <= ? AND ends_at > ?", at, at).
+			Where("quantity_cap = 0 OR sold_count < quantity_cap")
+	}
+}
+
+// ListLive returns schedules running at the given time for the given products, cheapest first
+func (r *priceRepository) ListLive(productIDs []uint, at time.Time) ([]models.PriceSchedule, error) {
+	var schedules []models.PriceSchedule
+	if len(productIDs) == 0 {
+		return schedules, nil
+	}
+	err := r.db.Scopes(liveScope(at)).
+		Where("product_id IN ?", productIDs).
+		Order("sale_price ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ListLiveFlashSales returns running flash sales, ending soonest first
+func (r *priceRepository) ListLiveFlashSales(at time.Time, limit int) ([]models.PriceSchedule, error) {
+	var schedules []models.PriceSchedule
+	err := r.db.Scopes(liveScope(at)).
+		Where("is_flash_sale = ?", true).
+		Order("ends_at ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ClaimSale atomically counts units against a schedule's cap and the buyer's limit,
+// recording them under claimKey so they can be given back. The schedule row stays
+// locked until the claim is saved, so concurrent claims cannot pass the buyer's limit.
+func (r *priceRepository) ClaimSale(scheduleID, buyerID uint, quantity int, claimKey string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var schedule models.PriceSchedule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, scheduleID).Error; err != nil {
+			return err
+		}
+
+		if schedule.PerBuyerLimit > 0 {
+			var bought int64
+			if err := tx.Model(&models.SalePurchase{}).
+				Select("COALESCE(SUM(quantity), 0)").
+				Where("price_schedule_id = ? AND buyer_id = ? AND released_at IS NULL", scheduleID, buyerID).
+				Scan(&bought).Error; err != nil {
+				return err
+			}
+			if int(bought)+quantity > schedule.PerBuyerLimit {
+				return ErrSaleUnavailable
+			}
+		}
+
+		result := tx.Model(&models.PriceSchedule{}).
+			Where("id = ? AND (quantity_cap = 0 OR sold_count + ? <= quantity_cap)", scheduleID, quantity).
+			UpdateColumn("sold_count", gorm.Expr("sold_count + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSaleUnavailable
+		}
+
+		return tx.Create(&models.SalePurchase{
+			PriceScheduleID: scheduleID,
+			BuyerID:         buyerID,
+			Quantity:        quantity,
+			ClaimKey:        claimKey,
+		}).Error
+	})
+}
+
+// ReleaseClaims gives back the sale units claimed under claimKey for the given
+// products, or for all of them when productIDs is empty. Releasing twice is harmless.
+func (r *priceRepository) ReleaseClaims(claimKey string, productIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("claim_key = ? AND released_at IS NULL", claimKey)
+		if len(productIDs) > 0 {
+			query = query.Where("price_schedule_id IN (?)",
+				tx.Model(&models.PriceSchedule{}).Select("id").Where("product_id IN ?", productIDs))
+		}
+		var claimed []models.SalePurchase
+		if err := query.Order("price_schedule_id").Find(&claimed).Error; err != nil {
+			return err
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(claimed))
+		for i, purchase := range claimed {
+			if err := tx.Model(&models.PriceSchedule{}).
+				Where("id = ?", purchase.PriceScheduleID).
+				UpdateColumn("sold_count", gorm.Expr("GREATEST(sold_count - ?, 0)", purchase.Quantity)).Error; err != nil {
+				return err
+			}
+			ids[i] = purchase.ID
+		}
+		return tx.Model(&models.SalePurchase{}).
+			Where("id IN ?", ids).
+			Update("released_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"product-service/models"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// productSort is a keyset ordering: a sort column with id as the tie-breaker,
+// both in the same direction so (column, id) can be compared as a row
+type productSort struct {
+	column    string
+	desc      bool
+	isTime    bool
+	salePrice bool // column is effectivePriceSQL, which takes the listing time twice
+	value     func(p *models.Product) string
+}
+
+// args are the values the sort column's placeholders take
+func (s productSort) args(at time.Time) []interface{} {
+	if s.salePrice {
+		return []interface{}{at, at}
+	}
+	return nil
+}
+
+func floatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// effectivePriceSQL is a product's price with its cheapest running sale applied, the
+// same price the listing shows. Both placeholders take the listing time.
+const effectivePriceSQL = "COALESCE((SELECT MIN(ps.sale_price) FROM price_schedules ps" +
+	" WHERE ps.product_id = products.id AND ps.starts_at <= ? AND ps.ends_at > ?" +
+	" AND (ps.quantity_cap = 0 OR ps.sold_count < ps.quantity_cap)), products.price)"
+
+// productSorts maps public sort keys to their keyset ordering. Price sorts use the
+// effective price, so a product on sale sorts by its sale price.
+var productSorts = map[string]productSort{
+	models.ProductSortNewest: {
+		column: "created_at", desc: true, isTime: true,
+		value: func(p *models.Product) string { return p.CreatedAt.UTC().Format(time.RFC3339Nano) },
+	},
+	models.ProductSortPriceAsc: {
+		column: effectivePriceSQL, salePrice: true,
+	},
+	models.ProductSortPriceDesc: {
+		column: effectivePriceSQL, desc: true, salePrice: true,
+	},
+	models.ProductSortRating: {
+		column: "rating", desc: true,
+		value: func(p *models.Product) string { return floatValue(p.Rating) },
+	},
+	models.ProductSortPopularity: {
+		column: "popularity_score", desc: true,
+		value: func(p *models.Product) string { return floatValue(p.PopularityScore) },
+	},
+}
+
+// productCursor marks the last row of a page. It carries the sort key so a
+// cursor cannot be replayed against a different ordering.
+type productCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func encodeProductCursor(sortKey, value string, id uint) string {
+	raw, _ := json.Marshal(productCursor{Sort: sortKey, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeProductCursor returns the sort value and id to continue after
+func decodeProductCursor(cursor, sortKey string, spec productSort) (interface{}, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var c productCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sortKey || c.ID == 0 {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	if spec.isTime {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		return t, c.ID, nil
+	}
+	v, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return v, c.ID, nil
+}
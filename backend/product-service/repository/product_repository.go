@@ -3,27 +3,51 @@ package repository
 import (
 	"errors"
 	"product-service/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductRepository defines the contract for product data access
 type ProductRepository interface {
 	Create(product *models.Product) error
-	GetAll(role string, sellerID uint, offset, limit int) ([]models.Product, error)
+	List(query models.ProductListQuery) (*models.ProductPage, error)
 	GetByID(id uint, role string, sellerID uint) (*models.Product, error)
-	SearchByName(name string) ([]models.Product, error)
-	FilterProducts(filters map[string]interface{}, offset, limit int) ([]models.Product, error)
+	GetVisibleByIDs(ids []uint) ([]models.Product, error)
 	Update(product *models.Product, role string, sellerID uint) error
 	Delete(id uint, role string, sellerID uint) error
-	IncreaseStock(productID uint, amount int) error
-	DecreaseStock(productID uint, amount int) error
+	AdjustStock(productID uint, delta int) (*models.Product, error)
+	AdjustBundleStock(bundleID uint, delta int) ([]StockChange, error)
+	ListLowStock(sellerID uint) ([]models.Product, error)
+}
+
+var (
+	ErrInsufficientStock = errors.New("not enough stock available")
+	ErrBundleStock       = errors.New("bundle stock is adjusted through its components")
+	ErrEmptyBundle       = errors.New("bundle has no components")
+)
+
+// StockChange is a product after a stock movement, with the quantity it had before
+type StockChange struct {
+	Product models.Product
+	Before  int
 }
 
 type productRepository struct {
 	db *gorm.DB
 }
 
+// publicScope limits a query to products buyers may see: active listings whose shop is
+// approved and unblocked, and which are not auto-hidden for being out of stock
+func publicScope(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ? AND shop_visible = ? AND stock_hidden = ?", models.ProductStatusActive, true, false)
+}
+
+// likeEscaper makes a search term match literally inside a LIKE ... ESCAPE '\' pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // NewProductRepository creates a new ProductRepository instance
 func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db: db}
@@ -34,23 +58,113 @@ func (r *productRepository) Create(product *models.Product) error {
 	return r.db.Create(product).Error
 }
 
-// GetAll retrieves products with pagination, filtered by role
-func (r *productRepository) GetAll(role string, sellerID uint, offset, limit int) ([]models.Product, error) {
-	var products []models.Product
+// List returns one keyset-paginated page of products, scoped by role and filtered.
+// Limit must already be normalized by the caller.
+func (r *productRepository) List(q models.ProductListQuery) (*models.ProductPage, error) {
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = models.ProductSortNewest
+	}
+	spec, ok := productSorts[sortKey]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	at := time.Now()
 	query := r.db.Model(&models.Product{})
+	switch q.Role {
+	case "seller":
+		query = query.Where("seller_id = ?", q.SellerID)
+	case "admin":
+	default:
+		query = query.Scopes(publicScope)
+	}
 
-	if role == "seller" {
-		query = query.Where("seller_id = ?", sellerID)
+	if q.Name != "" {
+		// Match the product's own name or any of its translations
+		pattern := "%" + likeEscaper.Replace(q.Name) + "%"
+		query = query.Where("(LOWER(name) LIKE LOWER(?) ESCAPE '\\' OR EXISTS ("+
+			"SELECT 1 FROM product_translations t WHERE t.product_id = products.id AND LOWER(t.name) LIKE LOWER(?) ESCAPE '\\'))",
+			pattern, pattern)
+	}
+	if q.CategoryID != 0 {
+		query = query.Where("category_id = ?", q.CategoryID)
+	}
+	if q.ShopOwner != 0 {
+		query = query.Where("seller_id = ?", q.ShopOwner)
+	}
+	if q.ShopID != 0 {
+		query = query.Where("shop_id = ?", q.ShopID)
+	}
+	if q.MinPrice != nil {
+		query = query.Where(effectivePriceSQL+" >= ?", at, at, *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		query = query.Where(effectivePriceSQL+" <= ?", at, at, *q.MaxPrice)
+	}
+
+	page := &models.ProductPage{}
+	if q.WithTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	direction, cmp := "ASC", ">"
+	if spec.desc {
+		direction, cmp = "DESC", "<"
+	}
+	if q.Cursor != "" {
+		value, id, err := decodeProductCursor(q.Cursor, sortKey, spec)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("("+spec.column+", id) "+cmp+" (?, ?)", append(spec.args(at), value, id)...)
+	} else if q.Offset > 0 {
+		// Deprecated offset paging, kept for older clients
+		query = query.Offset(q.Offset)
 	}
 
+	// Fetch one extra row to learn whether another page follows
+	var products []models.Product
 	if err := query.
-		Offset(offset).
-		Limit(limit).
-		Order("created_at DESC").
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                spec.column + " " + direction + ", id " + direction,
+			Vars:               spec.args(at),
+			WithoutParentheses: true,
+		}}).
+		Limit(q.Limit + 1).
 		Find(&products).Error; err != nil {
 		return nil, err
 	}
-	return products, nil
+
+	if len(products) > q.Limit {
+		products = products[:q.Limit]
+		cursor, err := r.cursorAfter(sortKey, spec, &products[len(products)-1], at)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = cursor
+	}
+	page.Items = products
+	return page, nil
+}
+
+// cursorAfter encodes the cursor continuing after a page's last product. A price sort
+// continues from the product's effective price at the listing time.
+func (r *productRepository) cursorAfter(sortKey string, spec productSort, last *models.Product, at time.Time) (string, error) {
+	if !spec.salePrice {
+		return encodeProductCursor(sortKey, spec.value(last), last.ID), nil
+	}
+	var price float64
+	err := r.db.Raw("SELECT "+effectivePriceSQL+" FROM products WHERE products.id = ?", at, at, last.ID).
+		Scan(&price).Error
+	if err != nil {
+		return "", err
+	}
+	return encodeProductCursor(sortKey, floatValue(price), last.ID), nil
 }
 
 // GetByID fetches a product by ID, checking role-based access
@@ -59,57 +173,53 @@ func (r *productRepository) GetByID(id uint, role string, sellerID uint) (*model
 	if err := r.db.First(&product, id).Error; err != nil {
 		return nil, err
 	}
-	if role == "seller" && product.SellerID != sellerID {
-		return nil, errors.New("unauthorized: vendor cannot access this product")
+	switch role {
+	case "seller":
+		if product.SellerID != sellerID {
+			return nil, errors.New("unauthorized: vendor cannot access this product")
+		}
+	case "admin":
+	default:
+		if !product.IsListed() {
+			return nil, gorm.ErrRecordNotFound
+		}
 	}
 	return &product, nil
 }
 
-// SearchByName performs a case-insensitive partial match
-func (r *productRepository) SearchByName(name string) ([]models.Product, error) {
+// GetVisibleByIDs loads the publicly visible products among ids, in no particular order
+func (r *productRepository) GetVisibleByIDs(ids []uint) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.
-		Where("LOWER(name) LIKE LOWER(?)", "%"+name+"%").
-		Find(&products).Error
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.Scopes(publicScope).Where("id IN ?", ids).Find(&products).Error
 	return products, err
 }
 
-// FilterProducts applies multiple optional filters
-func (r *productRepository) FilterProducts(filters map[string]interface{}, offset, limit int) ([]models.Product, error) {
-	var products []models.Product
-	query := r.db.Model(&models.Product{})
-
-	if name, ok := filters["name"].(string); ok && name != "" {
-		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+name+"%")
-	}
-	if category, ok := filters["category"].(string); ok && category != "" {
-		query = query.Where("category = ?", category)
-	}
-	if sellerID, ok := filters["seller_id"].(uint); ok && sellerID != 0 {
-		query = query.Where("seller_id = ?", sellerID)
-	}
-	if minPrice, ok := filters["min_price"].(float64); ok {
-		query = query.Where("price >= ?", minPrice)
-	}
-	if maxPrice, ok := filters["max_price"].(float64); ok {
-		query = query.Where("price <= ?", maxPrice)
-	}
-
-	err := query.
-		Offset(offset).
-		Limit(limit).
-		Order("created_at DESC").
-		Find(&products).Error
-
-	return products, err
+// productEditableColumns are what a product edit saves. Lifecycle, moderation, rating
+// and popularity columns only change through their own workflows, so an edit never
+// overwrites them with stale values.
+var productEditableColumns = []string{
+	"Name", "Description", "Price", "Quantity", "ImageURL", "Stock", "ReorderThreshold",
+	"AutoHideOutOfStock", "StockHidden", "Type", "DownloadLimit", "UsesLicenseKeys",
+	"CategoryID", "ShopID", "ShopVisible", "UpdatedAt",
 }
 
-// Update modifies a product, enforcing role access
+// Update saves a product's editable columns, enforcing role access, and recalculates
+// bundles containing it
 func (r *productRepository) Update(product *models.Product, role string, sellerID uint) error {
 	if role == "seller" && product.SellerID != sellerID {
 		return errors.New("unauthorized: vendor cannot update this product")
 	}
-	return r.db.Save(product).Error
+	product.UpdatedAt = time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(product).Select(productEditableColumns).Updates(product).Error; err != nil {
+			return err
+		}
+		_, err := syncBundles(tx, []uint{product.ID})
+		return err
+	})
 }
 
 // Delete removes a product, enforcing role access
@@ -121,19 +231,196 @@ func (r *productRepository) Delete(id uint, role string, sellerID uint) error {
 	if role == "seller" && product.SellerID != sellerID {
 		return errors.New("unauthorized: vendor cannot delete this product")
 	}
-	return r.db.Delete(&product).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		_, err := syncBundles(tx, []uint{id})
+		return err
+	})
 }
 
-// IncreaseStock adds to the product quantity
-func (r *productRepository) IncreaseStock(productID uint, amount int) error {
-	return r.db.Model(&models.Product{}).
-		Where("id = ?", productID).
-		Update("quantity", gorm.Expr("quantity + ?", amount)).Error
+// AdjustStock atomically adds delta to the quantity, refusing to go below zero, and
+// hides or restores the listing when the product auto-hides at zero. Bundles that
+// contain the product are recalculated in the same transaction. It returns the
+// product after the change, or ErrBundleStock for a bundle.
+func (r *productRepository) AdjustStock(productID uint, delta int) (*models.Product, error) {
+	var product *models.Product
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		product, err = productStock(tx, productID, delta)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
-// DecreaseStock subtracts from the product quantity if enough is available
-func (r *productRepository) DecreaseStock(productID uint, amount int) error {
-	return r.db.Model(&models.Product{}).
-		Where("id = ? AND quantity >= ?", productID, amount).
-		Update("quantity", gorm.Expr("quantity - ?", amount)).Error
+// AdjustBundleStock moves delta bundles' worth of stock on every component at once:
+// either all components change or none do. It returns each product whose quantity
+// changed, components first, then the bundles recalculated from them.
+func (r *productRepository) AdjustBundleStock(bundleID uint, delta int) ([]StockChange, error) {
+	var changes []StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		changes, err = bundleStock(tx, bundleID, delta)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// productStock is AdjustStock within the caller's transaction
+func productStock(tx *gorm.DB, productID uint, delta int) (*models.Product, error) {
+	product, err := adjustQuantity(tx.Where("type <> ?", models.ProductTypeBundle), productID, delta)
+	if errors.Is(err, ErrInsufficientStock) {
+		var existing models.Product
+		if err := tx.Select("id", "type").First(&existing, productID).Error; err != nil {
+			return nil, err
+		}
+		if existing.IsBundle() {
+			return nil, ErrBundleStock
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := syncBundles(tx, []uint{productID}); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// bundleStock is AdjustBundleStock within the caller's transaction
+func bundleStock(tx *gorm.DB, bundleID uint, delta int) ([]StockChange, error) {
+	components, err := bundleComponents(tx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	return componentStock(tx, components, delta)
+}
+
+// bundleComponents loads what a bundle holds now, in component order
+func bundleComponents(tx *gorm.DB, bundleID uint) ([]models.BundleComponent, error) {
+	var components []models.BundleComponent
+	// Component order keeps concurrent bundle sales from deadlocking
+	if err := tx.Where("bundle_id = ?", bundleID).Order("component_id").Find(&components).Error; err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, ErrEmptyBundle
+	}
+	return components, nil
+}
+
+// componentStock moves delta bundles' worth of the given components, which must be in
+// component order, and recalculates the bundles containing them
+func componentStock(tx *gorm.DB, components []models.BundleComponent, delta int) ([]StockChange, error) {
+	var changes []StockChange
+	componentIDs := make([]uint, len(components))
+	for i, component := range components {
+		change := delta * component.Quantity
+		product, err := adjustQuantity(tx, component.ComponentID, change)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, StockChange{Product: *product, Before: product.Quantity - change})
+		componentIDs[i] = component.ComponentID
+	}
+
+	bundles, err := syncBundles(tx, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, bundles...), nil
+}
+
+// adjustQuantity applies delta to one product's quantity, never going below zero
+func adjustQuantity(db *gorm.DB, productID uint, delta int) (*models.Product, error) {
+	var product models.Product
+	// SET expressions see the quantity from before the update
+	result := db.Model(&product).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND quantity + ? >= 0", productID, delta).
+		UpdateColumns(map[string]interface{}{
+			"quantity":     gorm.Expr("quantity + ?", delta),
+			"stock_hidden": gorm.Expr("auto_hide_out_of_stock AND quantity + ? <= 0", delta),
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInsufficientStock
+	}
+	return &product, nil
+}
+
+// syncBundles recalculates every bundle containing one of componentIDs as the number of
+// complete sets its components can make, and returns the bundles whose quantity changed.
+// A deleted component leaves its bundles out of stock.
+func syncBundles(db *gorm.DB, componentIDs []uint) ([]StockChange, error) {
+	var before []models.Product
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "quantity").
+		Where("type = ? AND id IN (SELECT bundle_id FROM bundle_components WHERE component_id IN ?)",
+			models.ProductTypeBundle, componentIDs).
+		Order("id").
+		Find(&before).Error; err != nil {
+		return nil, err
+	}
+	if len(before) == 0 {
+		return nil, nil
+	}
+	return recalculateBundles(db, before)
+}
+
+// recalculateBundles derives the quantity of each locked bundle in before from its components
+func recalculateBundles(db *gorm.DB, before []models.Product) ([]StockChange, error) {
+	ids := make([]uint, len(before))
+	previous := make(map[uint]int, len(before))
+	for i, bundle := range before {
+		ids[i] = bundle.ID
+		previous[bundle.ID] = bundle.Quantity
+	}
+
+	var updated []models.Product
+	err := db.Raw(`UPDATE products AS b
+		SET quantity = d.available,
+			stock_hidden = b.auto_hide_out_of_stock AND d.available <= 0,
+			updated_at = ?
+		FROM (
+			SELECT bc.bundle_id,
+				MIN(CASE WHEN c.id IS NULL THEN 0 ELSE c.quantity / bc.quantity END) AS available
+			FROM bundle_components bc
+			LEFT JOIN products c ON c.id = bc.component_id AND c.deleted_at IS NULL
+			WHERE bc.bundle_id IN ?
+			GROUP BY bc.bundle_id
+		) AS d
+		WHERE b.id = d.bundle_id AND b.quantity <> d.available
+		RETURNING b.*`, time.Now(), ids).Scan(&updated).Error
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]StockChange, len(updated))
+	for i := range updated {
+		changes[i] = StockChange{Product: updated[i], Before: previous[updated[i].ID]}
+	}
+	return changes, nil
+}
+
+// ListLowStock returns products at or below their reorder threshold or out of stock,
+// emptiest first. A sellerID of 0 lists every seller's products.
+func (r *productRepository) ListLowStock(sellerID uint) ([]models.Product, error) {
+	query := r.db.Where("(reorder_threshold > 0 AND quantity <= reorder_threshold) OR quantity <= 0")
+	if sellerID != 0 {
+		query = query.Where("seller_id = ?", sellerID)
+	}
+	var products []models.Product
+	err := query.Order("quantity, id").Find(&products).Error
+	return products, err
 }
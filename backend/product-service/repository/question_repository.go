@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Question list orders
+const (
+	QuestionSortTop    = "top"
+	QuestionSortNewest = "newest"
+)
+
+// QuestionFilter narrows a product's published questions
+type QuestionFilter struct {
+	Answered *bool
+	Sort     string
+	Offset   int
+	Limit    int
+}
+
+// OverdueQuestion is an unanswered question with the seller who should answer it
+type OverdueQuestion struct {
+	QuestionID uint
+	SellerID   uint
+	CreatedAt  time.Time
+}
+
+// QuestionRepository defines the contract for product Q&A data access
+type QuestionRepository interface {
+	Create(question *models.Question) error
+	GetByID(id uint) (*models.Question, error)
+	SaveAnswer(question *models.Question) error
+	SetStatus(id uint, status, note string) error
+	ListByProduct(productID uint, filter QuestionFilter) ([]models.Question, error)
+	ListUnansweredBySeller(sellerID uint, offset, limit int) ([]models.Question, error)
+	ListReported(offset, limit int) ([]models.Question, error)
+
+	AddVote(questionID, userID uint) (bool, error)
+	RemoveVote(questionID, userID uint) (bool, error)
+	AddReport(report *models.QuestionReport) (int, error)
+
+	ListOverdue(askedBefore time.Time, limit int) ([]OverdueQuestion, error)
+	MarkSLANotified(ids []uint, at time.Time) error
+}
+
+type questionRepository struct {
+	db *gorm.DB
+}
+
+// NewQuestionRepository creates a new QuestionRepository instance
+func NewQuestionRepository(db *gorm.DB) QuestionRepository {
+	return &questionRepository{db: db}
+}
+
+// Create inserts a new question
+func (r *questionRepository) Create(question *models.Question) error {
+	return r.db.Create(question).Error
+}
+
+// GetByID fetches a single question
+func (r *questionRepository) GetByID(id uint) (*models.Question, error) {
+	var question models.Question
+	if err := r.db.First(&question, id).Error; err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// SaveAnswer writes a question's answer and who gave it, leaving its vote and report
+// counters to the statements that keep them
+func (r *questionRepository) SaveAnswer(question *models.Question) error {
+	return r.db.Model(&models.Question{}).
+		Where("id = ?", question.ID).
+		Updates(map[string]interface{}{
+			"answer":      question.Answer,
+			"answered_by": question.AnsweredBy,
+			"answered_at": question.AnsweredAt,
+			"is_answered": question.IsAnswered,
+		}).Error
+}
+
+// SetStatus changes a question's moderation status and note
+func (r *questionRepository) SetStatus(id uint, status, note string) error {
+	return r.db.Model(&models.Question{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "moderation_note": note}).Error
+}
+
+// ListByProduct returns a page of a product's published questions, most upvoted or newest first
+func (r *questionRepository) ListByProduct(productID uint, filter QuestionFilter) ([]models.Question, error) {
+	query := r.db.Where("product_id = ? AND status = ?", productID, models.QuestionStatusPublished)
+	if filter.Answered != nil {
+		query = query.Where("is_answered = ?", *filter.Answered)
+	}
+	if filter.Sort == QuestionSortNewest {
+		query = query.Order("created_at DESC, id DESC")
+	} else {
+		query = query.Order("upvotes DESC, id DESC")
+	}
+
+	var questions []models.Question
+	err := query.Offset(filter.Offset).Limit(filter.Limit).Find(&questions).Error
+	return questions, err
+}
+
+// ListUnansweredBySeller returns published questions on a seller's products awaiting an answer, oldest first
+func (r *questionRepository) ListUnansweredBySeller(sellerID uint, offset, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.
+		Joins("JOIN products ON products.id = questions.product_id").
+		Where("products.seller_id = ? AND questions.is_answered = ? AND questions.status = ?",
+			sellerID, false, models.QuestionStatusPublished).
+		Order("questions.created_at, questions.id").
+		Offset(offset).
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+// ListReported returns questions with abuse reports, most reported first
+func (r *questionRepository) ListReported(offset, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.
+		Where("report_count > 0 AND status <> ?", models.QuestionStatusRejected).
+		Order("report_count DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+// AddVote records an upvote; it reports false when the user had already voted
+func (r *questionRepository) AddVote(questionID, userID uint) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.QuestionVote{QuestionID: questionID, UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return tx.Model(&models.Question{}).
+			Where("id = ?", questionID).
+			UpdateColumn("upvotes", gorm.Expr("upvotes + 1")).Error
+	})
+	return added, err
+}
+
+// RemoveVote withdraws an upvote; it reports false when there was none
+func (r *questionRepository) RemoveVote(questionID, userID uint) (bool, error) {
+	removed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("question_id = ? AND user_id = ?", questionID, userID).
+			Delete(&models.QuestionVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return tx.Model(&models.Question{}).
+			Where("id = ?", questionID).
+			UpdateColumn("upvotes", gorm.Expr("upvotes - 1")).Error
+	})
+	return removed, err
+}
+
+// AddReport records an abuse report, once per reporter, and returns the question's report count
+func (r *questionRepository) AddReport(report *models.QuestionReport) (int, error) {
+	var count int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&models.Question{}).
+				Where("id = ?", report.QuestionID).
+				UpdateColumn("report_count", gorm.Expr("report_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Question{}).
+			Where("id = ?", report.QuestionID).
+			Pluck("report_count", &count).Error
+	})
+	return count, err
+}
+
+// ListOverdue returns published, unanswered questions asked before the cutoff whose
+// seller has not yet been reminded, oldest first
+func (r *questionRepository) ListOverdue(askedBefore time.Time, limit int) ([]OverdueQuestion, error) {
+	var overdue []OverdueQuestion
+	err := r.db.Model(&models.Question{}).
+		Select("questions.id AS question_id, products.seller_id, questions.created_at").
+		Joins("JOIN products ON products.id = questions.product_id").
+		Where("questions.is_answered = ? AND questions.status = ? AND questions.sla_notified_at IS NULL AND questions.created_at < ?",
+			false, models.QuestionStatusPublished, askedBefore).
+		Order("questions.created_at, questions.id").
+		Limit(limit).
+		Scan(&overdue).Error
+	return overdue, err
+}
+
+// MarkSLANotified records that sellers were reminded about the questions
+func (r *questionRepository) MarkSLANotified(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Question{}).
+		Where("id IN ?", ids).
+		UpdateColumn("sla_notified_at", at).Error
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecommendationRepository defines the contract for recommendation data access
+type RecommendationRepository interface {
+	SavePurchases(records []models.PurchaseRecord) error
+	GetSyncState(name string) (time.Time, error)
+	SetSyncState(name string, value time.Time) error
+	RecordView(buyerID, productID uint, at time.Time) error
+
+	RebuildAssociations() error
+	ListAssociations(productIDs []uint, limit int) ([]models.ProductAssociation, error)
+
+	ListActiveBuyers(since time.Time) ([]uint, error)
+	ListPurchasedProductIDs(buyerID uint) ([]uint, error)
+	ListRecentViews(buyerID uint, limit int) ([]models.ProductView, error)
+	ReplaceBuyerRecommendations(buyerID uint, recs []models.BuyerRecommendation) error
+	ListBuyerRecommendations(buyerID uint, limit int) ([]models.BuyerRecommendation, error)
+
+	ListBestsellers(categoryID uint, limit int) ([]uint, error)
+}
+
+type recommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository creates a new RecommendationRepository instance
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &recommendationRepository{db: db}
+}
+
+// SavePurchases stores imported order lines, skipping ones already imported
+func (r *recommendationRepository) SavePurchases(records []models.PurchaseRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_item_id"}},
+		DoNothing: true,
+	}).Create(&records).Error
+}
+
+// GetSyncState returns a named high-water mark, or the zero time if never set
+func (r *recommendationRepository) GetSyncState(name string) (time.Time, error) {
+	var state models.SyncState
+	err := r.db.First(&state, "name = ?", name).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return state.Value, err
+}
+
+// SetSyncState upserts a named high-water mark
+func (r *recommendationRepository) SetSyncState(name string, value time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&models.SyncState{Name: name, Value: value}).Error
+}
+
+// RecordView upserts a buyer's browsing history for a product
+func (r *recommendationRepository) RecordView(buyerID, productID uint, at time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "buyer_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"view_count":     gorm.Expr("product_views.view_count + 1"),
+			"last_viewed_at": at,
+		}),
+	}).Create(&models.ProductView{BuyerID: buyerID, ProductID: productID, ViewCount: 1, LastViewedAt: at}).Error
+}
+
+// RebuildAssociations recomputes co-purchase scores from all imported orders.
+// The score is the number of distinct orders containing both products.
+func (r *recommendationRepository) RebuildAssociations() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_associations").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO product_associations (product_id, related_product_id, score, updated_at)
+			SELECT a.product_id, b.product_id, COUNT(DISTINCT a.order_id), NOW()
+			FROM purchase_records a
+			JOIN purchase_records b ON a.order_id = b.order_id AND a.product_id <> b.product_id
+			GROUP BY a.product_id, b.product_id`).Error
+	})
+}
+
+// ListAssociations returns the strongest associations of the given products
+func (r *recommendationRepository) ListAssociations(productIDs []uint, limit int) ([]models.ProductAssociation, error) {
+	var associations []models.ProductAssociation
+	if len(productIDs) == 0 {
+		return associations, nil
+	}
+	err := r.db.
+		Where("product_id IN ?", productIDs).
+		Order("score DESC, related_product_id ASC").
+		Limit(limit).
+		Find(&associations).Error
+	return associations, err
+}
+
+// ListActiveBuyers returns buyers who purchased or browsed since the given time
+func (r *recommendationRepository) ListActiveBuyers(since time.Time) ([]uint, error) {
+	var buyerIDs []uint
+	err := r.db.Raw(`
+		SELECT buyer_id FROM purchase_records WHERE purchased_at >= ?
+		UNION
+		SELECT buyer_id FROM product_views WHERE last_viewed_at >= ?
+		ORDER BY buyer_id`, since, since).
+		Scan(&buyerIDs).Error
+	return buyerIDs, err
+}
+
+// ListPurchasedProductIDs returns every product the buyer has received
+func (r *recommendationRepository) ListPurchasedProductIDs(buyerID uint) ([]uint, error) {
+	var productIDs []uint
+	err := r.db.Model(&models.PurchaseRecord{}).
+		Where("buyer_id = ?", buyerID).
+		Distinct().
+		Order("product_id").
+		Pluck("product_id", &productIDs).Error
+	return productIDs, err
+}
+
+// ListRecentViews returns the buyer's most recently viewed products
+func (r *recommendationRepository) ListRecentViews(buyerID uint, limit int) ([]models.ProductView, error) {
+	var views []models.ProductView
+	err := r.db.
+		Where("buyer_id = ?", buyerID).
+		Order("last_viewed_at DESC").
+		Limit(limit).
+		Find(&views).Error
+	return views, err
+}
+
+// ReplaceBuyerRecommendations swaps a buyer's stored recommendations in one transaction
+func (r *recommendationRepository) ReplaceBuyerRecommendations(buyerID uint, recs []models.BuyerRecommendation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("buyer_id = ?", buyerID).Delete(&models.BuyerRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recs) == 0 {
+			return nil
+		}
+		return tx.Create(&recs).Error
+	})
+}
+
+// ListBuyerRecommendations returns a buyer's stored recommendations by rank
+func (r *recommendationRepository) ListBuyerRecommendations(buyerID uint, limit int) ([]models.BuyerRecommendation, error) {
+	var recs []models.BuyerRecommendation
+	err := r.db.
+		Where("buyer_id = ?", buyerID).
+		Order("rank ASC").
+		Limit(limit).
+		Find(&recs).Error
+	return recs, err
+}
+
+// ListBestsellers returns publicly visible products ranked by units sold, optionally
+// within one category. Ties break on product id so results are deterministic.
+func (r *recommendationRepository) ListBestsellers(categoryID uint, limit int) ([]uint, error) {
+	query := r.db.Model(&models.Product{}).
+		Scopes(publicScope).
+		Select("products.id").
+		Joins("LEFT JOIN purchase_records ON purchase_records.product_id = products.id").
+		Group("products.id").
+		Order("COALESCE(SUM(purchase_records.quantity), 0) DESC, products.id ASC").
+		Limit(limit)
+	if categoryID != 0 {
+		query = query.Where("products.category_id = ?", categoryID)
+	}
+
+	var productIDs []uint
+	err := query.Pluck("products.id", &productIDs).Error
+	return productIDs, err
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"gorm.io/gorm"
+)
+
+// ReviewRepository defines the contract for review data access
+type ReviewRepository interface {
+	Create(review *models.Review) error
+	GetByID(id uint) (*models.Review, error)
+	ListByProduct(productID uint, status string, offset, limit int) ([]models.Review, error)
+	ExistsForPurchase(productID, buyerID, orderID uint) (bool, error)
+	Update(review *models.Review) error
+	RecalculateProductRating(productID uint) error
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository creates a new ReviewRepository instance
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// Create inserts a new review
+func (r *reviewRepository) Create(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+// GetByID fetches a single review
+func (r *reviewRepository) GetByID(id uint) (*models.Review, error) {
+	var review models.Review
+	if err := r.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ListByProduct returns a page of reviews for a product, newest first.
+// An empty status returns reviews in every moderation state.
+func (r *reviewRepository) ListByProduct(productID uint, status string, offset, limit int) ([]models.Review, error) {
+	var reviews []models.Review
+	query := r.db.Where("product_id = ?", productID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// ExistsForPurchase reports whether the buyer already reviewed the product for an order
+func (r *reviewRepository) ExistsForPurchase(productID, buyerID, orderID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).
+		Where("product_id = ? AND buyer_id = ? AND order_id = ?", productID, buyerID, orderID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update saves changes to a review
+func (r *reviewRepository) Update(review *models.Review) error {
+	return r.db.Save(review).Error
+}
+
+// RecalculateProductRating refreshes the product's average rating and review count
+// from its published reviews
+func (r *reviewRepository) RecalculateProductRating(productID uint) error {
+	var agg struct {
+		Average float64
+		Total   int
+	}
+	if err := r.db.Model(&models.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS total").
+		Where("product_id = ? AND status = ?", productID, models.ReviewStatusPublished).
+		Scan(&agg).Error; err != nil {
+		return err
+	}
+
+	// UpdateColumns skips the product hooks, which only validate the category
+	return r.db.Model(&models.Product{}).
+		Where("id = ?", productID).
+		UpdateColumns(map[string]interface{}{
+			"rating":       agg.Average,
+			"review_count": agg.Total,
+		}).Error
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockReservationRepository defines the contract for holding stock against orders
+type StockReservationRepository interface {
+	Reserve(orderID uint, items []models.StockReservation) ([]StockChange, error)
+	Release(orderID uint, productIDs []uint) ([]StockChange, error)
+	Restock(returnKey string, items []models.StockReturn) ([]StockChange, error)
+}
+
+type stockReservationRepository struct {
+	db *gorm.DB
+}
+
+// NewStockReservationRepository creates a new StockReservationRepository instance
+func NewStockReservationRepository(db *gorm.DB) StockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+
+// Reserve takes stock for every item of an order in one transaction: either all items
+// are reserved or none are. An order that already holds reservations is left as it is,
+// so retries reserve nothing twice. Items must be sorted by product id. A bundle's
+// reservation records the components it took, so they are what gets put back.
+func (r *stockReservationRepository) Reserve(orderID uint, items []models.StockReservation) ([]StockChange, error) {
+	var changes []StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.StockReservation{}).Where("order_id = ?", orderID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		for i := range items {
+			moved, components, err := takeStock(tx, items[i].ProductID, items[i].Quantity)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, moved...)
+			items[i].OrderID = orderID
+			items[i].Components = nil
+			for _, component := range components {
+				items[i].Components = append(items[i].Components, models.StockReservationComponent{
+					ComponentID: component.ComponentID,
+					Quantity:    component.Quantity,
+				})
+			}
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Release puts back the stock an order still holds for the given products, or for all
+// of them when productIDs is empty. Releasing twice is harmless.
+func (r *stockReservationRepository) Release(orderID uint, productIDs []uint) ([]StockChange, error) {
+	var changes []StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND released_at IS NULL", orderID)
+		if len(productIDs) > 0 {
+			query = query.Where("product_id IN ?", productIDs)
+		}
+		var held []models.StockReservation
+		if err := query.Preload("Components").Order("product_id").Find(&held).Error; err != nil {
+			return err
+		}
+
+		for _, reservation := range held {
+			moved, err := putBack(tx, &reservation, reservation.ProductID, reservation.Quantity)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, moved...)
+		}
+		if len(held) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(held))
+		for i, reservation := range held {
+			ids[i] = reservation.ID
+		}
+		return tx.Model(&models.StockReservation{}).
+			Where("id IN ?", ids).
+			Update("released_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Restock puts returned goods back in stock in one transaction. A return key that was
+// restocked before is left as it is, so retries count nothing twice. Items must be
+// sorted by product id. A returned bundle puts back the components its order reserved.
+func (r *stockReservationRepository) Restock(returnKey string, items []models.StockReturn) ([]StockChange, error) {
+	var changes []StockChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.StockReturn{}).Where("return_key = ?", returnKey).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		for i := range items {
+			var reservation models.StockReservation
+			err := tx.Preload("Components").
+				Where("order_id = ? AND product_id = ?", items[i].OrderID, items[i].ProductID).
+				First(&reservation).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				reservation = models.StockReservation{}
+			} else if err != nil {
+				return err
+			}
+			moved, err := putBack(tx, &reservation, items[i].ProductID, items[i].Quantity)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, moved...)
+			items[i].ReturnKey = returnKey
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// takeStock takes quantity of a product or, for a bundle, of its components within tx.
+// For a bundle it also returns the components it took one bundle's worth of.
+func takeStock(tx *gorm.DB, productID uint, quantity int) ([]StockChange, []models.BundleComponent, error) {
+	product, err := productStock(tx, productID, -quantity)
+	if errors.Is(err, ErrBundleStock) {
+		components, err := bundleComponents(tx, productID)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes, err := componentStock(tx, components, -quantity)
+		return changes, components, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []StockChange{{Product: *product, Before: product.Quantity + quantity}}, nil, nil
+}
+
+// putBack returns quantity of a product within tx. A bundle puts back the components
+// recorded with its reservation; one reserved without them, or not reserved at all,
+// falls back to what the bundle holds now.
+func putBack(tx *gorm.DB, reservation *models.StockReservation, productID uint, quantity int) ([]StockChange, error) {
+	if len(reservation.Components) > 0 {
+		components := make([]models.BundleComponent, len(reservation.Components))
+		for i, component := range reservation.Components {
+			components[i] = models.BundleComponent{ComponentID: component.ComponentID, Quantity: component.Quantity}
+		}
+		sort.Slice(components, func(i, j int) bool { return components[i].ComponentID < components[j].ComponentID })
+		return componentStock(tx, components, quantity)
+	}
+	product, err := productStock(tx, productID, quantity)
+	if errors.Is(err, ErrBundleStock) {
+		return bundleStock(tx, productID, quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []StockChange{{Product: *product, Before: product.Quantity - quantity}}, nil
+}
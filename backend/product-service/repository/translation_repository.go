@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranslationRepository defines the contract for localized product and category content
+type TranslationRepository interface {
+	ListProductTranslations(productIDs []uint, locales []string) ([]models.ProductTranslation, error)
+	ListByProduct(productID uint) ([]models.ProductTranslation, error)
+	SaveProductTranslation(translation *models.ProductTranslation) error
+	DeleteProductTranslation(productID uint, locale string) (bool, error)
+
+	ListCategories(ids []uint) ([]models.Category, error)
+	ListCategoryTranslations(categoryIDs []uint, locales []string) ([]models.CategoryTranslation, error)
+	SaveCategoryTranslation(translation *models.CategoryTranslation) error
+}
+
+type translationRepository struct {
+	db *gorm.DB
+}
+
+// NewTranslationRepository creates a new TranslationRepository instance
+func NewTranslationRepository(db *gorm.DB) TranslationRepository {
+	return &translationRepository{db: db}
+}
+
+// ListProductTranslations returns the products' translations in any of the locales
+func (r *translationRepository) ListProductTranslations(productIDs []uint, locales []string) ([]models.ProductTranslation, error) {
+	var translations []models.ProductTranslation
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+	err := r.db.Where("product_id IN ? AND locale IN ?", productIDs, locales).Find(&translations).Error
+	return translations, err
+}
+
+// ListByProduct returns every translation of a product
+func (r *translationRepository) ListByProduct(productID uint) ([]models.ProductTranslation, error) {
+	var translations []models.ProductTranslation
+	err := r.db.Where("product_id = ?", productID).Order("locale").Find(&translations).Error
+	return translations, err
+}
+
+// SaveProductTranslation inserts or replaces a product's translation for its locale
+func (r *translationRepository) SaveProductTranslation(translation *models.ProductTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(translation).Error
+}
+
+// DeleteProductTranslation removes a translation; it reports false when there was none
+func (r *translationRepository) DeleteProductTranslation(productID uint, locale string) (bool, error) {
+	result := r.db.Where("product_id = ? AND locale = ?", productID, locale).Delete(&models.ProductTranslation{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListCategories returns the given categories, or all of them when ids is nil, by name
+func (r *translationRepository) ListCategories(ids []uint) ([]models.Category, error) {
+	var categories []models.Category
+	query := r.db.Order("name")
+	if ids != nil {
+		if len(ids) == 0 {
+			return categories, nil
+		}
+		query = query.Where("id IN ?", ids)
+	}
+	err := query.Find(&categories).Error
+	return categories, err
+}
+
+// ListCategoryTranslations returns the categories' translations in any of the locales
+func (r *translationRepository) ListCategoryTranslations(categoryIDs []uint, locales []string) ([]models.CategoryTranslation, error) {
+	var translations []models.CategoryTranslation
+	query := r.db.Where("locale IN ?", locales)
+	if categoryIDs != nil {
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+	err := query.Find(&translations).Error
+	return translations, err
+}
+
+// SaveCategoryTranslation inserts or replaces a category's translation for its locale
+func (r *translationRepository) SaveCategoryTranslation(translation *models.CategoryTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(translation).Error
+}
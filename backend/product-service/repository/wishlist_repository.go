@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"product-service/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishlistItemRef is a saved product with the buyer who saved it, used for price checks
+type WishlistItemRef struct {
+	ItemID        uint
+	BuyerID       uint
+	ProductID     uint
+	SavedPrice    float64
+	NotifiedPrice float64
+}
+
+// WishlistRepository defines the contract for wishlist and stock subscription data access
+type WishlistRepository interface {
+	Create(wishlist *models.Wishlist) error
+	GetByID(id uint) (*models.Wishlist, error)
+	GetByShareToken(token string) (*models.Wishlist, error)
+	ListByBuyer(buyerID uint) ([]models.Wishlist, error)
+	CountByBuyer(buyerID uint) (int64, error)
+	Update(wishlist *models.Wishlist) error
+	Delete(id uint) error
+
+	AddItem(item *models.WishlistItem) error
+	CountItems(wishlistID uint) (int64, error)
+	RemoveItem(wishlistID, productID uint) error
+	ListItemsForPriceCheck(afterID uint, limit int) ([]WishlistItemRef, error)
+	SetNotifiedPrice(itemIDs []uint, price float64) error
+
+	Subscribe(buyerID, productID uint) error
+	Unsubscribe(buyerID, productID uint) error
+	ListSubscribers(productID uint) ([]uint, error)
+	DeleteSubscriptions(productID uint, buyerIDs []uint) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository creates a new WishlistRepository instance
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+// Create inserts a new, empty wishlist
+func (r *wishlistRepository) Create(wishlist *models.Wishlist) error {
+	return r.db.Omit("Items").Create(wishlist).Error
+}
+
+// GetByID fetches a wishlist with its items, oldest first
+func (r *wishlistRepository) GetByID(id uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := r.withItems(r.db).First(&wishlist, id).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// GetByShareToken fetches a shared wishlist with its items
+func (r *wishlistRepository) GetByShareToken(token string) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := r.withItems(r.db).Where("share_token = ?", token).First(&wishlist).Error; err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// ListByBuyer returns a buyer's wishlists with their items, oldest list first
+func (r *wishlistRepository) ListByBuyer(buyerID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.withItems(r.db).
+		Where("buyer_id = ?", buyerID).
+		Order("id").
+		Find(&wishlists).Error
+	return wishlists, err
+}
+
+// CountByBuyer returns how many wishlists a buyer has
+func (r *wishlistRepository) CountByBuyer(buyerID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Wishlist{}).Where("buyer_id = ?", buyerID).Count(&count).Error
+	return count, err
+}
+
+// Update saves a wishlist's name and share token
+func (r *wishlistRepository) Update(wishlist *models.Wishlist) error {
+	return r.db.Model(wishlist).
+		Select("name", "share_token", "updated_at").
+		Updates(wishlist).Error
+}
+
+// Delete removes a wishlist and its items
+func (r *wishlistRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&models.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wishlist{}, id).Error
+	})
+}
+
+// AddItem saves a product to a wishlist; saving it again keeps the original saved price
+func (r *wishlistRepository) AddItem(item *models.WishlistItem) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+}
+
+// CountItems returns how many products a wishlist holds
+func (r *wishlistRepository) CountItems(wishlistID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WishlistItem{}).Where("wishlist_id = ?", wishlistID).Count(&count).Error
+	return count, err
+}
+
+// RemoveItem drops a product from a wishlist
+func (r *wishlistRepository) RemoveItem(wishlistID, productID uint) error {
+	return r.db.
+		Where("wishlist_id = ? AND product_id = ?", wishlistID, productID).
+		Delete(&models.WishlistItem{}).Error
+}
+
+// ListItemsForPriceCheck returns a page of saved items across all wishlists, ordered by item id
+func (r *wishlistRepository) ListItemsForPriceCheck(afterID uint, limit int) ([]WishlistItemRef, error) {
+	var refs []WishlistItemRef
+	err := r.db.Model(&models.WishlistItem{}).
+		Select("wishlist_items.id AS item_id, wishlists.buyer_id, wishlist_items.product_id, "+
+			"wishlist_items.saved_price, wishlist_items.notified_price").
+		Joins("JOIN wishlists ON wishlists.id = wishlist_items.wishlist_id").
+		Where("wishlist_items.id > ?", afterID).
+		Order("wishlist_items.id").
+		Limit(limit).
+		Scan(&refs).Error
+	return refs, err
+}
+
+// SetNotifiedPrice records the price buyers were last alerted about
+func (r *wishlistRepository) SetNotifiedPrice(itemIDs []uint, price float64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.WishlistItem{}).
+		Where("id IN ?", itemIDs).
+		UpdateColumn("notified_price", price).Error
+}
+
+// Subscribe asks for a back-in-stock notification; subscribing twice is a no-op
+func (r *wishlistRepository) Subscribe(buyerID, productID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.StockSubscription{BuyerID: buyerID, ProductID: productID}).Error
+}
+
+// Unsubscribe cancels a back-in-stock notification
+func (r *wishlistRepository) Unsubscribe(buyerID, productID uint) error {
+	return r.db.
+		Where("buyer_id = ? AND product_id = ?", buyerID, productID).
+		Delete(&models.StockSubscription{}).Error
+}
+
+// ListSubscribers returns the buyers waiting for a product to return
+func (r *wishlistRepository) ListSubscribers(productID uint) ([]uint, error) {
+	var buyerIDs []uint
+	err := r.db.Model(&models.StockSubscription{}).
+		Where("product_id = ?", productID).
+		Order("id").
+		Pluck("buyer_id", &buyerIDs).Error
+	return buyerIDs, err
+}
+
+// DeleteSubscriptions removes subscriptions that have been fulfilled
+func (r *wishlistRepository) DeleteSubscriptions(productID uint, buyerIDs []uint) error {
+	if len(buyerIDs) == 0 {
+		return nil
+	}
+	return r.db.
+		Where("product_id = ? AND buyer_id IN ?", productID, buyerIDs).
+		Delete(&models.StockSubscription{}).Error
+}
+
+// withItems preloads wishlist items, oldest first
+func (r *wishlistRepository) withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("wishlist_items.id")
+	})
+}
@@ -7,13 +7,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterProductRoutes(r *gin.Engine, productController *controllers.ProductController) {
+func RegisterProductRoutes(r *gin.Engine, productController *controllers.ProductController, reviewController *controllers.ReviewController, pricingController *controllers.PricingController, moderationController *controllers.ModerationController, recommendationController *controllers.RecommendationController, analyticsController *controllers.AnalyticsController, wishlistController *controllers.WishlistController, questionController *controllers.QuestionController, localizationController *controllers.LocalizationController, digitalController *controllers.DigitalController, bundleController *controllers.BundleController, stockController *controllers.StockController) {
 	// Public routes
 	product := r.Group("/api/products")
 	{
 		product.GET("/", productController.GetAll)                    // 📦 List all products
 		product.GET("/:id", productController.GetByID)                // 🔍 Get product by ID
 		product.GET("/search", productController.SearchProduct)       // 🔍 Search product by name or ID
+		product.GET("/flash-sales", pricingController.ListFlashSales) // ⚡ Live flash sales
+		product.GET("/:id/prices", pricingController.ListSchedules)   // 📅 Price schedules
+		product.GET("/:id/reviews", reviewController.ListReviews)     // ⭐ List published reviews
+		product.GET("/:id/frequently-bought-together", recommendationController.FrequentlyBoughtTogether) // 🛒 Co-purchases
+		product.GET("/:id/questions", questionController.List)        // ❓ Published questions and answers
 	}
 
 	// Protected routes (seller only)
@@ -24,5 +29,116 @@ func RegisterProductRoutes(r *gin.Engine, productController *controllers.Product
 		protected.PUT("/:id", productController.UpdateProduct)       // ✏️ Update existing product
 		protected.DELETE("/:id", productController.DeleteProduct)    // ❌ Delete product
 		protected.POST("/adjust-stock", productController.AdjustStock) // 🔧 Adjust stock (internal/seller)
+		protected.GET("/low-stock", productController.ListLowStock)    // 📉 At or below reorder threshold
+		protected.POST("/:id/reviews", reviewController.CreateReview) // ⭐ Review a delivered product (buyer)
+		protected.POST("/:id/prices", pricingController.CreateSchedule) // 🏷️ Schedule a sale price
+		protected.DELETE("/prices/:id", pricingController.DeleteSchedule) // ❌ Remove a sale price
+		protected.PATCH("/:id/status", moderationController.ChangeStatus) // 🔄 Lifecycle transition
+		protected.GET("/:id/history", moderationController.History)       // 📜 Lifecycle history
+		protected.POST("/:id/views", recommendationController.RecordView) // 👀 Browsing history
+		protected.POST("/:id/events", productController.TrackEvent)       // 📈 Client events (add to cart)
+		protected.POST("/:id/stock-subscription", wishlistController.SubscribeStock)     // 🔔 Notify when back in stock
+		protected.DELETE("/:id/stock-subscription", wishlistController.UnsubscribeStock) // 🔕 Cancel notification
+		protected.POST("/:id/questions", questionController.Ask)                         // ❓ Ask the seller (buyer)
+		protected.GET("/:id/translations", localizationController.ListProductTranslations)            // 🌐 Product translations
+		protected.PUT("/:id/translations/:locale", localizationController.SetProductTranslation)      // ✏️ Translate name/description
+		protected.DELETE("/:id/translations/:locale", localizationController.DeleteProductTranslation) // ❌ Remove translation
+		protected.POST("/:id/files", digitalController.UploadFile)                 // 📎 Attach a download (digital)
+		protected.GET("/:id/files", digitalController.ListFiles)                   // 📂 Attached downloads
+		protected.DELETE("/:id/files/:file_id", digitalController.DeleteFile)      // ❌ Remove a download
+		protected.POST("/:id/license-keys", digitalController.AddKeys)             // 🔑 Add keys to the pool
+		protected.GET("/:id/license-keys", digitalController.KeyPool)              // 🔢 Available/assigned keys
+		protected.PUT("/:id/components", bundleController.SetComponents)           // 🧩 Bundle contents
+	}
+
+	// Shop catalogue
+	shops := r.Group("/api/shops")
+	{
+		shops.GET("/:id/products", productController.ListShopProducts) // 🏪 A shop's visible products
+	}
+
+	// Categories
+	categories := r.Group("/api/categories")
+	{
+		categories.GET("/", localizationController.ListCategories)                                                      // 🗂️ Localized categories
+		categories.PUT("/:id/translations/:locale", middleware.RequireAuth(), localizationController.SetCategoryTranslation) // 🏷️ Translate name (admin)
+	}
+
+	// Recommendations
+	recommendations := r.Group("/api/recommendations")
+	{
+		recommendations.GET("/bestsellers", recommendationController.Bestsellers)                      // 🏆 Category bestsellers
+		recommendations.GET("/for-you", middleware.RequireAuth(), recommendationController.ForYou) // 💡 Personalised
+	}
+
+	// Wishlists
+	r.GET("/api/wishlists/shared/:token", wishlistController.GetShared) // 👀 Shared wishlist (public)
+	wishlists := r.Group("/api/wishlists")
+	wishlists.Use(middleware.RequireAuth())
+	{
+		wishlists.GET("/", wishlistController.List)                                // 📋 My wishlists
+		wishlists.POST("/", wishlistController.Create)                             // 💖 New wishlist
+		wishlists.GET("/:id", wishlistController.Get)                              // 🔍 One wishlist
+		wishlists.PUT("/:id", wishlistController.Rename)                           // ✏️ Rename
+		wishlists.DELETE("/:id", wishlistController.Delete)                        // ❌ Delete
+		wishlists.POST("/:id/items", wishlistController.AddItem)                   // ➕ Save product
+		wishlists.DELETE("/:id/items/:product_id", wishlistController.RemoveItem)  // ➖ Remove product
+		wishlists.POST("/:id/share", wishlistController.Share)                     // 🔗 Create share link
+		wishlists.DELETE("/:id/share", wishlistController.Unshare)                 // 🚫 Revoke share link
+	}
+
+	// Digital purchases
+	r.GET("/api/digital/downloads/:id/:file_id", digitalController.Download) // ⬇️ Signed download (public)
+	digital := r.Group("/api/digital")
+	digital.Use(middleware.RequireAuth())
+	{
+		digital.GET("/library", digitalController.Library)                                  // 📚 My keys and files
+		digital.POST("/purchases/:id/files/:file_id/link", digitalController.CreateDownloadLink) // 🔗 Signed download link
+	}
+
+	// Product Q&A
+	questions := r.Group("/api/questions")
+	questions.Use(middleware.RequireAuth())
+	{
+		questions.GET("/unanswered", questionController.ListUnanswered) // 📥 Seller inbox
+		questions.GET("/reported", questionController.ListReported)     // 🗂️ Admin review queue
+		questions.POST("/:id/answer", questionController.Answer)        // 💬 Seller answer
+		questions.POST("/:id/upvote", questionController.Upvote)        // 👍 Upvote
+		questions.DELETE("/:id/upvote", questionController.RemoveUpvote) // 👎 Remove upvote
+		questions.POST("/:id/report", questionController.Report)        // 🚩 Report abuse
+		questions.PATCH("/:id/moderate", questionController.Moderate)   // 🛡️ Admin moderation
+	}
+
+	// Seller analytics
+	analytics := r.Group("/api/analytics")
+	analytics.Use(middleware.RequireAuth())
+	{
+		analytics.GET("/products", analyticsController.ProductReport) // 📊 Daily views and conversion
+	}
+
+	// Review management (seller reply, admin moderation)
+	reviews := r.Group("/api/reviews")
+	reviews.Use(middleware.RequireAuth())
+	{
+		reviews.POST("/:id/reply", reviewController.ReplyToReview)      // 💬 Seller reply
+		reviews.PATCH("/:id/moderate", reviewController.ModerateReview) // 🛡️ Admin moderation
+	}
+
+	// Internal service-to-service routes
+	internal := r.Group("/internal/products")
+	internal.Use(middleware.RequireAPIKey())
+	{
+		internal.POST("/prices/quote", pricingController.Quote) // 🧾 Effective prices (no reservation)
+		internal.POST("/prices/claim", pricingController.Claim) // 🧾 Effective prices for order placement
+		internal.POST("/prices/release", pricingController.ReleaseClaims) // ↩️ Give back an order's sale units
+		internal.POST("/availability", pricingController.Availability) // 🛒 Price and stock for carts (order-service)
+		internal.GET("/moderation/queue", moderationController.Queue)              // 🗂️ Review queue (admin-service)
+		internal.POST("/:id/status", moderationController.AdminChangeStatus)       // 🛡️ Approve/reject/suspend (admin-service)
+		internal.PUT("/shop-visibility", moderationController.SetShopVisibility) // 🏪 Shop approved/blocked (shop-service)
+		internal.POST("/digital/fulfil", digitalController.Fulfil)               // 📦 Deliver paid digital items (order-service)
+		internal.POST("/stock/reserve", stockController.Reserve)                 // 📦 Hold stock for a checkout (order-service)
+		internal.POST("/stock/release", stockController.Release)                 // ↩️ Return a checkout's stock (order-service)
+		internal.POST("/stock/restock", stockController.Restock)                 // 🔄 Put returned goods back (order-service)
+		internal.POST("/events/order", productController.RecordOrder)            // 🧾 Count a placed order (order-service)
 	}
 }
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+const (
+	eventBufferSize    = 10000
+	eventBatchSize     = 1000
+	eventFlushInterval = 5 * time.Second
+
+	maxAnalyticsRange = 90 * 24 * time.Hour
+	popularityWindow  = 7 * 24 * time.Hour
+)
+
+// popularityWeights favour events closer to a sale
+var popularityWeights = repository.PopularityWeights{View: 1, AddToCart: 3, Order: 10}
+
+var ErrInvalidDateRange = errors.New("to must not be before from, and the range may span at most 90 days")
+
+// AnalyticsRecorder accepts product events without blocking the request path
+type AnalyticsRecorder interface {
+	Record(productID uint, eventType string)
+}
+
+type analyticsEvent struct {
+	productID uint
+	eventType string
+	at        time.Time
+}
+
+// EventRecorder buffers analytics events in memory and writes them as daily
+// counter increments in batches. Events are dropped, not queued, when the buffer is full.
+type EventRecorder struct {
+	repo          repository.AnalyticsRepository
+	events        chan analyticsEvent
+	batchSize     int
+	flushInterval time.Duration
+	now           func() time.Time
+	dropped       atomic.Int64
+}
+
+func NewEventRecorder(repo repository.AnalyticsRepository) *EventRecorder {
+	return &EventRecorder{
+		repo:          repo,
+		events:        make(chan analyticsEvent, eventBufferSize),
+		batchSize:     eventBatchSize,
+		flushInterval: eventFlushInterval,
+		now:           time.Now,
+	}
+}
+
+// Record queues an event; it never blocks
+func (r *EventRecorder) Record(productID uint, eventType string) {
+	select {
+	case r.events <- analyticsEvent{productID: productID, eventType: eventType, at: r.now()}:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Run writes queued events until ctx is cancelled, then flushes what is left
+func (r *EventRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make(map[statKey]*models.ProductDailyStat)
+	count := 0
+	for {
+		select {
+		case event := <-r.events:
+			addEvent(batch, event)
+			if count++; count >= r.batchSize {
+				r.flush(batch)
+				batch, count = make(map[statKey]*models.ProductDailyStat), 0
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch, count = make(map[statKey]*models.ProductDailyStat), 0
+		case <-ctx.Done():
+			for {
+				select {
+				case event := <-r.events:
+					addEvent(batch, event)
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+type statKey struct {
+	productID uint
+	day       time.Time
+}
+
+// addEvent counts an event against its product's UTC day
+func addEvent(batch map[statKey]*models.ProductDailyStat, event analyticsEvent) {
+	at := event.at.UTC()
+	key := statKey{productID: event.productID, day: time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)}
+	stat, ok := batch[key]
+	if !ok {
+		stat = &models.ProductDailyStat{ProductID: key.productID, Day: key.day}
+		batch[key] = stat
+	}
+	switch event.eventType {
+	case models.EventImpression:
+		stat.Impressions++
+	case models.EventDetailView:
+		stat.Views++
+	case models.EventAddToCart:
+		stat.AddToCarts++
+	case models.EventOrder:
+		stat.Orders++
+	}
+}
+
+// flush writes a batch in a stable order; a failed batch is logged and discarded
+func (r *EventRecorder) flush(batch map[statKey]*models.ProductDailyStat) {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		log.Printf("⚠️ analytics buffer full, dropped %d events", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	deltas := make([]models.ProductDailyStat, 0, len(batch))
+	updatedAt := r.now()
+	for _, stat := range batch {
+		stat.UpdatedAt = updatedAt
+		deltas = append(deltas, *stat)
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].ProductID != deltas[j].ProductID {
+			return deltas[i].ProductID < deltas[j].ProductID
+		}
+		return deltas[i].Day.Before(deltas[j].Day)
+	})
+
+	if err := r.repo.IncrementDailyStats(deltas); err != nil {
+		log.Printf("⚠️ failed to write %d analytics rows: %v", len(deltas), err)
+	}
+}
+
+// StatCounts are event totals with derived rates
+type StatCounts struct {
+	Impressions    int64   `json:"impressions"`
+	Views          int64   `json:"views"`
+	AddToCarts     int64   `json:"add_to_carts"`
+	Orders         int64   `json:"orders"`
+	ViewRate       float64 `json:"view_rate"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// DailyStat is one day of a product's analytics
+type DailyStat struct {
+	Day string `json:"day"`
+	StatCounts
+}
+
+// ProductAnalytics is a product's totals and daily breakdown over a date range
+type ProductAnalytics struct {
+	ProductID uint        `json:"product_id"`
+	Totals    StatCounts  `json:"totals"`
+	Daily     []DailyStat `json:"daily"`
+}
+
+type AnalyticsService interface {
+	Report(role string, sellerID, productID uint, from, to time.Time) ([]ProductAnalytics, error)
+	RefreshPopularity() error
+}
+
+type analyticsService struct {
+	repo        repository.AnalyticsRepository
+	productRepo repository.ProductRepository
+	now         func() time.Time
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, productRepo repository.ProductRepository) AnalyticsService {
+	return &analyticsService{repo: repo, productRepo: productRepo, now: time.Now}
+}
+
+// Report aggregates daily stats per product. Sellers only see their own products.
+func (s *analyticsService) Report(role string, sellerID, productID uint, from, to time.Time) ([]ProductAnalytics, error) {
+	if to.Before(from) || to.Sub(from) > maxAnalyticsRange {
+		return nil, ErrInvalidDateRange
+	}
+
+	scope := uint(0)
+	if role == "seller" {
+		scope = sellerID
+		if productID != 0 {
+			if _, err := s.productRepo.GetByID(productID, role, sellerID); err != nil {
+				return nil, ErrNotProductOwner
+			}
+		}
+	}
+
+	stats, err := s.repo.ListDailyStats(scope, productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows arrive ordered by product, then day
+	report := []ProductAnalytics{}
+	for i := range stats {
+		stat := &stats[i]
+		if len(report) == 0 || report[len(report)-1].ProductID != stat.ProductID {
+			report = append(report, ProductAnalytics{ProductID: stat.ProductID, Daily: []DailyStat{}})
+		}
+		current := &report[len(report)-1]
+		current.Daily = append(current.Daily, DailyStat{Day: stat.Day.Format("2006-01-02"), StatCounts: countsOf(stat)})
+		current.Totals.Impressions += stat.Impressions
+		current.Totals.Views += stat.Views
+		current.Totals.AddToCarts += stat.AddToCarts
+		current.Totals.Orders += stat.Orders
+	}
+	for i := range report {
+		totals := models.ProductDailyStat{
+			Impressions: report[i].Totals.Impressions,
+			Views:       report[i].Totals.Views,
+			AddToCarts:  report[i].Totals.AddToCarts,
+			Orders:      report[i].Totals.Orders,
+		}
+		report[i].Totals = countsOf(&totals)
+	}
+	return report, nil
+}
+
+// RefreshPopularity recomputes the score behind the popularity sort from the last week of events
+func (s *analyticsService) RefreshPopularity() error {
+	since := s.now().UTC().Add(-popularityWindow).Truncate(24 * time.Hour)
+	return s.repo.RefreshPopularity(since, popularityWeights)
+}
+
+func countsOf(stat *models.ProductDailyStat) StatCounts {
+	return StatCounts{
+		Impressions:    stat.Impressions,
+		Views:          stat.Views,
+		AddToCarts:     stat.AddToCarts,
+		Orders:         stat.Orders,
+		ViewRate:       stat.ViewRate(),
+		ConversionRate: stat.ConversionRate(),
+	}
+}
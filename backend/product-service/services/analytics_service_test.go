@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var analyticsNow = time.Date(2025, 8, 1, 23, 30, 0, 0, time.UTC)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEventRecorder_AggregatesPerProductDayOnShutdown(t *testing.T) {
+	repo := new(repository.MockAnalyticsRepository)
+	recorder := NewEventRecorder(repo)
+	recorder.now = func() time.Time { return analyticsNow }
+
+	recorder.Record(2, models.EventImpression)
+	recorder.Record(1, models.EventImpression)
+	recorder.Record(1, models.EventDetailView)
+	recorder.Record(1, models.EventAddToCart)
+	recorder.Record(1, models.EventImpression)
+
+	repo.On("IncrementDailyStats", []models.ProductDailyStat{
+		{ProductID: 1, Day: day(2025, 8, 1), Impressions: 2, Views: 1, AddToCarts: 1, UpdatedAt: analyticsNow},
+		{ProductID: 2, Day: day(2025, 8, 1), Impressions: 1, UpdatedAt: analyticsNow},
+	}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.Run(ctx)
+
+	repo.AssertExpectations(t)
+}
+
+func TestEventRecorder_DropsWhenBufferIsFull(t *testing.T) {
+	repo := new(repository.MockAnalyticsRepository)
+	recorder := NewEventRecorder(repo)
+	recorder.events = make(chan analyticsEvent, 1)
+
+	recorder.Record(1, models.EventImpression)
+	recorder.Record(1, models.EventImpression)
+
+	assert.Equal(t, int64(1), recorder.dropped.Load())
+}
+
+func TestReport_GroupsDailyRowsAndComputesRates(t *testing.T) {
+	repo := new(repository.MockAnalyticsRepository)
+	service := &analyticsService{repo: repo, now: func() time.Time { return analyticsNow }}
+
+	from, to := day(2025, 7, 1), day(2025, 7, 31)
+	repo.On("ListDailyStats", uint(0), uint(0), from, to).Return([]models.ProductDailyStat{
+		{ProductID: 1, Day: day(2025, 7, 1), Impressions: 100, Views: 10, Orders: 1},
+		{ProductID: 1, Day: day(2025, 7, 2), Impressions: 100, Views: 30, Orders: 1},
+		{ProductID: 2, Day: day(2025, 7, 2), Impressions: 0, Views: 0},
+	}, nil)
+
+	report, err := service.Report("admin", 9, 0, from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, report, 2)
+	assert.Equal(t, uint(1), report[0].ProductID)
+	assert.Len(t, report[0].Daily, 2)
+	assert.Equal(t, "2025-07-02", report[0].Daily[1].Day)
+	assert.Equal(t, int64(200), report[0].Totals.Impressions)
+	assert.InDelta(t, 0.2, report[0].Totals.ViewRate, 1e-9)
+	assert.InDelta(t, 0.05, report[0].Totals.ConversionRate, 1e-9)
+	assert.Zero(t, report[1].Totals.ViewRate)
+}
+
+func TestReport_SellerCannotReadOtherSellersProduct(t *testing.T) {
+	repo := new(repository.MockAnalyticsRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &analyticsService{repo: repo, productRepo: productRepo, now: func() time.Time { return analyticsNow }}
+
+	productRepo.On("GetByID", uint(3), "seller", uint(7)).Return((*models.Product)(nil), gorm.ErrRecordNotFound)
+
+	_, err := service.Report("seller", 7, 3, day(2025, 7, 1), day(2025, 7, 31))
+
+	assert.ErrorIs(t, err, ErrNotProductOwner)
+	repo.AssertNotCalled(t, "ListDailyStats")
+}
+
+func TestReport_RejectsOversizedRange(t *testing.T) {
+	service := &analyticsService{now: func() time.Time { return analyticsNow }}
+
+	_, err := service.Report("admin", 0, 0, day(2025, 1, 1), day(2025, 7, 1))
+
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+// MaxBundleComponents caps how many different products one bundle may contain
+const MaxBundleComponents = 20
+
+var (
+	ErrNotBundle                = errors.New("product is not a bundle")
+	ErrBundleComponentsRequired = errors.New("a bundle needs at least one component")
+	ErrTooManyBundleComponents  = errors.New("a bundle can contain at most 20 products")
+	ErrInvalidComponentQuantity = errors.New("component quantity must be at least 1")
+	ErrComponentNotAllowed      = errors.New("bundles can only contain physical products other than the bundle itself")
+	ErrComponentNotOwned        = errors.New("bundle components must belong to the bundle's seller")
+)
+
+type BundleService interface {
+	SetComponents(bundleID uint, components []models.BundleComponent, role string, sellerID uint) ([]models.BundleComponent, error)
+	AttachComponents(products []models.Product) error
+}
+
+type bundleService struct {
+	repo        repository.BundleRepository
+	productRepo repository.ProductRepository
+	notifier    StockNotifier
+	now         func() time.Time
+}
+
+func NewBundleService(repo repository.BundleRepository, productRepo repository.ProductRepository, notifier StockNotifier) BundleService {
+	return &bundleService{repo: repo, productRepo: productRepo, notifier: notifier, now: time.Now}
+}
+
+// SetComponents replaces the contents of a bundle the caller may manage. Repeated
+// products are merged. The bundle's quantity is recalculated straight away.
+func (s *bundleService) SetComponents(bundleID uint, components []models.BundleComponent, role string, sellerID uint) ([]models.BundleComponent, error) {
+	bundle, err := ownedProduct(s.productRepo, bundleID, role, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if !bundle.IsBundle() {
+		return nil, ErrNotBundle
+	}
+
+	quantities := make(map[uint]int)
+	for _, component := range components {
+		if component.Quantity < 1 {
+			return nil, ErrInvalidComponentQuantity
+		}
+		quantities[component.ComponentID] += component.Quantity
+	}
+	if len(quantities) == 0 {
+		return nil, ErrBundleComponentsRequired
+	}
+	if len(quantities) > MaxBundleComponents {
+		return nil, ErrTooManyBundleComponents
+	}
+
+	merged := make([]models.BundleComponent, 0, len(quantities))
+	for componentID, quantity := range quantities {
+		if componentID == bundle.ID {
+			return nil, ErrComponentNotAllowed
+		}
+		product, err := s.productRepo.GetByID(componentID, "admin", 0)
+		if err != nil {
+			return nil, err
+		}
+		if product.IsBundle() || product.IsDigital() {
+			return nil, ErrComponentNotAllowed
+		}
+		if product.SellerID != bundle.SellerID {
+			return nil, ErrComponentNotOwned
+		}
+		merged = append(merged, models.BundleComponent{ComponentID: componentID, Quantity: quantity, Component: product})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ComponentID < merged[j].ComponentID })
+
+	changes, err := s.repo.SetComponents(bundle.ID, merged)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		for _, event := range StockEvents(changes[i].Before, &changes[i].Product, s.now()) {
+			s.notifier.Notify(event)
+		}
+	}
+	return merged, nil
+}
+
+// AttachComponents fills Components on every bundle among products
+func (s *bundleService) AttachComponents(products []models.Product) error {
+	var bundleIDs []uint
+	for i := range products {
+		if products[i].IsBundle() {
+			bundleIDs = append(bundleIDs, products[i].ID)
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	components, err := s.repo.ListComponents(bundleIDs)
+	if err != nil {
+		return err
+	}
+	byBundle := make(map[uint][]models.BundleComponent)
+	for _, component := range components {
+		byBundle[component.BundleID] = append(byBundle[component.BundleID], component)
+	}
+	for i := range products {
+		if products[i].IsBundle() {
+			products[i].Components = byBundle[products[i].ID]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func bundleProduct(id, sellerID uint, productType string, quantity int) *models.Product {
+	p := &models.Product{Name: "Phone combo", Type: productType, SellerID: sellerID, Quantity: quantity}
+	p.ID = id
+	return p
+}
+
+func TestBundleService_SetComponents_MergesAndRecalculates(t *testing.T) {
+	repo := new(repository.MockBundleRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := &bundleService{repo: repo, productRepo: productRepo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	productRepo.On("GetByID", uint(10), "seller", uint(7)).Return(bundleProduct(10, 7, models.ProductTypeBundle, 0), nil)
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(bundleProduct(2, 7, models.ProductTypePhysical, 9), nil)
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(bundleProduct(1, 7, models.ProductTypePhysical, 4), nil)
+	restocked := bundleProduct(10, 7, models.ProductTypeBundle, 4)
+	repo.On("SetComponents", uint(10), mock.MatchedBy(func(components []models.BundleComponent) bool {
+		return len(components) == 2 &&
+			components[0].ComponentID == 1 && components[0].Quantity == 1 &&
+			components[1].ComponentID == 2 && components[1].Quantity == 2
+	})).Return([]repository.StockChange{{Product: *restocked, Before: 0}}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(event models.StockEvent) bool {
+		return event.Type == models.StockEventBackInStock && event.ProductID == 10
+	})).Return()
+
+	components, err := service.SetComponents(10, []models.BundleComponent{
+		{ComponentID: 2, Quantity: 1},
+		{ComponentID: 1, Quantity: 1},
+		{ComponentID: 2, Quantity: 1},
+	}, "seller", 7)
+
+	assert.NoError(t, err)
+	assert.Len(t, components, 2)
+	notifier.AssertNumberOfCalls(t, "Notify", 1)
+}
+
+func TestBundleService_SetComponents_Rejections(t *testing.T) {
+	cases := []struct {
+		name      string
+		component *models.Product
+		expected  error
+	}{
+		{"digital product", bundleProduct(1, 7, models.ProductTypeDigital, 5), ErrComponentNotAllowed},
+		{"nested bundle", bundleProduct(1, 7, models.ProductTypeBundle, 5), ErrComponentNotAllowed},
+		{"other seller's product", bundleProduct(1, 8, models.ProductTypePhysical, 5), ErrComponentNotOwned},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(repository.MockBundleRepository)
+			productRepo := new(repository.MockProductRepository)
+			notifier := new(MockStockNotifier)
+			service := &bundleService{repo: repo, productRepo: productRepo, notifier: notifier, now: func() time.Time { return pricingNow }}
+			productRepo.On("GetByID", uint(10), "seller", uint(7)).Return(bundleProduct(10, 7, models.ProductTypeBundle, 0), nil)
+			productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(tc.component, nil)
+
+			_, err := service.SetComponents(10, []models.BundleComponent{{ComponentID: 1, Quantity: 1}}, "seller", 7)
+
+			assert.ErrorIs(t, err, tc.expected)
+			repo.AssertNotCalled(t, "SetComponents", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBundleService_SetComponents_RequiresBundle(t *testing.T) {
+	repo := new(repository.MockBundleRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := &bundleService{repo: repo, productRepo: productRepo, notifier: notifier, now: func() time.Time { return pricingNow }}
+	productRepo.On("GetByID", uint(10), "seller", uint(7)).Return(bundleProduct(10, 7, models.ProductTypePhysical, 3), nil)
+
+	_, err := service.SetComponents(10, []models.BundleComponent{{ComponentID: 1, Quantity: 1}}, "seller", 7)
+
+	assert.ErrorIs(t, err, ErrNotBundle)
+}
+
+func TestProductService_DecreaseStock_BundleMovesComponents(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := NewProductService(mockRepo, new(MockShopClient), notifier)
+
+	mockRepo.On("AdjustStock", uint(10), -1).Return(nil, repository.ErrBundleStock)
+	mockRepo.On("AdjustBundleStock", uint(10), -1).Return([]repository.StockChange{
+		{Product: *bundleProduct(1, 7, models.ProductTypePhysical, 0), Before: 1},
+		{Product: *bundleProduct(2, 7, models.ProductTypePhysical, 7), Before: 9},
+		{Product: *bundleProduct(10, 7, models.ProductTypeBundle, 0), Before: 1},
+	}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(event models.StockEvent) bool {
+		return event.Type == models.StockEventOut
+	})).Return()
+
+	err := service.DecreaseStock(10, 1)
+
+	assert.NoError(t, err)
+	// The last phone and the bundle sell out; the covers are still in stock
+	notifier.AssertNumberOfCalls(t, "Notify", 2)
+}
+
+func TestProductService_DecreaseStock_BundleComponentShort(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := NewProductService(mockRepo, new(MockShopClient), notifier)
+
+	mockRepo.On("AdjustStock", uint(10), -2).Return(nil, repository.ErrBundleStock)
+	mockRepo.On("AdjustBundleStock", uint(10), -2).Return(nil, repository.ErrInsufficientStock)
+
+	err := service.DecreaseStock(10, 2)
+
+	assert.ErrorIs(t, err, repository.ErrInsufficientStock)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestProductService_Update_BundleKeepsDerivedQuantity(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
+
+	product := bundleProduct(10, 7, models.ProductTypeBundle, 50)
+	mockRepo.On("GetByID", uint(10), "admin", uint(0)).Return(bundleProduct(10, 7, models.ProductTypeBundle, 3), nil)
+	mockRepo.On("Update", product, "seller", uint(7)).Return(nil)
+
+	err := service.UpdateProduct(product, "seller", 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, product.Quantity)
+}
+
+func TestProductService_Update_CannotConvertToBundle(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
+
+	mockRepo.On("GetByID", uint(1), "admin", uint(0)).Return(bundleProduct(1, 7, models.ProductTypePhysical, 3), nil)
+
+	err := service.UpdateProduct(bundleProduct(1, 7, models.ProductTypeBundle, 3), "seller", 7)
+
+	assert.ErrorIs(t, err, ErrBundleTypeChange)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPricingService_Claim_BundleListsComponents(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	bundles := new(repository.MockBundleRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, bundles: bundles, now: func() time.Time { return pricingNow }}
+
+	bundle := activeProduct(10, 25000)
+	bundle.Type = models.ProductTypeBundle
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return(bundle, nil)
+	priceRepo.On("ListLive", []uint{10}, pricingNow).Return([]models.PriceSchedule{}, nil)
+	bundles.On("ListComponents", []uint{10}).Return([]models.BundleComponent{
+		{BundleID: 10, ComponentID: 1, Quantity: 1, Component: &models.Product{Name: "Phone"}},
+		{BundleID: 10, ComponentID: 2, Quantity: 2, Component: &models.Product{Name: "Cover"}},
+	}, nil)
+
+	items, err := service.Claim(3, "order-key", []PriceRequestItem{{ProductID: 10, Quantity: 2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 25000.0, items[0].UnitPrice)
+	assert.Equal(t, []PricedComponent{
+		{ProductID: 1, ProductName: "Phone", Quantity: 2},
+		{ProductID: 2, ProductName: "Cover", Quantity: 4},
+	}, items[0].Components)
+}
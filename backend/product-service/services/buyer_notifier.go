@@ -0,0 +1,38 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"product-service/models"
+)
+
+// BuyerNotifier delivers price-drop and back-in-stock messages to buyers.
+// Notify must not block the caller.
+type BuyerNotifier interface {
+	Notify(notification models.BuyerNotification)
+}
+
+type logBuyerNotifier struct{}
+
+// NewLogBuyerNotifier creates a BuyerNotifier that only writes notifications to the log
+func NewLogBuyerNotifier() BuyerNotifier {
+	return logBuyerNotifier{}
+}
+
+func (logBuyerNotifier) Notify(notification models.BuyerNotification) {
+	log.Printf("🔔 %s: buyer %d, product %d at %.2f", notification.Type, notification.BuyerID, notification.ProductID, notification.Price)
+}
+
+type webhookBuyerNotifier struct {
+	webhook *webhook
+}
+
+// NewWebhookBuyerNotifier creates a BuyerNotifier that posts each notification as JSON to url
+func NewWebhookBuyerNotifier(url string) BuyerNotifier {
+	return &webhookBuyerNotifier{webhook: newWebhook(url)}
+}
+
+func (n *webhookBuyerNotifier) Notify(notification models.BuyerNotification) {
+	n.webhook.post(notification, fmt.Sprintf("%s notification for buyer %d", notification.Type, notification.BuyerID))
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxDigitalFileSize = 500 << 20
+	maxKeysPerUpload   = 1000
+	maxLicenseKeyLen   = 255
+)
+
+var (
+	ErrNotDigitalProduct    = errors.New("product is not a digital product")
+	ErrKeysNotUsed          = errors.New("product is not sold with license keys")
+	ErrNoLicenseKeys        = errors.New("provide between 1 and 1000 keys of at most 255 characters")
+	ErrFileTooLarge         = errors.New("file must not exceed 500 MB")
+	ErrDigitalFileNotFound  = errors.New("file not found")
+	ErrEntitlementNotFound  = errors.New("digital purchase not found")
+	ErrDownloadLimitReached = errors.New("download limit reached for this purchase")
+	ErrInvalidDownloadLink  = errors.New("download link is invalid")
+	ErrDownloadLinkExpired  = errors.New("download link has expired")
+)
+
+// FulfilItem is a paid order line to deliver
+type FulfilItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// KeyPoolStats summarizes a product's license keys
+type KeyPoolStats struct {
+	Available int64 `json:"available"`
+	Assigned  int64 `json:"assigned"`
+}
+
+// DownloadLink is a signed, time-limited path to one file of a purchase
+type DownloadLink struct {
+	Path      string    `json:"path"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DigitalService interface {
+	AddFile(productID uint, role string, sellerID uint, fileName, contentType string, size int64, content io.Reader) (*models.DigitalFile, error)
+	ListFiles(productID uint, role string, sellerID uint) ([]models.DigitalFile, error)
+	DeleteFile(productID, fileID uint, role string, sellerID uint) error
+	AddKeys(productID uint, role string, sellerID uint, keys []string) (*KeyPoolStats, error)
+	KeyPool(productID uint, role string, sellerID uint) (*KeyPoolStats, error)
+
+	Fulfil(orderID, buyerID uint, items []FulfilItem) ([]models.DigitalEntitlement, error)
+	Library(buyerID uint) ([]models.DigitalEntitlement, error)
+	CreateDownloadLink(entitlementID, fileID, buyerID uint) (*DownloadLink, error)
+	Download(entitlementID, fileID uint, expires int64, signature string) (*models.DigitalFile, string, error)
+}
+
+type digitalService struct {
+	repo        repository.DigitalRepository
+	productRepo repository.ProductRepository
+	stock       ProductService
+	store       FileStore
+	signingKey  []byte
+	linkTTL     time.Duration
+	now         func() time.Time
+}
+
+func NewDigitalService(repo repository.DigitalRepository, productRepo repository.ProductRepository, stock ProductService, store FileStore, signingKey string, linkTTL time.Duration) DigitalService {
+	return &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       store,
+		signingKey:  []byte(signingKey),
+		linkTTL:     linkTTL,
+		now:         time.Now,
+	}
+}
+
+// AddFile stores a file and attaches it to the seller's digital product
+func (s *digitalService) AddFile(productID uint, role string, sellerID uint, fileName, contentType string, size int64, content io.Reader) (*models.DigitalFile, error) {
+	if size > maxDigitalFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if _, err := s.digitalProduct(productID, role, sellerID); err != nil {
+		return nil, err
+	}
+
+	storagePath, err := s.store.Save(io.LimitReader(content, maxDigitalFileSize))
+	if err != nil {
+		return nil, err
+	}
+	file := &models.DigitalFile{
+		ProductID:   productID,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        size,
+		StoragePath: storagePath,
+	}
+	if err := s.repo.AddFile(file); err != nil {
+		s.store.Remove(storagePath)
+		return nil, err
+	}
+	return file, nil
+}
+
+// ListFiles returns the files attached to a digital product
+func (s *digitalService) ListFiles(productID uint, role string, sellerID uint) ([]models.DigitalFile, error) {
+	if _, err := ownedProduct(s.productRepo, productID, role, sellerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListFiles(productID)
+}
+
+// DeleteFile detaches a file; buyers lose access to it immediately
+func (s *digitalService) DeleteFile(productID, fileID uint, role string, sellerID uint) error {
+	if _, err := ownedProduct(s.productRepo, productID, role, sellerID); err != nil {
+		return err
+	}
+	file, err := s.repo.DeleteFile(productID, fileID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDigitalFileNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.store.Remove(file.StoragePath); err != nil {
+		log.Printf("⚠️ Failed to remove digital file %d content: %v", file.ID, err)
+	}
+	return nil
+}
+
+// AddKeys adds license keys to a product's pool. New keys count as stock and go first
+// to earlier purchases still waiting for keys.
+func (s *digitalService) AddKeys(productID uint, role string, sellerID uint, keys []string) (*KeyPoolStats, error) {
+	cleaned, err := cleanKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.digitalProduct(productID, role, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.UsesLicenseKeys {
+		return nil, ErrKeysNotUsed
+	}
+
+	added, err := s.repo.AddKeys(productID, cleaned)
+	if err != nil {
+		return nil, err
+	}
+	if added > 0 {
+		if err := s.stock.IncreaseStock(productID, added); err != nil {
+			log.Printf("⚠️ Failed to add %d keys to product %d stock: %v", added, productID, err)
+		}
+		if err := s.assignAwaitingKeys(productID); err != nil {
+			log.Printf("⚠️ Failed to assign keys to waiting purchases of product %d: %v", productID, err)
+		}
+	}
+	return s.keyPool(productID)
+}
+
+// KeyPool reports how many of a product's keys are available and assigned
+func (s *digitalService) KeyPool(productID uint, role string, sellerID uint) (*KeyPoolStats, error) {
+	if _, err := s.digitalProduct(productID, role, sellerID); err != nil {
+		return nil, err
+	}
+	return s.keyPool(productID)
+}
+
+// Fulfil grants the buyer access to the digital items of a paid order and assigns their
+// license keys. Physical items are ignored and repeated calls for an order are harmless.
+func (s *digitalService) Fulfil(orderID, buyerID uint, items []FulfilItem) ([]models.DigitalEntitlement, error) {
+	entitlements := []models.DigitalEntitlement{}
+	for _, item := range items {
+		product, err := s.productRepo.GetByID(item.ProductID, "admin", 0)
+		if err != nil {
+			return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+		}
+		if !product.IsDigital() {
+			continue
+		}
+
+		entitlement := &models.DigitalEntitlement{
+			OrderID:       orderID,
+			ProductID:     product.ID,
+			BuyerID:       buyerID,
+			SellerID:      product.SellerID,
+			ProductName:   product.Name,
+			Quantity:      item.Quantity,
+			DownloadLimit: product.DownloadLimit,
+		}
+		if entitlement.DownloadLimit <= 0 {
+			entitlement.DownloadLimit = models.DefaultDownloadLimit
+		}
+		if product.UsesLicenseKeys {
+			entitlement.KeysPending = item.Quantity
+		}
+
+		created, err := s.repo.CreateEntitlement(entitlement)
+		if err != nil {
+			return nil, err
+		}
+		if created && entitlement.KeysPending > 0 {
+			if _, err := s.assignKeys(entitlement); err != nil {
+				return nil, err
+			}
+		}
+
+		fulfilled, err := s.repo.FindEntitlement(orderID, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		entitlements = append(entitlements, *fulfilled)
+	}
+	return entitlements, nil
+}
+
+// Library returns the buyer's digital purchases with their keys and files
+func (s *digitalService) Library(buyerID uint) ([]models.DigitalEntitlement, error) {
+	entitlements, err := s.repo.ListEntitlements(buyerID)
+	if err != nil || len(entitlements) == 0 {
+		return entitlements, err
+	}
+	productIDs := make([]uint, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		productIDs = append(productIDs, entitlement.ProductID)
+	}
+	files, err := s.repo.ListFilesByProducts(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint][]models.DigitalFile)
+	for _, file := range files {
+		byProduct[file.ProductID] = append(byProduct[file.ProductID], file)
+	}
+	for i := range entitlements {
+		entitlements[i].Files = byProduct[entitlements[i].ProductID]
+	}
+	return entitlements, nil
+}
+
+// CreateDownloadLink signs a short-lived link to one file of the buyer's purchase
+func (s *digitalService) CreateDownloadLink(entitlementID, fileID, buyerID uint) (*DownloadLink, error) {
+	entitlement, err := s.repo.GetEntitlement(entitlementID)
+	if err != nil || entitlement.BuyerID != buyerID {
+		return nil, ErrEntitlementNotFound
+	}
+	file, err := s.repo.GetFile(fileID)
+	if err != nil || file.ProductID != entitlement.ProductID {
+		return nil, ErrDigitalFileNotFound
+	}
+	if entitlement.DownloadsLeft() == 0 {
+		return nil, ErrDownloadLimitReached
+	}
+
+	expiresAt := s.now().Add(s.linkTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	return &DownloadLink{
+		Path: fmt.Sprintf("/api/digital/downloads/%d/%d?expires=%d&signature=%s",
+			entitlementID, fileID, expires, s.sign(entitlementID, fileID, expires)),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Download checks a signed link and counts the download. It returns the file and
+// where its content can be read from.
+func (s *digitalService) Download(entitlementID, fileID uint, expires int64, signature string) (*models.DigitalFile, string, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(entitlementID, fileID, expires))) {
+		return nil, "", ErrInvalidDownloadLink
+	}
+	if s.now().Unix() > expires {
+		return nil, "", ErrDownloadLinkExpired
+	}
+
+	entitlement, err := s.repo.GetEntitlement(entitlementID)
+	if err != nil {
+		return nil, "", ErrEntitlementNotFound
+	}
+	file, err := s.repo.GetFile(fileID)
+	if err != nil || file.ProductID != entitlement.ProductID {
+		return nil, "", ErrDigitalFileNotFound
+	}
+	counted, err := s.repo.UseDownload(entitlementID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !counted {
+		return nil, "", ErrDownloadLimitReached
+	}
+	return file, s.store.Path(file.StoragePath), nil
+}
+
+// digitalProduct loads a digital product the caller may manage
+func (s *digitalService) digitalProduct(productID uint, role string, sellerID uint) (*models.Product, error) {
+	product, err := ownedProduct(s.productRepo, productID, role, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsDigital() {
+		return nil, ErrNotDigitalProduct
+	}
+	return product, nil
+}
+
+func (s *digitalService) keyPool(productID uint) (*KeyPoolStats, error) {
+	available, assigned, err := s.repo.CountKeys(productID)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPoolStats{Available: available, Assigned: assigned}, nil
+}
+
+// assignKeys gives an entitlement the keys it is owed, as far as the pool allows, and
+// takes them out of stock. Units the order's stock reservation already holds were taken
+// out at checkout and are not taken again.
+func (s *digitalService) assignKeys(entitlement *models.DigitalEntitlement) (int, error) {
+	assigned, err := s.repo.AssignKeys(entitlement.ID, s.now())
+	if err != nil || assigned == 0 {
+		return assigned, err
+	}
+	reserved, err := s.repo.ReservedQuantity(entitlement.OrderID, entitlement.ProductID)
+	if err != nil {
+		log.Printf("⚠️ Failed to look up the stock order %d holds for product %d: %v", entitlement.OrderID, entitlement.ProductID, err)
+		return assigned, nil
+	}
+	// Keys go out unit by unit, so the reservation covers the first units assigned
+	alreadyAssigned := entitlement.Quantity - entitlement.KeysPending
+	covered := min(max(reserved-alreadyAssigned, 0), assigned)
+	if uncovered := assigned - covered; uncovered > 0 {
+		if err := s.stock.DecreaseStock(entitlement.ProductID, uncovered); err != nil {
+			log.Printf("⚠️ Failed to take %d assigned keys of product %d out of stock: %v", uncovered, entitlement.ProductID, err)
+		}
+	}
+	return assigned, nil
+}
+
+// assignAwaitingKeys hands newly added keys to purchases that are still owed keys, oldest first
+func (s *digitalService) assignAwaitingKeys(productID uint) error {
+	awaiting, err := s.repo.ListAwaitingKeys(productID)
+	if err != nil {
+		return err
+	}
+	for i := range awaiting {
+		assigned, err := s.assignKeys(&awaiting[i])
+		if err != nil {
+			return err
+		}
+		if assigned < awaiting[i].KeysPending {
+			// The pool is empty again
+			return nil
+		}
+	}
+	return nil
+}
+
+// sign computes the signature of a download link
+func (s *digitalService) sign(entitlementID, fileID uint, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%d:%d:%d", entitlementID, fileID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cleanKeys trims keys and drops blanks and duplicates
+func cleanKeys(keys []string) ([]string, error) {
+	seen := make(map[string]bool, len(keys))
+	cleaned := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		if len(key) > maxLicenseKeyLen {
+			return nil, ErrNoLicenseKeys
+		}
+		seen[key] = true
+		cleaned = append(cleaned, key)
+	}
+	if len(cleaned) == 0 || len(cleaned) > maxKeysPerUpload {
+		return nil, ErrNoLicenseKeys
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"net/url"
+	"product-service/models"
+	"product-service/repository"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var digitalNow = time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC)
+
+func digitalProduct(id uint, usesKeys bool) *models.Product {
+	product := activeProduct(id, 500)
+	product.Type = models.ProductTypeDigital
+	product.UsesLicenseKeys = usesKeys
+	return product
+}
+
+// parseLink extracts the expiry and signature from a download link path
+func parseLink(t *testing.T, link *DownloadLink) (int64, string) {
+	parsed, err := url.Parse(link.Path)
+	assert.NoError(t, err)
+	expires, err := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+	assert.NoError(t, err)
+	return expires, parsed.Query().Get("signature")
+}
+
+func TestDigitalService_Fulfil_SkipsPhysicalAndAssignsKeys(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(activeProduct(1, 1000), nil)
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(digitalProduct(2, true), nil)
+	repo.On("CreateEntitlement", mock.MatchedBy(func(e *models.DigitalEntitlement) bool {
+		return e.OrderID == 10 && e.ProductID == 2 && e.BuyerID == 5 && e.KeysPending == 2 &&
+			e.DownloadLimit == models.DefaultDownloadLimit
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.DigitalEntitlement).ID = 7
+	}).Return(true, nil)
+	repo.On("AssignKeys", uint(7), digitalNow).Return(2, nil)
+	repo.On("ReservedQuantity", uint(10), uint(2)).Return(0, nil)
+	productRepo.On("AdjustStock", uint(2), -2).Return(digitalProduct(2, true), nil)
+	repo.On("FindEntitlement", uint(10), uint(2)).Return(&models.DigitalEntitlement{ID: 7, ProductID: 2}, nil)
+
+	entitlements, err := service.Fulfil(10, 5, []FulfilItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 2}})
+
+	assert.NoError(t, err)
+	assert.Len(t, entitlements, 1)
+	repo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+}
+
+func TestDigitalService_Fulfil_ReservedKeysLeaveStockAlone(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(digitalProduct(2, true), nil)
+	repo.On("CreateEntitlement", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.DigitalEntitlement).ID = 7
+	}).Return(true, nil)
+	repo.On("AssignKeys", uint(7), digitalNow).Return(2, nil)
+	// Checkout already took both units out of stock
+	repo.On("ReservedQuantity", uint(10), uint(2)).Return(2, nil)
+	repo.On("FindEntitlement", uint(10), uint(2)).Return(&models.DigitalEntitlement{ID: 7, ProductID: 2}, nil)
+
+	_, err := service.Fulfil(10, 5, []FulfilItem{{ProductID: 2, Quantity: 2}})
+
+	assert.NoError(t, err)
+	productRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything)
+}
+
+func TestDigitalService_Fulfil_RepeatedCallDoesNotReassign(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(digitalProduct(2, true), nil)
+	repo.On("CreateEntitlement", mock.Anything).Return(false, nil)
+	repo.On("FindEntitlement", uint(10), uint(2)).Return(&models.DigitalEntitlement{ID: 7, ProductID: 2}, nil)
+
+	_, err := service.Fulfil(10, 5, []FulfilItem{{ProductID: 2, Quantity: 2}})
+
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "AssignKeys", mock.Anything, mock.Anything)
+}
+
+func TestDigitalService_DownloadLink_RoundTrip(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	entitlement := &models.DigitalEntitlement{ID: 7, ProductID: 2, BuyerID: 5, DownloadLimit: 3}
+	file := &models.DigitalFile{ID: 4, ProductID: 2, FileName: "book.pdf", StoragePath: "abc"}
+	repo.On("GetEntitlement", uint(7)).Return(entitlement, nil)
+	repo.On("GetFile", uint(4)).Return(file, nil)
+	repo.On("UseDownload", uint(7)).Return(true, nil)
+
+	link, err := service.CreateDownloadLink(7, 4, 5)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link.Path, "/api/digital/downloads/7/4?"))
+	assert.Equal(t, digitalNow.Add(15*time.Minute), link.ExpiresAt)
+
+	expires, signature := parseLink(t, link)
+	downloaded, path, err := service.Download(7, 4, expires, signature)
+
+	assert.NoError(t, err)
+	assert.Equal(t, file, downloaded)
+	assert.True(t, strings.HasSuffix(path, "abc"))
+}
+
+func TestDigitalService_DownloadLink_OnlyOwnPurchase(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	repo.On("GetEntitlement", uint(7)).Return(&models.DigitalEntitlement{ID: 7, BuyerID: 8, DownloadLimit: 3}, nil)
+
+	_, err := service.CreateDownloadLink(7, 4, 5)
+
+	assert.ErrorIs(t, err, ErrEntitlementNotFound)
+}
+
+func TestDigitalService_Download_RejectsTamperedLink(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	signature := service.sign(7, 4, digitalNow.Unix()+60)
+
+	_, _, err := service.Download(7, 5, digitalNow.Unix()+60, signature)
+
+	assert.ErrorIs(t, err, ErrInvalidDownloadLink)
+	repo.AssertNotCalled(t, "UseDownload", mock.Anything)
+}
+
+func TestDigitalService_Download_RejectsExpiredLink(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	expires := digitalNow.Unix() - 1
+
+	_, _, err := service.Download(7, 4, expires, service.sign(7, 4, expires))
+
+	assert.ErrorIs(t, err, ErrDownloadLinkExpired)
+}
+
+func TestDigitalService_Download_EnforcesLimit(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	repo.On("GetEntitlement", uint(7)).Return(&models.DigitalEntitlement{ID: 7, ProductID: 2, DownloadLimit: 3, DownloadCount: 2}, nil)
+	repo.On("GetFile", uint(4)).Return(&models.DigitalFile{ID: 4, ProductID: 2}, nil)
+	repo.On("UseDownload", uint(7)).Return(false, nil)
+	expires := digitalNow.Unix() + 60
+
+	_, _, err := service.Download(7, 4, expires, service.sign(7, 4, expires))
+
+	assert.ErrorIs(t, err, ErrDownloadLimitReached)
+}
+
+func TestDigitalService_AddKeys_FillsWaitingPurchases(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	productRepo.On("GetByID", uint(2), "seller", uint(9)).Return(digitalProduct(2, true), nil)
+	repo.On("AddKeys", uint(2), []string{"AAA-1", "BBB-2"}).Return(2, nil)
+	productRepo.On("AdjustStock", uint(2), 2).Return(digitalProduct(2, true), nil)
+	repo.On("ListAwaitingKeys", uint(2)).Return([]models.DigitalEntitlement{{ID: 7, OrderID: 10, ProductID: 2, Quantity: 1, KeysPending: 1}}, nil)
+	repo.On("AssignKeys", uint(7), digitalNow).Return(1, nil)
+	repo.On("ReservedQuantity", uint(10), uint(2)).Return(0, nil)
+	productRepo.On("AdjustStock", uint(2), -1).Return(digitalProduct(2, true), nil)
+	repo.On("CountKeys", uint(2)).Return(int64(1), int64(1), nil)
+
+	pool, err := service.AddKeys(2, "seller", 9, []string{" AAA-1 ", "BBB-2", "AAA-1", ""})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &KeyPoolStats{Available: 1, Assigned: 1}, pool)
+	repo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+}
+
+func TestDigitalService_AddFile_RejectsPhysicalProduct(t *testing.T) {
+	repo := new(repository.MockDigitalRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	notifier.On("Notify", mock.Anything).Maybe().Return()
+	stock := &productService{repo: productRepo, notifier: notifier, now: func() time.Time { return digitalNow }}
+	service := &digitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		stock:       stock,
+		store:       NewLocalFileStore(t.TempDir()),
+		signingKey:  []byte("secret"),
+		linkTTL:     15 * time.Minute,
+		now:         func() time.Time { return digitalNow },
+	}
+
+	productRepo.On("GetByID", uint(1), "seller", uint(9)).Return(activeProduct(1, 1000), nil)
+
+	_, err := service.AddFile(1, "seller", 9, "book.pdf", "application/pdf", 10, strings.NewReader("0123456789"))
+
+	assert.ErrorIs(t, err, ErrNotDigitalProduct)
+	repo.AssertNotCalled(t, "AddFile", mock.Anything)
+}
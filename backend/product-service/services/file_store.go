@@ -0,0 +1,61 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps the content of digital product files
+type FileStore interface {
+	Save(content io.Reader) (string, error)
+	Path(storagePath string) string
+	Remove(storagePath string) error
+}
+
+type localFileStore struct {
+	dir string
+}
+
+// NewLocalFileStore creates a FileStore that writes files under dir
+func NewLocalFileStore(dir string) FileStore {
+	return &localFileStore{dir: dir}
+}
+
+// Save writes the content under a random name and returns that name
+func (s *localFileStore) Save(content io.Reader) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(buf)
+
+	file, err := os.OpenFile(filepath.Join(s.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	return name, file.Close()
+}
+
+// Path returns where a stored file can be read from
+func (s *localFileStore) Path(storagePath string) string {
+	return filepath.Join(s.dir, filepath.Base(storagePath))
+}
+
+// Remove deletes a stored file; a file that is already gone is not an error
+func (s *localFileStore) Remove(storagePath string) error {
+	if err := os.Remove(s.Path(storagePath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
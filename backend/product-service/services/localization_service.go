@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+const (
+	maxTranslatedNameLen        = 255
+	maxTranslatedDescriptionLen = 5000
+)
+
+var (
+	ErrUnsupportedLocale   = errors.New("locale must be one of en, bn")
+	ErrInvalidTranslation  = errors.New("name must be between 1 and 255 characters and description at most 5000")
+	ErrTranslationNotFound = errors.New("translation not found")
+	ErrCategoryNotFound    = errors.New("category not found")
+)
+
+type LocalizationService interface {
+	Localize(products []models.Product, locales []string) error
+	ListCategories(locales []string) ([]models.Category, error)
+
+	ListProductTranslations(productID uint, role string, sellerID uint) ([]models.ProductTranslation, error)
+	SetProductTranslation(productID uint, role string, sellerID uint, locale, name, description string) (*models.ProductTranslation, error)
+	DeleteProductTranslation(productID uint, role string, sellerID uint, locale string) error
+	SetCategoryTranslation(categoryID uint, locale, name string) (*models.CategoryTranslation, error)
+}
+
+type localizationService struct {
+	repo        repository.TranslationRepository
+	productRepo repository.ProductRepository
+	now         func() time.Time
+}
+
+func NewLocalizationService(repo repository.TranslationRepository, productRepo repository.ProductRepository) LocalizationService {
+	return &localizationService{repo: repo, productRepo: productRepo, now: time.Now}
+}
+
+// ResolveLocales turns an Accept-Language header into the supported locales to try, most
+// preferred first and always ending with the default locale. Region subtags are ignored,
+// so "bn-BD" asks for "bn".
+func ResolveLocales(acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var requested []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if q > 0 && isSupportedLocale(base) {
+			requested = append(requested, weighted{locale: base, q: q})
+		}
+	}
+	sort.SliceStable(requested, func(i, j int) bool { return requested[i].q > requested[j].q })
+
+	locales := make([]string, 0, len(models.SupportedLocales))
+	seen := make(map[string]bool)
+	for _, entry := range append(requested, weighted{locale: models.DefaultLocale}) {
+		if !seen[entry.locale] {
+			seen[entry.locale] = true
+			locales = append(locales, entry.locale)
+		}
+	}
+	return locales
+}
+
+// Localize replaces each product's name, description and category name with the first
+// translation found along the locale chain. Fields without any translation keep the
+// product's own content.
+func (s *localizationService) Localize(products []models.Product, locales []string) error {
+	if len(products) == 0 {
+		return nil
+	}
+	productIDs := make([]uint, 0, len(products))
+	var categoryIDs []uint
+	seenCategory := make(map[uint]bool)
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+		if !seenCategory[product.CategoryID] {
+			seenCategory[product.CategoryID] = true
+			categoryIDs = append(categoryIDs, product.CategoryID)
+		}
+	}
+
+	translations, err := s.repo.ListProductTranslations(productIDs, locales)
+	if err != nil {
+		return err
+	}
+	byProduct := make(map[uint]map[string]models.ProductTranslation)
+	for _, translation := range translations {
+		if byProduct[translation.ProductID] == nil {
+			byProduct[translation.ProductID] = make(map[string]models.ProductTranslation)
+		}
+		byProduct[translation.ProductID][translation.Locale] = translation
+	}
+
+	categories, err := s.localizedCategories(categoryIDs, locales)
+	if err != nil {
+		return err
+	}
+	byCategory := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		byCategory[category.ID] = category
+	}
+
+	for i := range products {
+		product := &products[i]
+		nameSet, descriptionSet := false, false
+		for _, locale := range locales {
+			translation, ok := byProduct[product.ID][locale]
+			if !ok {
+				continue
+			}
+			if !nameSet && translation.Name != "" {
+				product.Name = translation.Name
+				product.Locale = locale
+				nameSet = true
+			}
+			if !descriptionSet && translation.Description != "" {
+				product.Description = translation.Description
+				descriptionSet = true
+			}
+		}
+		if category, ok := byCategory[product.CategoryID]; ok {
+			product.Category = category
+		}
+	}
+	return nil
+}
+
+// ListCategories returns every category with its name localized
+func (s *localizationService) ListCategories(locales []string) ([]models.Category, error) {
+	return s.localizedCategories(nil, locales)
+}
+
+// localizedCategories loads categories, all of them when ids is nil, and applies the
+// first category translation found along the locale chain
+func (s *localizationService) localizedCategories(ids []uint, locales []string) ([]models.Category, error) {
+	categories, err := s.repo.ListCategories(ids)
+	if err != nil || len(categories) == 0 {
+		return categories, err
+	}
+	translations, err := s.repo.ListCategoryTranslations(ids, locales)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]map[string]string)
+	for _, translation := range translations {
+		if names[translation.CategoryID] == nil {
+			names[translation.CategoryID] = make(map[string]string)
+		}
+		names[translation.CategoryID][translation.Locale] = translation.Name
+	}
+
+	for i := range categories {
+		for _, locale := range locales {
+			if name := names[categories[i].ID][locale]; name != "" {
+				categories[i].Name = name
+				categories[i].Locale = locale
+				break
+			}
+		}
+	}
+	return categories, nil
+}
+
+// ListProductTranslations returns all translations of a product the caller can manage
+func (s *localizationService) ListProductTranslations(productID uint, role string, sellerID uint) ([]models.ProductTranslation, error) {
+	if _, err := ownedProduct(s.productRepo, productID, role, sellerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProduct(productID)
+}
+
+// SetProductTranslation creates or replaces a product's content in one locale
+func (s *localizationService) SetProductTranslation(productID uint, role string, sellerID uint, locale, name, description string) (*models.ProductTranslation, error) {
+	if !isSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" || len(name) > maxTranslatedNameLen || len(description) > maxTranslatedDescriptionLen {
+		return nil, ErrInvalidTranslation
+	}
+	if _, err := ownedProduct(s.productRepo, productID, role, sellerID); err != nil {
+		return nil, err
+	}
+
+	translation := &models.ProductTranslation{
+		ProductID:   productID,
+		Locale:      locale,
+		Name:        name,
+		Description: description,
+		UpdatedAt:   s.now(),
+	}
+	if err := s.repo.SaveProductTranslation(translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+// DeleteProductTranslation removes a product's content in one locale
+func (s *localizationService) DeleteProductTranslation(productID uint, role string, sellerID uint, locale string) error {
+	if !isSupportedLocale(locale) {
+		return ErrUnsupportedLocale
+	}
+	if _, err := ownedProduct(s.productRepo, productID, role, sellerID); err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteProductTranslation(productID, locale)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+// SetCategoryTranslation creates or replaces a category's name in one locale
+func (s *localizationService) SetCategoryTranslation(categoryID uint, locale, name string) (*models.CategoryTranslation, error) {
+	if !isSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxTranslatedNameLen {
+		return nil, ErrInvalidTranslation
+	}
+	categories, err := s.repo.ListCategories([]uint{categoryID})
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	translation := &models.CategoryTranslation{
+		CategoryID: categoryID,
+		Locale:     locale,
+		Name:       name,
+		UpdatedAt:  s.now(),
+	}
+	if err := s.repo.SaveCategoryTranslation(translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+func isSupportedLocale(locale string) bool {
+	for _, supported := range models.SupportedLocales {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var localizationNow = time.Date(2025, 9, 15, 8, 0, 0, 0, time.UTC)
+
+func TestResolveLocales(t *testing.T) {
+	cases := map[string][]string{
+		"":                           {"en"},
+		"bn":                         {"bn", "en"},
+		"bn-BD,bn;q=0.9,en-US;q=0.8": {"bn", "en"},
+		"en;q=0.5, bn;q=0.8":         {"bn", "en"},
+		"fr, de;q=0.9":               {"en"},
+		"bn;q=0, en":                 {"en"},
+	}
+	for header, expected := range cases {
+		assert.Equal(t, expected, ResolveLocales(header), header)
+	}
+}
+
+func TestLocalizationService_Localize_FallsBackPerField(t *testing.T) {
+	repo := new(repository.MockTranslationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &localizationService{repo: repo, productRepo: productRepo, now: func() time.Time { return localizationNow }}
+
+	products := []models.Product{
+		{Model: gorm.Model{ID: 1}, Name: "Panjabi", Description: "Cotton panjabi", CategoryID: 4},
+		{Model: gorm.Model{ID: 2}, Name: "Saree", Description: "Silk saree", CategoryID: 4},
+	}
+	locales := []string{"bn", "en"}
+	repo.On("ListProductTranslations", []uint{1, 2}, locales).Return([]models.ProductTranslation{
+		{ProductID: 1, Locale: "bn", Name: "পাঞ্জাবি"},
+		{ProductID: 1, Locale: "en", Name: "Cotton Panjabi", Description: "100% cotton panjabi"},
+	}, nil)
+	repo.On("ListCategories", []uint{4}).Return([]models.Category{{ID: 4, Name: "Clothing"}}, nil)
+	repo.On("ListCategoryTranslations", []uint{4}, locales).Return([]models.CategoryTranslation{
+		{CategoryID: 4, Locale: "bn", Name: "পোশাক"},
+	}, nil)
+
+	err := service.Localize(products, locales)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "পাঞ্জাবি", products[0].Name)
+	assert.Equal(t, "bn", products[0].Locale)
+	assert.Equal(t, "100% cotton panjabi", products[0].Description)
+	assert.Equal(t, "Saree", products[1].Name)
+	assert.Empty(t, products[1].Locale)
+	assert.Equal(t, "পোশাক", products[1].Category.Name)
+}
+
+func TestLocalizationService_SetProductTranslation_OnlyOwner(t *testing.T) {
+	repo := new(repository.MockTranslationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &localizationService{repo: repo, productRepo: productRepo, now: func() time.Time { return localizationNow }}
+
+	productRepo.On("GetByID", uint(1), "seller", uint(4)).Return((*models.Product)(nil), gorm.ErrRecordNotFound)
+
+	_, err := service.SetProductTranslation(1, "seller", 4, "bn", "পাঞ্জাবি", "")
+
+	assert.ErrorIs(t, err, ErrNotProductOwner)
+	repo.AssertNotCalled(t, "SaveProductTranslation", mock.Anything)
+}
+
+func TestLocalizationService_SetProductTranslation_RejectsUnsupportedLocale(t *testing.T) {
+	repo := new(repository.MockTranslationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &localizationService{repo: repo, productRepo: productRepo, now: func() time.Time { return localizationNow }}
+
+	_, err := service.SetProductTranslation(1, "seller", 9, "fr", "Panjabi", "")
+
+	assert.ErrorIs(t, err, ErrUnsupportedLocale)
+}
+
+func TestLocalizationService_SetProductTranslation_Saves(t *testing.T) {
+	repo := new(repository.MockTranslationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &localizationService{repo: repo, productRepo: productRepo, now: func() time.Time { return localizationNow }}
+
+	productRepo.On("GetByID", uint(1), "seller", uint(9)).Return(activeProduct(1, 100), nil)
+	repo.On("SaveProductTranslation", &models.ProductTranslation{
+		ProductID: 1, Locale: "bn", Name: "পাঞ্জাবি", Description: "সুতির পাঞ্জাবি", UpdatedAt: localizationNow,
+	}).Return(nil)
+
+	translation, err := service.SetProductTranslation(1, "seller", 9, "bn", " পাঞ্জাবি ", "সুতির পাঞ্জাবি")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "পাঞ্জাবি", translation.Name)
+	repo.AssertExpectations(t)
+}
+
+func TestLocalizationService_SetCategoryTranslation_UnknownCategory(t *testing.T) {
+	repo := new(repository.MockTranslationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &localizationService{repo: repo, productRepo: productRepo, now: func() time.Time { return localizationNow }}
+
+	repo.On("ListCategories", []uint{7}).Return([]models.Category{}, nil)
+
+	_, err := service.SetCategoryTranslation(7, "bn", "পোশাক")
+
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+	repo.AssertNotCalled(t, "SaveCategoryTranslation", mock.Anything)
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBuyerNotifier struct {
+	mock.Mock
+}
+
+func (m *MockBuyerNotifier) Notify(notification models.BuyerNotification) {
+	m.Called(notification)
+}
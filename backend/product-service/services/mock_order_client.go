@@ -0,0 +1,23 @@
+package services
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderClient struct {
+	mock.Mock
+}
+
+func (m *MockOrderClient) GetDeliveredPurchase(buyerID, productID uint) (*DeliveredPurchase, error) {
+	args := m.Called(buyerID, productID)
+	purchase, _ := args.Get(0).(*DeliveredPurchase)
+	return purchase, args.Error(1)
+}
+
+func (m *MockOrderClient) ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedOrderItem, error) {
+	args := m.Called(since, afterID, limit)
+	items, _ := args.Get(0).([]CompletedOrderItem)
+	return items, args.Error(1)
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSellerNotifier struct {
+	mock.Mock
+}
+
+func (m *MockSellerNotifier) Notify(notification models.SellerNotification) {
+	m.Called(notification)
+}
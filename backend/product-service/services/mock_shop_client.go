@@ -0,0 +1,13 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockShopClient struct {
+	mock.Mock
+}
+
+func (m *MockShopClient) GetShop(id uint) (*ShopInfo, error) {
+	args := m.Called(id)
+	shop, _ := args.Get(0).(*ShopInfo)
+	return shop, args.Error(1)
+}
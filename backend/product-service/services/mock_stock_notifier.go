@@ -0,0 +1,15 @@
+package services
+
+import (
+	"product-service/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockStockNotifier) Notify(event models.StockEvent) {
+	m.Called(event)
+}
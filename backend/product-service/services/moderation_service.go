@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+// ActorSystem is the role recorded for automatic transitions
+const ActorSystem = "system"
+
+var (
+	ErrInvalidTransition = errors.New("invalid product status transition")
+	ErrReasonRequired    = errors.New("a reason is required for this transition")
+	ErrCannotManage      = errors.New("unauthorized: vendor cannot manage this product")
+)
+
+// productTransitions lists, per current status, the statuses each role may move a product to
+var productTransitions = map[string]map[string][]string{
+	models.ProductStatusDraft: {
+		models.ProductStatusPendingReview: {"seller", "admin"},
+		models.ProductStatusArchived:      {"seller", "admin"},
+	},
+	models.ProductStatusPendingReview: {
+		models.ProductStatusActive: {"admin"},
+		models.ProductStatusDraft:  {"seller", "admin"}, // seller withdraws, admin rejects
+	},
+	models.ProductStatusActive: {
+		models.ProductStatusPendingReview: {ActorSystem},
+		models.ProductStatusSuspended:     {"admin"},
+		models.ProductStatusArchived:      {"seller", "admin"},
+	},
+	models.ProductStatusSuspended: {
+		models.ProductStatusActive:   {"admin"},
+		models.ProductStatusArchived: {"admin"},
+	},
+	models.ProductStatusArchived: {
+		models.ProductStatusDraft: {"seller", "admin"},
+	},
+}
+
+// CanTransition reports whether role may move a product from one status to another
+func CanTransition(from, to, role string) bool {
+	for _, allowed := range productTransitions[from][to] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// KeywordFilter flags product text containing banned keywords
+type KeywordFilter struct {
+	keywords []string
+}
+
+// NewKeywordFilter builds a case-insensitive filter from the configured keywords
+func NewKeywordFilter(keywords []string) *KeywordFilter {
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			normalized = append(normalized, keyword)
+		}
+	}
+	return &KeywordFilter{keywords: normalized}
+}
+
+// Scan returns the banned keywords found in the product's name or description
+func (f *KeywordFilter) Scan(product *models.Product) []string {
+	text := strings.ToLower(product.Name + " " + product.Description)
+	var hits []string
+	for _, keyword := range f.keywords {
+		if strings.Contains(text, keyword) {
+			hits = append(hits, keyword)
+		}
+	}
+	return hits
+}
+
+type ModerationService interface {
+	Transition(productID uint, to, role string, actorID uint, reason string) (*models.Product, error)
+	RecheckAfterEdit(productID uint) (*models.Product, error)
+	Queue(flaggedOnly bool, offset, limit int) ([]models.Product, error)
+	History(productID uint) ([]models.ProductStatusLog, error)
+	SetShopVisibility(shopID, ownerID uint, visible bool) error
+}
+
+type moderationService struct {
+	repo        repository.ModerationRepository
+	productRepo repository.ProductRepository
+	keywords    *KeywordFilter
+}
+
+func NewModerationService(repo repository.ModerationRepository, productRepo repository.ProductRepository, keywords *KeywordFilter) ModerationService {
+	return &moderationService{repo: repo, productRepo: productRepo, keywords: keywords}
+}
+
+// Transition moves a product through its lifecycle if the role is allowed to
+func (s *moderationService) Transition(productID uint, to, role string, actorID uint, reason string) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(productID, "admin", 0)
+	if err != nil {
+		return nil, err
+	}
+	if role == "seller" && product.SellerID != actorID {
+		return nil, ErrCannotManage
+	}
+
+	from := product.Status
+	if !CanTransition(from, to, role) {
+		return nil, fmt.Errorf("%w: %s cannot move a product from %s to %s", ErrInvalidTransition, role, from, to)
+	}
+	if role == "admin" && strings.TrimSpace(reason) == "" &&
+		(to == models.ProductStatusSuspended || (from == models.ProductStatusPendingReview && to == models.ProductStatusDraft)) {
+		return nil, ErrReasonRequired
+	}
+
+	switch to {
+	case models.ProductStatusPendingReview:
+		// Every submission is scanned so the queue shows what needs attention
+		product.FlagReasons = s.keywords.Scan(product)
+		product.Flagged = len(product.FlagReasons) > 0
+		product.ModerationNote = ""
+	case models.ProductStatusActive:
+		product.Flagged = false
+		product.FlagReasons = nil
+		product.ModerationNote = reason
+	default:
+		product.ModerationNote = reason
+	}
+
+	return product, s.apply(product, from, to, role, actorID, reason)
+}
+
+// RecheckAfterEdit sends a live product back to review when an edit introduces banned keywords
+func (s *moderationService) RecheckAfterEdit(productID uint) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(productID, "admin", 0)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != models.ProductStatusActive {
+		return product, nil
+	}
+
+	hits := s.keywords.Scan(product)
+	if len(hits) == 0 {
+		return product, nil
+	}
+
+	product.Flagged = true
+	product.FlagReasons = hits
+	reason := "banned keywords: " + strings.Join(hits, ", ")
+	return product, s.apply(product, product.Status, models.ProductStatusPendingReview, ActorSystem, 0, reason)
+}
+
+// Queue returns products awaiting admin review
+func (s *moderationService) Queue(flaggedOnly bool, offset, limit int) ([]models.Product, error) {
+	return s.repo.ListQueue(flaggedOnly, offset, limit)
+}
+
+// History returns a product's lifecycle transitions
+func (s *moderationService) History(productID uint) ([]models.ProductStatusLog, error) {
+	return s.repo.ListStatusLogs(productID)
+}
+
+// SetShopVisibility hides or restores a shop's products when the shop changes state,
+// including the owner's products that predate shops
+func (s *moderationService) SetShopVisibility(shopID, ownerID uint, visible bool) error {
+	return s.repo.SetShopVisibility(shopID, ownerID, visible)
+}
+
+// apply persists a transition together with its log entry
+func (s *moderationService) apply(product *models.Product, from, to, role string, actorID uint, reason string) error {
+	product.Status = to
+	product.IsActive = to == models.ProductStatusActive
+	return s.repo.Transition(product, &models.ProductStatusLog{
+		ProductID:  product.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+		ActorRole:  role,
+		Reason:     reason,
+	})
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func productInStatus(status string) *models.Product {
+	p := &models.Product{Name: "Replica Watch", Description: "Looks original", SellerID: 9, Status: status}
+	p.ID = 1
+	return p
+}
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to, role string
+		allowed        bool
+	}{
+		{models.ProductStatusDraft, models.ProductStatusPendingReview, "seller", true},
+		{models.ProductStatusDraft, models.ProductStatusActive, "seller", false},
+		{models.ProductStatusPendingReview, models.ProductStatusActive, "admin", true},
+		{models.ProductStatusPendingReview, models.ProductStatusActive, "seller", false},
+		{models.ProductStatusActive, models.ProductStatusSuspended, "admin", true},
+		{models.ProductStatusActive, models.ProductStatusSuspended, "seller", false},
+		{models.ProductStatusSuspended, models.ProductStatusActive, "seller", false},
+		{models.ProductStatusArchived, models.ProductStatusActive, "admin", false},
+		{models.ProductStatusArchived, models.ProductStatusDraft, "seller", true},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.allowed, CanTransition(tc.from, tc.to, tc.role), "%s -> %s as %s", tc.from, tc.to, tc.role)
+	}
+}
+
+func TestModerationService_Submit_FlagsBannedKeywords(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter([]string{"replica", "counterfeit"}))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusDraft), nil)
+	repo.On("Transition", mock.AnythingOfType("*models.Product"), mock.AnythingOfType("*models.ProductStatusLog")).Return(nil)
+
+	product, err := service.Transition(1, models.ProductStatusPendingReview, "seller", 9, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStatusPendingReview, product.Status)
+	assert.True(t, product.Flagged)
+	assert.Equal(t, []string{"replica"}, product.FlagReasons)
+	assert.False(t, product.IsActive)
+}
+
+func TestModerationService_SellerCannotApprove(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter(nil))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusPendingReview), nil)
+
+	_, err := service.Transition(1, models.ProductStatusActive, "seller", 9, "")
+
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	repo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+}
+
+func TestModerationService_SellerCannotManageOthersProduct(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter(nil))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusDraft), nil)
+
+	_, err := service.Transition(1, models.ProductStatusPendingReview, "seller", 4, "")
+
+	assert.ErrorIs(t, err, ErrCannotManage)
+	repo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+}
+
+func TestModerationService_RejectRequiresReason(t *testing.T) {
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(new(repository.MockModerationRepository), productRepo, NewKeywordFilter(nil))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusPendingReview), nil)
+
+	_, err := service.Transition(1, models.ProductStatusDraft, "admin", 2, " ")
+
+	assert.ErrorIs(t, err, ErrReasonRequired)
+}
+
+func TestModerationService_ApproveLogsTransition(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter(nil))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusPendingReview), nil)
+	repo.On("Transition", mock.AnythingOfType("*models.Product"), mock.MatchedBy(func(entry *models.ProductStatusLog) bool {
+		return entry.FromStatus == models.ProductStatusPendingReview &&
+			entry.ToStatus == models.ProductStatusActive &&
+			entry.ActorID == 2 && entry.ActorRole == "admin"
+	})).Return(nil)
+
+	product, err := service.Transition(1, models.ProductStatusActive, "admin", 2, "")
+
+	assert.NoError(t, err)
+	assert.True(t, product.IsActive)
+	repo.AssertExpectations(t)
+}
+
+func TestModerationService_RecheckAfterEdit_SendsLiveProductBackToReview(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter([]string{"replica"}))
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(productInStatus(models.ProductStatusActive), nil)
+	repo.On("Transition", mock.AnythingOfType("*models.Product"), mock.MatchedBy(func(entry *models.ProductStatusLog) bool {
+		return entry.ActorRole == ActorSystem && entry.ToStatus == models.ProductStatusPendingReview
+	})).Return(nil)
+
+	product, err := service.RecheckAfterEdit(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStatusPendingReview, product.Status)
+	assert.True(t, product.Flagged)
+	repo.AssertExpectations(t)
+}
+
+func TestModerationService_SetShopVisibility_IncludesOwnersLegacyProducts(t *testing.T) {
+	repo := new(repository.MockModerationRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewModerationService(repo, productRepo, NewKeywordFilter(nil))
+
+	repo.On("SetShopVisibility", uint(3), uint(9), false).Return(nil)
+
+	err := service.SetShopVisibility(3, 9, false)
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
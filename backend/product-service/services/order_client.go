@@ -0,0 +1,111 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"product-service/config"
+)
+
+// DeliveredPurchase identifies an order item that reached the buyer
+type DeliveredPurchase struct {
+	OrderID     uint `json:"order_id"`
+	OrderItemID uint `json:"order_item_id"`
+	ProductID   uint `json:"product_id"`
+}
+
+// CompletedOrderItem is a delivered order line from order-service's feed
+type CompletedOrderItem struct {
+	OrderItemID uint      `json:"order_item_id"`
+	OrderID     uint      `json:"order_id"`
+	BuyerID     uint      `json:"buyer_id"`
+	ProductID   uint      `json:"product_id"`
+	Quantity    int       `json:"quantity"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// OrderClient is the subset of order-service that product-service depends on
+type OrderClient interface {
+	GetDeliveredPurchase(buyerID, productID uint) (*DeliveredPurchase, error)
+	ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedOrderItem, error)
+}
+
+type httpOrderClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOrderClient creates an OrderClient that talks to order-service over HTTP
+func NewOrderClient() OrderClient {
+	return &httpOrderClient{
+		baseURL: config.GetOrderServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetDeliveredPurchase returns the buyer's delivered order item for a product,
+// or nil when the buyer never received it
+func (c *httpOrderClient) GetDeliveredPurchase(buyerID, productID uint) (*DeliveredPurchase, error) {
+	url := fmt.Sprintf("%s/internal/orders/delivered-items?buyer_id=%d&product_id=%d", c.baseURL, buyerID, productID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("order lookup failed: %s", string(body))
+	}
+
+	var purchase DeliveredPurchase
+	if err := json.NewDecoder(resp.Body).Decode(&purchase); err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+// ListCompletedItems pages through delivered order lines updated at or after since
+func (c *httpOrderClient) ListCompletedItems(since time.Time, afterID uint, limit int) ([]CompletedOrderItem, error) {
+	url := fmt.Sprintf("%s/internal/orders/completed-items?since=%s&after_id=%d&limit=%d",
+		c.baseURL, since.UTC().Format(time.RFC3339), afterID, limit)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("completed items lookup failed: %s", string(body))
+	}
+
+	var payload struct {
+		Items []CompletedOrderItem `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload.Items, nil
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidSaleWindow = errors.New("ends_at must be after starts_at")
+	ErrInvalidSalePrice  = errors.New("sale_price must be positive and below the regular price")
+	ErrInvalidSaleLimits = errors.New("quantity_cap and per_buyer_limit cannot be negative")
+	ErrInvalidQuantity   = errors.New("quantity must be at least 1")
+	ErrProductInactive   = errors.New("product is not available")
+	ErrClaimKeyRequired  = errors.New("claim_key is required")
+)
+
+// PriceRequestItem is a product and quantity to be priced
+type PriceRequestItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// PricedItem is the authoritative price of a line item at order time
+type PricedItem struct {
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	SellerID        uint    `json:"seller_id"`
+	ShopID          uint    `json:"shop_id"`
+	CategoryID      uint    `json:"category_id"`
+	Quantity        int     `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	RegularPrice    float64 `json:"regular_price"`
+	PriceScheduleID *uint   `json:"price_schedule_id,omitempty"`
+	IsDigital       bool    `json:"is_digital"`
+	// Products shipped for a bundle line, with quantities for the whole line
+	Components []PricedComponent `json:"components,omitempty"`
+}
+
+// PricedComponent is one product contained in a priced bundle line
+type PricedComponent struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+// ItemAvailability is the current price and stock of an item a buyer intends to buy
+type ItemAvailability struct {
+	PricedItem
+	Available bool `json:"available"` // false once buyers can no longer see the product
+	InStock   int  `json:"in_stock"`
+}
+
+type PricingService interface {
+	CreateSchedule(schedule *models.PriceSchedule, role string, sellerID uint) error
+	ListSchedules(productID uint) ([]models.PriceSchedule, error)
+	DeleteSchedule(id uint, role string, sellerID uint) error
+	ApplyEffectivePrices(products []models.Product) error
+	ListFlashSales(limit int) ([]models.Product, error)
+	Quote(items []PriceRequestItem) ([]PricedItem, error)
+	Claim(buyerID uint, claimKey string, items []PriceRequestItem) ([]PricedItem, error)
+	ReleaseClaims(claimKey string, productIDs []uint) error
+	Availability(items []PriceRequestItem) ([]ItemAvailability, error)
+}
+
+type pricingService struct {
+	repo        repository.PriceRepository
+	productRepo repository.ProductRepository
+	bundles     repository.BundleRepository
+	now         func() time.Time
+}
+
+func NewPricingService(repo repository.PriceRepository, productRepo repository.ProductRepository, bundles repository.BundleRepository) PricingService {
+	return &pricingService{repo: repo, productRepo: productRepo, bundles: bundles, now: time.Now}
+}
+
+// CreateSchedule validates and stores a sale for a product the caller may manage
+func (s *pricingService) CreateSchedule(schedule *models.PriceSchedule, role string, sellerID uint) error {
+	product, err := s.productRepo.GetByID(schedule.ProductID, role, sellerID)
+	if err != nil {
+		return err
+	}
+	if !schedule.EndsAt.After(schedule.StartsAt) {
+		return ErrInvalidSaleWindow
+	}
+	if schedule.SalePrice <= 0 || schedule.SalePrice >= product.Price {
+		return ErrInvalidSalePrice
+	}
+	if schedule.QuantityCap < 0 || schedule.PerBuyerLimit < 0 {
+		return ErrInvalidSaleLimits
+	}
+	schedule.SoldCount = 0
+	return s.repo.Create(schedule)
+}
+
+// ListSchedules returns all sales configured for a product
+func (s *pricingService) ListSchedules(productID uint) ([]models.PriceSchedule, error) {
+	return s.repo.ListByProduct(productID)
+}
+
+// DeleteSchedule removes a sale from a product the caller may manage
+func (s *pricingService) DeleteSchedule(id uint, role string, sellerID uint) error {
+	schedule, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.productRepo.GetByID(schedule.ProductID, role, sellerID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// ApplyEffectivePrices fills EffectivePrice and ActiveSale on each product
+func (s *pricingService) ApplyEffectivePrices(products []models.Product) error {
+	ids := make([]uint, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	best, err := s.bestLiveSchedules(ids)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		applySchedule(&products[i], best[products[i].ID])
+	}
+	return nil
+}
+
+// ListFlashSales returns products currently on flash sale with sale prices applied
+func (s *pricingService) ListFlashSales(limit int) ([]models.Product, error) {
+	schedules, err := s.repo.ListLiveFlashSales(s.now(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]models.Product, 0, len(schedules))
+	for i := range schedules {
+		product, err := s.productRepo.GetByID(schedules[i].ProductID, "admin", 0)
+		if err != nil || !product.IsActive || !product.ShopVisible || product.StockHidden {
+			continue
+		}
+		applySchedule(product, &schedules[i])
+		products = append(products, *product)
+	}
+	return products, nil
+}
+
+// Quote prices items at their current effective price without reserving sale quantity
+func (s *pricingService) Quote(items []PriceRequestItem) ([]PricedItem, error) {
+	return s.price(items, func(schedule *models.PriceSchedule, item PriceRequestItem) bool {
+		return schedule.QuantityCap == 0 || schedule.SoldCount+item.Quantity <= schedule.QuantityCap
+	})
+}
+
+// Claim prices items for an order, counting sale units against caps and buyer limits.
+// Items that no longer fit a sale fall back to the regular price. The units are
+// recorded under the order's claimKey, which gives them back through ReleaseClaims.
+func (s *pricingService) Claim(buyerID uint, claimKey string, items []PriceRequestItem) ([]PricedItem, error) {
+	var claimErr error
+	priced, err := s.price(items, func(schedule *models.PriceSchedule, item PriceRequestItem) bool {
+		err := s.repo.ClaimSale(schedule.ID, buyerID, item.Quantity, claimKey)
+		if err != nil && !errors.Is(err, repository.ErrSaleUnavailable) {
+			claimErr = err
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claimErr != nil {
+		return nil, claimErr
+	}
+	return priced, nil
+}
+
+// ReleaseClaims gives back the sale units an order claimed for the given products, or
+// for all of them when productIDs is empty, as when the order is cancelled
+func (s *pricingService) ReleaseClaims(claimKey string, productIDs []uint) error {
+	if claimKey == "" {
+		return ErrClaimKeyRequired
+	}
+	return s.repo.ReleaseClaims(claimKey, productIDs)
+}
+
+// Availability prices items and reports their stock, for carts to revalidate against.
+// Unlike Quote it does not fail on products that are no longer sold; they come back
+// unavailable.
+func (s *pricingService) Availability(items []PriceRequestItem) ([]ItemAvailability, error) {
+	result := make([]ItemAvailability, len(items))
+	var sellable []PriceRequestItem
+	var positions []int
+	for i, item := range items {
+		if item.Quantity < 1 {
+			return nil, ErrInvalidQuantity
+		}
+		result[i].ProductID = item.ProductID
+		result[i].Quantity = item.Quantity
+
+		product, err := s.productRepo.GetByID(item.ProductID, "admin", 0)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[i].ProductName = product.Name
+		result[i].SellerID = product.SellerID
+		result[i].ShopID = product.ShopID
+		result[i].CategoryID = product.CategoryID
+		result[i].UnitPrice = product.Price
+		result[i].RegularPrice = product.Price
+		result[i].IsDigital = product.IsDigital()
+		result[i].InStock = product.Quantity
+		if product.IsActive && product.ShopVisible && !product.StockHidden {
+			sellable = append(sellable, item)
+			positions = append(positions, i)
+		}
+	}
+	if len(sellable) == 0 {
+		return result, nil
+	}
+
+	priced, err := s.Quote(sellable)
+	if err != nil {
+		return nil, err
+	}
+	for j, line := range priced {
+		result[positions[j]].PricedItem = line
+		result[positions[j]].Available = true
+	}
+	return result, nil
+}
+
+// price resolves each item's unit price, taking the first live schedule accepted by take
+func (s *pricingService) price(items []PriceRequestItem, take func(*models.PriceSchedule, PriceRequestItem) bool) ([]PricedItem, error) {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		if item.Quantity < 1 {
+			return nil, ErrInvalidQuantity
+		}
+		ids[i] = item.ProductID
+	}
+
+	live, err := s.repo.ListLive(ids, s.now())
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint][]models.PriceSchedule)
+	for _, schedule := range live {
+		byProduct[schedule.ProductID] = append(byProduct[schedule.ProductID], schedule)
+	}
+
+	// Validate every product before take runs, so a failing item never
+	// leaves sale quantity claimed for the others
+	products := make([]*models.Product, len(items))
+	var bundleIDs []uint
+	for i, item := range items {
+		product, err := s.productRepo.GetByID(item.ProductID, "admin", 0)
+		if err != nil {
+			return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+		}
+		if !product.IsActive || !product.ShopVisible || product.StockHidden {
+			return nil, fmt.Errorf("product %d: %w", item.ProductID, ErrProductInactive)
+		}
+		if product.IsBundle() {
+			bundleIDs = append(bundleIDs, product.ID)
+		}
+		products[i] = product
+	}
+	contents, err := s.bundleContents(bundleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	priced := make([]PricedItem, 0, len(items))
+	for i, item := range items {
+		product := products[i]
+		line := PricedItem{
+			ProductID:    product.ID,
+			ProductName:  product.Name,
+			SellerID:     product.SellerID,
+			ShopID:       product.ShopID,
+			CategoryID:   product.CategoryID,
+			Quantity:     item.Quantity,
+			UnitPrice:    product.Price,
+			RegularPrice: product.Price,
+			IsDigital:    product.IsDigital(),
+		}
+		for _, component := range contents[product.ID] {
+			line.Components = append(line.Components, PricedComponent{
+				ProductID:   component.ComponentID,
+				ProductName: component.Component.Name,
+				Quantity:    component.Quantity * item.Quantity,
+			})
+		}
+		for j := range byProduct[item.ProductID] {
+			schedule := &byProduct[item.ProductID][j]
+			if take(schedule, item) {
+				scheduleID := schedule.ID
+				line.UnitPrice = schedule.SalePrice
+				line.PriceScheduleID = &scheduleID
+				break
+			}
+		}
+		priced = append(priced, line)
+	}
+	return priced, nil
+}
+
+// bundleContents loads the components of the given bundles, keyed by bundle. A bundle
+// that is empty or missing a component cannot be sold.
+func (s *pricingService) bundleContents(bundleIDs []uint) (map[uint][]models.BundleComponent, error) {
+	contents := make(map[uint][]models.BundleComponent)
+	if len(bundleIDs) == 0 {
+		return contents, nil
+	}
+	components, err := s.bundles.ListComponents(bundleIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		if component.Component == nil {
+			// A component has been deleted since the bundle was put together
+			return nil, fmt.Errorf("product %d: %w", component.BundleID, ErrProductInactive)
+		}
+		contents[component.BundleID] = append(contents[component.BundleID], component)
+	}
+	for _, id := range bundleIDs {
+		if len(contents[id]) == 0 {
+			return nil, fmt.Errorf("product %d: %w", id, ErrProductInactive)
+		}
+	}
+	return contents, nil
+}
+
+// bestLiveSchedules returns the cheapest running schedule per product
+func (s *pricingService) bestLiveSchedules(productIDs []uint) (map[uint]*models.PriceSchedule, error) {
+	live, err := s.repo.ListLive(productIDs, s.now())
+	if err != nil {
+		return nil, err
+	}
+	best := make(map[uint]*models.PriceSchedule)
+	for i := range live {
+		if _, seen := best[live[i].ProductID]; !seen {
+			best[live[i].ProductID] = &live[i]
+		}
+	}
+	return best, nil
+}
+
+// applySchedule sets a product's effective price from an optional schedule
+func applySchedule(product *models.Product, schedule *models.PriceSchedule) {
+	product.EffectivePrice = product.Price
+	product.ActiveSale = nil
+	if schedule == nil {
+		return
+	}
+
+	sale := &models.ActiveSale{
+		PriceScheduleID: schedule.ID,
+		SalePrice:       schedule.SalePrice,
+		EndsAt:          schedule.EndsAt,
+		IsFlashSale:     schedule.IsFlashSale,
+		PerBuyerLimit:   schedule.PerBuyerLimit,
+	}
+	if schedule.QuantityCap > 0 {
+		remaining := schedule.QuantityCap - schedule.SoldCount
+		sale.Remaining = &remaining
+	}
+	product.EffectivePrice = schedule.SalePrice
+	product.ActiveSale = sale
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var pricingNow = time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func activeProduct(id uint, price float64) *models.Product {
+	p := &models.Product{Name: "Panjabi", Price: price, SellerID: 9, IsActive: true, ShopVisible: true}
+	p.ID = id
+	return p
+}
+
+func TestPricingService_ApplyEffectivePrices(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	products := []models.Product{*activeProduct(1, 1000), *activeProduct(2, 500)}
+	priceRepo.On("ListLive", []uint{1, 2}, pricingNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 700, EndsAt: pricingNow.Add(time.Hour), QuantityCap: 10, SoldCount: 4},
+		{ID: 8, ProductID: 1, SalePrice: 800, EndsAt: pricingNow.Add(time.Hour)},
+	}, nil)
+
+	err := service.ApplyEffectivePrices(products)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 700.0, products[0].EffectivePrice)
+	assert.Equal(t, uint(7), products[0].ActiveSale.PriceScheduleID)
+	assert.Equal(t, 6, *products[0].ActiveSale.Remaining)
+	assert.Equal(t, 500.0, products[1].EffectivePrice)
+	assert.Nil(t, products[1].ActiveSale)
+}
+
+func TestPricingService_Claim_FallsBackWhenSaleExhausted(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(activeProduct(1, 1000), nil)
+	priceRepo.On("ListLive", []uint{1}, pricingNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 700, PerBuyerLimit: 1},
+	}, nil)
+	priceRepo.On("ClaimSale", uint(7), uint(3), 2, "order-key").Return(repository.ErrSaleUnavailable)
+
+	items, err := service.Claim(3, "order-key", []PriceRequestItem{{ProductID: 1, Quantity: 2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, items[0].UnitPrice)
+	assert.Nil(t, items[0].PriceScheduleID)
+}
+
+func TestPricingService_Claim_UsesSalePrice(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(activeProduct(1, 1000), nil)
+	priceRepo.On("ListLive", []uint{1}, pricingNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 700},
+	}, nil)
+	priceRepo.On("ClaimSale", uint(7), uint(3), 1, "order-key").Return(nil)
+
+	items, err := service.Claim(3, "order-key", []PriceRequestItem{{ProductID: 1, Quantity: 1}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 700.0, items[0].UnitPrice)
+	assert.Equal(t, 1000.0, items[0].RegularPrice)
+	assert.Equal(t, uint(7), *items[0].PriceScheduleID)
+	assert.Equal(t, uint(9), items[0].SellerID)
+}
+
+func TestPricingService_Claim_InactiveProductClaimsNothing(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	inactive := activeProduct(2, 300)
+	inactive.IsActive = false
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(activeProduct(1, 1000), nil)
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(inactive, nil)
+	priceRepo.On("ListLive", []uint{1, 2}, pricingNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 700},
+	}, nil)
+
+	_, err := service.Claim(3, "order-key", []PriceRequestItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}})
+
+	assert.ErrorIs(t, err, ErrProductInactive)
+	priceRepo.AssertNotCalled(t, "ClaimSale", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPricingService_ReleaseClaims_ByOrderClaimKey(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	priceRepo.On("ReleaseClaims", "order-key", []uint{1}).Return(nil)
+
+	err := service.ReleaseClaims("order-key", []uint{1})
+
+	assert.NoError(t, err)
+	priceRepo.AssertExpectations(t)
+}
+
+func TestPricingService_ReleaseClaims_RequiresClaimKey(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	err := service.ReleaseClaims("", nil)
+
+	assert.ErrorIs(t, err, ErrClaimKeyRequired)
+	priceRepo.AssertNotCalled(t, "ReleaseClaims", mock.Anything, mock.Anything)
+}
+
+func TestPricingService_CreateSchedule_RejectsPriceAboveRegular(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	productRepo.On("GetByID", uint(1), "seller", uint(9)).Return(activeProduct(1, 1000), nil)
+
+	err := service.CreateSchedule(&models.PriceSchedule{
+		ProductID: 1,
+		SalePrice: 1200,
+		StartsAt:  pricingNow,
+		EndsAt:    pricingNow.Add(time.Hour),
+	}, "seller", 9)
+
+	assert.ErrorIs(t, err, ErrInvalidSalePrice)
+	priceRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPricingService_Availability_MarksUnsellableItems(t *testing.T) {
+	priceRepo := new(repository.MockPriceRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return pricingNow }}
+
+	inStock := activeProduct(1, 1000)
+	inStock.Quantity = 3
+	delisted := activeProduct(2, 500)
+	delisted.IsActive = false
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(inStock, nil)
+	productRepo.On("GetByID", uint(2), "admin", uint(0)).Return(delisted, nil)
+	productRepo.On("GetByID", uint(3), "admin", uint(0)).Return((*models.Product)(nil), gorm.ErrRecordNotFound)
+	priceRepo.On("ListLive", []uint{1}, pricingNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 700},
+	}, nil)
+
+	items, err := service.Availability([]PriceRequestItem{
+		{ProductID: 1, Quantity: 5},
+		{ProductID: 2, Quantity: 1},
+		{ProductID: 3, Quantity: 1},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, items[0].Available)
+	assert.Equal(t, 700.0, items[0].UnitPrice)
+	assert.Equal(t, 3, items[0].InStock)
+	assert.False(t, items[1].Available)
+	assert.Equal(t, "Panjabi", items[1].ProductName)
+	assert.False(t, items[2].Available)
+	assert.Equal(t, uint(3), items[2].ProductID)
+}
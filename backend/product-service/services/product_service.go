@@ -4,11 +4,12 @@ import (
 	"errors"
 	"product-service/models"
 	"product-service/repository"
+	"time"
 )
 
 type ProductService interface {
 	CreateProduct(product *models.Product, role string) error
-	GetAll(role string, sellerID uint, offset int, limit int) ([]models.Product, error)
+	ListProducts(query models.ProductListQuery) (*models.ProductPage, error)
 	GetByID(id uint, role string, sellerID uint) (*models.Product, error)
 	UpdateProduct(product *models.Product, role string, sellerID uint) error
 	DeleteProduct(id uint, role string, sellerID uint) error
@@ -16,15 +17,35 @@ type ProductService interface {
 	DecreaseStock(productID uint, quantity int) error
 	IncreaseStock(productID uint, quantity int) error
 	CheckAvailability(productID uint, quantity int) (bool, error)
-	SearchByName(name string) ([]models.Product, error)
+	ListLowStock(role string, sellerID uint) ([]models.Product, error)
+	AssignShop(product *models.Product, role string) error
 }
 
+var (
+	ErrShopRequired         = errors.New("shop_id is required")
+	ErrShopNotOwned         = errors.New("shop does not belong to this seller")
+	ErrShopUnavailable      = errors.New("shop is not approved or is blocked")
+	ErrInvalidThreshold     = errors.New("reorder_threshold must not be negative")
+	ErrInvalidProductType   = errors.New("type must be physical, digital or bundle")
+	ErrInvalidDownloadLimit = errors.New("download_limit must not be negative")
+	ErrBundleTypeChange     = errors.New("a product cannot be turned into or out of a bundle")
+)
+
+// Page size bounds for product listings
+const (
+	DefaultProductPageSize = 20
+	MaxProductPageSize     = 100
+)
+
 type productService struct {
-	repo repository.ProductRepository
+	repo     repository.ProductRepository
+	shops    ShopClient
+	notifier StockNotifier
+	now      func() time.Time
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+func NewProductService(repo repository.ProductRepository, shops ShopClient, notifier StockNotifier) ProductService {
+	return &productService{repo: repo, shops: shops, notifier: notifier, now: time.Now}
 }
 
 // RBAC helper function
@@ -36,17 +57,78 @@ func isVendor(role string) bool {
 	return role == "seller"
 }
 
-// CreateProduct allows only admin to create
+// validateProduct checks seller-supplied fields, defaulting the type to physical
+func validateProduct(product *models.Product) error {
+	if product.ReorderThreshold < 0 {
+		return ErrInvalidThreshold
+	}
+	switch product.Type {
+	case "":
+		product.Type = models.ProductTypePhysical
+	case models.ProductTypePhysical, models.ProductTypeDigital, models.ProductTypeBundle:
+	default:
+		return ErrInvalidProductType
+	}
+	if product.DownloadLimit < 0 {
+		return ErrInvalidDownloadLimit
+	}
+	if !product.IsDigital() {
+		product.DownloadLimit = 0
+		product.UsesLicenseKeys = false
+	}
+	return nil
+}
+
+// ownedProduct loads a product the caller may manage: admins any product, sellers only their own
+func ownedProduct(repo repository.ProductRepository, productID uint, role string, sellerID uint) (*models.Product, error) {
+	if !isAdmin(role) && !isVendor(role) {
+		return nil, ErrNotProductOwner
+	}
+	product, err := repo.GetByID(productID, role, sellerID)
+	if err != nil {
+		if isVendor(role) {
+			return nil, ErrNotProductOwner
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+// CreateProduct allows sellers and admins to create; new products start as drafts
 func (s *productService) CreateProduct(product *models.Product, role string) error {
-	if !isAdmin(role) {
-		return errors.New("unauthorized: only admin can create products")
+	if !isAdmin(role) && !isVendor(role) {
+		return errors.New("unauthorized: only sellers or admin can create products")
+	}
+	if err := validateProduct(product); err != nil {
+		return err
 	}
+	if err := s.AssignShop(product, role); err != nil {
+		return err
+	}
+	product.Status = models.ProductStatusDraft
+	product.IsActive = false
+	product.Flagged = false
+	product.FlagReasons = nil
+	product.ModerationNote = ""
+	product.Rating = 0
+	product.ReviewCount = 0
+	if product.IsBundle() {
+		// Stock follows the components once they are set
+		product.Quantity = 0
+	}
+	product.StockHidden = product.AutoHideOutOfStock && product.Quantity <= 0
 	return s.repo.Create(product)
 }
 
-// GetAll accessible to all roles, with pagination
-func (s *productService) GetAll(role string, sellerID uint, offset, limit int) ([]models.Product, error) {
-	return s.repo.GetAll(role, sellerID, offset, limit)
+// ListProducts returns a cursor-paginated, sorted and filtered page of products for the caller's role
+func (s *productService) ListProducts(query models.ProductListQuery) (*models.ProductPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultProductPageSize
+	}
+	if query.Limit > MaxProductPageSize {
+		query.Limit = MaxProductPageSize
+	}
+	return s.repo.List(query)
 }
 
 // GetByID accessible to all roles, checks seller ownership for seller
@@ -54,12 +136,31 @@ func (s *productService) GetByID(id uint, role string, sellerID uint) (*models.P
 	return s.repo.GetByID(id, role, sellerID)
 }
 
-// UpdateProduct allows only admin or product owner to update
+// UpdateProduct allows only admin or product owner to update. Quantity edits raise
+// stock events like any other stock change; a bundle's quantity cannot be edited.
 func (s *productService) UpdateProduct(product *models.Product, role string, sellerID uint) error {
 	if !isAdmin(role) && product.SellerID != sellerID {
 		return errors.New("unauthorized: only admin or owner can update products")
 	}
-	return s.repo.Update(product, role, sellerID)
+	if err := validateProduct(product); err != nil {
+		return err
+	}
+	previous, err := s.repo.GetByID(product.ID, "admin", 0)
+	if err != nil {
+		return err
+	}
+	if previous.IsBundle() != product.IsBundle() {
+		return ErrBundleTypeChange
+	}
+	if product.IsBundle() {
+		product.Quantity = previous.Quantity
+	}
+	product.StockHidden = product.AutoHideOutOfStock && product.Quantity <= 0
+	if err := s.repo.Update(product, role, sellerID); err != nil {
+		return err
+	}
+	s.notifyStock(previous.Quantity, product)
+	return nil
 }
 
 // DeleteProduct allows only admin or product owner to delete
@@ -69,25 +170,44 @@ func (s *productService) DeleteProduct(id uint, role string, sellerID uint) erro
 
 // DecreaseStock decreases stock quantity (no role check here)
 func (s *productService) DecreaseStock(productID uint, quantity int) error {
-	product, err := s.repo.GetByID(productID, "admin", 0) // admin role to bypass ownership check
-	if err != nil {
-		return err
-	}
-	if product.Quantity < quantity {
-		return errors.New("not enough stock available")
-	}
-	product.Quantity -= quantity
-	return s.repo.Update(product, "admin", 0)
+	return s.adjustStock(productID, -quantity)
 }
 
 // IncreaseStock increases stock quantity (no role check here)
 func (s *productService) IncreaseStock(productID uint, quantity int) error {
-	product, err := s.repo.GetByID(productID, "admin", 0)
+	return s.adjustStock(productID, quantity)
+}
+
+// adjustStock moves a product's stock; a bundle moves all of its components together
+func (s *productService) adjustStock(productID uint, delta int) error {
+	product, err := s.repo.AdjustStock(productID, delta)
+	if errors.Is(err, repository.ErrBundleStock) {
+		changes, err := s.repo.AdjustBundleStock(productID, delta)
+		if err != nil {
+			return err
+		}
+		s.notifyStockChanges(changes)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	product.Quantity += quantity
-	return s.repo.Update(product, "admin", 0)
+	s.notifyStock(product.Quantity-delta, product)
+	return nil
+}
+
+// notifyStockChanges raises stock events for every product a stock movement touched
+func (s *productService) notifyStockChanges(changes []repository.StockChange) {
+	for i := range changes {
+		s.notifyStock(changes[i].Before, &changes[i].Product)
+	}
+}
+
+// notifyStock sends the seller any events raised by the quantity change
+func (s *productService) notifyStock(before int, product *models.Product) {
+	for _, event := range StockEvents(before, product, s.now()) {
+		s.notifier.Notify(event)
+	}
 }
 
 // CheckAvailability verifies product availability
@@ -99,6 +219,34 @@ func (s *productService) CheckAvailability(productID uint, quantity int) (bool,
 	return product.Quantity >= quantity, nil
 }
 
-func (s *productService) SearchByName(name string) ([]models.Product, error) {
-    return s.repo.SearchByName(name)
-}
\ No newline at end of file
+// ListLowStock returns products at or below their reorder threshold or out of stock;
+// sellers see their own, admins see all
+func (s *productService) ListLowStock(role string, sellerID uint) ([]models.Product, error) {
+	if isAdmin(role) {
+		sellerID = 0
+	} else if !isVendor(role) {
+		return nil, errors.New("unauthorized: only sellers or admin can view stock alerts")
+	}
+	return s.repo.ListLowStock(sellerID)
+}
+
+// AssignShop checks that the product's shop is approved, unblocked and owned by
+// the seller. Admins may place a product in any such shop on the owner's behalf.
+func (s *productService) AssignShop(product *models.Product, role string) error {
+	if product.ShopID == 0 {
+		return ErrShopRequired
+	}
+	shop, err := s.shops.GetShop(product.ShopID)
+	if err != nil {
+		return err
+	}
+	if isVendor(role) && shop.OwnerID != product.SellerID {
+		return ErrShopNotOwned
+	}
+	if !shop.IsApproved || shop.IsBlocked {
+		return ErrShopUnavailable
+	}
+	product.SellerID = shop.OwnerID
+	product.ShopVisible = true
+	return nil
+}
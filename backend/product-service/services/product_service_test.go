@@ -5,35 +5,55 @@ import (
 	"product-service/models"
 	"product-service/repository"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-func TestProductService_GetAll(t *testing.T) {
+func TestProductService_ListProducts(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
 
-	expected := []models.Product{
-		{Name: "Product1"}, {Name: "Product2"},
+	expected := &models.ProductPage{
+		Items:      []models.Product{{Name: "Product1"}, {Name: "Product2"}},
+		NextCursor: "abc",
 	}
+	query := models.ProductListQuery{Role: "public", Sort: models.ProductSortRating, Limit: 10}
 
-	mockRepo.On("GetAll").Return(expected, nil)
+	mockRepo.On("List", query).Return(expected, nil)
 
-	result, err := service.GetAll()
+	result, err := service.ListProducts(query)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_ListProducts_ClampsLimit(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
+
+	mockRepo.On("List", models.ProductListQuery{Role: "public", Limit: DefaultProductPageSize}).Return(&models.ProductPage{}, nil).Once()
+	mockRepo.On("List", models.ProductListQuery{Role: "public", Limit: MaxProductPageSize}).Return(&models.ProductPage{}, nil).Once()
+
+	_, err := service.ListProducts(models.ProductListQuery{Role: "public"})
+	assert.NoError(t, err)
+	_, err = service.ListProducts(models.ProductListQuery{Role: "public", Limit: 500})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestProductService_GetByID(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
 
-	expected := &models.Product{ID: 1, Name: "Product1"}
-	mockRepo.On("GetByID", uint(1)).Return(expected, nil)
+	expected := &models.Product{Name: "Product1"}
+	expected.ID = 1
+	mockRepo.On("GetByID", uint(1), "public", uint(0)).Return(expected, nil)
 
-	result, err := service.GetByID(1)
+	result, err := service.GetByID(1, "public", 0)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -42,25 +62,76 @@ func TestProductService_GetByID(t *testing.T) {
 
 func TestProductService_Create(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	shops := new(MockShopClient)
+	service := NewProductService(mockRepo, shops, new(MockStockNotifier))
 
-	product := &models.Product{Name: "New Product"}
+	product := &models.Product{Name: "New Product", SellerID: 7, ShopID: 3}
+	shops.On("GetShop", uint(3)).Return(&ShopInfo{ID: 3, OwnerID: 7, IsApproved: true}, nil)
 	mockRepo.On("Create", product).Return(nil)
 
-	err := service.Create(product)
+	err := service.CreateProduct(product, "seller")
 
 	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStatusDraft, product.Status)
+	assert.True(t, product.ShopVisible)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_Create_ShopRules(t *testing.T) {
+	cases := []struct {
+		name    string
+		shopID  uint
+		shop    *ShopInfo
+		shopErr error
+		want    error
+	}{
+		{name: "missing shop", shopID: 0, want: ErrShopRequired},
+		{name: "unknown shop", shopID: 3, shopErr: ErrShopNotFound, want: ErrShopNotFound},
+		{name: "someone else's shop", shopID: 3, shop: &ShopInfo{ID: 3, OwnerID: 8, IsApproved: true}, want: ErrShopNotOwned},
+		{name: "not approved", shopID: 3, shop: &ShopInfo{ID: 3, OwnerID: 7}, want: ErrShopUnavailable},
+		{name: "blocked", shopID: 3, shop: &ShopInfo{ID: 3, OwnerID: 7, IsApproved: true, IsBlocked: true}, want: ErrShopUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(repository.MockProductRepository)
+			shops := new(MockShopClient)
+			service := NewProductService(mockRepo, shops, new(MockStockNotifier))
+			if tc.shopID != 0 {
+				shops.On("GetShop", tc.shopID).Return(tc.shop, tc.shopErr)
+			}
+
+			err := service.CreateProduct(&models.Product{Name: "New Product", SellerID: 7, ShopID: tc.shopID}, "seller")
+
+			assert.ErrorIs(t, err, tc.want)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestProductService_AssignShop_AdminUsesShopOwner(t *testing.T) {
+	shops := new(MockShopClient)
+	service := NewProductService(new(repository.MockProductRepository), shops, new(MockStockNotifier))
+
+	product := &models.Product{SellerID: 1, ShopID: 3}
+	shops.On("GetShop", uint(3)).Return(&ShopInfo{ID: 3, OwnerID: 7, IsApproved: true}, nil)
+
+	err := service.AssignShop(product, "admin")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), product.SellerID)
+}
+
 func TestProductService_Update(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
 
-	product := &models.Product{ID: 1, Name: "Updated Product"}
-	mockRepo.On("Update", product).Return(nil)
+	product := &models.Product{Name: "Updated Product", SellerID: 7}
+	product.ID = 1
+	mockRepo.On("GetByID", uint(1), "admin", uint(0)).Return(&models.Product{}, nil)
+	mockRepo.On("Update", product, "seller", uint(7)).Return(nil)
 
-	err := service.Update(product)
+	err := service.UpdateProduct(product, "seller", 7)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -68,11 +139,11 @@ func TestProductService_Update(t *testing.T) {
 
 func TestProductService_Delete(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
 
-	mockRepo.On("Delete", uint(1)).Return(nil)
+	mockRepo.On("Delete", uint(1), "admin", uint(0)).Return(nil)
 
-	err := service.Delete(1)
+	err := service.DeleteProduct(1, "admin", 0)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -80,12 +151,108 @@ func TestProductService_Delete(t *testing.T) {
 
 func TestProductService_GetByID_Error(t *testing.T) {
 	mockRepo := new(repository.MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
 
-	mockRepo.On("GetByID", uint(2)).Return(&models.Product{}, errors.New("not found"))
+	mockRepo.On("GetByID", uint(2), "public", uint(0)).Return(&models.Product{}, errors.New("not found"))
 
-	_, err := service.GetByID(2)
+	_, err := service.GetByID(2, "public", 0)
 
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func stockedProduct(quantity, threshold int) *models.Product {
+	p := &models.Product{Name: "Shari", SellerID: 7, ShopID: 3, Quantity: quantity, ReorderThreshold: threshold}
+	p.ID = 1
+	return p
+}
+
+func TestStockEvents(t *testing.T) {
+	at := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		before   int
+		product  *models.Product
+		expected []string
+	}{
+		{"crosses threshold", 6, stockedProduct(5, 5), []string{models.StockEventLow}},
+		{"already below threshold", 4, stockedProduct(3, 5), nil},
+		{"no threshold set", 6, stockedProduct(1, 0), nil},
+		{"sells out", 2, stockedProduct(0, 5), []string{models.StockEventOut}},
+		{"restocked above threshold", 0, stockedProduct(10, 5), []string{models.StockEventBackInStock}},
+		{"restocked below threshold", 0, stockedProduct(2, 5), []string{models.StockEventBackInStock, models.StockEventLow}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var types []string
+			for _, event := range StockEvents(tc.before, tc.product, at) {
+				types = append(types, event.Type)
+				assert.Equal(t, tc.product.Quantity, event.Quantity)
+				assert.Equal(t, uint(7), event.SellerID)
+			}
+			assert.Equal(t, tc.expected, types)
+		})
+	}
+}
+
+func TestProductService_DecreaseStock_NotifiesWhenSoldOut(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := NewProductService(mockRepo, new(MockShopClient), notifier)
+
+	soldOut := stockedProduct(0, 5)
+	soldOut.AutoHideOutOfStock = true
+	soldOut.StockHidden = true
+	mockRepo.On("AdjustStock", uint(1), -2).Return(soldOut, nil)
+	notifier.On("Notify", mock.MatchedBy(func(event models.StockEvent) bool {
+		return event.Type == models.StockEventOut && event.Hidden
+	})).Return()
+
+	err := service.DecreaseStock(1, 2)
+
+	assert.NoError(t, err)
+	notifier.AssertNumberOfCalls(t, "Notify", 1)
+}
+
+func TestProductService_DecreaseStock_Insufficient(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := NewProductService(mockRepo, new(MockShopClient), notifier)
+
+	mockRepo.On("AdjustStock", uint(1), -9).Return(nil, repository.ErrInsufficientStock)
+
+	err := service.DecreaseStock(1, 9)
+
+	assert.ErrorIs(t, err, repository.ErrInsufficientStock)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestProductService_Update_AutoHidesAtZero(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	notifier := new(MockStockNotifier)
+	service := NewProductService(mockRepo, new(MockShopClient), notifier)
+
+	product := stockedProduct(0, 0)
+	product.AutoHideOutOfStock = true
+	mockRepo.On("GetByID", uint(1), "admin", uint(0)).Return(stockedProduct(4, 0), nil)
+	mockRepo.On("Update", product, "seller", uint(7)).Return(nil)
+	notifier.On("Notify", mock.Anything).Return()
+
+	err := service.UpdateProduct(product, "seller", 7)
+
+	assert.NoError(t, err)
+	assert.True(t, product.StockHidden)
+	notifier.AssertNumberOfCalls(t, "Notify", 1)
+}
+
+func TestProductService_ListLowStock_AdminSeesAllSellers(t *testing.T) {
+	mockRepo := new(repository.MockProductRepository)
+	service := NewProductService(mockRepo, new(MockShopClient), new(MockStockNotifier))
+
+	mockRepo.On("ListLowStock", uint(0)).Return([]models.Product{*stockedProduct(1, 5)}, nil)
+
+	products, err := service.ListLowStock("admin", 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	minQuestionLen = 5
+	maxQuestionLen = 1000
+	maxAnswerLen   = 2000
+	maxReasonLen   = 500
+
+	// reportsToHide hides a question pending admin review once this many users report it
+	reportsToHide    = 5
+	overdueBatchSize = 1000
+)
+
+var (
+	ErrQuestionNotFound      = errors.New("question not found")
+	ErrInvalidQuestion       = errors.New("question must be between 5 and 1000 characters")
+	ErrInvalidAnswer         = errors.New("answer must be between 1 and 2000 characters")
+	ErrInvalidReportReason   = errors.New("reason must be between 1 and 500 characters")
+	ErrInvalidQuestionStatus = errors.New("status must be one of published, hidden, rejected")
+	ErrAlreadyVoted          = errors.New("you have already upvoted this question")
+	ErrNotVoted              = errors.New("you have not upvoted this question")
+)
+
+type QuestionService interface {
+	Ask(productID, buyerID uint, body string) (*models.Question, error)
+	List(productID uint, filter repository.QuestionFilter) ([]models.Question, error)
+	Answer(questionID, sellerID uint, answer string) (*models.Question, error)
+	Upvote(questionID, userID uint) error
+	RemoveUpvote(questionID, userID uint) error
+	Report(questionID, reporterID uint, reason string) error
+	Moderate(questionID uint, status, note string) (*models.Question, error)
+	ListUnanswered(sellerID uint, offset, limit int) ([]models.Question, error)
+	ListReported(offset, limit int) ([]models.Question, error)
+	RemindOverdue() error
+}
+
+type questionService struct {
+	repo        repository.QuestionRepository
+	productRepo repository.ProductRepository
+	notifier    SellerNotifier
+	answerSLA   time.Duration
+	now         func() time.Time
+}
+
+func NewQuestionService(repo repository.QuestionRepository, productRepo repository.ProductRepository, notifier SellerNotifier, answerSLA time.Duration) QuestionService {
+	return &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: answerSLA, now: time.Now}
+}
+
+// Ask posts a buyer's question on a visible product
+func (s *questionService) Ask(productID, buyerID uint, body string) (*models.Question, error) {
+	body = strings.TrimSpace(body)
+	if len(body) < minQuestionLen || len(body) > maxQuestionLen {
+		return nil, ErrInvalidQuestion
+	}
+	if _, err := s.productRepo.GetByID(productID, "public", 0); err != nil {
+		return nil, err
+	}
+
+	question := &models.Question{
+		ProductID: productID,
+		BuyerID:   buyerID,
+		Body:      body,
+		Status:    models.QuestionStatusPublished,
+	}
+	if err := s.repo.Create(question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// List returns a page of a product's published questions
+func (s *questionService) List(productID uint, filter repository.QuestionFilter) ([]models.Question, error) {
+	return s.repo.ListByProduct(productID, filter)
+}
+
+// Answer lets the product's seller publish or replace the answer to a question
+func (s *questionService) Answer(questionID, sellerID uint, answer string) (*models.Question, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" || len(answer) > maxAnswerLen {
+		return nil, ErrInvalidAnswer
+	}
+	question, err := s.repo.GetByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.productRepo.GetByID(question.ProductID, "seller", sellerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotProductOwner
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	question.Answer = answer
+	question.AnsweredBy = sellerID
+	question.AnsweredAt = &now
+	question.IsAnswered = true
+	if err := s.repo.SaveAnswer(question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// Upvote counts a user's vote for a published question, once per user
+func (s *questionService) Upvote(questionID, userID uint) error {
+	if _, err := s.published(questionID); err != nil {
+		return err
+	}
+	added, err := s.repo.AddVote(questionID, userID)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrAlreadyVoted
+	}
+	return nil
+}
+
+// RemoveUpvote withdraws a user's vote
+func (s *questionService) RemoveUpvote(questionID, userID uint) error {
+	removed, err := s.repo.RemoveVote(questionID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrNotVoted
+	}
+	return nil
+}
+
+// Report flags a question as abusive. Repeat reports by the same user are ignored, and
+// a question reported by enough users is hidden until an admin reviews it.
+func (s *questionService) Report(questionID, reporterID uint, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxReasonLen {
+		return ErrInvalidReportReason
+	}
+	question, err := s.published(questionID)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repo.AddReport(&models.QuestionReport{QuestionID: questionID, ReporterID: reporterID, Reason: reason})
+	if err != nil {
+		return err
+	}
+	if count >= reportsToHide {
+		return s.repo.SetStatus(question.ID, models.QuestionStatusHidden, "hidden automatically after repeated reports")
+	}
+	return nil
+}
+
+// Moderate changes a question's visibility
+func (s *questionService) Moderate(questionID uint, status, note string) (*models.Question, error) {
+	switch status {
+	case models.QuestionStatusPublished, models.QuestionStatusHidden, models.QuestionStatusRejected:
+	default:
+		return nil, ErrInvalidQuestionStatus
+	}
+
+	question, err := s.repo.GetByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetStatus(question.ID, status, note); err != nil {
+		return nil, err
+	}
+	question.Status = status
+	question.ModerationNote = note
+	return question, nil
+}
+
+// ListUnanswered returns questions on the seller's products awaiting an answer
+func (s *questionService) ListUnanswered(sellerID uint, offset, limit int) ([]models.Question, error) {
+	return s.repo.ListUnansweredBySeller(sellerID, offset, limit)
+}
+
+// ListReported returns questions with abuse reports for admin review
+func (s *questionService) ListReported(offset, limit int) ([]models.Question, error) {
+	return s.repo.ListReported(offset, limit)
+}
+
+// RemindOverdue sends each seller one reminder listing their questions left unanswered
+// past the SLA. Every question is included in at most one reminder.
+func (s *questionService) RemindOverdue() error {
+	now := s.now()
+	overdue, err := s.repo.ListOverdue(now.Add(-s.answerSLA), overdueBatchSize)
+	if err != nil || len(overdue) == 0 {
+		return err
+	}
+
+	bySeller := make(map[uint]*models.SellerNotification)
+	var sellers []uint
+	ids := make([]uint, 0, len(overdue))
+	for _, question := range overdue {
+		ids = append(ids, question.QuestionID)
+		notification, ok := bySeller[question.SellerID]
+		if !ok {
+			// Rows arrive oldest first
+			notification = &models.SellerNotification{
+				Type:      models.NotificationUnansweredQuestions,
+				SellerID:  question.SellerID,
+				OldestAt:  question.CreatedAt,
+				CreatedAt: now,
+			}
+			bySeller[question.SellerID] = notification
+			sellers = append(sellers, question.SellerID)
+		}
+		notification.QuestionIDs = append(notification.QuestionIDs, question.QuestionID)
+	}
+
+	// Mark first so a failed write cannot cause duplicate reminders
+	if err := s.repo.MarkSLANotified(ids, now); err != nil {
+		return err
+	}
+	for _, sellerID := range sellers {
+		s.notifier.Notify(*bySeller[sellerID])
+	}
+	return nil
+}
+
+// published loads a question that is visible to buyers
+func (s *questionService) published(questionID uint) (*models.Question, error) {
+	question, err := s.repo.GetByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Status != models.QuestionStatusPublished {
+		return nil, ErrQuestionNotFound
+	}
+	return question, nil
+}
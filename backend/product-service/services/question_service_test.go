@@ -0,0 +1,149 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var questionNow = time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+
+func TestQuestionService_Ask_RejectsHiddenProduct(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	productRepo.On("GetByID", uint(1), "public", uint(0)).Return((*models.Product)(nil), gorm.ErrRecordNotFound)
+
+	_, err := service.Ask(1, 5, "Is this pure cotton?")
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestQuestionService_Ask_ValidatesLength(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	_, err := service.Ask(1, 5, " ok? ")
+
+	assert.ErrorIs(t, err, ErrInvalidQuestion)
+}
+
+func TestQuestionService_Answer_MarksAnswered(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	question := &models.Question{ID: 3, ProductID: 1, Status: models.QuestionStatusPublished}
+	repo.On("GetByID", uint(3)).Return(question, nil)
+	productRepo.On("GetByID", uint(1), "seller", uint(9)).Return(activeProduct(1, 100), nil)
+	repo.On("SaveAnswer", question).Return(nil)
+
+	answered, err := service.Answer(3, 9, "Yes, 100% cotton")
+
+	assert.NoError(t, err)
+	assert.True(t, answered.IsAnswered)
+	assert.Equal(t, uint(9), answered.AnsweredBy)
+	assert.Equal(t, questionNow, *answered.AnsweredAt)
+}
+
+func TestQuestionService_Answer_OnlyProductSeller(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	repo.On("GetByID", uint(3)).Return(&models.Question{ID: 3, ProductID: 1}, nil)
+	productRepo.On("GetByID", uint(1), "seller", uint(4)).Return((*models.Product)(nil), gorm.ErrRecordNotFound)
+
+	_, err := service.Answer(3, 4, "Yes")
+
+	assert.ErrorIs(t, err, ErrNotProductOwner)
+	repo.AssertNotCalled(t, "SaveAnswer", mock.Anything)
+}
+
+func TestQuestionService_Answer_PassesOnLookupFailure(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	repo.On("GetByID", uint(3)).Return(&models.Question{ID: 3, ProductID: 1}, nil)
+	productRepo.On("GetByID", uint(1), "seller", uint(9)).Return((*models.Product)(nil), gorm.ErrInvalidDB)
+
+	_, err := service.Answer(3, 9, "Yes")
+
+	assert.ErrorIs(t, err, gorm.ErrInvalidDB)
+	assert.NotErrorIs(t, err, ErrNotProductOwner)
+	repo.AssertNotCalled(t, "SaveAnswer", mock.Anything)
+}
+
+func TestQuestionService_Upvote_OncePerUser(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	repo.On("GetByID", uint(3)).Return(&models.Question{ID: 3, Status: models.QuestionStatusPublished}, nil)
+	repo.On("AddVote", uint(3), uint(5)).Return(false, nil)
+
+	err := service.Upvote(3, 5)
+
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+}
+
+func TestQuestionService_Report_HidesAfterRepeatedReports(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	question := &models.Question{ID: 3, Status: models.QuestionStatusPublished, ReportCount: reportsToHide - 1}
+	repo.On("GetByID", uint(3)).Return(question, nil)
+	repo.On("AddReport", mock.MatchedBy(func(report *models.QuestionReport) bool {
+		return report.QuestionID == 3 && report.ReporterID == 5 && report.Reason == "spam"
+	})).Return(reportsToHide, nil)
+	repo.On("SetStatus", uint(3), models.QuestionStatusHidden, "hidden automatically after repeated reports").Return(nil)
+
+	err := service.Report(3, 5, " spam ")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestQuestionService_RemindOverdue_GroupsBySeller(t *testing.T) {
+	repo := new(repository.MockQuestionRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockSellerNotifier)
+	service := &questionService{repo: repo, productRepo: productRepo, notifier: notifier, answerSLA: 24 * time.Hour, now: func() time.Time { return questionNow }}
+
+	oldest := questionNow.Add(-72 * time.Hour)
+	repo.On("ListOverdue", questionNow.Add(-24*time.Hour), overdueBatchSize).Return([]repository.OverdueQuestion{
+		{QuestionID: 1, SellerID: 9, CreatedAt: oldest},
+		{QuestionID: 2, SellerID: 7, CreatedAt: oldest.Add(time.Hour)},
+		{QuestionID: 3, SellerID: 9, CreatedAt: oldest.Add(2 * time.Hour)},
+	}, nil)
+	repo.On("MarkSLANotified", []uint{1, 2, 3}, questionNow).Return(nil)
+	notifier.On("Notify", models.SellerNotification{
+		Type: models.NotificationUnansweredQuestions, SellerID: 9, QuestionIDs: []uint{1, 3}, OldestAt: oldest, CreatedAt: questionNow,
+	}).Return()
+	notifier.On("Notify", models.SellerNotification{
+		Type: models.NotificationUnansweredQuestions, SellerID: 7, QuestionIDs: []uint{2}, OldestAt: oldest.Add(time.Hour), CreatedAt: questionNow,
+	}).Return()
+
+	err := service.RemindOverdue()
+
+	assert.NoError(t, err)
+	notifier.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+const (
+	// completedItemsSync names the high-water mark of the order-service import
+	completedItemsSync = "recommendations.completed_items"
+	// importOverlap re-reads a window before the last sync; already imported lines are skipped
+	importOverlap  = time.Hour
+	importPageSize = 500
+
+	activeBuyerWindow   = 30 * 24 * time.Hour
+	storedPerBuyer      = 20
+	recentViewSeeds     = 10
+	associationsPerSeed = 20
+
+	purchaseSeedWeight = 2.0
+	viewSeedWeight     = 1.0
+)
+
+type RecommendationService interface {
+	FrequentlyBoughtTogether(productID uint, limit int) ([]models.Product, error)
+	ForBuyer(buyerID uint, limit int) ([]models.Product, error)
+	Bestsellers(categoryID uint, limit int) ([]models.Product, error)
+	RecordView(buyerID, productID uint) error
+	Recompute() error
+}
+
+type recommendationService struct {
+	repo        repository.RecommendationRepository
+	productRepo repository.ProductRepository
+	orders      OrderClient
+	now         func() time.Time
+}
+
+func NewRecommendationService(repo repository.RecommendationRepository, productRepo repository.ProductRepository, orders OrderClient) RecommendationService {
+	return &recommendationService{repo: repo, productRepo: productRepo, orders: orders, now: time.Now}
+}
+
+// FrequentlyBoughtTogether returns products most often ordered with the given one,
+// topped up with bestsellers from its category
+func (s *recommendationService) FrequentlyBoughtTogether(productID uint, limit int) ([]models.Product, error) {
+	product, err := s.productRepo.GetByID(productID, "public", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Over-fetch, since some related products may no longer be visible
+	associations, err := s.repo.ListAssociations([]uint{productID}, limit*2)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]uint, 0, len(associations))
+	for _, association := range associations {
+		candidates = append(candidates, association.RelatedProductID)
+	}
+
+	return s.withFallback(candidates, map[uint]bool{productID: true}, product.CategoryID, limit)
+}
+
+// ForBuyer returns the buyer's precomputed recommendations, topped up with overall bestsellers
+func (s *recommendationService) ForBuyer(buyerID uint, limit int) ([]models.Product, error) {
+	recs, err := s.repo.ListBuyerRecommendations(buyerID, limit*2)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]uint, 0, len(recs))
+	for _, rec := range recs {
+		candidates = append(candidates, rec.ProductID)
+	}
+
+	purchased, err := s.repo.ListPurchasedProductIDs(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := make(map[uint]bool, len(purchased))
+	for _, id := range purchased {
+		exclude[id] = true
+	}
+
+	return s.withFallback(candidates, exclude, 0, limit)
+}
+
+// Bestsellers returns the best-selling visible products, optionally within a category
+func (s *recommendationService) Bestsellers(categoryID uint, limit int) ([]models.Product, error) {
+	return s.withFallback(nil, nil, categoryID, limit)
+}
+
+// RecordView adds a product to the buyer's browsing history
+func (s *recommendationService) RecordView(buyerID, productID uint) error {
+	if _, err := s.productRepo.GetByID(productID, "public", 0); err != nil {
+		return err
+	}
+	return s.repo.RecordView(buyerID, productID, s.now())
+}
+
+// Recompute imports newly delivered orders, rebuilds co-purchase associations and
+// refreshes stored recommendations for recently active buyers
+func (s *recommendationService) Recompute() error {
+	if err := s.importPurchases(); err != nil {
+		return fmt.Errorf("import purchases: %w", err)
+	}
+	if err := s.repo.RebuildAssociations(); err != nil {
+		return fmt.Errorf("rebuild associations: %w", err)
+	}
+
+	buyerIDs, err := s.repo.ListActiveBuyers(s.now().Add(-activeBuyerWindow))
+	if err != nil {
+		return fmt.Errorf("list active buyers: %w", err)
+	}
+	// One buyer failing should not stop the rest of the batch
+	var errs []error
+	for _, buyerID := range buyerIDs {
+		if err := s.recomputeBuyer(buyerID); err != nil {
+			errs = append(errs, fmt.Errorf("buyer %d: %w", buyerID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importPurchases pulls delivered order lines from order-service since the last run
+func (s *recommendationService) importPurchases() error {
+	last, err := s.repo.GetSyncState(completedItemsSync)
+	if err != nil {
+		return err
+	}
+	startedAt := s.now()
+	since := last
+	if !since.IsZero() {
+		since = since.Add(-importOverlap)
+	}
+
+	var afterID uint
+	for {
+		items, err := s.orders.ListCompletedItems(since, afterID, importPageSize)
+		if err != nil {
+			return err
+		}
+		records := make([]models.PurchaseRecord, len(items))
+		for i, item := range items {
+			records[i] = models.PurchaseRecord{
+				OrderItemID: item.OrderItemID,
+				OrderID:     item.OrderID,
+				BuyerID:     item.BuyerID,
+				ProductID:   item.ProductID,
+				Quantity:    item.Quantity,
+				PurchasedAt: item.CompletedAt,
+			}
+		}
+		if err := s.repo.SavePurchases(records); err != nil {
+			return err
+		}
+		if len(items) < importPageSize {
+			break
+		}
+		afterID = items[len(items)-1].OrderItemID
+	}
+
+	return s.repo.SetSyncState(completedItemsSync, startedAt)
+}
+
+// recomputeBuyer scores products associated with the buyer's purchases and views
+func (s *recommendationService) recomputeBuyer(buyerID uint) error {
+	purchased, err := s.repo.ListPurchasedProductIDs(buyerID)
+	if err != nil {
+		return err
+	}
+	views, err := s.repo.ListRecentViews(buyerID, recentViewSeeds)
+	if err != nil {
+		return err
+	}
+
+	seeds := append([]uint{}, purchased...)
+	for _, view := range views {
+		seeds = append(seeds, view.ProductID)
+	}
+	associations, err := s.repo.ListAssociations(seeds, len(seeds)*associationsPerSeed)
+	if err != nil {
+		return err
+	}
+
+	recs := RankRecommendations(buyerID, purchased, views, associations, storedPerBuyer)
+	updatedAt := s.now()
+	for i := range recs {
+		recs[i].UpdatedAt = updatedAt
+	}
+	return s.repo.ReplaceBuyerRecommendations(buyerID, recs)
+}
+
+// RankRecommendations scores candidates by their association strength with the
+// buyer's purchases (weighted higher) and recent views. Products the buyer has
+// already bought or viewed are not recommended. Ties break on product id.
+func RankRecommendations(buyerID uint, purchased []uint, views []models.ProductView, associations []models.ProductAssociation, limit int) []models.BuyerRecommendation {
+	weights := make(map[uint]float64)
+	for _, view := range views {
+		weights[view.ProductID] = viewSeedWeight
+	}
+	for _, id := range purchased {
+		weights[id] = purchaseSeedWeight
+	}
+
+	scores := make(map[uint]float64)
+	for _, association := range associations {
+		weight, isSeed := weights[association.ProductID]
+		if !isSeed {
+			continue
+		}
+		if _, seen := weights[association.RelatedProductID]; seen {
+			continue
+		}
+		scores[association.RelatedProductID] += weight * association.Score
+	}
+
+	recs := make([]models.BuyerRecommendation, 0, len(scores))
+	for productID, score := range scores {
+		recs = append(recs, models.BuyerRecommendation{BuyerID: buyerID, ProductID: productID, Score: score})
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Score != recs[j].Score {
+			return recs[i].Score > recs[j].Score
+		}
+		return recs[i].ProductID < recs[j].ProductID
+	})
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+	for i := range recs {
+		recs[i].Rank = i + 1
+	}
+	return recs
+}
+
+// withFallback loads visible candidates in order, then fills up to limit with
+// bestsellers from the category (or overall when categoryID is 0)
+func (s *recommendationService) withFallback(candidates []uint, exclude map[uint]bool, categoryID uint, limit int) ([]models.Product, error) {
+	picked := make(map[uint]bool)
+	for id := range exclude {
+		picked[id] = true
+	}
+
+	products, err := s.loadInOrder(candidates, picked, limit)
+	if err != nil || len(products) >= limit {
+		return products, err
+	}
+
+	bestsellers, err := s.repo.ListBestsellers(categoryID, limit+len(picked))
+	if err != nil {
+		return nil, err
+	}
+	more, err := s.loadInOrder(bestsellers, picked, limit-len(products))
+	if err != nil {
+		return nil, err
+	}
+	return append(products, more...), nil
+}
+
+// loadInOrder returns up to limit visible products in the order of ids, skipping
+// ids already picked and marking the returned ones as picked
+func (s *recommendationService) loadInOrder(ids []uint, picked map[uint]bool, limit int) ([]models.Product, error) {
+	products := make([]models.Product, 0, limit)
+	if len(ids) == 0 || limit <= 0 {
+		return products, nil
+	}
+
+	visible, err := s.productRepo.GetVisibleByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(visible))
+	for _, product := range visible {
+		byID[product.ID] = product
+	}
+
+	for _, id := range ids {
+		if len(products) == limit {
+			break
+		}
+		product, ok := byID[id]
+		if !ok || picked[id] {
+			continue
+		}
+		picked[id] = true
+		products = append(products, product)
+	}
+	return products, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var recommendationNow = time.Date(2025, 8, 1, 3, 0, 0, 0, time.UTC)
+
+func visibleProduct(id, categoryID uint) models.Product {
+	p := models.Product{Name: "Product", CategoryID: categoryID}
+	p.ID = id
+	return p
+}
+
+func productIDs(products []models.Product) []uint {
+	ids := make([]uint, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestRankRecommendations_WeightsPurchasesAndSkipsKnownProducts(t *testing.T) {
+	purchased := []uint{1}
+	views := []models.ProductView{{BuyerID: 5, ProductID: 2}}
+	associations := []models.ProductAssociation{
+		{ProductID: 1, RelatedProductID: 10, Score: 3}, // 2 * 3 = 6
+		{ProductID: 2, RelatedProductID: 11, Score: 5}, // 1 * 5 = 5
+		{ProductID: 2, RelatedProductID: 10, Score: 1}, // +1 → 10 scores 7
+		{ProductID: 1, RelatedProductID: 2, Score: 9},  // already viewed
+		{ProductID: 2, RelatedProductID: 12, Score: 5}, // ties with 11, higher id
+	}
+
+	recs := RankRecommendations(5, purchased, views, associations, 10)
+
+	assert.Equal(t, []models.BuyerRecommendation{
+		{BuyerID: 5, ProductID: 10, Score: 7, Rank: 1},
+		{BuyerID: 5, ProductID: 11, Score: 5, Rank: 2},
+		{BuyerID: 5, ProductID: 12, Score: 5, Rank: 3},
+	}, recs)
+}
+
+func TestRankRecommendations_RespectsLimit(t *testing.T) {
+	associations := []models.ProductAssociation{
+		{ProductID: 1, RelatedProductID: 10, Score: 1},
+		{ProductID: 1, RelatedProductID: 11, Score: 2},
+	}
+
+	recs := RankRecommendations(5, []uint{1}, nil, associations, 1)
+
+	assert.Len(t, recs, 1)
+	assert.Equal(t, uint(11), recs[0].ProductID)
+}
+
+func TestFrequentlyBoughtTogether_FallsBackToCategoryBestsellers(t *testing.T) {
+	repo := new(repository.MockRecommendationRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := &recommendationService{repo: repo, productRepo: productRepo, orders: orders, now: func() time.Time { return recommendationNow }}
+
+	base := visibleProduct(1, 4)
+	productRepo.On("GetByID", uint(1), "public", uint(0)).Return(&base, nil)
+	repo.On("ListAssociations", []uint{1}, 6).Return([]models.ProductAssociation{
+		{ProductID: 1, RelatedProductID: 20, Score: 4},
+		{ProductID: 1, RelatedProductID: 21, Score: 2}, // hidden since
+	}, nil)
+	productRepo.On("GetVisibleByIDs", []uint{20, 21}).Return([]models.Product{visibleProduct(20, 4)}, nil)
+	repo.On("ListBestsellers", uint(4), 5).Return([]uint{1, 20, 30, 31}, nil)
+	productRepo.On("GetVisibleByIDs", []uint{1, 20, 30, 31}).Return([]models.Product{
+		visibleProduct(1, 4), visibleProduct(20, 4), visibleProduct(30, 4), visibleProduct(31, 4),
+	}, nil)
+
+	products, err := service.FrequentlyBoughtTogether(1, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{20, 30, 31}, productIDs(products))
+}
+
+func TestForBuyer_NoHistoryUsesBestsellers(t *testing.T) {
+	repo := new(repository.MockRecommendationRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := &recommendationService{repo: repo, productRepo: productRepo, orders: orders, now: func() time.Time { return recommendationNow }}
+
+	repo.On("ListBuyerRecommendations", uint(5), 4).Return(nil, nil)
+	repo.On("ListPurchasedProductIDs", uint(5)).Return(nil, nil)
+	repo.On("ListBestsellers", uint(0), 2).Return([]uint{7, 8}, nil)
+	productRepo.On("GetVisibleByIDs", []uint{7, 8}).Return([]models.Product{visibleProduct(8, 1), visibleProduct(7, 1)}, nil)
+
+	products, err := service.ForBuyer(5, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{7, 8}, productIDs(products))
+}
+
+func TestRecompute_ImportsFeedAndStoresRecommendations(t *testing.T) {
+	repo := new(repository.MockRecommendationRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := &recommendationService{repo: repo, productRepo: productRepo, orders: orders, now: func() time.Time { return recommendationNow }}
+
+	lastSync := recommendationNow.Add(-24 * time.Hour)
+	repo.On("GetSyncState", completedItemsSync).Return(lastSync, nil)
+	orders.On("ListCompletedItems", lastSync.Add(-importOverlap), uint(0), importPageSize).Return([]CompletedOrderItem{
+		{OrderItemID: 1, OrderID: 9, BuyerID: 5, ProductID: 1, Quantity: 1},
+		{OrderItemID: 2, OrderID: 9, BuyerID: 5, ProductID: 2, Quantity: 1},
+	}, nil)
+	repo.On("SavePurchases", mock.MatchedBy(func(records []models.PurchaseRecord) bool {
+		return len(records) == 2 && records[1].OrderItemID == 2 && records[1].BuyerID == 5
+	})).Return(nil)
+	repo.On("SetSyncState", completedItemsSync, recommendationNow).Return(nil)
+	repo.On("RebuildAssociations").Return(nil)
+	repo.On("ListActiveBuyers", recommendationNow.Add(-activeBuyerWindow)).Return([]uint{5}, nil)
+	repo.On("ListPurchasedProductIDs", uint(5)).Return([]uint{1, 2}, nil)
+	repo.On("ListRecentViews", uint(5), recentViewSeeds).Return(nil, nil)
+	repo.On("ListAssociations", []uint{1, 2}, 2*associationsPerSeed).Return([]models.ProductAssociation{
+		{ProductID: 1, RelatedProductID: 3, Score: 2},
+	}, nil)
+	repo.On("ReplaceBuyerRecommendations", uint(5), []models.BuyerRecommendation{
+		{BuyerID: 5, ProductID: 3, Score: 4, Rank: 1, UpdatedAt: recommendationNow},
+	}).Return(nil)
+
+	err := service.Recompute()
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	orders.AssertExpectations(t)
+}
+
+func TestRecompute_FeedFailureKeepsSyncState(t *testing.T) {
+	repo := new(repository.MockRecommendationRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := &recommendationService{repo: repo, productRepo: productRepo, orders: orders, now: func() time.Time { return recommendationNow }}
+
+	repo.On("GetSyncState", completedItemsSync).Return(time.Time{}, nil)
+	orders.On("ListCompletedItems", time.Time{}, uint(0), importPageSize).Return(nil, errors.New("order-service down"))
+
+	err := service.Recompute()
+
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "SetSyncState", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "RebuildAssociations")
+}
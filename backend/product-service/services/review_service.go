@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"gorm.io/gorm"
+)
+
+const maxReviewPhotos = 5
+
+var (
+	ErrInvalidRating       = errors.New("rating must be between 1 and 5")
+	ErrTooManyPhotos       = errors.New("a review can have at most 5 photos")
+	ErrNotDelivered        = errors.New("only buyers with a delivered order for this product can review it")
+	ErrAlreadyReviewed     = errors.New("this purchase has already been reviewed")
+	ErrNotProductOwner     = errors.New("unauthorized: only the product's seller can reply")
+	ErrInvalidReviewStatus = errors.New("status must be one of published, hidden, rejected")
+)
+
+type ReviewService interface {
+	CreateReview(review *models.Review) error
+	ListReviews(productID uint, offset, limit int) ([]models.Review, error)
+	ReplyToReview(reviewID, sellerID uint, reply string) (*models.Review, error)
+	ModerateReview(reviewID uint, status, note string) (*models.Review, error)
+}
+
+type reviewService struct {
+	repo        repository.ReviewRepository
+	productRepo repository.ProductRepository
+	orders      OrderClient
+}
+
+func NewReviewService(repo repository.ReviewRepository, productRepo repository.ProductRepository, orders OrderClient) ReviewService {
+	return &reviewService{repo: repo, productRepo: productRepo, orders: orders}
+}
+
+// CreateReview stores a buyer's review after confirming they received the product
+func (s *reviewService) CreateReview(review *models.Review) error {
+	if review.Rating < 1 || review.Rating > 5 {
+		return ErrInvalidRating
+	}
+	if len(review.Photos) > maxReviewPhotos {
+		return ErrTooManyPhotos
+	}
+	if _, err := s.productRepo.GetByID(review.ProductID, "admin", 0); err != nil {
+		return err
+	}
+
+	purchase, err := s.orders.GetDeliveredPurchase(review.BuyerID, review.ProductID)
+	if err != nil {
+		return err
+	}
+	if purchase == nil {
+		return ErrNotDelivered
+	}
+
+	exists, err := s.repo.ExistsForPurchase(review.ProductID, review.BuyerID, purchase.OrderID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrAlreadyReviewed
+	}
+
+	review.OrderID = purchase.OrderID
+	review.Status = models.ReviewStatusPublished
+	review.SellerReply = ""
+	review.SellerRepliedAt = nil
+	if err := s.repo.Create(review); err != nil {
+		return err
+	}
+	return s.repo.RecalculateProductRating(review.ProductID)
+}
+
+// ListReviews returns published reviews for a product
+func (s *reviewService) ListReviews(productID uint, offset, limit int) ([]models.Review, error) {
+	return s.repo.ListByProduct(productID, models.ReviewStatusPublished, offset, limit)
+}
+
+// ReplyToReview lets the product's seller publicly respond to a review
+func (s *reviewService) ReplyToReview(reviewID, sellerID uint, reply string) (*models.Review, error) {
+	review, err := s.repo.GetByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.productRepo.GetByID(review.ProductID, "admin", 0)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotProductOwner
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.SellerID != sellerID {
+		return nil, ErrNotProductOwner
+	}
+
+	now := time.Now()
+	review.SellerReply = strings.TrimSpace(reply)
+	review.SellerRepliedAt = &now
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ModerateReview changes a review's visibility and keeps the product rating in sync
+func (s *reviewService) ModerateReview(reviewID uint, status, note string) (*models.Review, error) {
+	switch status {
+	case models.ReviewStatusPublished, models.ReviewStatusHidden, models.ReviewStatusRejected:
+	default:
+		return nil, ErrInvalidReviewStatus
+	}
+
+	review, err := s.repo.GetByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	review.Status = status
+	review.ModerationNote = note
+	if err := s.repo.Update(review); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RecalculateProductRating(review.ProductID); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
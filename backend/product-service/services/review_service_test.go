@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestReviewService_Create_DeliveredPurchase(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := NewReviewService(reviewRepo, productRepo, orders)
+
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return(&models.Product{}, nil)
+	orders.On("GetDeliveredPurchase", uint(3), uint(10)).Return(&DeliveredPurchase{OrderID: 55, ProductID: 10}, nil)
+	reviewRepo.On("ExistsForPurchase", uint(10), uint(3), uint(55)).Return(false, nil)
+	reviewRepo.On("Create", mock.AnythingOfType("*models.Review")).Return(nil)
+	reviewRepo.On("RecalculateProductRating", uint(10)).Return(nil)
+
+	review := &models.Review{ProductID: 10, BuyerID: 3, Rating: 4, Comment: "Good fit"}
+	err := service.CreateReview(review)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(55), review.OrderID)
+	assert.Equal(t, models.ReviewStatusPublished, review.Status)
+	reviewRepo.AssertExpectations(t)
+	orders.AssertExpectations(t)
+}
+
+func TestReviewService_Create_NotDelivered(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := NewReviewService(reviewRepo, productRepo, orders)
+
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return(&models.Product{}, nil)
+	orders.On("GetDeliveredPurchase", uint(3), uint(10)).Return(nil, nil)
+
+	err := service.CreateReview(&models.Review{ProductID: 10, BuyerID: 3, Rating: 5})
+
+	assert.ErrorIs(t, err, ErrNotDelivered)
+	reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReviewService_Create_AlreadyReviewed(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	productRepo := new(repository.MockProductRepository)
+	orders := new(MockOrderClient)
+	service := NewReviewService(reviewRepo, productRepo, orders)
+
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return(&models.Product{}, nil)
+	orders.On("GetDeliveredPurchase", uint(3), uint(10)).Return(&DeliveredPurchase{OrderID: 55}, nil)
+	reviewRepo.On("ExistsForPurchase", uint(10), uint(3), uint(55)).Return(true, nil)
+
+	err := service.CreateReview(&models.Review{ProductID: 10, BuyerID: 3, Rating: 5})
+
+	assert.ErrorIs(t, err, ErrAlreadyReviewed)
+}
+
+func TestReviewService_Create_InvalidRating(t *testing.T) {
+	orders := new(MockOrderClient)
+	service := NewReviewService(new(repository.MockReviewRepository), new(repository.MockProductRepository), orders)
+
+	err := service.CreateReview(&models.Review{ProductID: 10, BuyerID: 3, Rating: 6})
+
+	assert.ErrorIs(t, err, ErrInvalidRating)
+	orders.AssertNotCalled(t, "GetDeliveredPurchase", mock.Anything, mock.Anything)
+}
+
+func TestReviewService_Reply_NotOwner(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewReviewService(reviewRepo, productRepo, new(MockOrderClient))
+
+	reviewRepo.On("GetByID", uint(1)).Return(&models.Review{ID: 1, ProductID: 10}, nil)
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return(&models.Product{SellerID: 9}, nil)
+
+	_, err := service.ReplyToReview(1, 8, "Thanks!")
+
+	assert.ErrorIs(t, err, ErrNotProductOwner)
+	reviewRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestReviewService_Reply_PassesThroughLookupErrors(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	productRepo := new(repository.MockProductRepository)
+	service := NewReviewService(reviewRepo, productRepo, new(MockOrderClient))
+
+	reviewRepo.On("GetByID", uint(1)).Return(&models.Review{ID: 1, ProductID: 10}, nil)
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return((*models.Product)(nil), errors.New("connection refused")).Once()
+	productRepo.On("GetByID", uint(10), "admin", uint(0)).Return((*models.Product)(nil), gorm.ErrRecordNotFound).Once()
+
+	_, err := service.ReplyToReview(1, 8, "Thanks!")
+	assert.EqualError(t, err, "connection refused")
+
+	_, err = service.ReplyToReview(1, 8, "Thanks!")
+	assert.ErrorIs(t, err, ErrNotProductOwner)
+	reviewRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestReviewService_Moderate_RecalculatesRating(t *testing.T) {
+	reviewRepo := new(repository.MockReviewRepository)
+	service := NewReviewService(reviewRepo, new(repository.MockProductRepository), new(MockOrderClient))
+
+	review := &models.Review{ID: 1, ProductID: 10, Status: models.ReviewStatusPublished}
+	reviewRepo.On("GetByID", uint(1)).Return(review, nil)
+	reviewRepo.On("Update", review).Return(nil)
+	reviewRepo.On("RecalculateProductRating", uint(10)).Return(nil)
+
+	result, err := service.ModerateReview(1, models.ReviewStatusHidden, "spam")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusHidden, result.Status)
+	reviewRepo.AssertExpectations(t)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"product-service/repository"
+)
+
+// RunEvery runs job once immediately and then on every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func RunEvery(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(); err != nil {
+			log.Printf("⚠️ %s job failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// JobLeases keep scheduled jobs to one instance of the service at a time. Every
+// instance runs the same timers; a lock in the database decides which of them does
+// each round of a job.
+type JobLeases struct {
+	Locks  repository.JobLockRepository
+	Holder string // names this instance in the locks it takes
+}
+
+func NewJobLeases(locks repository.JobLockRepository) *JobLeases {
+	return &JobLeases{Locks: locks, Holder: leaseHolder()}
+}
+
+// Leased wraps a job run every interval so a round only runs on the instance that
+// takes the named lock. The lease is a little shorter than the interval so the next
+// tick, on this instance or another, finds the lock free.
+func (l *JobLeases) Leased(name string, interval time.Duration, job func() error) func() error {
+	lease := interval - interval/10
+	return func() error {
+		now := time.Now()
+		acquired, err := l.Locks.Acquire(name, l.Holder, now, now.Add(lease))
+		if err != nil || !acquired {
+			return err
+		}
+		runErr := job()
+		lastErr := ""
+		if runErr != nil {
+			lastErr = runErr.Error()
+		}
+		if err := l.Locks.Finish(name, l.Holder, lastErr); err != nil {
+			log.Printf("⚠️ Recording run of job %s: %v", name, err)
+		}
+		return runErr
+	}
+}
+
+// leaseHolder names this process uniquely among the service's instances
+func leaseHolder() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
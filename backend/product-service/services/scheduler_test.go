@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJobLeases_Leased_SkipsWhileAnotherInstanceHoldsTheLock(t *testing.T) {
+	locks := new(repository.MockJobLockRepository)
+	leases := NewJobLeases(locks)
+	ran := false
+	job := leases.Leased("recommendations", time.Hour, func() error {
+		ran = true
+		return nil
+	})
+
+	locks.On("Acquire", "recommendations", leases.Holder, mock.Anything, mock.Anything).Return(false, nil)
+
+	assert.NoError(t, job())
+	assert.False(t, ran)
+	locks.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestJobLeases_Leased_LeasesLessThanAnIntervalAndRecordsFailure(t *testing.T) {
+	locks := new(repository.MockJobLockRepository)
+	leases := NewJobLeases(locks)
+	job := leases.Leased("recommendations", time.Hour, func() error {
+		return errors.New("order-service unavailable")
+	})
+
+	locks.On("Acquire", "recommendations", leases.Holder, mock.Anything, mock.MatchedBy(func(until time.Time) bool {
+		return until.Before(time.Now().Add(time.Hour))
+	})).Return(true, nil)
+	locks.On("Finish", "recommendations", leases.Holder, "order-service unavailable").Return(nil)
+
+	assert.EqualError(t, job(), "order-service unavailable")
+	locks.AssertExpectations(t)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"product-service/models"
+)
+
+// SellerNotifier delivers reminders to sellers. Notify must not block the caller.
+type SellerNotifier interface {
+	Notify(notification models.SellerNotification)
+}
+
+type logSellerNotifier struct{}
+
+// NewLogSellerNotifier creates a SellerNotifier that only writes notifications to the log
+func NewLogSellerNotifier() SellerNotifier {
+	return logSellerNotifier{}
+}
+
+func (logSellerNotifier) Notify(notification models.SellerNotification) {
+	log.Printf("❓ %s: seller %d has %d questions waiting", notification.Type, notification.SellerID, len(notification.QuestionIDs))
+}
+
+type webhookSellerNotifier struct {
+	webhook *webhook
+}
+
+// NewWebhookSellerNotifier creates a SellerNotifier that posts each notification as JSON to url
+func NewWebhookSellerNotifier(url string) SellerNotifier {
+	return &webhookSellerNotifier{webhook: newWebhook(url)}
+}
+
+func (n *webhookSellerNotifier) Notify(notification models.SellerNotification) {
+	n.webhook.post(notification, fmt.Sprintf("%s notification for seller %d", notification.Type, notification.SellerID))
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"product-service/config"
+)
+
+var ErrShopNotFound = errors.New("shop not found")
+
+// ShopInfo is the part of a shop-service shop that product-service checks
+type ShopInfo struct {
+	ID         uint `json:"id"`
+	OwnerID    uint `json:"owner_id"`
+	IsApproved bool `json:"is_approved"`
+	IsBlocked  bool `json:"is_blocked"`
+}
+
+// ShopClient is the subset of shop-service that product-service depends on
+type ShopClient interface {
+	GetShop(id uint) (*ShopInfo, error)
+}
+
+type httpShopClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewShopClient creates a ShopClient that talks to shop-service over HTTP
+func NewShopClient() ShopClient {
+	return &httpShopClient{
+		baseURL: config.GetShopServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetShop returns a shop's owner and status, or ErrShopNotFound
+func (c *httpShopClient) GetShop(id uint) (*ShopInfo, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/internal/shops/%d", c.baseURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrShopNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("shop lookup failed: %s", string(body))
+	}
+
+	var shop ShopInfo
+	if err := json.NewDecoder(resp.Body).Decode(&shop); err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
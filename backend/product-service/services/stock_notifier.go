@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/models"
+)
+
+// StockNotifier tells sellers about low-stock, out-of-stock and back-in-stock events.
+// Notify must not block the stock change that raised the event.
+type StockNotifier interface {
+	Notify(event models.StockEvent)
+}
+
+type logStockNotifier struct{}
+
+// NewLogStockNotifier creates a StockNotifier that only writes events to the log
+func NewLogStockNotifier() StockNotifier {
+	return logStockNotifier{}
+}
+
+func (logStockNotifier) Notify(event models.StockEvent) {
+	log.Printf("📦 %s: product %d (seller %d) has %d left", event.Type, event.ProductID, event.SellerID, event.Quantity)
+}
+
+type webhookStockNotifier struct {
+	webhook *webhook
+}
+
+// NewWebhookStockNotifier creates a StockNotifier that posts each event as JSON to url
+func NewWebhookStockNotifier(url string) StockNotifier {
+	return &webhookStockNotifier{webhook: newWebhook(url)}
+}
+
+func (n *webhookStockNotifier) Notify(event models.StockEvent) {
+	n.webhook.post(event, fmt.Sprintf("stock alert for product %d", event.ProductID))
+}
+
+type multiStockNotifier []StockNotifier
+
+// StockNotifiers fans each event out to every notifier
+func StockNotifiers(notifiers ...StockNotifier) StockNotifier {
+	return multiStockNotifier(notifiers)
+}
+
+func (m multiStockNotifier) Notify(event models.StockEvent) {
+	for _, notifier := range m {
+		notifier.Notify(event)
+	}
+}
+
+// StockEvents returns the events raised by a product's quantity moving from before to
+// its current quantity. Low stock fires only when the threshold is first crossed.
+func StockEvents(before int, product *models.Product, at time.Time) []models.StockEvent {
+	after := product.Quantity
+	threshold := product.ReorderThreshold
+
+	var types []string
+	switch {
+	case before > 0 && after <= 0:
+		types = append(types, models.StockEventOut)
+	case before <= 0 && after > 0:
+		types = append(types, models.StockEventBackInStock)
+		if after <= threshold {
+			types = append(types, models.StockEventLow)
+		}
+	case threshold > 0 && before > threshold && after > 0 && after <= threshold:
+		types = append(types, models.StockEventLow)
+	}
+
+	events := make([]models.StockEvent, len(types))
+	for i, eventType := range types {
+		events[i] = models.StockEvent{
+			Type:       eventType,
+			ProductID:  product.ID,
+			ShopID:     product.ShopID,
+			SellerID:   product.SellerID,
+			Name:       product.Name,
+			Quantity:   after,
+			Threshold:  threshold,
+			Hidden:     product.StockHidden,
+			OccurredAt: at,
+		}
+	}
+	return events
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+)
+
+var (
+	ErrNothingToReserve  = errors.New("reservation must contain at least one item")
+	ErrReturnKeyRequired = errors.New("return_key is required")
+)
+
+// ReservationItem is a product and quantity to hold for an order
+type ReservationItem struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+// StockReservationService holds stock for orders while they are checked out and puts
+// it back if the checkout is abandoned or the goods are returned
+type StockReservationService interface {
+	Reserve(orderID uint, items []ReservationItem) error
+	Release(orderID uint, productIDs []uint) error
+	Restock(orderID uint, returnKey string, items []ReservationItem) error
+}
+
+type stockReservationService struct {
+	repo     repository.StockReservationRepository
+	notifier StockNotifier
+	now      func() time.Time
+}
+
+func NewStockReservationService(repo repository.StockReservationRepository, notifier StockNotifier) StockReservationService {
+	return &stockReservationService{repo: repo, notifier: notifier, now: time.Now}
+}
+
+// Reserve takes stock for all of an order's items or none of them. Lines for the same
+// product are combined; reserving an order again changes nothing.
+func (s *stockReservationService) Reserve(orderID uint, items []ReservationItem) error {
+	quantities, err := combineItems(items)
+	if err != nil {
+		return err
+	}
+	reservations := make([]models.StockReservation, len(quantities))
+	for i, item := range quantities {
+		reservations[i] = models.StockReservation{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	changes, err := s.repo.Reserve(orderID, reservations)
+	if err != nil {
+		return err
+	}
+	s.notify(changes)
+	return nil
+}
+
+// Release returns whatever stock an order still holds for the given products, or for
+// the whole order when no products are given, as when part of an order is cancelled
+func (s *stockReservationService) Release(orderID uint, productIDs []uint) error {
+	changes, err := s.repo.Release(orderID, productIDs)
+	if err != nil {
+		return err
+	}
+	s.notify(changes)
+	return nil
+}
+
+// Restock puts goods that came back in a return of an order into stock again. Lines for
+// the same product are combined; restocking the same return again changes nothing.
+func (s *stockReservationService) Restock(orderID uint, returnKey string, items []ReservationItem) error {
+	if returnKey == "" {
+		return ErrReturnKeyRequired
+	}
+	quantities, err := combineItems(items)
+	if err != nil {
+		return err
+	}
+	returns := make([]models.StockReturn, len(quantities))
+	for i, item := range quantities {
+		returns[i] = models.StockReturn{OrderID: orderID, ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	changes, err := s.repo.Restock(returnKey, returns)
+	if err != nil {
+		return err
+	}
+	s.notify(changes)
+	return nil
+}
+
+// combineItems adds up the lines for each product, sorted by product id: a fixed lock
+// order keeps concurrent stock moves from deadlocking
+func combineItems(items []ReservationItem) ([]ReservationItem, error) {
+	if len(items) == 0 {
+		return nil, ErrNothingToReserve
+	}
+	quantities := make(map[uint]int)
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return nil, ErrInvalidQuantity
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	combined := make([]ReservationItem, 0, len(quantities))
+	for productID, quantity := range quantities {
+		combined = append(combined, ReservationItem{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(combined, func(i, j int) bool { return combined[i].ProductID < combined[j].ProductID })
+	return combined, nil
+}
+
+// notify raises stock events for every product a reservation moved
+func (s *stockReservationService) notify(changes []repository.StockChange) {
+	for i := range changes {
+		for _, event := range StockEvents(changes[i].Before, &changes[i].Product, s.now()) {
+			s.notifier.Notify(event)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStockReservationService_Reserve_CombinesLinesInProductOrder(t *testing.T) {
+	repo := new(repository.MockStockReservationRepository)
+	notifier := new(MockStockNotifier)
+	service := &stockReservationService{repo: repo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	emptied := bundleProduct(3, 7, models.ProductTypePhysical, 0)
+	repo.On("Reserve", uint(42), []models.StockReservation{
+		{ProductID: 3, Quantity: 2},
+		{ProductID: 8, Quantity: 3},
+	}).Return([]repository.StockChange{{Product: *emptied, Before: 2}}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(event models.StockEvent) bool {
+		return event.Type == models.StockEventOut && event.ProductID == 3
+	})).Once()
+
+	err := service.Reserve(42, []ReservationItem{
+		{ProductID: 8, Quantity: 1},
+		{ProductID: 3, Quantity: 2},
+		{ProductID: 8, Quantity: 2},
+	})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestStockReservationService_Reserve_RejectsBadItems(t *testing.T) {
+	repo := new(repository.MockStockReservationRepository)
+	notifier := new(MockStockNotifier)
+	service := &stockReservationService{repo: repo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	assert.ErrorIs(t, service.Reserve(42, nil), ErrNothingToReserve)
+	assert.ErrorIs(t, service.Reserve(42, []ReservationItem{{ProductID: 3, Quantity: 0}}), ErrInvalidQuantity)
+	repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything)
+}
+
+func TestStockReservationService_Reserve_InsufficientStock(t *testing.T) {
+	repo := new(repository.MockStockReservationRepository)
+	notifier := new(MockStockNotifier)
+	service := &stockReservationService{repo: repo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	repo.On("Reserve", uint(42), mock.Anything).Return(nil, repository.ErrInsufficientStock)
+
+	err := service.Reserve(42, []ReservationItem{{ProductID: 3, Quantity: 5}})
+
+	assert.ErrorIs(t, err, repository.ErrInsufficientStock)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestStockReservationService_Release_OnlyGivenProducts(t *testing.T) {
+	repo := new(repository.MockStockReservationRepository)
+	notifier := new(MockStockNotifier)
+	service := &stockReservationService{repo: repo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	restocked := bundleProduct(3, 7, models.ProductTypePhysical, 4)
+	repo.On("Release", uint(42), []uint{3}).Return([]repository.StockChange{{Product: *restocked, Before: 2}}, nil)
+	notifier.On("Notify", mock.Anything).Maybe()
+
+	err := service.Release(42, []uint{3})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestStockReservationService_Restock_CombinesLinesOncePerReturn(t *testing.T) {
+	repo := new(repository.MockStockReservationRepository)
+	notifier := new(MockStockNotifier)
+	service := &stockReservationService{repo: repo, notifier: notifier, now: func() time.Time { return pricingNow }}
+
+	restocked := bundleProduct(3, 7, models.ProductTypePhysical, 2)
+	repo.On("Restock", "rma-5", []models.StockReturn{
+		{OrderID: 42, ProductID: 3, Quantity: 2},
+		{OrderID: 42, ProductID: 8, Quantity: 1},
+	}).Return([]repository.StockChange{{Product: *restocked, Before: 0}}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(event models.StockEvent) bool {
+		return event.Type == models.StockEventBackInStock && event.ProductID == 3
+	})).Once()
+
+	err := service.Restock(42, "rma-5", []ReservationItem{
+		{ProductID: 8, Quantity: 1},
+		{ProductID: 3, Quantity: 1},
+		{ProductID: 3, Quantity: 1},
+	})
+
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.Restock(42, "", []ReservationItem{{ProductID: 3, Quantity: 1}}), ErrReturnKeyRequired)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// webhook posts notifications as JSON to an external receiver. The receiver is
+// outside the cluster, so it never gets the internal API_KEY; when WEBHOOK_SECRET
+// is set the body is signed with it instead
+type webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func newWebhook(url string) *webhook {
+	return &webhook{
+		url:    url,
+		secret: []byte(os.Getenv("WEBHOOK_SECRET")),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// signature is the hex HMAC-SHA256 of body under the webhook secret
+func (w *webhook) signature(body []byte) string {
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// post delivers payload in the background; failures are logged and not retried
+func (w *webhook) post(payload interface{}, what string) {
+	go func() {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("⚠️ %s not sent: %v", what, err)
+			return
+		}
+		req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("⚠️ %s not sent: %v", what, err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if len(w.secret) > 0 {
+			req.Header.Set("X-Webhook-Signature", "sha256="+w.signature(body))
+		}
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			log.Printf("⚠️ %s not sent: %v", what, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			log.Printf("⚠️ %s rejected with status %d", what, resp.StatusCode)
+		}
+	}()
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Post_SignsBodyWithoutAPIKey(t *testing.T) {
+	t.Setenv("API_KEY", "internal")
+	t.Setenv("WEBHOOK_SECRET", "shared")
+	received := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+	}))
+	defer server.Close()
+
+	hook := newWebhook(server.URL)
+	hook.post(map[string]int{"product_id": 1}, "test")
+
+	select {
+	case r := <-received:
+		assert.Empty(t, r.Header.Get("X-API-Key"))
+		assert.Equal(t, "sha256="+hook.signature([]byte(`{"product_id":1}`)), r.Header.Get("X-Webhook-Signature"))
+	case <-time.After(time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
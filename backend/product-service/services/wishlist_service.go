@@ -0,0 +1,427 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"product-service/models"
+	"product-service/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxWishlistsPerBuyer = 20
+	maxItemsPerWishlist  = 200
+	maxWishlistNameLen   = 100
+	priceCheckPageSize   = 500
+)
+
+var (
+	ErrWishlistNotFound    = errors.New("wishlist not found")
+	ErrInvalidWishlistName = errors.New("name must be between 1 and 100 characters")
+	ErrTooManyWishlists    = errors.New("a buyer can have at most 20 wishlists")
+	ErrWishlistFull        = errors.New("a wishlist can hold at most 200 products")
+	ErrProductInStock      = errors.New("product is in stock")
+)
+
+// WishlistService manages wishlists and back-in-stock subscriptions. It is also a
+// StockNotifier so that stock increases reach subscribed buyers.
+type WishlistService interface {
+	StockNotifier
+
+	Create(buyerID uint, name string) (*models.Wishlist, error)
+	List(buyerID uint) ([]models.Wishlist, error)
+	Get(id, buyerID uint) (*models.Wishlist, error)
+	Rename(id, buyerID uint, name string) (*models.Wishlist, error)
+	Delete(id, buyerID uint) error
+	AddItem(id, buyerID, productID uint) (*models.WishlistItem, error)
+	RemoveItem(id, buyerID, productID uint) error
+	Share(id, buyerID uint) (string, error)
+	Unshare(id, buyerID uint) error
+	GetShared(token string) (*models.Wishlist, error)
+
+	Subscribe(buyerID, productID uint) error
+	Unsubscribe(buyerID, productID uint) error
+	DetectPriceDrops() error
+}
+
+type wishlistService struct {
+	repo        repository.WishlistRepository
+	productRepo repository.ProductRepository
+	pricing     PricingService
+	notifier    BuyerNotifier
+	now         func() time.Time
+}
+
+func NewWishlistService(repo repository.WishlistRepository, productRepo repository.ProductRepository, pricing PricingService, notifier BuyerNotifier) WishlistService {
+	return &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: time.Now}
+}
+
+// Create adds an empty named wishlist for the buyer
+func (s *wishlistService) Create(buyerID uint, name string) (*models.Wishlist, error) {
+	name, err := validWishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountByBuyer(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWishlistsPerBuyer {
+		return nil, ErrTooManyWishlists
+	}
+
+	wishlist := &models.Wishlist{BuyerID: buyerID, Name: name, Items: []models.WishlistItem{}}
+	if err := s.repo.Create(wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// List returns the buyer's wishlists with current prices
+func (s *wishlistService) List(buyerID uint) ([]models.Wishlist, error) {
+	wishlists, err := s.repo.ListByBuyer(buyerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range wishlists {
+		if err := s.resolveItems(&wishlists[i]); err != nil {
+			return nil, err
+		}
+	}
+	return wishlists, nil
+}
+
+// Get returns one of the buyer's wishlists with current prices
+func (s *wishlistService) Get(id, buyerID uint) (*models.Wishlist, error) {
+	wishlist, err := s.owned(id, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolveItems(wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// Rename changes a wishlist's name
+func (s *wishlistService) Rename(id, buyerID uint, name string) (*models.Wishlist, error) {
+	name, err := validWishlistName(name)
+	if err != nil {
+		return nil, err
+	}
+	wishlist, err := s.owned(id, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	wishlist.Name = name
+	if err := s.repo.Update(wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// Delete removes a wishlist and everything saved in it
+func (s *wishlistService) Delete(id, buyerID uint) error {
+	if _, err := s.owned(id, buyerID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// AddItem saves a visible product to a wishlist at its current effective price
+func (s *wishlistService) AddItem(id, buyerID, productID uint) (*models.WishlistItem, error) {
+	if _, err := s.owned(id, buyerID); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountItems(id)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxItemsPerWishlist {
+		return nil, ErrWishlistFull
+	}
+
+	product, err := s.productRepo.GetByID(productID, "public", 0)
+	if err != nil {
+		return nil, err
+	}
+	priced := []models.Product{*product}
+	if err := s.pricing.ApplyEffectivePrices(priced); err != nil {
+		return nil, err
+	}
+
+	item := &models.WishlistItem{WishlistID: id, ProductID: productID, SavedPrice: priced[0].EffectivePrice}
+	if err := s.repo.AddItem(item); err != nil {
+		return nil, err
+	}
+	item.Product = &priced[0]
+	return item, nil
+}
+
+// RemoveItem drops a product from a wishlist
+func (s *wishlistService) RemoveItem(id, buyerID, productID uint) error {
+	if _, err := s.owned(id, buyerID); err != nil {
+		return err
+	}
+	return s.repo.RemoveItem(id, productID)
+}
+
+// Share returns the wishlist's share token, creating one on first use
+func (s *wishlistService) Share(id, buyerID uint) (string, error) {
+	wishlist, err := s.owned(id, buyerID)
+	if err != nil {
+		return "", err
+	}
+	if wishlist.ShareToken != nil {
+		return *wishlist.ShareToken, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+	wishlist.ShareToken = &token
+	if err := s.repo.Update(wishlist); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Unshare revokes the share link; a later Share issues a new one
+func (s *wishlistService) Unshare(id, buyerID uint) error {
+	wishlist, err := s.owned(id, buyerID)
+	if err != nil {
+		return err
+	}
+	wishlist.ShareToken = nil
+	return s.repo.Update(wishlist)
+}
+
+// GetShared returns a shared wishlist to anyone holding its link
+func (s *wishlistService) GetShared(token string) (*models.Wishlist, error) {
+	wishlist, err := s.repo.GetByShareToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolveItems(wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// Subscribe asks to be notified when an out-of-stock product returns. Products that
+// are auto-hidden at zero quantity can still be subscribed to.
+func (s *wishlistService) Subscribe(buyerID, productID uint) error {
+	product, err := s.productRepo.GetByID(productID, "admin", 0)
+	if err != nil {
+		return err
+	}
+	if product.Status != models.ProductStatusActive || !product.ShopVisible {
+		return gorm.ErrRecordNotFound
+	}
+	if product.Quantity > 0 {
+		return ErrProductInStock
+	}
+	return s.repo.Subscribe(buyerID, productID)
+}
+
+// Unsubscribe cancels a back-in-stock subscription
+func (s *wishlistService) Unsubscribe(buyerID, productID uint) error {
+	return s.repo.Unsubscribe(buyerID, productID)
+}
+
+// Notify handles stock events from product-service; back-in-stock events are
+// delivered to subscribers in the background so the stock change is not delayed
+func (s *wishlistService) Notify(event models.StockEvent) {
+	if event.Type != models.StockEventBackInStock {
+		return
+	}
+	go func() {
+		if err := s.notifyBackInStock(event.ProductID); err != nil {
+			log.Printf("⚠️ back-in-stock notifications for product %d failed: %v", event.ProductID, err)
+		}
+	}()
+}
+
+// notifyBackInStock tells every subscriber the product has returned, then drops
+// the fulfilled subscriptions
+func (s *wishlistService) notifyBackInStock(productID uint) error {
+	buyerIDs, err := s.repo.ListSubscribers(productID)
+	if err != nil || len(buyerIDs) == 0 {
+		return err
+	}
+	product, err := s.productRepo.GetByID(productID, "public", 0)
+	if err != nil {
+		return err
+	}
+	priced := []models.Product{*product}
+	if err := s.pricing.ApplyEffectivePrices(priced); err != nil {
+		return err
+	}
+
+	for _, buyerID := range buyerIDs {
+		s.notifier.Notify(models.BuyerNotification{
+			Type:      models.NotificationBackInStock,
+			BuyerID:   buyerID,
+			ProductID: productID,
+			Name:      product.Name,
+			Price:     priced[0].EffectivePrice,
+			CreatedAt: s.now(),
+		})
+	}
+	return s.repo.DeleteSubscriptions(productID, buyerIDs)
+}
+
+// DetectPriceDrops alerts buyers whose saved products now cost less than when saved.
+// A buyer is alerted again only if the price falls below the last alerted price.
+func (s *wishlistService) DetectPriceDrops() error {
+	var errs []error
+	var afterID uint
+	for {
+		refs, err := s.repo.ListItemsForPriceCheck(afterID, priceCheckPageSize)
+		if err != nil {
+			return err
+		}
+		if err := s.checkPrices(refs); err != nil {
+			errs = append(errs, err)
+		}
+		if len(refs) < priceCheckPageSize {
+			break
+		}
+		afterID = refs[len(refs)-1].ItemID
+	}
+	return errors.Join(errs...)
+}
+
+// checkPrices compares one page of saved items with current effective prices
+func (s *wishlistService) checkPrices(refs []repository.WishlistItemRef) error {
+	ids := make([]uint, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ProductID)
+	}
+	products, err := s.productRepo.GetVisibleByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if err := s.pricing.ApplyEffectivePrices(products); err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	// The same product may sit in several of a buyer's lists; alert once
+	type follower struct{ buyerID, productID uint }
+	dropped := make(map[follower][]uint)
+	var order []follower
+	for _, ref := range refs {
+		product, ok := byID[ref.ProductID]
+		if !ok {
+			continue
+		}
+		baseline := ref.SavedPrice
+		if ref.NotifiedPrice > 0 && ref.NotifiedPrice < baseline {
+			baseline = ref.NotifiedPrice
+		}
+		if product.EffectivePrice >= baseline {
+			continue
+		}
+		key := follower{ref.BuyerID, ref.ProductID}
+		if _, seen := dropped[key]; !seen {
+			order = append(order, key)
+			s.notifier.Notify(models.BuyerNotification{
+				Type:      models.NotificationPriceDrop,
+				BuyerID:   ref.BuyerID,
+				ProductID: ref.ProductID,
+				Name:      product.Name,
+				Price:     product.EffectivePrice,
+				OldPrice:  baseline,
+				CreatedAt: s.now(),
+			})
+		}
+		dropped[key] = append(dropped[key], ref.ItemID)
+	}
+
+	var errs []error
+	for _, key := range order {
+		if err := s.repo.SetNotifiedPrice(dropped[key], byID[key.productID].EffectivePrice); err != nil {
+			errs = append(errs, fmt.Errorf("product %d: %w", key.productID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// owned loads a wishlist, hiding other buyers' lists behind ErrWishlistNotFound
+func (s *wishlistService) owned(id, buyerID uint) (*models.Wishlist, error) {
+	wishlist, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.BuyerID != buyerID {
+		return nil, ErrWishlistNotFound
+	}
+	return wishlist, nil
+}
+
+// resolveItems attaches visible products with effective prices to the wishlist items.
+// Items whose product is no longer visible are kept without product details.
+func (s *wishlistService) resolveItems(wishlist *models.Wishlist) error {
+	if len(wishlist.Items) == 0 {
+		wishlist.Items = []models.WishlistItem{}
+		return nil
+	}
+	ids := make([]uint, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.productRepo.GetVisibleByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if err := s.pricing.ApplyEffectivePrices(products); err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	for i := range wishlist.Items {
+		item := &wishlist.Items[i]
+		if product, ok := byID[item.ProductID]; ok {
+			item.Product = product
+			item.PriceDropped = product.EffectivePrice < item.SavedPrice
+		}
+	}
+	return nil
+}
+
+func validWishlistName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxWishlistNameLen {
+		return "", ErrInvalidWishlistName
+	}
+	return name, nil
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"product-service/models"
+	"product-service/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var wishlistNow = time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)
+
+func TestWishlistService_Create_LimitsListsPerBuyer(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("CountByBuyer", uint(5)).Return(int64(maxWishlistsPerBuyer), nil)
+
+	_, err := service.Create(5, "Eid")
+
+	assert.ErrorIs(t, err, ErrTooManyWishlists)
+	repo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestWishlistService_Create_RejectsBlankName(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	_, err := service.Create(5, "   ")
+
+	assert.ErrorIs(t, err, ErrInvalidWishlistName)
+}
+
+func TestWishlistService_Get_HidesOtherBuyersLists(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("GetByID", uint(3)).Return(&models.Wishlist{ID: 3, BuyerID: 8}, nil)
+
+	_, err := service.Get(3, 5)
+
+	assert.ErrorIs(t, err, ErrWishlistNotFound)
+}
+
+func TestWishlistService_AddItem_SavesEffectivePrice(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	priceRepo := new(repository.MockPriceRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("GetByID", uint(3)).Return(&models.Wishlist{ID: 3, BuyerID: 5}, nil)
+	repo.On("CountItems", uint(3)).Return(int64(0), nil)
+	productRepo.On("GetByID", uint(1), "public", uint(0)).Return(activeProduct(1, 1000), nil)
+	priceRepo.On("ListLive", []uint{1}, wishlistNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 850, EndsAt: wishlistNow.Add(time.Hour)},
+	}, nil)
+	repo.On("AddItem", mock.MatchedBy(func(item *models.WishlistItem) bool {
+		return item.WishlistID == 3 && item.ProductID == 1 && item.SavedPrice == 850
+	})).Return(nil)
+
+	item, err := service.AddItem(3, 5, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 850.0, item.SavedPrice)
+	repo.AssertExpectations(t)
+}
+
+func TestWishlistService_Share_ReusesExistingToken(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	token := "abc123"
+	repo.On("GetByID", uint(3)).Return(&models.Wishlist{ID: 3, BuyerID: 5, ShareToken: &token}, nil)
+
+	shared, err := service.Share(3, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, token, shared)
+	repo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestWishlistService_GetShared_UnknownToken(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("GetByShareToken", "nope").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.GetShared("nope")
+
+	assert.ErrorIs(t, err, ErrWishlistNotFound)
+}
+
+func TestWishlistService_Subscribe_RejectsInStockProduct(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: new(repository.MockPriceRepository), productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	product := activeProduct(1, 1000)
+	product.Status = models.ProductStatusActive
+	product.Quantity = 4
+	productRepo.On("GetByID", uint(1), "admin", uint(0)).Return(product, nil)
+
+	err := service.Subscribe(5, 1)
+
+	assert.ErrorIs(t, err, ErrProductInStock)
+	repo.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+}
+
+func TestWishlistService_NotifyBackInStock_NotifiesAndClearsSubscribers(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	priceRepo := new(repository.MockPriceRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("ListSubscribers", uint(1)).Return([]uint{5, 6}, nil)
+	productRepo.On("GetByID", uint(1), "public", uint(0)).Return(activeProduct(1, 1000), nil)
+	priceRepo.On("ListLive", []uint{1}, wishlistNow).Return([]models.PriceSchedule{}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(n models.BuyerNotification) bool {
+		return n.Type == models.NotificationBackInStock && n.ProductID == 1 && n.Price == 1000
+	})).Return()
+	repo.On("DeleteSubscriptions", uint(1), []uint{5, 6}).Return(nil)
+
+	err := service.notifyBackInStock(1)
+
+	assert.NoError(t, err)
+	notifier.AssertNumberOfCalls(t, "Notify", 2)
+	repo.AssertExpectations(t)
+}
+
+func TestWishlistService_DetectPriceDrops_AlertsOncePerBuyerAndProduct(t *testing.T) {
+	repo := new(repository.MockWishlistRepository)
+	productRepo := new(repository.MockProductRepository)
+	priceRepo := new(repository.MockPriceRepository)
+	notifier := new(MockBuyerNotifier)
+	pricing := &pricingService{repo: priceRepo, productRepo: productRepo, now: func() time.Time { return wishlistNow }}
+	service := &wishlistService{repo: repo, productRepo: productRepo, pricing: pricing, notifier: notifier, now: func() time.Time { return wishlistNow }}
+
+	repo.On("ListItemsForPriceCheck", uint(0), priceCheckPageSize).Return([]repository.WishlistItemRef{
+		{ItemID: 1, BuyerID: 5, ProductID: 1, SavedPrice: 1000},                     // dropped
+		{ItemID: 2, BuyerID: 5, ProductID: 1, SavedPrice: 1000},                     // same buyer, other list
+		{ItemID: 3, BuyerID: 6, ProductID: 1, SavedPrice: 1000, NotifiedPrice: 800}, // already told about 800
+		{ItemID: 4, BuyerID: 6, ProductID: 2, SavedPrice: 500},                      // unchanged
+	}, nil)
+	productRepo.On("GetVisibleByIDs", []uint{1, 1, 1, 2}).Return([]models.Product{*activeProduct(1, 1000), *activeProduct(2, 500)}, nil)
+	priceRepo.On("ListLive", []uint{1, 2}, wishlistNow).Return([]models.PriceSchedule{
+		{ID: 7, ProductID: 1, SalePrice: 800, EndsAt: wishlistNow.Add(time.Hour)},
+	}, nil)
+	notifier.On("Notify", models.BuyerNotification{
+		Type: models.NotificationPriceDrop, BuyerID: 5, ProductID: 1, Name: "Panjabi",
+		Price: 800, OldPrice: 1000, CreatedAt: wishlistNow,
+	}).Return()
+	repo.On("SetNotifiedPrice", []uint{1, 2}, 800.0).Return(nil)
+
+	err := service.DetectPriceDrops()
+
+	assert.NoError(t, err)
+	notifier.AssertNumberOfCalls(t, "Notify", 1)
+	repo.AssertExpectations(t)
+}
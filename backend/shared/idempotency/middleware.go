@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header is the header clients set to make a request safe to retry
+const Header = "Idempotency-Key"
+
+// persistedKey marks a request whose work was saved before it answered
+const persistedKey = "idempotency_persisted"
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// MarkPersisted tells Middleware that the request has saved what it creates, so its
+// response is kept for retries even if it ends in a server error
+func MarkPersisted(c *gin.Context) {
+	c.Set(persistedKey, true)
+}
+
+// Middleware replays the stored response when a request is retried with the same
+// Idempotency-Key. Requests without the header run as usual. It must come after the
+// auth middleware, since keys belong to the signed-in user.
+func Middleware(service *Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := c.GetUint("user_id")
+		record, replay, err := service.Begin(userID, key, c.Request.Method, c.FullPath(), body)
+		switch {
+		case errors.Is(err, ErrKeyInvalid):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrKeyReused), errors.Is(err, ErrKeyInFlight):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key"})
+			return
+		case replay != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(replay.StatusCode, replay.ContentType, replay.Response)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		err = service.Finish(record, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes(), c.GetBool(persistedKey))
+		if err != nil {
+			log.Printf("⚠️ Failed to store response for Idempotency-Key %q: %v", key, err)
+		}
+	}
+}
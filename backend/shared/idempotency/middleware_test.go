@@ -0,0 +1,36 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMiddleware_KeepsServerErrorAfterMarkPersisted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := new(MockRepository)
+	repo.On("Reserve", mock.Anything).Return(nil, nil)
+	repo.On("Complete", mock.MatchedBy(func(record *Record) bool {
+		return record.StatusCode == http.StatusInternalServerError
+	})).Return(nil)
+
+	router := gin.New()
+	router.POST("/orders", Middleware(NewService(repo, 0)), func(c *gin.Context) {
+		MarkPersisted(c)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "order saved, checkout not started"})
+	})
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+	req.Header.Set(Header, "abc")
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "Release", mock.Anything)
+}
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Reserve(record *Record) (*Record, error) {
+	args := m.Called(record)
+	existing, _ := args.Get(0).(*Record)
+	return existing, args.Error(1)
+}
+
+func (m *MockRepository) Complete(record *Record) error {
+	args := m.Called(record)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Release(record *Record) error {
+	args := m.Called(record)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteExpired(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import "time"
+
+// Record remembers a client's Idempotency-Key for one request, so a retry with the
+// same key gets the first response instead of running the request again
+type Record struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key         string    `json:"key" gorm:"size:255;uniqueIndex:idx_idempotency_user_key"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	RequestHash string    `json:"request_hash"` // sha256 of method, path and body
+	Completed   bool      `json:"completed"`    // false while the first request is still running
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type"`
+	Response    []byte    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+}
+
+// TableName keeps the table the services created before this package existed
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
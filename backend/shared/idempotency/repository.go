@@ -0,0 +1,75 @@
+package idempotency
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository defines the contract for stored Idempotency-Keys
+type Repository interface {
+	Reserve(record *Record) (*Record, error)
+	Complete(record *Record) error
+	Release(record *Record) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type idempotencyRepo struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &idempotencyRepo{db}
+}
+
+// Reserve stores a new key for the caller. If the user already has a live record with
+// that key, nothing is stored and the existing record is returned; an expired one is
+// replaced.
+func (r *idempotencyRepo) Reserve(record *Record) (*Record, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var existing Record
+		err := r.db.Where("user_id = ? AND key = ?", record.UserID, record.Key).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			continue // deleted in the meantime
+		}
+		if err != nil {
+			return nil, err
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return &existing, nil
+		}
+		if err := r.db.Where("id = ? AND expires_at <= ?", existing.ID, time.Now()).
+			Delete(&Record{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return nil, gorm.ErrDuplicatedKey
+}
+
+// Complete stores the response of the request a key was reserved for
+func (r *idempotencyRepo) Complete(record *Record) error {
+	record.Completed = true
+	return r.db.Model(&Record{}).Where("id = ?", record.ID).
+		Select("completed", "status_code", "content_type", "response").
+		Updates(record).Error
+}
+
+// Release forgets a key whose request failed, so the client can retry with it
+func (r *idempotencyRepo) Release(record *Record) error {
+	return r.db.Delete(&Record{}, record.ID).Error
+}
+
+// DeleteExpired removes keys past their expiry and reports how many went
+func (r *idempotencyRepo) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
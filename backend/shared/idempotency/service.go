@@ -0,0 +1,112 @@
+// Package idempotency lets clients retry unsafe requests with an Idempotency-Key header.
+// order-service and payment-service both use it.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DefaultTTL is how long a key is remembered unless configured otherwise
+const DefaultTTL = 24 * time.Hour
+
+// lockTimeout is how long a key stays claimed by a request that never finished,
+// e.g. because the service restarted mid-request
+const lockTimeout = 2 * time.Minute
+
+// MaxKeyLength caps the Idempotency-Key header
+const MaxKeyLength = 255
+
+var (
+	ErrKeyInvalid  = errors.New("Idempotency-Key must be 1 to 255 characters")
+	ErrKeyReused   = errors.New("Idempotency-Key was already used for a different request")
+	ErrKeyInFlight = errors.New("a request with this Idempotency-Key is still being processed")
+)
+
+// Service runs the first request with a key and stores its response; later ones with
+// the same key and payload get that response back without running again.
+type Service struct {
+	Repo Repository
+	TTL  time.Duration
+}
+
+func NewService(repo Repository, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{Repo: repo, TTL: ttl}
+}
+
+// Begin claims key for a request. It returns the new record when the request should
+// run, or the stored record of an earlier request to replay. A key already used for
+// another payload fails with ErrKeyReused, and one whose request is still running
+// with ErrKeyInFlight.
+func (s *Service) Begin(userID uint, key, method, path string, body []byte) (*Record, *Record, error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, nil, ErrKeyInvalid
+	}
+	record := &Record{
+		UserID:      userID,
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		RequestHash: requestHash(method, path, body),
+		ExpiresAt:   time.Now().Add(s.TTL),
+	}
+	existing, err := s.Repo.Reserve(record)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil && !existing.Completed && time.Since(existing.CreatedAt) > lockTimeout {
+		if err := s.Repo.Release(existing); err != nil {
+			return nil, nil, err
+		}
+		if existing, err = s.Repo.Reserve(record); err != nil {
+			return nil, nil, err
+		}
+	}
+	if existing == nil {
+		return record, nil, nil
+	}
+	if existing.RequestHash != record.RequestHash {
+		return nil, nil, ErrKeyReused
+	}
+	if !existing.Completed {
+		return nil, nil, ErrKeyInFlight
+	}
+	return nil, existing, nil
+}
+
+// Finish stores the response to a request begun with Begin. Server errors are not
+// kept, so the client can retry with the same key, unless the request already saved
+// what it was creating: running it again would create it twice, so persisted keeps
+// even a server error.
+func (s *Service) Finish(record *Record, statusCode int, contentType string, body []byte, persisted bool) error {
+	if statusCode >= http.StatusInternalServerError && !persisted {
+		return s.Repo.Release(record)
+	}
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Response = body
+	if err := s.Repo.Complete(record); err != nil {
+		// Better a retry that runs again than a key stuck in flight
+		s.Repo.Release(record)
+		return err
+	}
+	return nil
+}
+
+// PurgeExpired forgets keys past their expiry
+func (s *Service) PurgeExpired() (int64, error) {
+	return s.Repo.DeleteExpired(time.Now())
+}
+
+func requestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,105 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_Begin_ClaimsNewKey(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, time.Hour)
+	repo.On("Reserve", mock.AnythingOfType("*idempotency.Record")).Return(nil, nil)
+
+	record, replay, err := service.Begin(1, "abc", http.MethodPost, "/api/orders/", []byte(`{"a":1}`))
+
+	assert.NoError(t, err)
+	assert.Nil(t, replay)
+	assert.Equal(t, "abc", record.Key)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), record.ExpiresAt, time.Minute)
+}
+
+func TestService_Begin_ReplaysCompletedRequest(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	body := []byte(`{"a":1}`)
+	stored := &Record{Key: "abc", Completed: true, StatusCode: http.StatusCreated,
+		RequestHash: requestHash(http.MethodPost, "/api/orders/", body), Response: []byte(`{"order":{}}`)}
+	repo.On("Reserve", mock.Anything).Return(stored, nil)
+
+	record, replay, err := service.Begin(1, "abc", http.MethodPost, "/api/orders/", body)
+
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Equal(t, stored, replay)
+}
+
+func TestService_Begin_RejectsDifferentPayload(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	stored := &Record{Key: "abc", Completed: true,
+		RequestHash: requestHash(http.MethodPost, "/api/orders/", []byte(`{"a":1}`))}
+	repo.On("Reserve", mock.Anything).Return(stored, nil)
+
+	_, _, err := service.Begin(1, "abc", http.MethodPost, "/api/orders/", []byte(`{"a":2}`))
+
+	assert.ErrorIs(t, err, ErrKeyReused)
+}
+
+func TestService_Begin_TakesOverAbandonedKey(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	body := []byte(`{}`)
+	abandoned := &Record{ID: 3, Key: "abc", CreatedAt: time.Now().Add(-time.Hour),
+		RequestHash: requestHash(http.MethodPost, "/api/orders/", body)}
+	repo.On("Reserve", mock.Anything).Return(abandoned, nil).Once()
+	repo.On("Release", abandoned).Return(nil)
+	repo.On("Reserve", mock.Anything).Return(nil, nil).Once()
+
+	record, _, err := service.Begin(1, "abc", http.MethodPost, "/api/orders/", body)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, record)
+	repo.AssertExpectations(t)
+}
+
+func TestService_Finish_ForgetsServerErrors(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	record := &Record{ID: 3}
+	repo.On("Release", record).Return(nil)
+
+	assert.NoError(t, service.Finish(record, http.StatusInternalServerError, "application/json", nil, false))
+	repo.AssertNotCalled(t, "Complete", mock.Anything)
+}
+
+func TestService_Finish_ReleasesKeyWhenStoringFails(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	record := &Record{ID: 3}
+	repo.On("Complete", record).Return(errors.New("db down"))
+	repo.On("Release", record).Return(nil)
+
+	err := service.Finish(record, http.StatusCreated, "application/json", []byte(`{}`), false)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusCreated, record.StatusCode)
+	repo.AssertCalled(t, "Release", record)
+}
+
+func TestService_Finish_KeepsServerErrorOncePersisted(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 0)
+	record := &Record{ID: 3}
+	repo.On("Complete", record).Return(nil)
+
+	err := service.Finish(record, http.StatusInternalServerError, "application/json", []byte(`{"error":"x"}`), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, record.StatusCode)
+	repo.AssertNotCalled(t, "Release", mock.Anything)
+}
@@ -38,7 +38,7 @@ func main() {
 
     // Initialize repository, service, and controller
     shipmentRepo := repository.NewShipmentRepository(db)
-	shipmentService := services.NewShipmentService(shipmentRepo)
+	shipmentService := services.NewShipmentService(shipmentRepo, services.NewOrderClient())
 	shipmentController := controllers.NewShipmentController(shipmentService)
     // Initialize Gin router
     router := gin.Default()
@@ -71,7 +71,10 @@ func LoadConfig() Config {
 	}
 }
 
-
+// GetOrderServiceURL fetches the order service URL from env vars
+func GetOrderServiceURL() string {
+	return os.Getenv("ORDER_SERVICE_URL")
+}
 
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
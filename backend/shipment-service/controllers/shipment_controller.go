@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"shipment-service/models"
 	"shipment-service/services"
@@ -34,7 +35,14 @@ func (sc *ShipmentController) CreateShipment(c *gin.Context) {
 
 	shipment.SellerID = userID
 	if err := sc.svc.Create(&shipment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, services.ErrNothingToShip):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -130,3 +138,48 @@ func (sc *ShipmentController) DeleteShipment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Shipment deleted"})
 }
+
+// order-service requests the shipment for a checked-out order
+func (sc *ShipmentController) RequestShipment(c *gin.Context) {
+	var shipment models.Shipment
+	if err := c.ShouldBindJSON(&shipment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if shipment.OrderID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_id is required"})
+		return
+	}
+
+	result, err := sc.svc.RequestForOrder(&shipment)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNothingToShip):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// order-service shows an order's shipments in its order details
+func (sc *ShipmentController) ListOrderShipments(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("order_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order_id"})
+		return
+	}
+
+	shipments, err := sc.svc.ListByOrderID(uint(orderID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
@@ -40,6 +40,18 @@ func (m *MockShipmentService) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockShipmentService) RequestForOrder(s *models.Shipment) (*models.Shipment, error) {
+	args := m.Called(s)
+	shipment, _ := args.Get(0).(*models.Shipment)
+	return shipment, args.Error(1)
+}
+
+func (m *MockShipmentService) ListByOrderID(orderID uint) ([]models.Shipment, error) {
+	args := m.Called(orderID)
+	shipments, _ := args.Get(0).([]models.Shipment)
+	return shipments, args.Error(1)
+}
+
 func setupRouter(ctrl *ShipmentController, role string, userID uint) *gin.Engine {
 	r := gin.Default()
 	// Middleware to set user_id and role
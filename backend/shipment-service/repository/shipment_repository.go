@@ -9,7 +9,9 @@ import (
 type ShipmentRepository interface {
     Create(*models.Shipment) error
     GetByOrderID(uint) (*models.Shipment, error)
+    GetByOrderAndSeller(orderID, sellerID uint) (*models.Shipment, error)
     GetBySeller(uint) ([]models.Shipment, error)
+    ListByOrderID(orderID uint) ([]models.Shipment, error)
     UpdateStatus(uint, string) error
     Delete(uint) error
 }
@@ -32,12 +34,26 @@ func (r *shipmentRepo) GetByOrderID(orderID uint) (*models.Shipment, error) {
     return &shipment, err
 }
 
+// GetByOrderAndSeller finds the shipment sending one seller's part of an order
+func (r *shipmentRepo) GetByOrderAndSeller(orderID, sellerID uint) (*models.Shipment, error) {
+    var shipment models.Shipment
+    err := r.db.Where("order_id = ? AND seller_id = ?", orderID, sellerID).First(&shipment).Error
+    return &shipment, err
+}
+
 func (r *shipmentRepo) GetBySeller(sellerID uint) ([]models.Shipment, error) {
     var shipments []models.Shipment
     err := r.db.Where("seller_id = ?", sellerID).Find(&shipments).Error
     return shipments, err
 }
 
+// ListByOrderID lists every shipment of an order, one per seller sending goods
+func (r *shipmentRepo) ListByOrderID(orderID uint) ([]models.Shipment, error) {
+    var shipments []models.Shipment
+    err := r.db.Where("order_id = ?", orderID).Order("id").Find(&shipments).Error
+    return shipments, err
+}
+
 func (r *shipmentRepo) UpdateStatus(id uint, status string) error {
     return r.db.Model(&models.Shipment{}).Where("id = ?", id).Update("status", status).Error
 }
@@ -21,4 +21,12 @@ func RegisterShipmentRoutes(router *gin.Engine, shipmentController *controllers.
 
     }
 
+    // Internal service-to-service routes
+    internal := router.Group("/internal/shipments")
+    internal.Use(middleware.RequireAPIKey())
+    {
+        internal.POST("/", shipmentController.RequestShipment)                      // Shipment for a checked-out order (order-service)
+        internal.GET("/order/:order_id", shipmentController.ListOrderShipments)     // An order's shipments (order-service order details)
+    }
+
 }
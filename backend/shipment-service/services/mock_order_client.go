@@ -0,0 +1,13 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockOrderClient struct {
+	mock.Mock
+}
+
+func (m *MockOrderClient) GetOrder(orderID uint) (*Order, error) {
+	args := m.Called(orderID)
+	order, _ := args.Get(0).(*Order)
+	return order, args.Error(1)
+}
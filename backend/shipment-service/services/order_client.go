@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"shipment-service/config"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+// OrderItem is an order line as reported by order-service
+type OrderItem struct {
+	ProductID uint `json:"product_id"`
+	SellerID  uint `json:"seller_id"`
+	Quantity  int  `json:"quantity"`
+	IsDigital bool `json:"is_digital"`
+}
+
+// Order is an order as reported by order-service
+type Order struct {
+	ID         uint        `json:"id"`
+	BuyerID    uint        `json:"buyer_id"`
+	Status     string      `json:"status"`
+	OrderItems []OrderItem `json:"order_items"`
+}
+
+// HasPhysicalItems reports whether any item of the order needs shipping
+func (o *Order) HasPhysicalItems() bool {
+	return o.HasPhysicalItemsFrom(0)
+}
+
+// HasPhysicalItemsFrom reports whether the seller has items in the order that need
+// shipping. A zero seller matches every item, as do items of orders placed before
+// order-service recorded sellers.
+func (o *Order) HasPhysicalItemsFrom(sellerID uint) bool {
+	for _, item := range o.OrderItems {
+		if !item.IsDigital && (sellerID == 0 || item.SellerID == 0 || item.SellerID == sellerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderClient is the subset of order-service that shipment-service depends on
+type OrderClient interface {
+	GetOrder(orderID uint) (*Order, error)
+}
+
+type httpOrderClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOrderClient creates an OrderClient that talks to order-service over HTTP
+func NewOrderClient() OrderClient {
+	return &httpOrderClient{
+		baseURL: config.GetOrderServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetOrder fetches an order with its items
+func (c *httpOrderClient) GetOrder(orderID uint) (*Order, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/internal/orders/%d", c.baseURL, orderID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrOrderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("order-service returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var order Order
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
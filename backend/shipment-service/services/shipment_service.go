@@ -1,24 +1,45 @@
 package services
 
-import "shipment-service/models"
+import (
+    "errors"
+
+    "shipment-service/models"
+    "shipment-service/repository"
+
+    "gorm.io/gorm"
+)
+
+var ErrNothingToShip = errors.New("order has only digital items, which are delivered without shipment")
 
 type ShipmentService interface {
     Create(*models.Shipment) error
     GetByOrderID(uint) (*models.Shipment, error)
     GetBySeller(uint) ([]models.Shipment, error)
+    ListByOrderID(orderID uint) ([]models.Shipment, error)
     UpdateStatus(uint, string) error
     Delete(uint) error
+    RequestForOrder(*models.Shipment) (*models.Shipment, error)
 }
 
 type shipmentService struct {
-    repo ShipmentService
+    repo   repository.ShipmentRepository
+    orders OrderClient
 }
 
-func NewShipmentService(r ShipmentService) ShipmentService {
-    return &shipmentService{repo: r}
+func NewShipmentService(r repository.ShipmentRepository, orders OrderClient) ShipmentService {
+    return &shipmentService{repo: r, orders: orders}
 }
 
+// Create starts a shipment for an order that has physical items to send. A shipment
+// with a seller only sends that seller's items.
 func (s *shipmentService) Create(shipment *models.Shipment) error {
+    order, err := s.orders.GetOrder(shipment.OrderID)
+    if err != nil {
+        return err
+    }
+    if !order.HasPhysicalItemsFrom(shipment.SellerID) {
+        return ErrNothingToShip
+    }
     shipment.Status = "pending"
     return s.repo.Create(shipment)
 }
@@ -31,6 +52,10 @@ func (s *shipmentService) GetBySeller(sellerID uint) ([]models.Shipment, error)
     return s.repo.GetBySeller(sellerID)
 }
 
+func (s *shipmentService) ListByOrderID(orderID uint) ([]models.Shipment, error) {
+    return s.repo.ListByOrderID(orderID)
+}
+
 func (s *shipmentService) UpdateStatus(id uint, status string) error {
     return s.repo.UpdateStatus(id, status)
 }
@@ -38,3 +63,20 @@ func (s *shipmentService) UpdateStatus(id uint, status string) error {
 func (s *shipmentService) Delete(id uint) error {
     return s.repo.Delete(id)
 }
+
+// RequestForOrder starts the shipment of one seller's part of an order checked out by
+// order-service. A part that already has a shipment gets that one back, so retries
+// create only one.
+func (s *shipmentService) RequestForOrder(shipment *models.Shipment) (*models.Shipment, error) {
+    existing, err := s.repo.GetByOrderAndSeller(shipment.OrderID, shipment.SellerID)
+    if err == nil {
+        return existing, nil
+    }
+    if !errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, err
+    }
+    if err := s.Create(shipment); err != nil {
+        return nil, err
+    }
+    return shipment, nil
+}
@@ -8,12 +8,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockShipmentRepo struct {
 	mock.Mock
 }
 
+var _ repository.ShipmentRepository = (*MockShipmentRepo)(nil)
+
 func (m *MockShipmentRepo) Create(s *models.Shipment) error {
 	args := m.Called(s)
 	return args.Error(0)
@@ -24,6 +27,11 @@ func (m *MockShipmentRepo) GetByOrderID(orderID uint) (*models.Shipment, error)
 	return args.Get(0).(*models.Shipment), args.Error(1)
 }
 
+func (m *MockShipmentRepo) GetByOrderAndSeller(orderID, sellerID uint) (*models.Shipment, error) {
+	args := m.Called(orderID, sellerID)
+	return args.Get(0).(*models.Shipment), args.Error(1)
+}
+
 func (m *MockShipmentRepo) GetBySeller(sellerID uint) ([]models.Shipment, error) {
 	args := m.Called(sellerID)
 	return args.Get(0).([]models.Shipment), args.Error(1)
@@ -39,12 +47,23 @@ func (m *MockShipmentRepo) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockShipmentRepo) ListByOrderID(orderID uint) ([]models.Shipment, error) {
+	args := m.Called(orderID)
+	shipments, _ := args.Get(0).([]models.Shipment)
+	return shipments, args.Error(1)
+}
+
 func TestCreateShipment(t *testing.T) {
 	mockRepo := new(MockShipmentRepo)
-	service := NewShipmentService(mockRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
 
 	shipment := &models.Shipment{OrderID: 1, SellerID: 2, BuyerID: 3, Address: "Addr"}
 
+	mockOrders.On("GetOrder", uint(1)).Return(&Order{ID: 1, OrderItems: []OrderItem{
+		{ProductID: 10, Quantity: 1},
+		{ProductID: 12, Quantity: 1, IsDigital: true},
+	}}, nil)
 	mockRepo.On("Create", shipment).Return(nil)
 
 	err := service.Create(shipment)
@@ -54,9 +73,35 @@ func TestCreateShipment(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateShipment_DigitalOnlyOrder(t *testing.T) {
+	mockRepo := new(MockShipmentRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
+
+	mockOrders.On("GetOrder", uint(1)).Return(&Order{ID: 1, OrderItems: []OrderItem{
+		{ProductID: 12, Quantity: 1, IsDigital: true},
+	}}, nil)
+
+	err := service.Create(&models.Shipment{OrderID: 1, SellerID: 2})
+	assert.ErrorIs(t, err, ErrNothingToShip)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateShipment_OrderLookupFails(t *testing.T) {
+	mockRepo := new(MockShipmentRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
+
+	mockOrders.On("GetOrder", uint(1)).Return(nil, errors.New("order-service unavailable"))
+
+	err := service.Create(&models.Shipment{OrderID: 1, SellerID: 2})
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 func TestGetByOrderID(t *testing.T) {
 	mockRepo := new(MockShipmentRepo)
-	service := NewShipmentService(mockRepo)
+	service := NewShipmentService(mockRepo, new(MockOrderClient))
 
 	expected := &models.Shipment{ID: 1, OrderID: 1}
 	mockRepo.On("GetByOrderID", uint(1)).Return(expected, nil)
@@ -70,7 +115,7 @@ func TestGetByOrderID(t *testing.T) {
 
 func TestGetBySeller(t *testing.T) {
 	mockRepo := new(MockShipmentRepo)
-	service := NewShipmentService(mockRepo)
+	service := NewShipmentService(mockRepo, new(MockOrderClient))
 
 	expected := []models.Shipment{{ID: 1, SellerID: 2}, {ID: 2, SellerID: 2}}
 	mockRepo.On("GetBySeller", uint(2)).Return(expected, nil)
@@ -84,7 +129,7 @@ func TestGetBySeller(t *testing.T) {
 
 func TestUpdateStatus(t *testing.T) {
 	mockRepo := new(MockShipmentRepo)
-	service := NewShipmentService(mockRepo)
+	service := NewShipmentService(mockRepo, new(MockOrderClient))
 
 	mockRepo.On("UpdateStatus", uint(1), "shipped").Return(nil)
 
@@ -96,7 +141,7 @@ func TestUpdateStatus(t *testing.T) {
 
 func TestDeleteShipment(t *testing.T) {
 	mockRepo := new(MockShipmentRepo)
-	service := NewShipmentService(mockRepo)
+	service := NewShipmentService(mockRepo, new(MockOrderClient))
 
 	mockRepo.On("Delete", uint(1)).Return(nil)
 
@@ -105,3 +150,50 @@ func TestDeleteShipment(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestRequestForOrder_CreatesShipment(t *testing.T) {
+	mockRepo := new(MockShipmentRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
+
+	shipment := &models.Shipment{OrderID: 1, SellerID: 2, BuyerID: 3}
+	mockRepo.On("GetByOrderAndSeller", uint(1), uint(2)).Return((*models.Shipment)(nil), gorm.ErrRecordNotFound)
+	mockOrders.On("GetOrder", uint(1)).Return(&Order{ID: 1, OrderItems: []OrderItem{{ProductID: 10, SellerID: 2, Quantity: 1}}}, nil)
+	mockRepo.On("Create", shipment).Return(nil)
+
+	result, err := service.RequestForOrder(shipment)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequestForOrder_ReturnsExistingShipment(t *testing.T) {
+	mockRepo := new(MockShipmentRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
+
+	existing := &models.Shipment{ID: 5, OrderID: 1, SellerID: 2, Status: "shipped"}
+	mockRepo.On("GetByOrderAndSeller", uint(1), uint(2)).Return(existing, nil)
+
+	result, err := service.RequestForOrder(&models.Shipment{OrderID: 1, SellerID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, existing, result)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockOrders.AssertNotCalled(t, "GetOrder", mock.Anything)
+}
+
+func TestRequestForOrder_RejectsSellerWithNothingToShip(t *testing.T) {
+	mockRepo := new(MockShipmentRepo)
+	mockOrders := new(MockOrderClient)
+	service := NewShipmentService(mockRepo, mockOrders)
+
+	mockRepo.On("GetByOrderAndSeller", uint(1), uint(4)).Return((*models.Shipment)(nil), gorm.ErrRecordNotFound)
+	mockOrders.On("GetOrder", uint(1)).Return(&Order{ID: 1, OrderItems: []OrderItem{
+		{ProductID: 10, SellerID: 2, Quantity: 1},
+		{ProductID: 11, SellerID: 4, Quantity: 1, IsDigital: true},
+	}}, nil)
+
+	_, err := service.RequestForOrder(&models.Shipment{OrderID: 1, SellerID: 4})
+	assert.ErrorIs(t, err, ErrNothingToShip)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+    "context"
     "log"
     "shop-service/config"
     "shop-service/controllers"
@@ -26,9 +27,12 @@ func main() {
     db.AutoMigrate(&models.Shop{})
 
     shopRepo := repository.NewShopRepository(db)
-    shopService := services.NewShopService(shopRepo)
+    shopService := services.NewShopService(shopRepo, services.NewProductClient())
     shopController := controllers.NewShopController(shopService)
 
+    // 🏪 Resend shop visibility so syncs that failed after a shop change still land
+    go services.RunEvery(context.Background(), config.GetVisibilitySyncInterval(), "shop visibility", shopService.ReconcileVisibility)
+
     route := gin.Default()
 
     routes.RegisterShopRoutes(route, shopController)
@@ -4,6 +4,7 @@ import (
     "fmt"
 	"log"
 	"os"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -75,6 +76,27 @@ func LoadConfig() Config {
 }
 
 
+// GetProductServiceURL fetches the product service URL from env vars
+func GetProductServiceURL() string {
+	return os.Getenv("PRODUCT_SERVICE_URL")
+}
+
+// GetVisibilitySyncInterval is how often every shop's product visibility is sent to
+// product-service again, from VISIBILITY_SYNC_INTERVAL (default 10m)
+func GetVisibilitySyncInterval() time.Duration {
+	const fallback = 10 * time.Minute
+	raw := os.Getenv("VISIBILITY_SYNC_INTERVAL")
+	if raw == "" {
+		return fallback
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval <= 0 {
+		log.Printf("⚠️ Invalid VISIBILITY_SYNC_INTERVAL %q, using %s", raw, fallback)
+		return fallback
+	}
+	return interval
+}
+
 // mustGetEnv fetches required environment variable or logs fatal error
 func mustGetEnv(key string) string {
 	value := os.Getenv(key)
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+    "errors"
     "fmt"
     "net/http"
     "shop-service/models"
@@ -8,6 +9,7 @@ import (
     "strconv"
 
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
 )
 
 // ShopController handles all shop-related endpoints
@@ -29,8 +31,8 @@ func getAuthUser(c *gin.Context) (uint, string, error) {
         return 0, "", fmt.Errorf("user ID not found in context")
     }
 
-    // JWT typically parses numeric values as float64
-    uidFloat, ok := rawID.(float64)
+    // RequireAuth already converts the JWT's numeric id to uint
+    uid, ok := rawID.(uint)
     if !ok {
         return 0, "", fmt.Errorf("user ID is not a valid number")
     }
@@ -45,7 +47,7 @@ func getAuthUser(c *gin.Context) (uint, string, error) {
         return 0, "", fmt.Errorf("role is not a valid string")
     }
 
-    return uint(uidFloat), roleStr, nil
+    return uid, roleStr, nil
 }
 
 // ======================
@@ -70,6 +72,9 @@ func (ctrl *ShopController) CreateShop(c *gin.Context) {
     }
 
     shop.OwnerID = userID
+    // New shops wait for admin approval
+    shop.IsApproved = false
+    shop.IsBlocked = false
 
     if err := ctrl.Service.CreateShop(&shop); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -133,6 +138,10 @@ func (ctrl *ShopController) UpdateShop(c *gin.Context) {
 
     shop.ID = uint(id)
     shop.OwnerID = userID
+    // Approval and blocking are admin decisions
+    shop.IsApproved = existing.IsApproved
+    shop.IsBlocked = existing.IsBlocked
+    shop.CreatedAt = existing.CreatedAt
 
     if err := ctrl.Service.UpdateShop(&shop); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -232,3 +241,38 @@ func (ctrl *ShopController) GetDashboard(c *gin.Context) {
 
     c.JSON(http.StatusOK, dashboard)
 }
+
+// ======================
+// ✅ Approve / Block Shop (internal, called by admin-service)
+// ======================
+func (ctrl *ShopController) ApproveShop(c *gin.Context) {
+    ctrl.changeStatus(c, func(id uint) (*models.Shop, error) { return ctrl.Service.SetApproved(id, true) })
+}
+
+func (ctrl *ShopController) BlockShop(c *gin.Context) {
+    ctrl.changeStatus(c, func(id uint) (*models.Shop, error) { return ctrl.Service.SetBlocked(id, true) })
+}
+
+func (ctrl *ShopController) UnblockShop(c *gin.Context) {
+    ctrl.changeStatus(c, func(id uint) (*models.Shop, error) { return ctrl.Service.SetBlocked(id, false) })
+}
+
+func (ctrl *ShopController) changeStatus(c *gin.Context, change func(uint) (*models.Shop, error)) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shop ID"})
+        return
+    }
+
+    shop, err := change(uint(id))
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, shop)
+}
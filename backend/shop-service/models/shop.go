@@ -10,6 +10,11 @@ type Shop struct {
     Name        string         `gorm:"unique;not null" json:"name"`
     Description string         `json:"description"`
     OwnerID     uint           `gorm:"index" json:"owner_id"`
+    // Business details printed on the shop's invoices
+    LegalName   string         `json:"legal_name"`
+    Address     string         `json:"address"`
+    Phone       string         `json:"phone"`
+    BIN         string         `json:"bin"` // VAT Business Identification Number
     IsApproved  bool           `gorm:"default:false" json:"is_approved"`
     IsBlocked   bool           `gorm:"default:false" json:"is_blocked"`
     CreatedAt   time.Time      `json:"created_at"`
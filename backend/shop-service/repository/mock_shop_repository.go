@@ -17,12 +17,8 @@ func (m *MockShopRepository) Create(shop *models.Shop) error {
 
 func (m *MockShopRepository) GetByID(id uint) (*models.Shop, error) {
 	args := m.Called(id)
-	return args.Get(0).(*models.Shop), args.Error(1)
-}
-
-func (m *MockShopRepository) GetBySellerID(sellerID uint) ([]models.Shop, error) {
-	args := m.Called(sellerID)
-	return args.Get(0).([]models.Shop), args.Error(1)
+	shop, _ := args.Get(0).(*models.Shop)
+	return shop, args.Error(1)
 }
 
 func (m *MockShopRepository) Update(shop *models.Shop) error {
@@ -34,3 +30,45 @@ func (m *MockShopRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
+
+func (m *MockShopRepository) ListAll() ([]models.Shop, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Shop), args.Error(1)
+}
+
+func (m *MockShopRepository) SearchByName(name string) ([]models.Shop, error) {
+	args := m.Called(name)
+	return args.Get(0).([]models.Shop), args.Error(1)
+}
+
+func (m *MockShopRepository) OldestByOwner(ownerID uint) (*models.Shop, error) {
+	args := m.Called(ownerID)
+	shop, _ := args.Get(0).(*models.Shop)
+	return shop, args.Error(1)
+}
+
+func (m *MockShopRepository) ListWithDeleted() ([]models.Shop, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Shop), args.Error(1)
+}
+
+func (m *MockShopRepository) CountByOwner(ownerID uint) (int64, error) {
+	args := m.Called(ownerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockShopRepository) CountByOwnerAndApproved(ownerID uint, approved bool) (int64, error) {
+	args := m.Called(ownerID, approved)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockShopRepository) CountByOwnerAndBlocked(ownerID uint, blocked bool) (int64, error) {
+	args := m.Called(ownerID, blocked)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockShopRepository) CountRecentByOwner(ownerID uint, days int) (int64, error) {
+	args := m.Called(ownerID, days)
+	return args.Get(0).(int64), args.Error(1)
+}
+
@@ -13,6 +13,8 @@ type ShopRepository interface {
 	Delete(id uint) error
 	ListAll() ([]models.Shop, error)
 	SearchByName(name string) ([]models.Shop, error)
+	OldestByOwner(ownerID uint) (*models.Shop, error)
+	ListWithDeleted() ([]models.Shop, error)
 
 	CountByOwner(ownerID uint) (int64, error)
 	CountByOwnerAndApproved(ownerID uint, approved bool) (int64, error)
@@ -60,6 +62,22 @@ func (r *shopRepo) SearchByName(name string) ([]models.Shop, error) {
 	return shops, err
 }
 
+// OldestByOwner returns the owner's first shop that still exists
+func (r *shopRepo) OldestByOwner(ownerID uint) (*models.Shop, error) {
+	var shop models.Shop
+	if err := r.db.Where("owner_id = ?", ownerID).Order("id ASC").First(&shop).Error; err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
+
+// ListWithDeleted returns every shop, deleted ones included, oldest first
+func (r *shopRepo) ListWithDeleted() ([]models.Shop, error) {
+	var shops []models.Shop
+	err := r.db.Unscoped().Order("id ASC").Find(&shops).Error
+	return shops, err
+}
+
 func (r *shopRepo) CountByOwner(ownerID uint) (int64, error) {
 	var count int64
 	err := r.db.Model(&models.Shop{}).Where("owner_id = ?", ownerID).Count(&count).Error
@@ -90,3 +108,4 @@ func (r *shopRepo) CountRecentByOwner(ownerID uint, days int) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
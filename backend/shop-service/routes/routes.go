@@ -25,4 +25,14 @@ func RegisterShopRoutes(r *gin.Engine, shopController *controllers.ShopControlle
             protected.GET("/dashboard", shopController.GetDashboard)
         }
     }
+
+    // Internal endpoints for other services (shared API key)
+    internal := r.Group("/internal/shops")
+    internal.Use(middleware.RequireAPIKey())
+    {
+        internal.GET("/:id", shopController.GetShop)
+        internal.PATCH("/:id/approve", shopController.ApproveShop)
+        internal.PATCH("/:id/block", shopController.BlockShop)
+        internal.PATCH("/:id/unblock", shopController.UnblockShop)
+    }
 }
@@ -0,0 +1,12 @@
+package services
+
+import "github.com/stretchr/testify/mock"
+
+type MockProductClient struct {
+	mock.Mock
+}
+
+func (m *MockProductClient) SetShopVisibility(shopID, ownerID uint, visible bool) error {
+	args := m.Called(shopID, ownerID, visible)
+	return args.Error(0)
+}
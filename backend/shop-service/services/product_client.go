@@ -0,0 +1,61 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"shop-service/config"
+)
+
+// ProductClient is the subset of product-service that shop-service depends on
+type ProductClient interface {
+	SetShopVisibility(shopID, ownerID uint, visible bool) error
+}
+
+type httpProductClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewProductClient creates a ProductClient that talks to product-service over HTTP
+func NewProductClient() ProductClient {
+	return &httpProductClient{
+		baseURL: config.GetProductServiceURL(),
+		apiKey:  os.Getenv("API_KEY"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetShopVisibility hides or restores all of a shop's products in the public catalogue.
+// The owner is sent along so products listed before shops existed follow the shop too.
+func (c *httpProductClient) SetShopVisibility(shopID, ownerID uint, visible bool) error {
+	body, err := json.Marshal(map[string]interface{}{"shop_id": shopID, "owner_id": ownerID, "visible": visible})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, c.baseURL+"/internal/products/shop-visibility", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("product visibility update failed: %s", string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery runs job once immediately and then on every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func RunEvery(ctx context.Context, interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(); err != nil {
+			log.Printf("⚠️ %s job failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"shop-service/models"
 	"shop-service/repository"
+
+	"gorm.io/gorm"
 )
 
 type ShopDashboard struct {
@@ -20,14 +25,18 @@ type ShopService interface {
 	ListShops() ([]models.Shop, error)
 	SearchShops(name string) ([]models.Shop, error)
 	GetShopDashboard(ownerID uint) (*ShopDashboard, error)
+	SetApproved(id uint, approved bool) (*models.Shop, error)
+	SetBlocked(id uint, blocked bool) (*models.Shop, error)
+	ReconcileVisibility() error
 }
 
 type shopService struct {
-	repo repository.ShopRepository
+	repo     repository.ShopRepository
+	products ProductClient
 }
 
-func NewShopService(repo repository.ShopRepository) ShopService {
-	return &shopService{repo}
+func NewShopService(repo repository.ShopRepository, products ProductClient) ShopService {
+	return &shopService{repo: repo, products: products}
 }
 
 func (s *shopService) CreateShop(shop *models.Shop) error {
@@ -42,8 +51,29 @@ func (s *shopService) UpdateShop(shop *models.Shop) error {
 	return s.repo.Update(shop)
 }
 
+// DeleteShop removes a shop and hides its products from the catalogue. The owner's
+// shopless products move on to their next shop, or are hidden when none is left.
 func (s *shopService) DeleteShop(id uint) error {
-	return s.repo.Delete(id)
+	shop, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	next, err := s.repo.OldestByOwner(shop.OwnerID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		s.pushVisibility(id, shop.OwnerID, false)
+	case err != nil:
+		s.pushVisibility(id, 0, false)
+		log.Printf("⚠️ Shop %d deleted, owner %d's shopless products left for the visibility sync: %v", id, shop.OwnerID, err)
+	default:
+		s.pushVisibility(id, 0, false)
+		s.pushVisibility(next.ID, shop.OwnerID, visible(next))
+	}
+	return nil
 }
 
 func (s *shopService) ListShops() ([]models.Shop, error) {
@@ -82,3 +112,94 @@ func (s *shopService) GetShopDashboard(ownerID uint) (*ShopDashboard, error) {
 		RecentShopsCount: recent,
 	}, nil
 }
+
+// SetApproved changes a shop's approval and syncs the owner's product visibility
+func (s *shopService) SetApproved(id uint, approved bool) (*models.Shop, error) {
+	return s.updateStatus(id, func(shop *models.Shop) { shop.IsApproved = approved })
+}
+
+// SetBlocked blocks or unblocks a shop and syncs the owner's product visibility
+func (s *shopService) SetBlocked(id uint, blocked bool) (*models.Shop, error) {
+	return s.updateStatus(id, func(shop *models.Shop) { shop.IsBlocked = blocked })
+}
+
+// updateStatus applies a status change, then shows the shop's products only
+// while it is approved and not blocked
+func (s *shopService) updateStatus(id uint, change func(*models.Shop)) (*models.Shop, error) {
+	shop, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	change(shop)
+	if err := s.repo.Update(shop); err != nil {
+		return nil, err
+	}
+
+	oldest, err := s.repo.OldestByOwner(shop.OwnerID)
+	if err != nil {
+		log.Printf("⚠️ Shop %d product visibility left for the visibility sync: %v", shop.ID, err)
+		return shop, nil
+	}
+	s.pushVisibility(shop.ID, legacyOwner(shop, oldest.ID), visible(shop))
+	return shop, nil
+}
+
+// ReconcileVisibility sends every shop's product visibility to product-service again,
+// so changes whose sync failed still take effect. Deleted shops stay hidden.
+func (s *shopService) ReconcileVisibility() error {
+	shops, err := s.repo.ListWithDeleted()
+	if err != nil {
+		return err
+	}
+
+	// Shopless products follow their owner's oldest remaining shop
+	carriers := make(map[uint]uint)
+	for _, shop := range shops {
+		if _, ok := carriers[shop.OwnerID]; !ok && !shop.DeletedAt.Valid {
+			carriers[shop.OwnerID] = shop.ID
+		}
+	}
+
+	var failed int
+	var lastErr error
+	for i := range shops {
+		shop := &shops[i]
+		carrier, ok := carriers[shop.OwnerID]
+		owner := legacyOwner(shop, carrier)
+		if !ok {
+			// Nothing is left to carry them, so they are hidden with the deleted shops
+			owner = shop.OwnerID
+		}
+		if err := s.products.SetShopVisibility(shop.ID, owner, visible(shop)); err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d shops not synced: %w", failed, len(shops), lastErr)
+	}
+	return nil
+}
+
+// pushVisibility tells product-service whether a shop's products may be listed. The shop
+// change is already saved, so a failure is only logged; ReconcileVisibility sends it again.
+func (s *shopService) pushVisibility(shopID, ownerID uint, show bool) {
+	if err := s.products.SetShopVisibility(shopID, ownerID, show); err != nil {
+		log.Printf("⚠️ Shop %d product visibility left for the visibility sync: %v", shopID, err)
+	}
+}
+
+// visible reports whether a shop's products belong in the catalogue
+func visible(shop *models.Shop) bool {
+	return !shop.DeletedAt.Valid && shop.IsApproved && !shop.IsBlocked
+}
+
+// legacyOwner is the owner to send along with a shop's visibility. Products listed before
+// shops existed have no shop and follow only the owner's oldest shop, so blocking any
+// other shop leaves them alone.
+func legacyOwner(shop *models.Shop, oldestID uint) uint {
+	if shop.ID == oldestID {
+		return shop.OwnerID
+	}
+	return 0
+}
@@ -5,47 +5,50 @@ import (
 	"shop-service/models"
 	"shop-service/repository"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestCreateShop_Success(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	service := NewShopService(mockRepo, new(MockProductClient))
 
 	shop := &models.Shop{
-		Name:     "MyShop",
-		SellerID: 101,
+		Name:    "MyShop",
+		OwnerID: 101,
 	}
 
 	mockRepo.On("Create", shop).Return(nil)
 
-	err := service.Create(shop)
+	err := service.CreateShop(shop)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateShop_Error(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	service := NewShopService(mockRepo, new(MockProductClient))
 
 	shop := &models.Shop{Name: "FailShop"}
 
 	mockRepo.On("Create", shop).Return(errors.New("DB error"))
 
-	err := service.Create(shop)
+	err := service.CreateShop(shop)
 	assert.Error(t, err)
 }
 
 func TestGetByID_Success(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	service := NewShopService(mockRepo, new(MockProductClient))
 
 	expected := &models.Shop{ID: 1, Name: "TestShop"}
 
 	mockRepo.On("GetByID", uint(1)).Return(expected, nil)
 
-	result, err := service.GetByID(1)
+	result, err := service.GetShopByID(1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -53,53 +56,146 @@ func TestGetByID_Success(t *testing.T) {
 
 func TestGetByID_NotFound(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	service := NewShopService(mockRepo, new(MockProductClient))
 
 	mockRepo.On("GetByID", uint(2)).Return(&models.Shop{}, errors.New("not found"))
 
-	_, err := service.GetByID(2)
+	_, err := service.GetShopByID(2)
 
 	assert.Error(t, err)
 	assert.Equal(t, "not found", err.Error())
 }
 
-func TestGetBySellerID(t *testing.T) {
+func TestUpdateShop_Success(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
-
-	mockShops := []models.Shop{
-		{ID: 1, Name: "Shop1", SellerID: 200},
-		{ID: 2, Name: "Shop2", SellerID: 200},
-	}
+	service := NewShopService(mockRepo, new(MockProductClient))
 
-	mockRepo.On("GetBySellerID", uint(200)).Return(mockShops, nil)
+	shop := &models.Shop{ID: 1, Name: "UpdatedShop"}
 
-	result, err := service.GetBySellerID(200)
+	mockRepo.On("Update", shop).Return(nil)
 
+	err := service.UpdateShop(shop)
 	assert.NoError(t, err)
-	assert.Equal(t, 2, len(result))
 }
 
-func TestUpdateShop_Success(t *testing.T) {
+func TestDeleteShop_Success(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
 
-	shop := &models.Shop{ID: 1, Name: "UpdatedShop"}
+	mockRepo.On("GetByID", uint(1)).Return(&models.Shop{ID: 1, OwnerID: 7}, nil)
+	mockRepo.On("Delete", uint(1)).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+	products.On("SetShopVisibility", uint(1), uint(7), false).Return(nil)
 
-	mockRepo.On("Update", shop).Return(nil)
+	err := service.DeleteShop(1)
 
-	err := service.Update(shop)
 	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	products.AssertExpectations(t)
 }
 
-func TestDeleteShop_Success(t *testing.T) {
+func TestDeleteShop_HandsShoplessProductsToNextShop(t *testing.T) {
 	mockRepo := new(repository.MockShopRepository)
-	service := NewShopService(mockRepo)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
 
+	mockRepo.On("GetByID", uint(1)).Return(&models.Shop{ID: 1, OwnerID: 7}, nil)
 	mockRepo.On("Delete", uint(1)).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(&models.Shop{ID: 4, OwnerID: 7, IsApproved: true}, nil)
+	products.On("SetShopVisibility", uint(1), uint(0), false).Return(nil)
+	products.On("SetShopVisibility", uint(4), uint(7), true).Return(nil)
+
+	assert.NoError(t, service.DeleteShop(1))
+	products.AssertExpectations(t)
+}
+
+func TestBlockShop_HidesProducts(t *testing.T) {
+	mockRepo := new(repository.MockShopRepository)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
 
-	err := service.Delete(1)
+	shop := &models.Shop{ID: 1, OwnerID: 7, IsApproved: true}
+	mockRepo.On("GetByID", uint(1)).Return(shop, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Shop")).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(shop, nil)
+	products.On("SetShopVisibility", uint(1), uint(7), false).Return(nil)
+
+	result, err := service.SetBlocked(1, true)
 
 	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
+	assert.True(t, result.IsBlocked)
+	products.AssertExpectations(t)
+}
+
+func TestUnblockShop_RestoresProductsOnlyWhenApproved(t *testing.T) {
+	mockRepo := new(repository.MockShopRepository)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Shop{ID: 1, OwnerID: 7, IsBlocked: true}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Shop")).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(&models.Shop{ID: 1, OwnerID: 7}, nil)
+	products.On("SetShopVisibility", uint(1), uint(7), false).Return(nil)
+
+	_, err := service.SetBlocked(1, false)
+
+	assert.NoError(t, err)
+	products.AssertExpectations(t)
+}
+
+func TestBlockShop_LeavesShoplessProductsToOldestShop(t *testing.T) {
+	mockRepo := new(repository.MockShopRepository)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
+
+	mockRepo.On("GetByID", uint(5)).Return(&models.Shop{ID: 5, OwnerID: 7, IsApproved: true}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Shop")).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(&models.Shop{ID: 1, OwnerID: 7, IsApproved: true}, nil)
+	products.On("SetShopVisibility", uint(5), uint(0), false).Return(nil)
+
+	_, err := service.SetBlocked(5, true)
+
+	assert.NoError(t, err)
+	products.AssertExpectations(t)
+}
+
+func TestBlockShop_SavedEvenWhenProductServiceFails(t *testing.T) {
+	mockRepo := new(repository.MockShopRepository)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
+
+	shop := &models.Shop{ID: 1, OwnerID: 7, IsApproved: true}
+	mockRepo.On("GetByID", uint(1)).Return(shop, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Shop")).Return(nil)
+	mockRepo.On("OldestByOwner", uint(7)).Return(shop, nil)
+	products.On("SetShopVisibility", uint(1), uint(7), false).Return(errors.New("connection refused"))
+
+	result, err := service.SetBlocked(1, true)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsBlocked)
+}
+
+func TestReconcileVisibility_ResendsEveryShop(t *testing.T) {
+	mockRepo := new(repository.MockShopRepository)
+	products := new(MockProductClient)
+	service := NewShopService(mockRepo, products)
+
+	deleted := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	mockRepo.On("ListWithDeleted").Return([]models.Shop{
+		{ID: 1, OwnerID: 7, IsApproved: true, DeletedAt: deleted},
+		{ID: 2, OwnerID: 7, IsApproved: true},
+		{ID: 3, OwnerID: 7, IsApproved: true, IsBlocked: true},
+		{ID: 4, OwnerID: 8, IsApproved: true, DeletedAt: deleted},
+	}, nil)
+	products.On("SetShopVisibility", uint(1), uint(0), false).Return(nil)
+	products.On("SetShopVisibility", uint(2), uint(7), true).Return(nil)
+	products.On("SetShopVisibility", uint(3), uint(0), false).Return(errors.New("timeout"))
+	products.On("SetShopVisibility", uint(4), uint(8), false).Return(nil)
+
+	err := service.ReconcileVisibility()
+
+	assert.ErrorContains(t, err, "1 of 4 shops not synced")
+	products.AssertExpectations(t)
 }
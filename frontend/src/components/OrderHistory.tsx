@@ -23,7 +23,8 @@ export const OrderHistory: React.FC<OrderHistoryProps> = ({ orders, onClose }) =
       case 'processing': return 'bg-blue-100 text-blue-800';
       case 'shipped':
       case 'out_for_delivery': return 'bg-purple-100 text-purple-800';
-      case 'delivered': return 'bg-green-100 text-green-800';
+      case 'delivered':
+      case 'completed': return 'bg-green-100 text-green-800';
       case 'cancelled':
       case 'returned': return 'bg-red-100 text-red-800';
       default: return 'bg-gray-100 text-gray-800';
@@ -55,7 +56,7 @@ export const OrderHistory: React.FC<OrderHistoryProps> = ({ orders, onClose }) =
 
             {/* Status Filter */}
             <div className="mt-4 flex flex-wrap gap-2">
-              {['all', 'pending', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled'].map((status) => (
+              {['all', 'pending', 'confirmed', 'processing', 'shipped', 'delivered', 'completed', 'cancelled'].map((status) => (
                 <button
                   key={status}
                   onClick={() => setStatusFilter(status)}
@@ -166,7 +167,7 @@ export const OrderHistory: React.FC<OrderHistoryProps> = ({ orders, onClose }) =
                         <span>Download Invoice</span>
                       </button>
 
-                      {order.status === 'delivered' && (
+                      {['delivered', 'completed'].includes(order.status) && (
                         <button className="flex items-center space-x-2 px-4 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors">
                           <RefreshCw className="h-4 w-4" />
                           <span>Reorder</span>
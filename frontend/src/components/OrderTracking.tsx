@@ -17,6 +17,7 @@ export const OrderTracking: React.FC<OrderTrackingProps> = ({ order, onClose })
       case 'out_for_delivery':
         return Truck;
       case 'delivered':
+      case 'completed':
         return CheckCircle;
       default:
         return Clock;
@@ -34,6 +35,7 @@ export const OrderTracking: React.FC<OrderTrackingProps> = ({ order, onClose })
       case 'out_for_delivery':
         return 'text-purple-600 bg-purple-100';
       case 'delivered':
+      case 'completed':
         return 'text-green-600 bg-green-100';
       case 'cancelled':
       case 'returned':
@@ -270,7 +272,7 @@ export const OrderTracking: React.FC<OrderTrackingProps> = ({ order, onClose })
               Close Tracking
             </button>
 
-            {order.status === 'delivered' && (
+            {['delivered', 'completed'].includes(order.status) && (
               <button className="flex-1 border border-gray-300 text-gray-700 py-3 rounded-lg hover:bg-gray-50 transition-colors font-medium">
                 Leave Review
               </button>
@@ -96,7 +96,7 @@ export interface Order {
   tax: number;
   shipping: number
   codFee?: number; // Cash on Delivery fee
-  status: 'pending' | 'confirmed' | 'processing' | 'shipped' | 'out_for_delivery' | 'delivered' | 'cancelled' | 'returned';
+  status: 'pending' | 'confirmed' | 'processing' | 'shipped' | 'out_for_delivery' | 'delivered' | 'completed' | 'cancelled' | 'returned';
   orderDate: string;
   shippingAddress: Address;
   billingAddress: Address;
@@ -30,7 +30,7 @@ export const createMockTrackingEvents = (status: string): TrackingEvent[] => {
     },
   ];
 
-  if (['shipped', 'out_for_delivery', 'delivered'].includes(status)) {
+  if (['shipped', 'out_for_delivery', 'delivered', 'completed'].includes(status)) {
     events.push({
       id: '3',
       status: 'shipped',
@@ -41,7 +41,7 @@ export const createMockTrackingEvents = (status: string): TrackingEvent[] => {
     });
   }
 
-  if (['out_for_delivery', 'delivered'].includes(status)) {
+  if (['out_for_delivery', 'delivered', 'completed'].includes(status)) {
     events.push({
       id: '4',
       status: 'out_for_delivery',
@@ -52,7 +52,7 @@ export const createMockTrackingEvents = (status: string): TrackingEvent[] => {
     });
   }
 
-  if (status === 'delivered') {
+  if (['delivered', 'completed'].includes(status)) {
     events.push({
       id: '5',
       status: 'delivered',